@@ -15,18 +15,35 @@ import (
 )
 
 var extLanguageMap = map[string]string{
-	".c":   "C",
-	".cpp": "C++",
-	".cs":  "C#",
-	".go":  "Go",
+	".c":      "C",
+	".h":      "C",
+	".cc":     "C++",
+	".cpp":    "C++",
+	".hpp":    "C++",
+	".cs":     "C#",
+	".ex":     "Elixir",
+	".exs":    "Elixir",
+	".go":     "Go",
+	".groovy": "Groovy",
 	//"hcl":  "HCL",
 	".java": "Java",
 	".js":   "JavaScript",
+	".jsx":  "JavaScript",
+	".mjs":  "JavaScript",
 	//"json": "JSON",
+	".kt":  "Kotlin",
+	".kts": "Kotlin",
 	//"md":   "Markdown",
-	".py": "Python",
-	".sh": "Shell",
+	".php":   "PHP",
+	".py":    "Python",
+	".rb":    "Ruby",
+	".rs":    "Rust",
+	".scala": "Scala",
+	".sh":    "Shell",
+	".swift": "Swift",
 	//"toml": "TOML",
+	".ts":  "TypeScript",
+	".tsx": "TypeScript",
 	//"yaml": "YAML",
 }
 
@@ -0,0 +1,436 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package analysis
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// detector detects a service from any of its manifests
+type detector struct {
+	manifests []string
+	lang      string
+	detect    func(dir string, svc *Service)
+}
+
+// detectors in order of precedence when a directory has several manifests
+var detectors = []detector{
+	{[]string{"go.mod"}, "go", detectGo},
+	{[]string{"Cargo.toml"}, "rust", detectRust},
+	{[]string{"pom.xml"}, "java", detectMaven},
+	{[]string{"build.gradle", "build.gradle.kts"}, "java", detectGradle},
+	{[]string{"package.json"}, "javascript", detectNode},
+	{[]string{"pyproject.toml", "requirements.txt"}, "python", detectPython},
+	{[]string{"Gemfile"}, "ruby", detectRuby},
+}
+
+// Frameworks by dependency name per language
+var (
+	goFrameworks = map[string]string{
+		"github.com/gin-gonic/gin":    "gin",
+		"github.com/labstack/echo":    "echo",
+		"github.com/gorilla/mux":      "gorilla",
+		"github.com/gofiber/fiber":    "fiber",
+		"github.com/go-chi/chi":       "chi",
+		"google.golang.org/grpc":      "grpc",
+		"github.com/valyala/fasthttp": "fasthttp",
+	}
+	rustFrameworks = map[string]string{
+		"actix-web": "actix-web",
+		"rocket":    "rocket",
+		"axum":      "axum",
+		"warp":      "warp",
+		"tide":      "tide",
+		"tonic":     "grpc",
+	}
+	nodeFrameworks = map[string]string{
+		"express":       "express",
+		"koa":           "koa",
+		"fastify":       "fastify",
+		"hapi":          "hapi",
+		"@hapi/hapi":    "hapi",
+		"@nestjs/core":  "nestjs",
+		"next":          "next",
+		"react":         "react",
+		"vue":           "vue",
+		"@angular/core": "angular",
+		"typescript":    "typescript",
+	}
+	pythonFrameworks = map[string]string{
+		"django":  "django",
+		"flask":   "flask",
+		"fastapi": "fastapi",
+		"tornado": "tornado",
+		"aiohttp": "aiohttp",
+	}
+	rubyFrameworks = map[string]string{
+		"rails":   "rails",
+		"sinatra": "sinatra",
+		"hanami":  "hanami",
+	}
+)
+
+var (
+	versionRe     = regexp.MustCompile(`\d+(\.\d+)*`)
+	goDirectiveRe = regexp.MustCompile(`(?m)^go\s+(\S+)`)
+	goMainRe      = regexp.MustCompile(`(?m)^package main\b`)
+	rustVersionRe = regexp.MustCompile(`(?m)^rust-version\s*=\s*"([^"]+)"`)
+	tomlSectionRe = regexp.MustCompile(`^\[([^\]]+)\]`)
+	tomlKeyRe     = regexp.MustCompile(`^"?([A-Za-z0-9_.\-]+)"?\s*=`)
+	gradleJavaRe  = regexp.MustCompile(`(?:sourceCompatibility\s*=\s*['"]?(?:JavaVersion\.VERSION_)?([0-9_.]+)|JavaLanguageVersion\.of\((\d+)\))`)
+	javaMainRe    = regexp.MustCompile(`static\s+void\s+main\s*\(`)
+	pyRequiresRe  = regexp.MustCompile(`(?m)^(?:requires-python|python)\s*=\s*"([^"]+)"`)
+	pyReqNameRe   = regexp.MustCompile(`^([A-Za-z0-9_.\-]+)`)
+	pyDepRe       = regexp.MustCompile(`"([A-Za-z0-9_.\-]+)`)
+	rubyVersionRe = regexp.MustCompile(`(?m)^ruby\s+['"]([^'"]+)['"]`)
+	gemRe         = regexp.MustCompile(`(?m)^\s*gem\s+['"]([^'"]+)['"]`)
+	nodeStartRe   = regexp.MustCompile(`\bnode\s+(\S+\.[cm]?js)\b`)
+)
+
+// cleanVersion returns the first version in a constraint i.e. >=1.10 or
+// ^3.8 yielding 1.10 and 3.8
+func cleanVersion(s string) string {
+	return versionRe.FindString(s)
+}
+
+// addFramework adds the framework of the dependency if known
+func addFramework(svc *Service, frameworks map[string]string, dep string) {
+	if f, ok := frameworks[dep]; ok {
+		appendFramework(svc, f)
+	}
+}
+
+func appendFramework(svc *Service, f string) {
+	for _, v := range svc.Frameworks {
+		if v == f {
+			return
+		}
+	}
+	svc.Frameworks = append(svc.Frameworks, f)
+}
+
+// readVersionFile returns the version in the first of the files found i.e.
+// .python-version
+func readVersionFile(dir string, files ...string) string {
+	for _, f := range files {
+		b, err := ioutil.ReadFile(filepath.Join(dir, f))
+		if err == nil {
+			return cleanVersion(string(b))
+		}
+	}
+	return ""
+}
+
+func detectGo(dir string, svc *Service) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return
+	}
+	if m := goDirectiveRe.FindSubmatch(b); m != nil {
+		svc.Version = string(m[1])
+	}
+
+	// Matches require blocks and single line requires
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) > 1 && fields[0] == "require" {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		mod := fields[0]
+		// Strip major version suffixes i.e. /v4
+		if i := strings.LastIndex(mod, "/v"); i > 0 && versionRe.MatchString(mod[i+2:]) {
+			mod = mod[:i]
+		}
+		addFramework(svc, goFrameworks, mod)
+	}
+
+	svc.EntryPoint = goEntryPoint(dir)
+}
+
+// goEntryPoint returns the directory of the main package preferring the root
+// over cmd/<name>
+func goEntryPoint(dir string) string {
+	if isGoMain(dir) {
+		return "."
+	}
+	infos, err := ioutil.ReadDir(filepath.Join(dir, "cmd"))
+	if err != nil {
+		return ""
+	}
+	for _, info := range infos {
+		if info.IsDir() && isGoMain(filepath.Join(dir, "cmd", info.Name())) {
+			return "cmd/" + info.Name()
+		}
+	}
+	return ""
+}
+
+func isGoMain(dir string) bool {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, f := range files {
+		if strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		b, err := ioutil.ReadFile(f)
+		if err == nil && goMainRe.Match(b) && bytes.Contains(b, []byte("func main(")) {
+			return true
+		}
+	}
+	return false
+}
+
+func detectRust(dir string, svc *Service) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, "Cargo.toml"))
+	if err != nil {
+		return
+	}
+	if m := rustVersionRe.FindSubmatch(b); m != nil {
+		svc.Version = string(m[1])
+	} else {
+		svc.Version = readVersionFile(dir, "rust-toolchain")
+	}
+
+	for _, dep := range tomlDeps(b, "dependencies") {
+		addFramework(svc, rustFrameworks, dep)
+	}
+	svc.EntryPoint = firstExisting(dir, "src/main.rs")
+}
+
+// tomlDeps returns the keys of the toml section and its subsections i.e.
+// dependencies and dependencies.foo
+func tomlDeps(b []byte, section string) []string {
+	var (
+		out []string
+		in  bool
+	)
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if m := tomlSectionRe.FindStringSubmatch(line); m != nil {
+			name := m[1]
+			in = name == section || strings.HasSuffix(name, "."+section)
+			if strings.HasPrefix(name, section+".") {
+				out = append(out, strings.TrimPrefix(name, section+"."))
+			}
+			continue
+		}
+		if !in {
+			continue
+		}
+		if m := tomlKeyRe.FindStringSubmatch(line); m != nil {
+			out = append(out, m[1])
+		}
+	}
+	return out
+}
+
+type mavenDep struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+}
+
+type mavenPom struct {
+	Parent     mavenDep `xml:"parent"`
+	Properties struct {
+		JavaVersion     string `xml:"java.version"`
+		CompilerRelease string `xml:"maven.compiler.release"`
+		CompilerSource  string `xml:"maven.compiler.source"`
+	} `xml:"properties"`
+	Dependencies []mavenDep `xml:"dependencies>dependency"`
+}
+
+func detectMaven(dir string, svc *Service) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, "pom.xml"))
+	if err != nil {
+		return
+	}
+	var pom mavenPom
+	if err = xml.Unmarshal(b, &pom); err != nil {
+		return
+	}
+
+	for _, v := range []string{pom.Properties.JavaVersion, pom.Properties.CompilerRelease, pom.Properties.CompilerSource} {
+		if v != "" {
+			svc.Version = v
+			break
+		}
+	}
+
+	for _, dep := range append(pom.Dependencies, pom.Parent) {
+		addJavaFramework(svc, dep.GroupID, dep.ArtifactID)
+	}
+	svc.EntryPoint = javaEntryPoint(dir)
+}
+
+func detectGradle(dir string, svc *Service) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, svc.Manifest))
+	if err != nil {
+		return
+	}
+	if m := gradleJavaRe.FindSubmatch(b); m != nil {
+		v := string(m[1])
+		if v == "" {
+			v = string(m[2])
+		}
+		svc.Version = strings.Replace(v, "_", ".", -1)
+	}
+
+	s := string(b)
+	if strings.Contains(s, "org.springframework.boot") {
+		addJavaFramework(svc, "org.springframework.boot", "")
+	}
+	if strings.Contains(s, "io.quarkus") {
+		addJavaFramework(svc, "io.quarkus", "")
+	}
+	if strings.Contains(s, "io.micronaut") {
+		addJavaFramework(svc, "io.micronaut", "")
+	}
+	svc.EntryPoint = javaEntryPoint(dir)
+}
+
+func addJavaFramework(svc *Service, group, artifact string) {
+	switch {
+	case group == "org.springframework.boot" || strings.HasPrefix(artifact, "spring-boot"):
+		appendFramework(svc, "spring-boot")
+	case strings.HasPrefix(group, "io.quarkus"):
+		appendFramework(svc, "quarkus")
+	case strings.HasPrefix(group, "io.micronaut"):
+		appendFramework(svc, "micronaut")
+	}
+}
+
+// javaEntryPoint returns the first source file under src/main declaring a
+// main method
+func javaEntryPoint(dir string) string {
+	var out string
+	root := filepath.Join(dir, "src", "main")
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || out != "" {
+			return filepath.SkipDir
+		}
+		if info.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".java", ".kt":
+		default:
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err == nil && javaMainRe.Match(b) {
+			out, _ = filepath.Rel(dir, path)
+			out = filepath.ToSlash(out)
+		}
+		return nil
+	})
+	return out
+}
+
+type packageJSON struct {
+	Main    string `json:"main"`
+	Engines struct {
+		Node string `json:"node"`
+	} `json:"engines"`
+	Scripts         map[string]string `json:"scripts"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+func detectNode(dir string, svc *Service) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return
+	}
+	var pkg packageJSON
+	if err = json.Unmarshal(b, &pkg); err != nil {
+		return
+	}
+
+	svc.Version = cleanVersion(pkg.Engines.Node)
+	if svc.Version == "" {
+		svc.Version = readVersionFile(dir, ".nvmrc", ".node-version")
+	}
+
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies} {
+		names := make([]string, 0, len(deps))
+		for k := range deps {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			addFramework(svc, nodeFrameworks, k)
+		}
+	}
+
+	switch {
+	case pkg.Main != "" && fileExists(filepath.Join(dir, pkg.Main)):
+		svc.EntryPoint = pkg.Main
+	case nodeStartRe.MatchString(pkg.Scripts["start"]):
+		svc.EntryPoint = nodeStartRe.FindStringSubmatch(pkg.Scripts["start"])[1]
+	default:
+		svc.EntryPoint = firstExisting(dir, "server.js", "index.js", "app.js", "src/index.js", "src/index.ts", "src/main.ts")
+	}
+}
+
+func detectPython(dir string, svc *Service) {
+	if b, err := ioutil.ReadFile(filepath.Join(dir, "pyproject.toml")); err == nil {
+		if m := pyRequiresRe.FindSubmatch(b); m != nil {
+			svc.Version = cleanVersion(string(m[1]))
+		}
+		// Matches pep 621 dependency lists and poetry dependency tables
+		for _, dep := range tomlDeps(b, "dependencies") {
+			addFramework(svc, pythonFrameworks, strings.ToLower(dep))
+		}
+		for _, m := range pyDepRe.FindAllSubmatch(b, -1) {
+			addFramework(svc, pythonFrameworks, strings.ToLower(string(m[1])))
+		}
+	}
+
+	if b, err := ioutil.ReadFile(filepath.Join(dir, "requirements.txt")); err == nil {
+		s := bufio.NewScanner(bytes.NewReader(b))
+		for s.Scan() {
+			if m := pyReqNameRe.FindStringSubmatch(strings.TrimSpace(s.Text())); m != nil {
+				addFramework(svc, pythonFrameworks, strings.ToLower(m[1]))
+			}
+		}
+	}
+
+	if svc.Version == "" {
+		svc.Version = readVersionFile(dir, ".python-version", "runtime.txt")
+	}
+	svc.EntryPoint = firstExisting(dir, "manage.py", "app.py", "main.py", "wsgi.py", "app/main.py", "src/main.py")
+}
+
+func detectRuby(dir string, svc *Service) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, "Gemfile"))
+	if err != nil {
+		return
+	}
+	if m := rubyVersionRe.FindSubmatch(b); m != nil {
+		svc.Version = cleanVersion(string(m[1]))
+	} else {
+		svc.Version = readVersionFile(dir, ".ruby-version")
+	}
+
+	for _, m := range gemRe.FindAllSubmatch(b, -1) {
+		addFramework(svc, rubyFrameworks, string(m[1]))
+	}
+	svc.EntryPoint = firstExisting(dir, "config.ru", "app.rb", "main.rb")
+}
@@ -0,0 +1,142 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package analysis
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxPortScanFiles is the max number of files scanned per service
+	maxPortScanFiles = 500
+	// maxPortScanSize is the max size of a file scanned for ports
+	maxPortScanSize = 1 << 20
+)
+
+// Patterns matching the port a service listens on i.e. port = 8080,
+// PORT || 3000, ListenAndServe(":8080"), listen(3000), bind("0.0.0.0:8080")
+// and EXPOSE 8080
+var portPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bport\b["']?\s*[:=]\s*["']?(\d{2,5})\b`),
+	regexp.MustCompile(`(?i)\bport\s*(?:\|\||\?\?|or)\s*["']?(\d{2,5})\b`),
+	regexp.MustCompile(`(?i)\b(?:listen\w*|run|bind)\(\s*["'][\w.\-]*:(\d{2,5})["']`),
+	regexp.MustCompile(`(?i)\blisten\(\s*(\d{2,5})\b`),
+	regexp.MustCompile(`(?im)^\s*EXPOSE\s+(\d{2,5})\b`),
+}
+
+// Configuration files scanned for ports in addition to sources
+var portConfigExts = map[string]bool{
+	".properties": true,
+	".yml":        true,
+	".yaml":       true,
+	".toml":       true,
+	".env":        true,
+}
+
+// Ports frameworks listen on by default
+var frameworkPorts = map[string]int32{
+	"spring-boot": 8080,
+	"quarkus":     8080,
+	"micronaut":   8080,
+	"django":      8000,
+	"fastapi":     8000,
+	"flask":       5000,
+	"rails":       3000,
+	"sinatra":     4567,
+	"next":        3000,
+	"react":       3000,
+	"angular":     4200,
+	"actix-web":   8080,
+	"rocket":      8000,
+}
+
+// scanPorts returns the ports found in the service sources, configuration
+// and dockerfiles.  Directories of other services in roots are skipped
+func scanPorts(root string, svc *Service, roots map[string]bool) []int32 {
+	var (
+		dir   = filepath.Join(root, svc.Dir)
+		found = make(map[int32]bool)
+		n     int
+	)
+
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path == dir {
+				return nil
+			}
+			rel, _ := filepath.Rel(root, path)
+			if isIgnoredDir(info.Name()) || roots[rel] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if n >= maxPortScanFiles {
+			return filepath.SkipDir
+		}
+		if !isPortScanFile(info) {
+			return nil
+		}
+		n++
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		for _, re := range portPatterns {
+			for _, m := range re.FindAllSubmatch(b, -1) {
+				if p, err := strconv.ParseInt(string(m[1]), 10, 32); err == nil && p > 0 && p < 65536 {
+					found[int32(p)] = true
+				}
+			}
+		}
+		return nil
+	})
+
+	out := make([]int32, 0, len(found))
+	for p := range found {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func isPortScanFile(info os.FileInfo) bool {
+	if info.Size() > maxPortScanSize || strings.HasSuffix(info.Name(), "_test.go") {
+		return false
+	}
+	name := info.Name()
+	if name == "Dockerfile" || strings.HasSuffix(name, ".dockerfile") || name == ".env" {
+		return true
+	}
+
+	ext := filepath.Ext(name)
+	if portConfigExts[ext] {
+		return true
+	}
+	_, ok := extLanguageMap[ext]
+	return ok
+}
+
+// defaultPorts returns the default port of the first framework of the
+// service that has one
+func defaultPorts(svc *Service) []int32 {
+	for _, f := range svc.Frameworks {
+		if p, ok := frameworkPorts[f]; ok {
+			return []int32{p}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package analysis
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxServiceDepth is the directory depth below the project root searched for
+// services
+const maxServiceDepth = 3
+
+// Directories never containing services or sources of interest
+var ignoredDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"dist":         true,
+	"build":        true,
+	"venv":         true,
+	"__pycache__":  true,
+	"testdata":     true,
+}
+
+// Service is a buildable unit detected from a language manifest
+type Service struct {
+	// Directory relative to the project root.  "." for the root
+	Dir string
+	// Manifest file the service was detected from i.e. go.mod
+	Manifest string
+	// Lower cased language as returned by EstimateLanguage
+	Language string
+	// Language version declared by the service if any
+	Version string
+	// Frameworks found in the service dependencies
+	Frameworks []string
+	// Ports the service likely listens on
+	Ports []int32
+	// Entry point relative to Dir if found
+	EntryPoint string
+}
+
+// HasFramework returns true if the framework was detected for the service
+func (svc *Service) HasFramework(name string) bool {
+	for _, f := range svc.Frameworks {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Project is the result of analyzing a project directory
+type Project struct {
+	Dir string
+	// Services sorted by directory.  The root service is first if any
+	Services []*Service
+}
+
+// Root returns the service at the root of the project or nil
+func (p *Project) Root() *Service {
+	if len(p.Services) > 0 && p.Services[0].Dir == "." {
+		return p.Services[0]
+	}
+	return nil
+}
+
+// Analyze detects the services in dir from their language manifests i.e.
+// go.mod, package.json, pom.xml etc.  Manifests in subdirectories are
+// separate services unless they belong to an enclosing service of the same
+// language with an entry point.  Enclosing services without one are treated
+// as aggregators i.e. maven parents or cargo workspaces and dropped
+func Analyze(dir string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	proj := &Project{Dir: dir}
+	err = walkDirs(dir, maxServiceDepth, func(rel string) {
+		if svc := detectService(dir, rel); svc != nil {
+			proj.Services = append(proj.Services, svc)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	proj.Services = dropNested(proj.Services)
+
+	roots := make(map[string]bool, len(proj.Services))
+	for _, svc := range proj.Services {
+		roots[svc.Dir] = true
+	}
+	for _, svc := range proj.Services {
+		if len(svc.Ports) == 0 {
+			svc.Ports = scanPorts(dir, svc, roots)
+		}
+		if len(svc.Ports) == 0 {
+			svc.Ports = defaultPorts(svc)
+		}
+	}
+
+	return proj, nil
+}
+
+// walkDirs calls f with the path relative to root of root and each directory
+// below it up to depth skipping hidden and ignored directories
+func walkDirs(root string, depth int, f func(rel string)) error {
+	var walk func(rel string, d int) error
+	walk = func(rel string, d int) error {
+		f(rel)
+		if d == depth {
+			return nil
+		}
+
+		infos, err := ioutil.ReadDir(filepath.Join(root, rel))
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			if !info.IsDir() || isIgnoredDir(info.Name()) {
+				continue
+			}
+			if err = walk(filepath.Join(rel, info.Name()), d+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(".", 0)
+}
+
+func isIgnoredDir(name string) bool {
+	return strings.HasPrefix(name, ".") || ignoredDirs[name]
+}
+
+// dropNested removes services enclosed by a service of the same language
+// with an entry point, and enclosing services without one
+func dropNested(in []*Service) []*Service {
+	drop := make(map[*Service]bool)
+	for _, svc := range in {
+		parent := enclosing(in, svc)
+		if parent == nil {
+			continue
+		}
+		if parent.EntryPoint != "" {
+			drop[svc] = true
+		} else {
+			drop[parent] = true
+		}
+	}
+
+	out := make([]*Service, 0, len(in))
+	for _, svc := range in {
+		if !drop[svc] {
+			out = append(out, svc)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Dir == "." || out[j].Dir == "." {
+			return out[i].Dir == "."
+		}
+		return out[i].Dir < out[j].Dir
+	})
+	return out
+}
+
+// enclosing returns the closest service of the same language enclosing svc
+func enclosing(services []*Service, svc *Service) *Service {
+	var out *Service
+	for _, other := range services {
+		if other == svc || other.Language != svc.Language || !isSubdir(other.Dir, svc.Dir) {
+			continue
+		}
+		if out == nil || len(other.Dir) > len(out.Dir) {
+			out = other
+		}
+	}
+	return out
+}
+
+// isSubdir returns true if the relative path child is below parent
+func isSubdir(parent, child string) bool {
+	if parent == "." {
+		return child != "."
+	}
+	return strings.HasPrefix(child, parent+string(filepath.Separator))
+}
+
+// detectService returns the service in the directory rel of root based on
+// the first manifest found.  It returns nil if none is found
+func detectService(root, rel string) *Service {
+	dir := filepath.Join(root, rel)
+	for _, d := range detectors {
+		for _, name := range d.manifests {
+			if !fileExists(filepath.Join(dir, name)) {
+				continue
+			}
+			svc := &Service{Dir: rel, Manifest: name, Language: d.lang}
+			d.detect(dir, svc)
+			sort.Strings(svc.Frameworks)
+			return svc
+		}
+	}
+	return nil
+}
+
+func fileExists(fpath string) bool {
+	info, err := os.Stat(fpath)
+	return err == nil && !info.IsDir()
+}
+
+// firstExisting returns the first of the files existing in dir
+func firstExisting(dir string, files ...string) string {
+	for _, f := range files {
+		if fileExists(filepath.Join(dir, filepath.FromSlash(f))) {
+			return f
+		}
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package analysis
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestTree(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "analysis-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		fpath := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(fpath), 0755)
+		if err = ioutil.WriteFile(fpath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func Test_Analyze(t *testing.T) {
+	dir := writeTestTree(t, map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.11
+
+require (
+	github.com/gin-gonic/gin v1.3.0
+	google.golang.org/grpc v1.14.0
+)
+`,
+		"cmd/app/main.go": "package main\n\nfunc main() {\n\tr.Run(\":9090\")\n}\n",
+		"web/package.json": `{
+  "engines": {"node": ">=10.4"},
+  "scripts": {"start": "node server.js"},
+  "dependencies": {"express": "^4.16.0"}
+}`,
+		"web/server.js":                           "app.listen(process.env.PORT || 3000)\n",
+		"web/node_modules/x/x.js":                 "app.listen(4000)\n",
+		"services/billing/pom.xml":                pomXML,
+		"services/billing/src/main/java/App.java": "class App { public static void main(String[] args) {} }\n",
+		"worker/requirements.txt":                 "Flask==1.0.2\nrequests\n",
+		"worker/app.py":                           "app.run()\n",
+	})
+	defer os.RemoveAll(dir)
+
+	proj, err := Analyze(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(proj.Services))
+
+	root := proj.Root()
+	assert.NotNil(t, root)
+	assert.Equal(t, "go", root.Language)
+	assert.Equal(t, "1.11", root.Version)
+	assert.Equal(t, []string{"gin", "grpc"}, root.Frameworks)
+	assert.Equal(t, "cmd/app", root.EntryPoint)
+	assert.Equal(t, []int32{9090}, root.Ports)
+
+	billing := proj.Services[1]
+	assert.Equal(t, filepath.FromSlash("services/billing"), billing.Dir)
+	assert.Equal(t, "java", billing.Language)
+	assert.Equal(t, "1.8", billing.Version)
+	assert.Equal(t, []string{"spring-boot"}, billing.Frameworks)
+	assert.Equal(t, "src/main/java/App.java", billing.EntryPoint)
+	assert.Equal(t, []int32{8080}, billing.Ports)
+
+	web := proj.Services[2]
+	assert.Equal(t, "javascript", web.Language)
+	assert.Equal(t, "10.4", web.Version)
+	assert.Equal(t, "server.js", web.EntryPoint)
+	assert.Equal(t, []int32{3000}, web.Ports)
+
+	worker := proj.Services[3]
+	assert.Equal(t, "python", worker.Language)
+	assert.True(t, worker.HasFramework("flask"))
+	assert.Equal(t, "app.py", worker.EntryPoint)
+	assert.Equal(t, []int32{5000}, worker.Ports)
+}
+
+func Test_Analyze_nested(t *testing.T) {
+	// Aggregator without an entry point is dropped in favor of its modules
+	dir := writeTestTree(t, map[string]string{
+		"Cargo.toml":         "[workspace]\nmembers = [\"api\", \"cli\"]\n",
+		"api/Cargo.toml":     "[package]\nname = \"api\"\n\n[dependencies]\naxum = \"0.6\"\n",
+		"api/src/main.rs":    "fn main() { bind(\"0.0.0.0:7000\") }\n",
+		"api/lib/Cargo.toml": "[package]\nname = \"lib\"\n",
+		"cli/Cargo.toml":     "[package]\nname = \"cli\"\nrust-version = \"1.60\"\n",
+		"cli/src/main.rs":    "fn main() {}\n",
+		"docs/conf/.keep":    "",
+		".hidden/Gemfile":    "gem 'rails'\n",
+	})
+	defer os.RemoveAll(dir)
+
+	proj, err := Analyze(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, proj.Root())
+	assert.Equal(t, 2, len(proj.Services))
+
+	assert.Equal(t, "api", proj.Services[0].Dir)
+	assert.Equal(t, []string{"axum"}, proj.Services[0].Frameworks)
+	assert.Equal(t, []int32{7000}, proj.Services[0].Ports)
+	assert.Equal(t, "cli", proj.Services[1].Dir)
+	assert.Equal(t, "1.60", proj.Services[1].Version)
+	assert.Equal(t, 0, len(proj.Services[1].Ports))
+}
+
+func Test_EstimateLanguage_manifest(t *testing.T) {
+	dir := writeTestTree(t, map[string]string{
+		"Gemfile":  "source 'https://rubygems.org'\nruby '2.5.1'\ngem 'sinatra'\n",
+		"a.sh":     "",
+		"b.sh":     "",
+		"c.sh":     "",
+		"app.rb":   "",
+		"build.sh": "",
+	})
+	defer os.RemoveAll(dir)
+
+	assert.Equal(t, "ruby", EstimateLanguage(dir))
+}
+
+const pomXML = `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+  </parent>
+  <properties>
+    <java.version>1.8</java.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>org.springframework.boot</groupId>
+      <artifactId>spring-boot-starter-web</artifactId>
+    </dependency>
+  </dependencies>
+</project>
+`
@@ -12,7 +12,14 @@ import (
 
 // EstimateLanguage estimates the programming language by percent of file types
 // present in dir.  If less than 50% an empty string is returned
+// EstimateLanguage returns the language of the manifest at the root of dir
+// i.e. go.mod.  Otherwise it returns the language of more than half the
+// files if any
 func EstimateLanguage(dir string) string {
+	if svc := detectService(dir, "."); svc != nil {
+		return svc.Language
+	}
+
 	fts := BuildFileTypeSpread(dir)
 	highest := fts.Highest()
 	if highest != nil && highest.Percent > 50 {
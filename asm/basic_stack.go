@@ -7,6 +7,7 @@ Sniperkit-Bot
 package asm
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/sniperkit/snk.fork.thrap/consts"
@@ -15,13 +16,21 @@ import (
 	"github.com/sniperkit/snk.fork.thrap/vars"
 )
 
+var (
+	errComponentExists = errors.New("component already exists")
+	errComponentKind   = errors.New("exactly one of language, datastore or web server required")
+)
+
 // BasicStackConfig holds a configurations to build canned stacks.  Currently
-// supports a dev, datastore and web component
+// supports a dev, datastore and web component.  The default dev component is
+// omitted if no language is set and components are provided
 type BasicStackConfig struct {
 	Name      string
 	Language  thrapb.LanguageID
 	DataStore string
 	WebServer string
+	// Additional components i.e. services detected in an existing project
+	Components []*ComponentConfig
 }
 
 // NewBasicStack builds a skeleton stack inferring as much information as
@@ -34,9 +43,12 @@ func NewBasicStack(c *BasicStackConfig, pks *packs.Packs) (*thrapb.Stack, error)
 		Name: c.Name,
 	}
 
-	devComp := makeDevComp(consts.DefaultAPICompID, c.Language)
-	comps := map[string]*thrapb.Component{
-		consts.DefaultAPICompID: devComp,
+	comps := make(map[string]*thrapb.Component, 3+len(c.Components))
+
+	var devComp *thrapb.Component
+	if c.Language != "" || len(c.Components) == 0 {
+		devComp = makeDevComp(consts.DefaultAPICompID, c.Language)
+		comps[consts.DefaultAPICompID] = devComp
 	}
 
 	var dsComp *thrapb.Component
@@ -51,9 +63,11 @@ func NewBasicStack(c *BasicStackConfig, pks *packs.Packs) (*thrapb.Stack, error)
 		comps[consts.DefaultDSCompID] = dsComp
 
 		// Add env var for host, port and addr to dev component
-		ev := defaultCompEnvVars(consts.DefaultDSCompID)
-		for k, v := range ev {
-			devComp.Env.Vars[k] = v
+		if devComp != nil {
+			ev := defaultCompEnvVars(consts.DefaultDSCompID)
+			for k, v := range ev {
+				devComp.Env.Vars[k] = v
+			}
 		}
 	}
 
@@ -68,16 +82,183 @@ func NewBasicStack(c *BasicStackConfig, pks *packs.Packs) (*thrapb.Stack, error)
 		comps[consts.DefaultWebCompID] = wsComp
 
 		// Add env var for host, port and addr of dev to web component (the just created)
-		wsComp.Env = &thrapb.Envionment{
-			Vars: defaultCompEnvVars(consts.DefaultAPICompID),
+		wsComp.Env = &thrapb.Envionment{Vars: make(map[string]string)}
+		if devComp != nil {
+			wsComp.Env.Vars = defaultCompEnvVars(consts.DefaultAPICompID)
 		}
 	}
 
 	stack.Components = comps
 
+	// Additional components are wired to the above by AddComponent
+	for _, cc := range c.Components {
+		if _, err := AddComponent(&stack, cc, pks); err != nil {
+			return nil, err
+		}
+	}
+
 	return &stack, nil
 }
 
+// ComponentConfig holds the configuration to add a single component to an
+// existing stack.  Only one of Language, DataStore or WebServer may be set
+type ComponentConfig struct {
+	ID        string
+	Language  thrapb.LanguageID
+	DataStore string
+	WebServer string
+	// Build context of a dev component.  Defaults to the root for the first
+	// dev component and the id for others
+	Context string
+	// Ports the component listens on
+	Ports []int32
+}
+
+// AddComponent adds a component to an existing stack wiring env vars between
+// it and the existing components the same way NewBasicStack does i.e. dev
+// components get the vars of datastores and web servers get the vars of dev
+// components.  Existing vars are left untouched.  It returns the vars added
+// to each existing component by id
+func AddComponent(stack *thrapb.Stack, c *ComponentConfig, pks *packs.Packs) (map[string]map[string]string, error) {
+	if _, ok := stack.Components[c.ID]; ok {
+		return nil, errComponentExists
+	}
+
+	var set int
+	for _, v := range []string{string(c.Language), c.DataStore, c.WebServer} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, errComponentKind
+	}
+
+	var (
+		comp *thrapb.Component
+		// env vars to add to existing components
+		deps = make(map[string]map[string]string)
+	)
+
+	switch {
+	case c.Language != "":
+		devpack, err := pks.Dev().Load(c.Language.Lang())
+		if err != nil {
+			return nil, err
+		}
+		lang := c.Language
+		if lang.Version() == "" {
+			lang = thrapb.LanguageID(devpack.Name + ":" + devpack.DefaultVersion)
+		}
+
+		comp = makeDevComp(c.ID, lang)
+		comp.Head = !hasHeadComp(stack)
+		// Additional dev components get their own build context so their
+		// files do not collide with existing ones
+		if c.Context != "" {
+			comp.Build.Context = c.Context
+		} else if hasDevComp(stack) {
+			comp.Build.Context = c.ID
+		}
+
+		for id, other := range stack.Components {
+			switch other.Type {
+			case thrapb.CompTypeDatastore:
+				for k, v := range defaultCompEnvVars(id) {
+					comp.Env.Vars[k] = v
+				}
+			case thrapb.CompTypeWeb:
+				deps[id] = defaultCompEnvVars(c.ID)
+			}
+		}
+
+	case c.DataStore != "":
+		ds, err := pks.Datastore().Load(c.DataStore)
+		if err != nil {
+			return nil, err
+		}
+		comp = thrapb.NewComponent(ds.Image, ds.DefaultVersion, thrapb.CompTypeDatastore)
+
+		for id, other := range stack.Components {
+			if isDevComp(other) {
+				deps[id] = defaultCompEnvVars(c.ID)
+			}
+		}
+
+	default:
+		ws, err := pks.Web().Load(c.WebServer)
+		if err != nil {
+			return nil, err
+		}
+		comp = thrapb.NewComponent(ws.Image, ws.DefaultVersion, thrapb.CompTypeWeb)
+		comp.Env = &thrapb.Envionment{Vars: make(map[string]string)}
+
+		for id, other := range stack.Components {
+			if isDevComp(other) {
+				for k, v := range defaultCompEnvVars(id) {
+					comp.Env.Vars[k] = v
+				}
+			}
+		}
+	}
+	comp.ID = c.ID
+	if len(c.Ports) > 0 {
+		comp.Ports = thrapb.PortLabels(c.Ports)
+	}
+
+	added := make(map[string]map[string]string, len(deps))
+	for id, ev := range deps {
+		other := stack.Components[id]
+		if other.Env == nil {
+			other.Env = &thrapb.Envionment{}
+		}
+		if other.Env.Vars == nil {
+			other.Env.Vars = make(map[string]string, len(ev))
+		}
+
+		for k, v := range ev {
+			if _, ok := other.Env.Vars[k]; ok {
+				continue
+			}
+			other.Env.Vars[k] = v
+			if added[id] == nil {
+				added[id] = make(map[string]string)
+			}
+			added[id][k] = v
+		}
+	}
+
+	if stack.Components == nil {
+		stack.Components = make(map[string]*thrapb.Component, 1)
+	}
+	stack.Components[c.ID] = comp
+
+	return added, nil
+}
+
+// isDevComp returns true if the component is built from source in the stack
+func isDevComp(comp *thrapb.Component) bool {
+	return comp.Type == thrapb.CompTypeAPI && comp.IsBuildable()
+}
+
+func hasDevComp(stack *thrapb.Stack) bool {
+	for _, comp := range stack.Components {
+		if isDevComp(comp) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasHeadComp(stack *thrapb.Stack) bool {
+	for _, comp := range stack.Components {
+		if comp.Head {
+			return true
+		}
+	}
+	return false
+}
+
 func defaultCompEnvVars(pre string) map[string]string {
 	upre := strings.ToUpper(pre)
 	return map[string]string{
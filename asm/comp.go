@@ -9,6 +9,7 @@ package asm
 import (
 	"io/ioutil"
 	"path/filepath"
+	"sort"
 
 	"github.com/euforia/pseudo"
 	"github.com/euforia/pseudo/scope"
@@ -98,7 +99,9 @@ func (asm *BuildCompAsm) Assemble(variables scope.Variables) (err error) {
 	}
 
 	// Add all env vars as build args
-	asm.addArgAndEnvToDockerfile()
+	if err = asm.addArgAndEnvToDockerfile(); err != nil {
+		return err
+	}
 
 	if asm.comp.HasSecrets() {
 		// Local file
@@ -127,26 +130,46 @@ func (asm *BuildCompAsm) loadDockerfile(fpath string) error {
 	return nil
 }
 
-func (asm *BuildCompAsm) addArgAndEnvToDockerfile() {
+// addArgAndEnvToDockerfile adds the component env vars as build args and
+// env vars to the build stage and as env vars to the final stage
+func (asm *BuildCompAsm) addArgAndEnvToDockerfile() error {
+	if !asm.comp.HasEnvVars() {
+		return nil
+	}
+
 	cenv := asm.comp.Env
-	ei := &dockerfile.Env{Vars: make(map[string]string, len(cenv.Vars))}
+	keys := make([]string, 0, len(cenv.Vars))
 	for k := range cenv.Vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ei := &dockerfile.Env{Vars: make(map[string]string, len(keys))}
+	for _, k := range keys {
 		ei.Vars[k] = "${" + k + "}"
 	}
-	asm.dockerfile.AddInstruction(0, ei)
+	if err := asm.dockerfile.AddInstruction(0, ei); err != nil {
+		return err
+	}
 
-	// Add all env vars as build args to build stage
-	for k := range cenv.Vars {
-		ai := &dockerfile.Arg{Name: k}
-		asm.dockerfile.AddInstruction(0, ai)
+	// Add all env vars as build args to build stage.  Each is inserted
+	// after FROM so they are added in reverse to keep them sorted
+	for i := len(keys) - 1; i >= 0; i-- {
+		ai := &dockerfile.Arg{Name: keys[i]}
+		if err := asm.dockerfile.AddInstruction(0, ai); err != nil {
+			return err
+		}
 	}
 
 	// Add all env vars to the final image artifact
-	pei := &dockerfile.Env{Vars: make(map[string]string, len(cenv.Vars))}
-	for k := range cenv.Vars {
+	if len(asm.dockerfile.Stages) < 2 {
+		return nil
+	}
+	pei := &dockerfile.Env{Vars: make(map[string]string, len(keys))}
+	for _, k := range keys {
 		pei.Vars[k] = ""
 	}
-	asm.dockerfile.AddInstruction(1, pei)
+	return asm.dockerfile.AddInstruction(1, pei)
 }
 
 func (asm *BuildCompAsm) addSecretsVolumeToDockerfile(i int, workdir *dockerfile.WorkDir) error {
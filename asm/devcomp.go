@@ -7,6 +7,8 @@ Sniperkit-Bot
 package asm
 
 import (
+	"path/filepath"
+
 	"github.com/euforia/pseudo/scope"
 	"github.com/hashicorp/hil/ast"
 	"github.com/sniperkit/snk.fork.thrap/dockerfile"
@@ -111,7 +113,9 @@ func (asm *DevCompAsm) Assemble(variables scope.Variables) (err error) {
 	}
 
 	// Add all env vars as build args
-	asm.addArgAndEnvToDockerfile()
+	if err = asm.addArgAndEnvToDockerfile(); err != nil {
+		return err
+	}
 
 	if asm.comp.HasSecrets() {
 		// Local file
@@ -122,3 +126,18 @@ func (asm *DevCompAsm) Assemble(variables scope.Variables) (err error) {
 
 	return err
 }
+
+// ScaffoldFiles returns the files rendered from the pack i.e. the pack files
+// and the dockerfile, keyed by their path relative to the project
+func (asm *DevCompAsm) ScaffoldFiles() map[string][]byte {
+	ctxDir := asm.comp.Build.Context
+	files := make(map[string][]byte, len(asm.files)+1)
+	for k, v := range asm.files {
+		if asm.comp.HasSecrets() && k == asm.comp.Secrets.Destination {
+			continue
+		}
+		files[filepath.Join(ctxDir, k)] = v
+	}
+	files[filepath.Join(ctxDir, asm.comp.Build.Dockerfile)] = []byte(asm.dockerfile.String())
+	return files
+}
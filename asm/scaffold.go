@@ -0,0 +1,298 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package asm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/euforia/pseudo/scope"
+	"github.com/pkg/errors"
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/merge"
+	"github.com/sniperkit/snk.fork.thrap/packs"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"github.com/sniperkit/snk.fork.thrap/vcs"
+)
+
+// ScaffoldRecord records the pack source and revision each component was
+// scaffolded from along with the digests of the files rendered.  It is stored in the
+// project working directory and used to upgrade scaffolded files
+type ScaffoldRecord struct {
+	Components map[string]*ScaffoldedComponent `json:"components"`
+}
+
+// ScaffoldedComponent is the scaffold record of a single component
+type ScaffoldedComponent struct {
+	// Dev pack id
+	Pack string `json:"pack"`
+	// Pack source the files were rendered from.  Records without one were
+	// rendered from the default packs
+	Source string `json:"source,omitempty"`
+	// Revision of the pack source the files were rendered from
+	Version string `json:"version"`
+	// Path relative to the project to the sha256 of the rendered contents
+	Files map[string]string `json:"files"`
+}
+
+func (sc *ScaffoldedComponent) source() string {
+	if sc.Source == "" {
+		return packs.DefaultSource
+	}
+	return sc.Source
+}
+
+// ReadScaffoldRecord reads the scaffold record of the project in dir.  An
+// empty record is returned if the project does not have one
+func ReadScaffoldRecord(dir string) (*ScaffoldRecord, error) {
+	rec := &ScaffoldRecord{Components: make(map[string]*ScaffoldedComponent)}
+
+	b, err := ioutil.ReadFile(scaffoldRecordPath(dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return rec, nil
+		}
+		return nil, err
+	}
+
+	if err = json.Unmarshal(b, rec); err == nil && rec.Components == nil {
+		rec.Components = make(map[string]*ScaffoldedComponent)
+	}
+	return rec, err
+}
+
+// Marshal returns the json encoded record
+func (rec *ScaffoldRecord) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(rec, "", "  ")
+	return append(b, '\n'), err
+}
+
+// Write writes the record to the project in dir.  The record is committed
+// with the project so the .gitignore is updated to track it
+func (rec *ScaffoldRecord) Write(dir string) error {
+	b, err := rec.Marshal()
+	if err != nil {
+		return err
+	}
+
+	fpath := scaffoldRecordPath(dir)
+	if err = os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(fpath, b, 0644); err != nil {
+		return err
+	}
+	return vcs.UntrackIgnores(dir, scaffoldRecordFile)
+}
+
+// Project relative path of the scaffold record
+var scaffoldRecordFile = filepath.ToSlash(filepath.Join(consts.WorkDir, consts.ScaffoldFile))
+
+func scaffoldRecordPath(dir string) string {
+	return filepath.Join(dir, scaffoldRecordFile)
+}
+
+func scaffoldDigest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// recordScaffold records the files about to be scaffolded for the
+// component.  Files already in the project are not ours and not recorded
+func (asm *StackAsm) recordScaffold(casm *DevCompAsm, files map[string][]byte) {
+	if asm.scaffold == nil {
+		rec, err := ReadScaffoldRecord(asm.cwd)
+		if err != nil {
+			rec = &ScaffoldRecord{Components: make(map[string]*ScaffoldedComponent)}
+		}
+		asm.scaffold = rec
+	}
+
+	source := asm.packs.DevSource(casm.pack.Name)
+	ver, _ := asm.packs.SourceVersion(source)
+
+	sc := &ScaffoldedComponent{
+		Pack:    casm.pack.Name,
+		Source:  source,
+		Version: ver,
+		Files:   make(map[string]string, len(files)),
+	}
+	for k, v := range files {
+		if !utils.FileExists(filepath.Join(asm.cwd, k)) {
+			sc.Files[k] = scaffoldDigest(v)
+		}
+	}
+
+	asm.scaffold.Components[casm.comp.ID] = sc
+}
+
+// FileUpgrade is the upgrade of a single scaffolded file
+type FileUpgrade struct {
+	// Component the file belongs to
+	Component string
+	// Path relative to the project
+	Path string
+	// Contents in the project.  nil if the file is new
+	Current []byte
+	// Contents after the upgrade
+	Upgraded []byte
+	// Number of conflicts marked in the upgraded contents
+	Conflicts int
+}
+
+// UpgradeScaffold re-renders the scaffolded files of dev components with the
+// packs of the assembler and three-way merges them with the project files,
+// using the render of the recorded pack version as the base.  snapshot must
+// return the packs with a source at a given revision.  Unmodified files are
+// replaced and files new to the pack are added.  The record is updated to the
+// new version.  It returns the files that change.  Nothing is written to the
+// project
+func (asm *StackAsm) UpgradeScaffold(rec *ScaffoldRecord, snapshot func(source, version string) (*packs.Packs, error)) ([]*FileUpgrade, error) {
+	ids := make([]string, 0, len(rec.Components))
+	for id := range rec.Components {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var out []*FileUpgrade
+	for _, id := range ids {
+		sc := rec.Components[id]
+		comp, ok := asm.stack.Components[id]
+		if !ok || !comp.HasLanguage() {
+			continue
+		}
+
+		source := asm.packs.DevSource(comp.Language.Lang())
+		ver, err := asm.packs.SourceVersion(source)
+		if err != nil {
+			return nil, err
+		}
+		if sc.source() == source && sc.Version == ver {
+			continue
+		}
+
+		theirs, err := renderScaffold(comp, asm.packs, asm.vars)
+		if err != nil {
+			return nil, err
+		}
+
+		// Without the recorded version only unmodified files can be upgraded
+		// cleanly
+		var base map[string][]byte
+		if sc.Version != "" {
+			bpacks, err := snapshot(sc.source(), sc.Version)
+			if err == nil {
+				base, err = renderScaffold(comp, bpacks, asm.vars)
+			}
+			if err != nil && errors.Cause(err) != packs.ErrSnapshotUnavailable {
+				return nil, err
+			}
+		}
+
+		label := "pack " + comp.Language.Lang() + "@" + shortVersion(ver)
+		ups, files, err := asm.upgradeComponent(id, label, sc, base, theirs)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ups...)
+
+		rec.Components[id] = &ScaffoldedComponent{
+			Pack:    comp.Language.Lang(),
+			Source:  source,
+			Version: ver,
+			Files:   files,
+		}
+	}
+
+	return out, nil
+}
+
+// upgradeComponent returns the upgrades of the files of a component and the
+// digests of the files to record
+func (asm *StackAsm) upgradeComponent(id, label string, sc *ScaffoldedComponent, base, theirs map[string][]byte) ([]*FileUpgrade, map[string]string, error) {
+	files := make(map[string]string, len(theirs))
+	paths := make([]string, 0, len(theirs))
+	for k := range theirs {
+		paths = append(paths, k)
+	}
+	sort.Strings(paths)
+
+	var out []*FileUpgrade
+	for _, fpath := range paths {
+		up := &FileUpgrade{Component: id, Path: fpath, Upgraded: theirs[fpath]}
+
+		current, err := ioutil.ReadFile(filepath.Join(asm.cwd, fpath))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return nil, nil, err
+			}
+			// Only add files new to the pack.  Others were removed by the user
+			if _, ok := sc.Files[fpath]; ok {
+				continue
+			}
+			if _, ok := base[fpath]; ok {
+				continue
+			}
+			files[fpath] = scaffoldDigest(theirs[fpath])
+			out = append(out, up)
+			continue
+		}
+		up.Current = current
+
+		digest, ok := sc.Files[fpath]
+		if !ok {
+			// Not scaffolded by us
+			continue
+		}
+		files[fpath] = scaffoldDigest(theirs[fpath])
+
+		if digest != scaffoldDigest(current) {
+			// Modified since scaffolded
+			result := merge.Merge3(string(base[fpath]), string(current), string(theirs[fpath]),
+				fpath, label)
+			up.Upgraded = []byte(result.Text)
+			up.Conflicts = result.Conflicts
+		}
+
+		if !bytes.Equal(up.Current, up.Upgraded) {
+			out = append(out, up)
+		}
+	}
+
+	return out, files, nil
+}
+
+func shortVersion(ver string) string {
+	ver = strings.TrimPrefix(ver, "sha256:")
+	if len(ver) > 8 {
+		return ver[:8]
+	}
+	return ver
+}
+
+// renderScaffold renders the scaffold files of the dev component with the
+// given packs
+func renderScaffold(comp *thrapb.Component, pks *packs.Packs, svars scope.Variables) (map[string][]byte, error) {
+	langpack, err := pks.Dev().Load(comp.Language.Lang())
+	if err != nil {
+		return nil, err
+	}
+
+	casm := NewDevCompAsm(comp, langpack)
+	if err = casm.Assemble(svars); err != nil {
+		return nil, err
+	}
+	return casm.ScaffoldFiles(), nil
+}
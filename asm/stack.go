@@ -46,6 +46,9 @@ type StackAsm struct {
 
 	// assemblers for each component
 	casms map[string]ComponentAssembler
+
+	// record of scaffolded files.  Loaded on first use
+	scaffold *ScaffoldRecord
 }
 
 // NewStackAsm returns a new stack assembler
@@ -95,38 +98,45 @@ func (asm *StackAsm) ComponentAsm(id string) ComponentAssembler {
 }
 
 func (asm *StackAsm) Assemble() error {
-	st := asm.stack
-
-	for k, cmpt := range st.Components {
-		if cmpt.IsBuildable() {
-
-			var (
-				casm ComponentAssembler
-				err  error
-			)
-
-			// Only assemble with ones that have supplied a language
-			if cmpt.Language.Lang() != "" {
-				casm, err = asm.assembleDevComponent(cmpt)
-			} else {
-				casm, err = asm.assembleBuildComponent(cmpt)
-			}
+	for k := range asm.stack.Components {
+		if err := asm.AssembleComponent(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			if err != nil {
-				return err
-			}
+// AssembleComponent assembles a single component of the stack.  This is used
+// when adding a component to an existing stack
+func (asm *StackAsm) AssembleComponent(id string) error {
+	cmpt, ok := asm.stack.Components[id]
+	if !ok {
+		return errors.Errorf("component not found: %s", id)
+	}
 
-			asm.casms[k] = casm
+	if !cmpt.IsBuildable() {
+		//
+		// TODO: handle other components
+		//
+		return nil
+	}
 
-		} else {
-			//
-			// TODO: handle other components
-			//
-		}
+	var (
+		casm ComponentAssembler
+		err  error
+	)
 
+	// Only assemble with ones that have supplied a language
+	if cmpt.Language.Lang() != "" {
+		casm, err = asm.assembleDevComponent(cmpt)
+	} else {
+		casm, err = asm.assembleBuildComponent(cmpt)
 	}
 
-	return nil
+	if err == nil {
+		asm.casms[id] = casm
+	}
+	return err
 }
 
 // Materialize materialized all files and resources
@@ -139,6 +149,10 @@ func (asm *StackAsm) Materialize() (err error) {
 		}
 	}
 
+	if err == nil && asm.scaffold != nil {
+		err = asm.writeScaffold()
+	}
+
 	return
 }
 
@@ -171,21 +185,29 @@ func (asm *StackAsm) assembleDevComponent(cmpt *thrapb.Component) (*DevCompAsm,
 // TODO: change logic
 func (asm *StackAsm) materializeDevComp(casm *DevCompAsm) error {
 
-	files := make(map[string][]byte, 2)
-	files[casm.comp.Build.Dockerfile] = []byte(casm.dockerfile.String())
+	// Component files are relative to its build context
+	ctxDir := casm.comp.Build.Context
+	compFiles := casm.ScaffoldFiles()
+	asm.recordScaffold(casm, compFiles)
+
+	if casm.comp.HasSecrets() {
+		dst := casm.comp.Secrets.Destination
+		compFiles[filepath.Join(ctxDir, dst)] = casm.files[dst]
+	}
 
 	// TODO: check if file exists. load and confirm values
-	files[dockerfile.DockerIgnoresFile] = []byte(strings.Join(casm.dockerignores, "\n") + "\n")
+	compFiles[filepath.Join(ctxDir, dockerfile.DockerIgnoresFile)] = []byte(strings.Join(casm.dockerignores, "\n") + "\n")
 
 	// Default files to write independent of language pack
+	files := make(map[string][]byte, 2)
 	name, content := asm.vcsIgnoreFile(casm.pack.IgnoreFiles...)
 	files[name] = content
 
-	if _, ok := casm.files[consts.DefaultReadmeFile]; !ok {
+	if _, ok := compFiles[consts.DefaultReadmeFile]; !ok {
 		files[consts.DefaultReadmeFile] = asm.readmeFile()
 	}
 
-	return asm.writeFiles(casm.files, files)
+	return asm.writeFiles(compFiles, files)
 }
 
 // write all files
@@ -255,6 +277,49 @@ func (asm *StackAsm) WriteManifest() error {
 	return err
 }
 
+// WritePackLock writes the packs lock to the project dir
+func (asm *StackAsm) WritePackLock(lock *packs.Lock) error {
+	b, err := lock.Marshal()
+	if err == nil {
+		err = asm.writeFile(filepath.Join(consts.WorkDir, consts.PacksLockFile), b, true)
+	}
+	return err
+}
+
+// writeScaffold writes the scaffold record to the project and stages it
+func (asm *StackAsm) writeScaffold() error {
+	b, err := asm.scaffold.Marshal()
+	if err == nil {
+		err = asm.untrackIgnores(scaffoldRecordFile)
+	}
+	if err == nil {
+		err = asm.writeFile(scaffoldRecordFile, b, true)
+	}
+	return err
+}
+
+// untrackIgnores updates the ignores file of the project so the files are
+// tracked and stages it
+func (asm *StackAsm) untrackIgnores(files ...string) error {
+	root := asm.worktree.Filesystem.Root()
+	if err := vcs.UntrackIgnores(root, files...); err != nil {
+		return err
+	}
+
+	name := asm.vcs.IgnoresFile()
+	if !utils.FileExists(filepath.Join(root, name)) {
+		return nil
+	}
+	_, err := asm.worktree.Add(name)
+	return err
+}
+
+// UpdateManifest overwrites the manifest file in the project dir with the
+// given contents.  This is used to preserve user edits to the manifest
+func (asm *StackAsm) UpdateManifest(contents []byte) error {
+	return asm.writeFile(consts.DefaultManifestFile, contents, true)
+}
+
 func (asm *StackAsm) vcsCommit(msg string) error {
 	// we set the signature to thrap as it performed the init
 	commitOpt := &git.CommitOptions{
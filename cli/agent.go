@@ -7,9 +7,12 @@ Sniperkit-Bot
 package cli
 
 import (
+	"context"
 	"log"
 	"net"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/sniperkit/snk.fork.thrap"
 	"github.com/sniperkit/snk.fork.thrap/config"
@@ -35,6 +38,32 @@ func commandAgent() *cli.Command {
 				Usage: "Data directory",
 				Value: consts.DefaultDataDir,
 			},
+			&cli.StringFlag{
+				Name:  "webhook-addr",
+				Usage: "vcs webhook listen `address`. Disabled if empty",
+			},
+			&cli.StringFlag{
+				Name:  "webhook-url",
+				Usage: "public webhook `url` registered with the vcs (default: derived from webhook-addr)",
+			},
+			&cli.StringFlag{
+				Name:    "webhook-secret",
+				Usage:   "webhook `secret` used to verify deliveries (default: generated and stored in the creds)",
+				EnvVars: []string{"THRAP_WEBHOOK_SECRET"},
+			},
+			&cli.StringSliceFlag{
+				Name:  "webhook-branch",
+				Usage: "`branch` whose pushes trigger a build (default: master)",
+			},
+			&cli.StringFlag{
+				Name:  "webhook-profile",
+				Usage: "`profile` webhook builds are run with (default: default profile in <data-dir>/profiles.hcl)",
+			},
+			&cli.DurationFlag{
+				Name:  "preview-reap-interval",
+				Usage: "`interval` to destroy expired previews. 0 to disable",
+				Value: 5 * time.Minute,
+			},
 			// &cli.StringFlag{
 			// 	Name:  "adv-addr",
 			// 	Usage: "advertise address",
@@ -49,6 +78,24 @@ func commandAgent() *cli.Command {
 			pconf, err := config.ReadProjectConfig(".")
 			if err == nil {
 				conf.ThrapConfig = pconf
+			} else {
+				conf.ThrapConfig = &config.ThrapConfig{}
+			}
+			waddr := ctx.String("webhook-addr")
+			wurl := ctx.String("webhook-url")
+			if wurl == "" && waddr != "" {
+				if wurl, err = core.WebhookURL(waddr); err != nil {
+					return err
+				}
+			}
+			conf.ThrapConfig.Webhook = &config.WebhookConfig{
+				URL:      wurl,
+				Branches: ctx.StringSlice("webhook-branch"),
+				Profile:  ctx.String("webhook-profile"),
+			}
+			if secret := ctx.String("webhook-secret"); secret != "" {
+				conf.Creds = &config.CredsConfig{}
+				conf.Creds.SetWebhookSecret(secret)
 			}
 
 			core, err := core.NewCore(conf)
@@ -56,6 +103,16 @@ func commandAgent() *cli.Command {
 				return err
 			}
 
+			if waddr != "" {
+				if err = startWebhookServer(core, waddr, conf.Logger); err != nil {
+					return err
+				}
+			}
+
+			if interval := ctx.Duration("preview-reap-interval"); interval > 0 {
+				go core.RunPreviewReaper(context.Background(), interval)
+			}
+
 			srv := grpc.NewServer()
 			svc := thrap.NewService(core, conf.Logger)
 			thrapb.RegisterThrapServer(srv, svc)
@@ -72,3 +129,26 @@ func commandAgent() *cli.Command {
 		},
 	}
 }
+
+// startWebhookServer starts the vcs webhook receiver in the background
+func startWebhookServer(cr *core.Core, addr string, logger *log.Logger) error {
+	wh, err := core.NewWebhookHandler(cr)
+	if err != nil {
+		return err
+	}
+	go wh.Run(context.Background())
+
+	mux := http.NewServeMux()
+	mux.Handle(core.WebhookPath, wh)
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	logger.Println("Starting webhook server:", lis.Addr().String()+core.WebhookPath)
+
+	go func() {
+		logger.Println("Webhook server stopped:", http.Serve(lis, mux))
+	}()
+	return nil
+}
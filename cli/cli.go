@@ -55,6 +55,7 @@ func NewCLI(version string) *cli.App {
 			commandAgent(),
 			commandStack(),
 			commandPack(),
+			commandManifest(),
 			commandVersion(),
 		},
 	}
@@ -81,10 +82,13 @@ func commandConfigure() *cli.Command {
 		Usage: "Configure global settings",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:   vars.VcsID,
-				Usage:  "version control `provider`",
-				Value:  "github",
-				Hidden: true,
+				Name:  vars.VcsID,
+				Usage: "version control `provider` (github, gitlab, gitea)",
+				Value: "github",
+			},
+			&cli.StringFlag{
+				Name:  vars.VcsAddr,
+				Usage: "version control `address` for self-hosted providers",
 			},
 			&cli.StringFlag{
 				Name:  vars.VcsUsername,
@@ -104,6 +108,7 @@ func commandConfigure() *cli.Command {
 			opts := core.ConfigureOptions{
 				VCS: &config.VCSConfig{
 					ID:       ctx.String(vars.VcsID),
+					Addr:     ctx.String(vars.VcsAddr),
 					Username: ctx.String(vars.VcsUsername),
 				},
 				DataDir:  ctx.String("data-dir"),
@@ -180,6 +185,7 @@ func loadProfile(ctx *cli.Context) (*store.HCLFileProfileStorage, *thrapb.Profil
 		return profs, nil, fmt.Errorf("profile not found: %s", profName)
 	}
 
-	fmt.Printf("Profile: %s\n\n", profName)
+	// Written to stderr so it is not mixed with command output i.e. json
+	fmt.Fprintf(os.Stderr, "Profile: %s\n\n", profName)
 	return profs, prof, nil
 }
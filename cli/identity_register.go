@@ -61,9 +61,9 @@ func commandIdentityRegister() *cli.Command {
 				return errNotConfigured
 			}
 
-			kp, err := utils.LoadECDSAKeyPair(filepath.Join(consts.DefaultDataDir, "ecdsa256"))
+			kp, err := loadIdentityKeyPair()
 			if err != nil {
-				return errors.Wrap(err, "loading keypair")
+				return err
 			}
 			pk := kp.PublicKey
 
@@ -111,6 +111,15 @@ func commandIdentityRegister() *cli.Command {
 	}
 }
 
+// loadIdentityKeyPair loads the keypair identities are registered with
+func loadIdentityKeyPair() (*ecdsa.PrivateKey, error) {
+	kp, err := utils.LoadECDSAKeyPair(filepath.Join(consts.DefaultDataDir, "ecdsa256"))
+	if err != nil {
+		return nil, errors.Wrap(err, "loading keypair")
+	}
+	return kp, nil
+}
+
 func confirmUserRegistration(cc thrapb.ThrapClient, kp *ecdsa.PrivateKey, ident *thrapb.Identity, confirmCode string) (*thrapb.Identity, error) {
 	code := base58.Decode([]byte(confirmCode))
 	r, s, err := ecdsa.Sign(rand.Reader, kp, code)
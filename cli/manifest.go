@@ -0,0 +1,81 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/sniperkit/snk.fork.thrap/manifest"
+	"gopkg.in/urfave/cli.v2"
+)
+
+func commandManifest() *cli.Command {
+	return &cli.Command{
+		Name:  "manifest",
+		Usage: "Manifest operations",
+		Subcommands: []*cli.Command{
+			commandManifestSchema(),
+			commandManifestValidate(),
+		},
+	}
+}
+
+func commandManifestSchema() *cli.Command {
+	return &cli.Command{
+		Name:  "schema",
+		Usage: "Print the JSON Schema of the manifest for editors",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "manifest `format` [ yaml | hcl ]",
+				Value: manifest.SchemaYAML,
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			schema, err := manifest.GenerateSchema(ctx.String("format"))
+			if err == nil {
+				writeJSON(schema)
+			}
+			return err
+		},
+	}
+}
+
+func commandManifestValidate() *cli.Command {
+	return &cli.Command{
+		Name:      "validate",
+		Usage:     "Validate a manifest against the schema",
+		ArgsUsage: "[file]",
+		Action: func(ctx *cli.Context) error {
+			mfile := ctx.Args().Get(0)
+			if mfile == "" {
+				mfile = "thrap.yml"
+			}
+
+			b, err := ioutil.ReadFile(mfile)
+			if err != nil {
+				return err
+			}
+
+			var warns manifest.SchemaErrors
+			if filepath.Ext(mfile) == ".hcl" {
+				warns, err = manifest.ValidateHCL(mfile, b)
+			} else {
+				warns, err = manifest.ValidateYAML(mfile, b)
+			}
+			for _, w := range warns {
+				fmt.Println("Warning:", w)
+			}
+			if err == nil {
+				fmt.Println(mfile, "is valid")
+			}
+			return err
+		},
+	}
+}
@@ -7,14 +7,17 @@ Sniperkit-Bot
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"text/tabwriter"
 
 	"github.com/pkg/errors"
-	"github.com/sniperkit/snk.fork.thrap/consts"
-	"github.com/sniperkit/snk.fork.thrap/packs"
+	"github.com/sniperkit/snk.fork.thrap/core"
+	"github.com/sniperkit/snk.fork.thrap/merge"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	git "gopkg.in/src-d/go-git.v4"
 	"gopkg.in/urfave/cli.v2"
 )
 
@@ -24,7 +27,12 @@ func commandPack() *cli.Command {
 		Usage: "Pack operations",
 		Subcommands: []*cli.Command{
 			commandPackUpdate(),
+			commandPackUpgrade(),
 			commandPackList(),
+			commandPackSource(),
+			commandPackNew(),
+			commandPackValidate(),
+			commandPackTest(),
 		},
 	}
 }
@@ -32,15 +40,153 @@ func commandPack() *cli.Command {
 func commandPackUpdate() *cli.Command {
 	return &cli.Command{
 		Name:  "update",
-		Usage: "Update packs",
+		Usage: "Update packs and pack sources",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "project",
+				Usage: "lock the project to the updated packs and show the changed pack files",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			pks, srcs, err := loadPacks()
+			if err != nil {
+				return err
+			}
+
+			if utils.FileExists(pks.Dir()) {
+				err = pks.Update()
+				if err != nil && err != git.NoErrAlreadyUpToDate {
+					return err
+				}
+			}
+
+			root, err := packSourcesDir()
+			if err != nil {
+				return err
+			}
+			for _, name := range srcs.Names() {
+				src := srcs.Sources[name]
+				if err = src.Install(context.Background(), src.Path(root, name)); err != nil {
+					return fmt.Errorf("%s: %v", name, err)
+				}
+			}
+
+			if !ctx.Bool("project") {
+				return nil
+			}
+			return updateProjectPackLock(ctx)
+		},
+	}
+}
+
+func updateProjectPackLock(ctx *cli.Context) error {
+	lpath, err := utils.GetLocalPath("")
+	if err != nil {
+		return err
+	}
+
+	cr, err := loadCore(ctx)
+	if err != nil {
+		return err
+	}
+	stm, err := cr.Stack(thrapb.DefaultProfile())
+	if err != nil {
+		return err
+	}
+
+	changes, err := stm.UpdatePackLock(lpath)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("Packs lock up to date")
+		return nil
+	}
+
+	for _, ch := range changes {
+		name := ch.Source + "/" + ch.Path
+		if ch.Old == nil && ch.Status != "A" {
+			// Previous contents of non-git sources are not available
+			fmt.Printf("%s %s\n", ch.Status, name)
+			continue
+		}
+		fmt.Print(merge.Unified(string(ch.Old), string(ch.New), "a/"+name, "b/"+name))
+	}
+	fmt.Printf("\n%d pack file(s) changed.  Run 'thrap pack upgrade' to upgrade scaffolded files\n", len(changes))
+	return nil
+}
+
+func commandPackUpgrade() *cli.Command {
+	return &cli.Command{
+		Name:  "upgrade",
+		Usage: "Upgrade files scaffolded from packs to the current packs",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"dryrun"},
+				Usage:   "show a diff of the upgrade without writing files",
+			},
+		},
 		Action: func(ctx *cli.Context) error {
-			packdir := filepath.Join(consts.DefaultDataDir, consts.PacksDir)
-			pks, err := packs.New(packdir)
+			lpath, err := utils.GetLocalPath("")
+			if err != nil {
+				return err
+			}
+
+			stack, err := loadManifest("")
+			if err != nil {
+				return err
+			}
+			if errs := stack.Validate(); len(errs) > 0 {
+				return utils.FlattenErrors(errs)
+			}
+
+			cr, err := loadCore(ctx)
+			if err != nil {
+				return err
+			}
+			stm, err := cr.Stack(thrapb.DefaultProfile())
+			if err != nil {
+				return err
+			}
+
+			dryrun := ctx.Bool("dry-run")
+			upgrades, err := stm.UpgradePacks(stack, core.PackUpgradeOptions{
+				Workdir: lpath,
+				Dryrun:  dryrun,
+			})
 			if err != nil {
 				return err
 			}
 
-			return pks.Update()
+			if len(upgrades) == 0 {
+				fmt.Println("Scaffolded files up to date")
+				return nil
+			}
+
+			var conflicts int
+			for _, up := range upgrades {
+				conflicts += up.Conflicts
+				if dryrun {
+					fmt.Print(merge.Unified(string(up.Current), string(up.Upgraded), "a/"+up.Path, "b/"+up.Path))
+					continue
+				}
+
+				status := "M"
+				if up.Current == nil {
+					status = "A"
+				}
+				if up.Conflicts > 0 {
+					status = "C"
+				}
+				fmt.Printf("%s %s\n", status, up.Path)
+			}
+
+			if conflicts > 0 {
+				fmt.Printf("\n%d conflict(s) marked in upgraded files\n", conflicts)
+			}
+			return nil
 		},
 	}
 }
@@ -58,8 +204,7 @@ func commandPackList() *cli.Command {
 			},
 		},
 		Action: func(ctx *cli.Context) error {
-			packdir := filepath.Join(consts.DefaultDataDir, consts.PacksDir)
-			pks, err := packs.New(packdir)
+			pks, _, err := loadPacks()
 			if err != nil {
 				return err
 			}
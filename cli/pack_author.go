@@ -0,0 +1,158 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sniperkit/snk.fork.thrap/core"
+	"github.com/sniperkit/snk.fork.thrap/packs"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"gopkg.in/urfave/cli.v2"
+)
+
+func packDirFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "dir",
+		Usage: "`directory` containing the packs by type",
+		Value: ".",
+	}
+}
+
+// packArgs returns the type and id args of pack authoring commands
+func packArgs(ctx *cli.Context) (string, string, error) {
+	if ctx.NArg() != 2 {
+		return "", "", errors.New("pack type and id required")
+	}
+	return ctx.Args().Get(0), ctx.Args().Get(1), nil
+}
+
+func commandPackNew() *cli.Command {
+	return &cli.Command{
+		Name:      "new",
+		Usage:     "Scaffold a new pack",
+		ArgsUsage: "<dev|web|datastore> <id>",
+		Flags:     []cli.Flag{packDirFlag()},
+		Action: func(ctx *cli.Context) error {
+			typ, id, err := packArgs(ctx)
+			if err != nil {
+				return err
+			}
+
+			files, err := packs.NewPack(ctx.String("dir"), typ, id)
+			if err != nil {
+				return err
+			}
+			for _, f := range files {
+				fmt.Println("A", f)
+			}
+			return nil
+		},
+	}
+}
+
+func commandPackValidate() *cli.Command {
+	return &cli.Command{
+		Name:      "validate",
+		Usage:     "Validate a pack",
+		ArgsUsage: "<dev|web|datastore> <id>",
+		Flags:     []cli.Flag{packDirFlag()},
+		Action: func(ctx *cli.Context) error {
+			typ, id, err := packArgs(ctx)
+			if err != nil {
+				return err
+			}
+			return validatePack(ctx.String("dir"), typ, id)
+		},
+	}
+}
+
+func validatePack(dir, typ, id string) error {
+	errs := packs.Validate(dir, typ, id)
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s/%s: %d problem(s) found", typ, id, len(errs))
+	}
+	return nil
+}
+
+func commandPackTest() *cli.Command {
+	return &cli.Command{
+		Name:      "test",
+		Usage:     "Render a dev pack, build its image and run a smoke command",
+		ArgsUsage: "<id>",
+		Flags: []cli.Flag{
+			packDirFlag(),
+			&cli.StringFlag{
+				Name:  "lang-version",
+				Usage: "language `version` to render. Defaults to the pack default",
+			},
+			&cli.StringFlag{
+				Name:  "cmd",
+				Usage: "smoke `command` to run in the image. Defaults to the image command",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "`duration` to wait for the smoke command. Without --cmd the image passes if still running",
+				Value: 30 * time.Second,
+			},
+			&cli.BoolFlag{
+				Name:  "keep",
+				Usage: "keep the rendered project and built image",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			id := ctx.Args().First()
+			if id == "" {
+				return errors.New("pack id required")
+			}
+
+			dir, err := utils.GetAbsPath(ctx.String("dir"))
+			if err != nil {
+				return err
+			}
+			if err = validatePack(dir, "dev", id); err != nil {
+				return err
+			}
+
+			cr, err := loadCore(ctx)
+			if err != nil {
+				return err
+			}
+
+			result, err := cr.TestPack(context.Background(), core.PackTestOptions{
+				Dir:     dir,
+				ID:      id,
+				Version: ctx.String("lang-version"),
+				Cmd:     strings.Fields(ctx.String("cmd")),
+				Timeout: ctx.Duration("timeout"),
+				Keep:    ctx.Bool("keep"),
+				Output:  os.Stdout,
+			})
+			if result != nil && result.Dir != "" {
+				fmt.Println("\nRendered project:", result.Dir)
+			}
+			if err != nil {
+				return err
+			}
+
+			if ctx.Bool("keep") {
+				fmt.Printf("\nPack %s passed. Image: %s\n", id, result.Image)
+			} else {
+				fmt.Printf("\nPack %s passed\n", id)
+			}
+			return nil
+		},
+	}
+}
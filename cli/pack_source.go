@@ -0,0 +1,202 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/packs"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"gopkg.in/urfave/cli.v2"
+)
+
+// loadPacks returns the default packs with the installed sources layered
+// over them along with the configured sources
+func loadPacks() (*packs.Packs, *packs.Sources, error) {
+	datadir, err := utils.GetAbsPath(consts.DefaultDataDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return packs.NewLayered(
+		filepath.Join(datadir, consts.PacksDir),
+		filepath.Join(datadir, consts.PackSourcesFile),
+		filepath.Join(datadir, consts.PackSourcesDir),
+	)
+}
+
+func packSourcesFile() (string, error) {
+	return utils.GetAbsPath(filepath.Join(consts.DefaultDataDir, consts.PackSourcesFile))
+}
+
+func packSourcesDir() (string, error) {
+	return utils.GetAbsPath(filepath.Join(consts.DefaultDataDir, consts.PackSourcesDir))
+}
+
+func commandPackSource() *cli.Command {
+	return &cli.Command{
+		Name:  "source",
+		Usage: "Pack source operations",
+		Subcommands: []*cli.Command{
+			commandPackSourceAdd(),
+			commandPackSourceRm(),
+			commandPackSourceList(),
+		},
+	}
+}
+
+func commandPackSourceAdd() *cli.Command {
+	return &cli.Command{
+		Name:      "add",
+		Usage:     "Add and install a pack source",
+		ArgsUsage: "<name>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "type",
+				Usage: "source `type` [git|dir|archive|oci]",
+				Value: string(packs.SourceGit),
+			},
+			&cli.StringFlag{
+				Name:  "url",
+				Usage: "git url, local directory, archive path or url, or oci://host/repo:tag",
+			},
+			&cli.StringFlag{
+				Name:  "ref",
+				Usage: "git `ref` to pin",
+			},
+			&cli.StringFlag{
+				Name:  "checksum",
+				Usage: "sha256:<hex> `checksum` of an archive or oci layer. Required unless the oci reference is pinned by digest",
+			},
+			&cli.IntFlag{
+				Name:  "priority",
+				Usage: "sources with a higher `priority` take precedence",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			name := ctx.Args().First()
+			if name == "" {
+				return errors.New("source name required")
+			}
+
+			fpath, err := packSourcesFile()
+			if err != nil {
+				return err
+			}
+			root, err := packSourcesDir()
+			if err != nil {
+				return err
+			}
+			srcs, err := packs.ReadSources(fpath)
+			if err != nil {
+				return err
+			}
+
+			src := &packs.Source{
+				Type:     packs.SourceType(ctx.String("type")),
+				URL:      ctx.String("url"),
+				Ref:      ctx.String("ref"),
+				Checksum: ctx.String("checksum"),
+				Priority: ctx.Int("priority"),
+			}
+			if src.Type == packs.SourceDir {
+				if src.URL, err = utils.GetAbsPath(src.URL); err != nil {
+					return err
+				}
+			}
+			if err = srcs.Add(name, src); err != nil {
+				return err
+			}
+
+			if err = src.Install(context.Background(), src.Path(root, name)); err != nil {
+				return err
+			}
+
+			return srcs.Write(fpath)
+		},
+	}
+}
+
+func commandPackSourceRm() *cli.Command {
+	return &cli.Command{
+		Name:      "rm",
+		Usage:     "Remove a pack source",
+		ArgsUsage: "<name>",
+		Action: func(ctx *cli.Context) error {
+			name := ctx.Args().First()
+			if name == "" {
+				return errors.New("source name required")
+			}
+
+			fpath, err := packSourcesFile()
+			if err != nil {
+				return err
+			}
+			srcs, err := packs.ReadSources(fpath)
+			if err != nil {
+				return err
+			}
+
+			src, ok := srcs.Sources[name]
+			if !ok {
+				return errors.New("source not found: " + name)
+			}
+			delete(srcs.Sources, name)
+
+			// Local directories are not ours to remove
+			if src.Type != packs.SourceDir {
+				root, err := packSourcesDir()
+				if err != nil {
+					return err
+				}
+				if err = os.RemoveAll(src.Path(root, name)); err != nil {
+					return err
+				}
+			}
+
+			return srcs.Write(fpath)
+		},
+	}
+}
+
+func commandPackSourceList() *cli.Command {
+	return &cli.Command{
+		Name:    "list",
+		Usage:   "List pack sources in priority order",
+		Aliases: []string{"ls"},
+		Action: func(ctx *cli.Context) error {
+			pks, srcs, err := loadPacks()
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "NAME\tTYPE\tPRIORITY\tURL\tREF\n")
+			for _, name := range srcs.Names() {
+				src := srcs.Sources[name]
+				fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", name, src.Type, src.Priority, src.URL, src.Ref)
+			}
+
+			ver, _ := pks.Version()
+			fmt.Fprintf(w, "%s\t%s\t-\t%s\t%s\n", packs.DefaultSource, packs.SourceGit, pks.Dir(), shortRef(ver))
+			return w.Flush()
+		},
+	}
+}
+
+func shortRef(ref string) string {
+	if len(ref) > 8 {
+		return ref[:8]
+	}
+	return ref
+}
@@ -8,16 +8,16 @@ package cli
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
 	"text/tabwriter"
 
 	"github.com/sniperkit/snk.fork.thrap/core"
-	"github.com/sniperkit/snk.fork.thrap/manifest"
-	"github.com/sniperkit/snk.fork.thrap/store"
 	"github.com/sniperkit/snk.fork.thrap/thrapb"
 	"github.com/sniperkit/snk.fork.thrap/utils"
+	"github.com/sniperkit/snk.fork.thrap/vcs"
 	"gopkg.in/urfave/cli.v2"
 )
 
@@ -36,6 +36,11 @@ func commandStack() *cli.Command {
 		Subcommands: []*cli.Command{
 			commandStackList(),
 			commandStackInit(),
+			commandStackAdd(),
+			commandStackImport(),
+			commandStackExport(),
+			commandStackValidate(),
+			commandStackLockImages(),
 			commandStackRegister(),
 			commandStackEnsure(),
 			commandStackCommit(),
@@ -44,9 +49,16 @@ func commandStack() *cli.Command {
 			commandStackDeploy(),
 			commandStackStatus(),
 			commandStackLogs(),
+			commandStackExec(),
+			commandStackPortForward(),
+			commandStackIngress(),
+			commandStackSnapshot(),
 			commandStackStop(),
 			commandStackDestroy(),
 			commandStackVersion(),
+			commandStackRelease(),
+			commandStackChangelog(),
+			commandStackPreview(),
 			commandProfile(),
 		},
 	}
@@ -57,7 +69,7 @@ func commandStackVersion() *cli.Command {
 		Name:  "version",
 		Usage: "Show stack version",
 		Action: func(ctx *cli.Context) error {
-			stack, err := manifest.LoadManifest("")
+			stack, err := loadManifest("")
 			if err == nil {
 				fmt.Println(stack.Version)
 			}
@@ -76,46 +88,98 @@ func commandStackBuild() *cli.Command {
 				Name:  "pub",
 				Usage: "publish artifacts",
 			},
+			&cli.BoolFlag{
+				Name:  "rebuild",
+				Usage: "build components whose version is unchanged",
+			},
+			&cli.BoolFlag{
+				Name:  "lint",
+				Usage: "lint dockerfiles failing the build on errors",
+			},
+			&cli.StringFlag{
+				Name:  "source",
+				Usage: "build from the git `url` rather than the working directory",
+			},
+			&cli.StringFlag{
+				Name:  "ref",
+				Usage: "`branch`, tag or commit of the source to build",
+			},
+			&cli.StringFlag{
+				Name:  "override",
+				Usage: "publish despite publish policy violations giving a `reason`",
+			},
+			&cli.StringFlag{
+				Name:  "identity",
+				Usage: "registered identity `email` signing the override",
+			},
 		},
 		Action: func(ctx *cli.Context) error {
-
-			stack, err := manifest.LoadManifest("")
+			lpath, err := utils.GetLocalPath("")
 			if err != nil {
 				return err
 			}
 
-			lpath, err := utils.GetLocalPath("")
+			_, prof, err := loadProfile(ctx)
 			if err != nil {
 				return err
 			}
 
-			// Load profiles
-			profs, err := store.LoadHCLFileProfileStorage(lpath)
+			cr, err := loadCore(ctx)
 			if err != nil {
 				return err
 			}
 
-			// Load request profile
-			profName := ctx.String("profile")
-			prof := profs.Get(profName)
-			if prof == nil {
-				return fmt.Errorf("profile not found: %s", profName)
+			stm, err := cr.Stack(prof)
+			if err != nil {
+				return err
 			}
 
-			cr, err := loadCore(ctx)
-			if err != nil {
+			if src := ctx.String("source"); src != "" {
+				sopt := core.SourceOptions{
+					URL:     src,
+					Ref:     ctx.String("ref"),
+					Publish: ctx.Bool("pub"),
+					Rebuild: ctx.Bool("rebuild"),
+					Lint:    ctx.Bool("lint"),
+				}
+				if reason := ctx.String("override"); reason != "" {
+					sopt.Override = func(stack *thrapb.Stack, commit string) (*thrapb.PublishOverride, error) {
+						return newPublishOverride(ctx, stack.ID, prof.ID, commit, reason)
+					}
+					if sopt.VerifyOverride, err = agentOverrideVerifier(ctx); err != nil {
+						return err
+					}
+				}
+				_, err = stm.BuildSource(context.Background(), sopt)
 				return err
 			}
 
-			stm, err := cr.Stack(prof)
+			stack, versioned, err := loadVersionedManifest()
 			if err != nil {
 				return err
 			}
 
 			// lpath, _ := utils.GetLocalPath("")
 			opt := core.BuildOptions{
-				Workdir: lpath,
-				Publish: ctx.Bool("pub"),
+				Workdir:   lpath,
+				Publish:   ctx.Bool("pub"),
+				Rebuild:   ctx.Bool("rebuild"),
+				Versioned: versioned,
+				Lint:      ctx.Bool("lint"),
+			}
+
+			if reason := ctx.String("override"); reason != "" {
+				head, err := vcs.ReadHead(lpath)
+				if err != nil {
+					return err
+				}
+				opt.Override, err = newPublishOverride(ctx, stack.ID, prof.ID, head.Commit, reason)
+				if err != nil {
+					return err
+				}
+				if opt.VerifyOverride, err = agentOverrideVerifier(ctx); err != nil {
+					return err
+				}
 			}
 
 			return stm.Build(context.Background(), stack, opt)
@@ -123,41 +187,38 @@ func commandStackBuild() *cli.Command {
 	}
 }
 
-func commandStackLogs() *cli.Command {
-	return &cli.Command{
-		Name:      "logs",
-		Usage:     "Show stack runtime logs",
-		ArgsUsage: "[component]",
-		Action: func(ctx *cli.Context) error {
-
-			stack, err := manifest.LoadManifest("")
-			if err != nil {
-				return err
-			}
-
-			if errs := stack.Validate(); len(errs) > 0 {
-				return utils.FlattenErrors(errs)
-			}
-
-			cr, err := loadCore(ctx)
-			if err != nil {
-				return err
-			}
+// newPublishOverride returns an override of the publish policy for the
+// commit signed by the keypair the identity was registered with
+func newPublishOverride(ctx *cli.Context, stackID, profID, commit, reason string) (*thrapb.PublishOverride, error) {
+	ident := ctx.String("identity")
+	if ident == "" {
+		return nil, fmt.Errorf("--identity required to override")
+	}
 
-			stm, err := cr.Stack(thrapb.DefaultProfile())
-			if err != nil {
-				return err
-			}
+	kp, err := loadIdentityKeyPair()
+	if err != nil {
+		return nil, err
+	}
 
-			cid := ctx.Args().Get(0)
-			c := context.Background()
-			if cid == "" {
-				return stm.Logs(c, stack, os.Stdout, os.Stderr)
-			}
+	override, err := core.NewPublishOverride(ident, stackID, profID, commit, reason)
+	if err == nil {
+		override.Signature, err = utils.Sign(kp, override.SigHash(sha256.New()))
+	}
+	return override, err
+}
 
-			return stm.Log(c, cid+"."+stack.ID, os.Stdout, os.Stderr)
-		},
+// agentOverrideVerifier returns a func verifying overrides with the agent
+// as it holds the registered identities
+func agentOverrideVerifier(ctx *cli.Context) (func(*thrapb.PublishOverride) error, error) {
+	tclient, err := newThrapClient(ctx)
+	if err != nil {
+		return nil, err
 	}
+
+	return func(o *thrapb.PublishOverride) error {
+		_, err := tclient.VerifyPublishOverride(context.Background(), o)
+		return err
+	}, nil
 }
 
 func commandStackList() *cli.Command {
@@ -208,7 +269,7 @@ func commandStackEnsure() *cli.Command {
 		Name:  "ensure",
 		Usage: "Ensure resources exist",
 		Action: func(ctx *cli.Context) error {
-			stack, err := manifest.LoadManifest("")
+			stack, err := loadManifest("")
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,77 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sniperkit/snk.fork.thrap/asm"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"gopkg.in/urfave/cli.v2"
+	"gopkg.in/yaml.v2"
+)
+
+func commandStackAdd() *cli.Command {
+	return &cli.Command{
+		Name:      "add",
+		Usage:     "Add a component to the stack",
+		ArgsUsage: "<component-id>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "lang",
+				Aliases: []string{"l"},
+				Usage:   "add a dev component using the language `pack`",
+			},
+			&cli.StringFlag{
+				Name:  "datastore",
+				Usage: "add a datastore using the `pack`",
+			},
+			&cli.StringFlag{
+				Name:  "web",
+				Usage: "add a web server using the `pack`",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			id := ctx.Args().First()
+			if id == "" {
+				return errors.New("component id required")
+			}
+
+			lpath, err := utils.GetLocalPath("")
+			if err != nil {
+				return err
+			}
+
+			stack, stm, err := loadStackWithProfile(ctx)
+			if err != nil {
+				return err
+			}
+
+			c := &asm.ComponentConfig{
+				ID:        id,
+				Language:  thrapb.LanguageID(ctx.String("lang")),
+				DataStore: ctx.String("datastore"),
+				WebServer: ctx.String("web"),
+			}
+
+			comp, err := stm.AddComponent(stack, c, lpath)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Added component %s:\n\n", id)
+			b, err := yaml.Marshal(map[string]*thrapb.Component{id: comp})
+			if err == nil {
+				_, err = os.Stdout.Write(b)
+			}
+			return err
+		},
+	}
+}
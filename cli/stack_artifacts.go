@@ -13,7 +13,6 @@ import (
 	"time"
 
 	"github.com/sniperkit/snk.fork.thrap/core"
-	"github.com/sniperkit/snk.fork.thrap/manifest"
 	"github.com/sniperkit/snk.fork.thrap/thrapb"
 	"github.com/sniperkit/snk.fork.thrap/utils"
 	"gopkg.in/urfave/cli.v2"
@@ -26,7 +25,7 @@ func commandStackArtifacts() *cli.Command {
 		Usage:   "List stack artifacts",
 		Action: func(ctx *cli.Context) error {
 
-			stack, err := manifest.LoadManifest("")
+			stack, _, err := loadVersionedManifest()
 			if err != nil {
 				return err
 			}
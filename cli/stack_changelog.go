@@ -0,0 +1,85 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sniperkit/snk.fork.thrap/core"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"gopkg.in/urfave/cli.v2"
+)
+
+func commandStackChangelog() *cli.Command {
+	return &cli.Command{
+		Name:  "changelog",
+		Usage: "Generate release notes from the commit history",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "start after `revision`. Defaults to the previous version",
+			},
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "end at `revision`",
+				Value: "HEAD",
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "output `format` [md|json]",
+				Value: "md",
+			},
+			&cli.BoolFlag{
+				Name:  "write",
+				Usage: "prepend to " + core.ChangelogFile,
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			format := ctx.String("format")
+			if format != "md" && format != "json" {
+				return fmt.Errorf("unsupported format: %s", format)
+			}
+
+			stack, err := loadManifest("")
+			if err != nil {
+				return err
+			}
+			if errs := stack.Validate(); len(errs) > 0 {
+				return utils.FlattenErrors(errs)
+			}
+
+			lpath, err := utils.GetLocalPath("")
+			if err != nil {
+				return err
+			}
+
+			opt := core.ChangelogOptions{
+				Workdir: lpath,
+				From:    ctx.String("from"),
+				To:      ctx.String("to"),
+			}
+			cl, err := core.GenerateChangelog(stack, opt)
+			if err != nil {
+				return err
+			}
+
+			if ctx.Bool("write") {
+				if err = core.WriteChangelog(lpath, cl); err != nil {
+					return err
+				}
+			}
+
+			if format == "json" {
+				writeJSON(cl)
+			} else {
+				cl.WriteMarkdown(os.Stdout)
+			}
+			return nil
+		},
+	}
+}
@@ -9,7 +9,6 @@ package cli
 import (
 	"fmt"
 
-	"github.com/sniperkit/snk.fork.thrap/manifest"
 	"github.com/sniperkit/snk.fork.thrap/orchestrator"
 	"github.com/sniperkit/snk.fork.thrap/store"
 	"github.com/sniperkit/snk.fork.thrap/utils"
@@ -21,16 +20,20 @@ func commandStackDeploy() *cli.Command {
 	return &cli.Command{
 		Name:  "deploy",
 		Usage: "Deploy stack",
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.BoolFlag{
 				Name:    "dryrun",
 				Aliases: []string{"dry"},
 				Usage:   "perform a dry run",
 				Value:   false,
 			},
-		},
+			&cli.BoolFlag{
+				Name:  "no-ingress",
+				Usage: "do not run the ingress proxy after a local deploy",
+			},
+		}, ingressFlags()...),
 		Action: func(ctx *cli.Context) error {
-			stack, err := manifest.LoadManifest("")
+			stack, _, err := loadVersionedManifest()
 			if err != nil {
 				return err
 			}
@@ -67,7 +70,15 @@ func commandStackDeploy() *cli.Command {
 				return err
 			}
 
-			return st.Deploy(stack, opt)
+			if err = st.Deploy(stack, opt); err != nil {
+				return err
+			}
+
+			// Local stacks are served through the ingress until interrupted
+			if st.Local() && !opt.Dryrun && !ctx.Bool("no-ingress") && hasIngressRoutes(stack) {
+				return runIngress(ctx, st, stack)
+			}
+			return nil
 		},
 	}
 }
@@ -0,0 +1,207 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/docker/docker/pkg/term"
+	"github.com/sniperkit/snk.fork.thrap/core"
+	"github.com/sniperkit/snk.fork.thrap/manifest"
+	"github.com/sniperkit/snk.fork.thrap/orchestrator"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"gopkg.in/urfave/cli.v2"
+)
+
+var (
+	errComponentRequired = errors.New("component required")
+	errCommandRequired   = errors.New("command required")
+	errPortMapRequired   = errors.New("port mapping required i.e. <local>:<label>")
+)
+
+func commandStackExec() *cli.Command {
+	return &cli.Command{
+		Name:      "exec",
+		Usage:     "Run a command in a running component",
+		ArgsUsage: "<component> -- <cmd> [args ...]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "interactive",
+				Aliases: []string{"i"},
+				Usage:   "keep stdin open",
+			},
+			&cli.BoolFlag{
+				Name:    "tty",
+				Aliases: []string{"t"},
+				Usage:   "allocate a tty",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			args := ctx.Args()
+			if args.Len() == 0 {
+				return errComponentRequired
+			}
+			// Flag parsing stops at the component so the separator is kept
+			cmd := args.Tail()
+			if len(cmd) > 0 && cmd[0] == "--" {
+				cmd = cmd[1:]
+			}
+			if len(cmd) == 0 {
+				return errCommandRequired
+			}
+
+			stack, stm, err := loadStackWithProfile(ctx)
+			if err != nil {
+				return err
+			}
+
+			req := &orchestrator.ExecRequest{
+				Cmd:    cmd,
+				TTY:    ctx.Bool("tty"),
+				Stdout: os.Stdout,
+				Stderr: os.Stderr,
+			}
+			if ctx.Bool("interactive") {
+				req.Stdin = os.Stdin
+			}
+
+			if req.TTY {
+				fd, isTerm := term.GetFdInfo(os.Stdin)
+				if isTerm {
+					if ws, err := term.GetWinsize(fd); err == nil {
+						req.Height = uint(ws.Height)
+						req.Width = uint(ws.Width)
+					}
+
+					state, err := term.SetRawTerminal(fd)
+					if err != nil {
+						return err
+					}
+					defer term.RestoreTerminal(fd, state)
+				}
+			}
+
+			code, err := stm.Exec(context.Background(), stack, args.First(), req)
+			if err != nil {
+				return err
+			}
+			if code != 0 {
+				return cli.Exit("", code)
+			}
+			return nil
+		},
+	}
+}
+
+func commandStackPortForward() *cli.Command {
+	return &cli.Command{
+		Name:      "port-forward",
+		Usage:     "Forward a local port to a running component port",
+		ArgsUsage: "<component> <local>:<label>",
+		Action: func(ctx *cli.Context) error {
+			args := ctx.Args()
+			if args.Len() == 0 {
+				return errComponentRequired
+			}
+
+			mapping := args.Get(1)
+			i := strings.LastIndex(mapping, ":")
+			if i < 1 || i == len(mapping)-1 {
+				return errPortMapRequired
+			}
+
+			stack, stm, err := loadStackWithProfile(ctx)
+			if err != nil {
+				return err
+			}
+
+			c, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			sigs := make(chan os.Signal, 1)
+			signal.Notify(sigs, os.Interrupt)
+			defer signal.Stop(sigs)
+			go func() {
+				<-sigs
+				cancel()
+			}()
+
+			return stm.PortForward(c, stack, args.First(), mapping[:i], mapping[i+1:])
+		},
+	}
+}
+
+// loadStackWithProfile loads and validates the local manifest returning it
+// along with a stack instance for the requested profile
+func loadStackWithProfile(ctx *cli.Context) (*thrapb.Stack, *core.Stack, error) {
+	stack, err := loadManifest("")
+	if err != nil {
+		return nil, nil, err
+	}
+	return validateStackWithProfile(ctx, stack)
+}
+
+// loadVersionedStackWithProfile is loadStackWithProfile with the versions of
+// buildable components computed from their build sources
+func loadVersionedStackWithProfile(ctx *cli.Context) (*thrapb.Stack, *core.Stack, error) {
+	stack, _, err := loadVersionedManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+	return validateStackWithProfile(ctx, stack)
+}
+
+func validateStackWithProfile(ctx *cli.Context, stack *thrapb.Stack) (*thrapb.Stack, *core.Stack, error) {
+	if errs := stack.Validate(); len(errs) > 0 {
+		return nil, nil, utils.FlattenErrors(errs)
+	}
+
+	_, prof, err := loadProfile(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr, err := loadCore(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stm, err := cr.Stack(prof)
+	return stack, stm, err
+}
+
+// loadManifest loads the manifest printing any schema warnings
+func loadManifest(mfile string) (*thrapb.Stack, error) {
+	stack, warns, err := manifest.LoadManifest(mfile)
+	for _, w := range warns {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", w)
+	}
+	return stack, err
+}
+
+// loadVersionedManifest loads the local manifest with the version of each
+// buildable component computed from its build sources.  This is only needed
+// by commands using component versions.  It also returns the components
+// versioned from their sources
+func loadVersionedManifest() (*thrapb.Stack, map[string]bool, error) {
+	stack, err := loadManifest("")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lpath, err := utils.GetLocalPath("")
+	if err != nil {
+		return nil, nil, err
+	}
+	return stack, manifest.SetComponentVersions(stack, lpath), nil
+}
@@ -0,0 +1,70 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sniperkit/snk.fork.thrap/core"
+	"github.com/sniperkit/snk.fork.thrap/manifest"
+	"gopkg.in/urfave/cli.v2"
+)
+
+const defaultExportDir = "export"
+
+func commandStackExport() *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "Export the stack for use with other tools",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "export `format` [ " + strings.Join(manifest.ExportFormats, " | ") + " ]",
+			},
+			&cli.StringFlag{
+				Name:  "dir",
+				Usage: "`directory` to write files to",
+				Value: defaultExportDir,
+			},
+			&cli.StringSliceFlag{
+				Name:  "secret-var",
+				Usage: "env var `name` to export as a secret reference",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			format := ctx.String("format")
+			if format == "" {
+				return fmt.Errorf("format required: %s", strings.Join(manifest.ExportFormats, ", "))
+			}
+
+			stack, stm, err := loadVersionedStackWithProfile(ctx)
+			if err != nil {
+				return err
+			}
+
+			opt := core.ExportOptions{
+				Format:     format,
+				Dir:        ctx.String("dir"),
+				SecretVars: ctx.StringSlice("secret-var"),
+			}
+			files, err := stm.Export(stack, opt)
+			if err != nil {
+				return fmt.Errorf("%s: %v", format, err)
+			}
+
+			fmt.Printf("Exported %s to:\n\n", stack.ID)
+			for _, f := range files {
+				fmt.Println(" ", f)
+			}
+			fmt.Printf("\nSecrets are references and must be provided to the target\n")
+
+			return nil
+		},
+	}
+}
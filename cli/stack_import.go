@@ -0,0 +1,129 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/manifest"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"gopkg.in/urfave/cli.v2"
+)
+
+// Compose files looked for when none is given
+var defaultComposeFiles = []string{
+	"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml",
+}
+
+func commandStackImport() *cli.Command {
+	return &cli.Command{
+		Name:  "import",
+		Usage: "Import a stack from another format",
+		Subcommands: []*cli.Command{
+			commandStackImportCompose(),
+		},
+	}
+}
+
+func commandStackImportCompose() *cli.Command {
+	return &cli.Command{
+		Name:      "compose",
+		Usage:     "Import a docker compose file writing thrap.yml next to it",
+		ArgsUsage: "[file]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "name",
+				Aliases: []string{"n"},
+				Usage:   "stack `name` (default: <compose file directory>)",
+			},
+			&cli.BoolFlag{
+				Name:  "overwrite",
+				Usage: "overwrite an existing manifest",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			fpath, err := composeFile(ctx.Args().First())
+			if err != nil {
+				return err
+			}
+
+			dir := filepath.Dir(fpath)
+			mfile := filepath.Join(dir, consts.DefaultManifestFile)
+			if utils.FileExists(mfile) && !ctx.Bool("overwrite") {
+				return fmt.Errorf("manifest %s already exists", mfile)
+			}
+
+			name := ctx.String("name")
+			if name == "" {
+				name = filepath.Base(dir)
+			}
+
+			b, err := ioutil.ReadFile(fpath)
+			if err != nil {
+				return err
+			}
+
+			stack, issues, err := manifest.ImportCompose(b, name)
+			if err != nil {
+				return err
+			}
+
+			fh, err := os.Create(mfile)
+			if err != nil {
+				return err
+			}
+			err = manifest.WriteYAMLManifest(stack, fh)
+			fh.Close()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Imported %d component(s) from %s into %s\n", len(stack.Components), fpath, mfile)
+
+			if len(issues) > 0 {
+				fmt.Printf("\nNot imported:\n\n")
+				for _, issue := range issues {
+					fmt.Println(" ", issue)
+				}
+			}
+
+			if errs := stack.Validate(); len(errs) > 0 {
+				keys := make([]string, 0, len(errs))
+				for k := range errs {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+
+				fmt.Printf("\nFix before use:\n\n")
+				for _, k := range keys {
+					fmt.Printf("  %s: %v\n", k, errs[k])
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// composeFile returns the absolute path of the compose file or the first
+// default one found in the current directory
+func composeFile(fpath string) (string, error) {
+	if fpath != "" {
+		return utils.GetAbsPath(fpath)
+	}
+
+	for _, name := range defaultComposeFiles {
+		if utils.FileExists(name) {
+			return utils.GetAbsPath(name)
+		}
+	}
+	return "", fmt.Errorf("compose file not found: %v", defaultComposeFiles)
+}
@@ -0,0 +1,81 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/sniperkit/snk.fork.thrap/core"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"gopkg.in/urfave/cli.v2"
+)
+
+func ingressFlags() []cli.Flag {
+	def := core.DefaultIngressOptions()
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "http-addr",
+			Usage: "ingress http listen `address`. empty to disable",
+			Value: def.HTTPAddr,
+		},
+		&cli.StringFlag{
+			Name:  "https-addr",
+			Usage: "ingress https listen `address`. empty to disable",
+			Value: def.HTTPSAddr,
+		},
+	}
+}
+
+func commandStackIngress() *cli.Command {
+	return &cli.Command{
+		Name:  "ingress",
+		Usage: "Run a local ingress proxy for head and external components",
+		Flags: ingressFlags(),
+		Action: func(ctx *cli.Context) error {
+			stack, stm, err := loadStackWithProfile(ctx)
+			if err != nil {
+				return err
+			}
+
+			return runIngress(ctx, stm, stack)
+		},
+	}
+}
+
+// hasIngressRoutes returns true if the stack has head or external components
+// with ports to route to
+func hasIngressRoutes(stack *thrapb.Stack) bool {
+	for _, comp := range stack.Components {
+		if (comp.Head || comp.External) && len(comp.Ports) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// runIngress runs the ingress proxy in the foreground until interrupted
+func runIngress(ctx *cli.Context, stm *core.Stack, stack *thrapb.Stack) error {
+	opts := core.IngressOptions{
+		HTTPAddr:  ctx.String("http-addr"),
+		HTTPSAddr: ctx.String("https-addr"),
+	}
+
+	c, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+	go func() {
+		<-sigs
+		cancel()
+	}()
+
+	return stm.Ingress(c, stack, opts)
+}
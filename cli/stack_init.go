@@ -10,6 +10,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode"
 
 	"github.com/pkg/errors"
 	"github.com/sniperkit/snk.fork.thrap/analysis"
@@ -50,8 +54,7 @@ func commandStackInit() *cli.Command {
 			},
 			&cli.StringFlag{
 				Name:   vars.VcsID,
-				Usage:  "version control `provider` (experimental)",
-				Value:  "github",
+				Usage:  "version control `provider` (default: configured)",
 				Hidden: true,
 			},
 			&cli.StringFlag{
@@ -88,15 +91,31 @@ func commandStackInit() *cli.Command {
 			}
 
 			pks := cr.Packs()
-			// Set language from input or otherwise and other related params
-			_, err = setLanguage(ctx, pks.Dev(), projPath)
+			// Propose a component per service found in an existing project
+			comps, err := proposeComps(ctx, pks.Dev(), projPath)
 			if err != nil {
 				return err
 			}
+			lang := ""
+			if len(comps) == 0 {
+				// Set language from input or otherwise and other related params
+				if _, err = setLanguage(ctx, pks.Dev(), projPath); err != nil {
+					return err
+				}
+				lang = ctx.String("lang")
+			}
 
 			gconf := cr.Config()
-			vcsID := ctx.String(vars.VcsID)
-			defaultVCS := gconf.VCS[vcsID]
+			defaultVCS := gconf.DefaultVCS()
+			if vcsID := ctx.String(vars.VcsID); vcsID != "" {
+				var ok bool
+				if defaultVCS, ok = gconf.VCS[vcsID]; !ok {
+					return fmt.Errorf("vcs not configured: '%s'", vcsID)
+				}
+			}
+			if defaultVCS == nil {
+				return errNotConfigured
+			}
 			repoOwner := setRepoOwner(ctx, defaultVCS.ID, defaultVCS.Username)
 
 			// Local project setup
@@ -113,10 +132,11 @@ func commandStackInit() *cli.Command {
 			}
 
 			// Prompt for missing
-			bsc, err := promptComps(projName, ctx.String("lang"), pks)
+			bsc, err := promptComps(projName, lang, pks)
 			if err != nil {
 				return err
 			}
+			bsc.Components = comps
 
 			fmt.Println()
 
@@ -185,6 +205,101 @@ func setLanguage(ctx *cli.Context, devpacks *packs.DevPacks, dir string) (*packs
 	return devpack, err
 }
 
+// proposeComps analyzes the project in dir and returns a dev component for
+// each service found.  The root service is the default api component.  The
+// language is prompted for if the detected one has no pack.  The detected
+// version is used if the pack supports it
+func proposeComps(ctx *cli.Context, devpacks *packs.DevPacks, dir string) ([]*asm.ComponentConfig, error) {
+	proj, err := analysis.Analyze(dir)
+	if err != nil || len(proj.Services) == 0 {
+		return nil, err
+	}
+
+	supported, err := devpacks.List()
+	if err != nil {
+		return nil, err
+	}
+
+	// Reserved for the web server and datastore prompted for after
+	ids := map[string]bool{consts.DefaultWebCompID: true, consts.DefaultDSCompID: true}
+	comps := make([]*asm.ComponentConfig, len(proj.Services))
+	for i, svc := range proj.Services {
+		comps[i] = &asm.ComponentConfig{ID: serviceCompID(svc, ids), Ports: svc.Ports}
+		if svc.Dir != "." {
+			comps[i].Context = filepath.ToSlash(svc.Dir)
+		}
+	}
+	printServices(proj, comps)
+
+	for i, svc := range proj.Services {
+		c := comps[i]
+
+		lang := svc.Language
+		if flang := ctx.String("lang"); svc.Dir == "." && isSupported(flang, supported) {
+			lang = flang
+		}
+		if !isSupported(lang, supported) {
+			lang = promptForSupported("Language for "+c.ID, supported, "")
+		}
+
+		devpack, err := devpacks.Load(lang)
+		if err != nil {
+			return nil, err
+		}
+
+		ver := devpack.DefaultVersion
+		if lang == svc.Language && svc.Version != "" && devpack.SupportsVersion(svc.Version) {
+			ver = svc.Version
+		}
+		c.Language = thrapb.LanguageID(devpack.Name + ":" + ver)
+	}
+
+	return comps, nil
+}
+
+// serviceCompID returns a unique component id for the service based on its
+// directory.  The root service is the default api component
+func serviceCompID(svc *analysis.Service, ids map[string]bool) string {
+	id := consts.DefaultAPICompID
+	if svc.Dir != "." {
+		id = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return '-'
+		}, filepath.Base(svc.Dir))
+	}
+
+	uid := id
+	for i := 2; ids[uid]; i++ {
+		uid = fmt.Sprintf("%s-%d", id, i)
+	}
+	ids[uid] = true
+	return uid
+}
+
+func printServices(proj *analysis.Project, comps []*asm.ComponentConfig) {
+	fmt.Printf("Found %d component(s):\n\n", len(proj.Services))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "COMPONENT\tDIR\tLANGUAGE\tFRAMEWORKS\tPORTS\tENTRYPOINT\n")
+	for i, svc := range proj.Services {
+		lang := svc.Language
+		if svc.Version != "" {
+			lang += ":" + svc.Version
+		}
+		ports := make([]string, len(svc.Ports))
+		for j, p := range svc.Ports {
+			ports[j] = strconv.Itoa(int(p))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", comps[i].ID, svc.Dir, lang,
+			strings.Join(svc.Frameworks, ","), strings.Join(ports, ","), svc.EntryPoint)
+	}
+	w.Flush()
+
+	fmt.Println()
+}
+
 func setRepoOwner(ctx *cli.Context, vcsID, defRepoOwner string) string {
 	//var err error
 
@@ -0,0 +1,57 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/core"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"gopkg.in/urfave/cli.v2"
+)
+
+func commandStackLockImages() *cli.Command {
+	return &cli.Command{
+		Name:  "lock-images",
+		Usage: "Pin base and service images to their registry digests",
+		Action: func(ctx *cli.Context) error {
+			lpath, err := utils.GetLocalPath("")
+			if err != nil {
+				return err
+			}
+
+			stack, stm, err := loadStackWithProfile(ctx)
+			if err != nil {
+				return err
+			}
+
+			images, err := stm.LockImages(stack, core.LockImagesOptions{Workdir: lpath})
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(tw, "IMAGE\tCOMPONENTS\tDIGEST\tNEWER\n")
+			for _, li := range images {
+				dgst := li.Digest
+				if li.Previous != "" {
+					dgst += " (updated)"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", li.Image, strings.Join(li.Components, ","), dgst, li.Newer)
+			}
+			tw.Flush()
+
+			fmt.Printf("\nLocked %d images in %s\n", len(images), filepath.Join(consts.WorkDir, consts.ImagesLockFile))
+			return nil
+		},
+	}
+}
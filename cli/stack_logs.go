@@ -0,0 +1,126 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"time"
+
+	"github.com/sniperkit/snk.fork.thrap/core"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"gopkg.in/urfave/cli.v2"
+)
+
+func commandStackLogs() *cli.Command {
+	return &cli.Command{
+		Name:      "logs",
+		Usage:     "Show stack runtime logs",
+		ArgsUsage: "[component ...]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "follow",
+				Aliases: []string{"f"},
+				Usage:   "follow log output",
+			},
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "show logs since a relative `duration` (e.g. 10m) or RFC3339 timestamp. Not supported by nomad",
+			},
+			&cli.StringFlag{
+				Name:  "grep",
+				Usage: "only show lines matching the `regexp`",
+			},
+			&cli.BoolFlag{
+				Name:    "timestamps",
+				Aliases: []string{"t"},
+				Usage:   "show timestamps",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "output each line as json",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+
+			stack, err := loadManifest("")
+			if err != nil {
+				return err
+			}
+
+			if errs := stack.Validate(); len(errs) > 0 {
+				return utils.FlattenErrors(errs)
+			}
+
+			opts := core.LogOptions{
+				JSON:       ctx.Bool("json"),
+				Timestamps: ctx.Bool("timestamps"),
+			}
+			opts.Components = ctx.Args().Slice()
+			opts.Follow = ctx.Bool("follow")
+
+			if s := ctx.String("since"); s != "" {
+				opts.Since, err = parseSince(s, time.Now())
+				if err != nil {
+					return err
+				}
+			}
+
+			if s := ctx.String("grep"); s != "" {
+				opts.Grep, err = regexp.Compile(s)
+				if err != nil {
+					return err
+				}
+			}
+
+			_, prof, err := loadProfile(ctx)
+			if err != nil {
+				return err
+			}
+
+			cr, err := loadCore(ctx)
+			if err != nil {
+				return err
+			}
+
+			stm, err := cr.Stack(prof)
+			if err != nil {
+				return err
+			}
+
+			c, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			sigs := make(chan os.Signal, 1)
+			signal.Notify(sigs, os.Interrupt)
+			defer signal.Stop(sigs)
+			go func() {
+				<-sigs
+				cancel()
+			}()
+
+			return stm.Logs(c, stack, opts, os.Stdout, os.Stderr)
+		},
+	}
+}
+
+// parseSince parses either a duration relative to now or an RFC3339
+// timestamp
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return t, fmt.Errorf("invalid since: %s", s)
+	}
+	return t, nil
+}
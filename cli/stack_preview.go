@@ -0,0 +1,221 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sniperkit/snk.fork.thrap/core"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"gopkg.in/urfave/cli.v2"
+)
+
+const defaultPreviewTTL = 72 * time.Hour
+
+func previewNameFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "name",
+		Aliases: []string{"n"},
+		Usage:   "`branch` or pull request name of the preview",
+	}
+}
+
+func commandStackPreview() *cli.Command {
+	return &cli.Command{
+		Name:  "preview",
+		Usage: "Manage per branch preview environments",
+		Subcommands: []*cli.Command{
+			commandStackPreviewUp(),
+			commandStackPreviewDown(),
+			commandStackPreviewList(),
+		},
+	}
+}
+
+func commandStackPreviewUp() *cli.Command {
+	return &cli.Command{
+		Name:  "up",
+		Usage: "Build, publish and deploy a preview of the branch",
+		Flags: []cli.Flag{
+			previewNameFlag(),
+			&cli.DurationFlag{
+				Name:  "ttl",
+				Usage: "destroy the preview after `duration`. 0 to keep it",
+				Value: defaultPreviewTTL,
+			},
+			&cli.BoolFlag{
+				Name:    "dryrun",
+				Aliases: []string{"dry"},
+				Usage:   "perform a dry run",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			name := ctx.String("name")
+			if name == "" {
+				return fmt.Errorf("preview name required")
+			}
+
+			lpath, err := utils.GetLocalPath("")
+			if err != nil {
+				return err
+			}
+
+			// Previews are recorded with the agent which destroys them once
+			// expired
+			tclient, err := newThrapClient(ctx)
+			if err != nil {
+				return err
+			}
+
+			stack, versioned, err := loadVersionedManifest()
+			if err != nil {
+				return err
+			}
+			stack, stm, err := validateStackWithProfile(ctx, stack)
+			if err != nil {
+				return err
+			}
+
+			opt := core.PreviewOptions{
+				Name:      name,
+				Workdir:   lpath,
+				TTL:       ctx.Duration("ttl"),
+				Versioned: versioned,
+				Dryrun:    ctx.Bool("dryrun"),
+			}
+
+			preview, err := stm.Preview(context.Background(), stack, opt)
+			if err != nil {
+				return err
+			}
+			if !opt.Dryrun {
+				if preview, err = tclient.CreatePreview(context.Background(), preview); err != nil {
+					return err
+				}
+			}
+
+			if len(preview.Hosts) > 0 {
+				fmt.Printf("Hosts:\n\n")
+				for _, h := range preview.Hosts {
+					fmt.Printf("  %s\n", h)
+				}
+				fmt.Println()
+			}
+			return nil
+		},
+	}
+}
+
+func commandStackPreviewDown() *cli.Command {
+	return &cli.Command{
+		Name:  "down",
+		Usage: "Destroy the preview of the branch",
+		Flags: []cli.Flag{previewNameFlag()},
+		Action: func(ctx *cli.Context) error {
+			stack, err := loadManifest("")
+			if err != nil {
+				return err
+			}
+
+			id, err := core.PreviewID(stack.ID, ctx.String("name"))
+			if err != nil {
+				return err
+			}
+
+			tclient, err := newThrapClient(ctx)
+			if err != nil {
+				return err
+			}
+
+			_, err = tclient.DestroyPreview(context.Background(), &thrapb.Preview{ID: id})
+			if err != nil {
+				return fmt.Errorf("%s: %v", id, err)
+			}
+			fmt.Println("Destroyed:", id)
+
+			return nil
+		},
+	}
+}
+
+func commandStackPreviewList() *cli.Command {
+	return &cli.Command{
+		Name:    "list",
+		Usage:   "List previews of the stack",
+		Aliases: []string{"ls"},
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "all",
+				Aliases: []string{"a"},
+				Usage:   "list previews of all stacks",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			var stackID string
+			if !ctx.Bool("all") {
+				stack, err := loadManifest("")
+				if err != nil {
+					return err
+				}
+				stackID = stack.ID
+			}
+
+			tclient, err := newThrapClient(ctx)
+			if err != nil {
+				return err
+			}
+
+			// Preview ids are prefixed with the stack id
+			var prefix string
+			if stackID != "" {
+				prefix = stackID + "-"
+			}
+			stream, err := tclient.IterPreviews(context.Background(), &thrapb.IterOptions{Prefix: prefix})
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.StripEscape)
+			defer tw.Flush()
+
+			fmt.Fprintf(tw, "ID\tNAME\tPROFILE\tCREATED\tEXPIRES\tHOSTS\n")
+			for {
+				p, err := stream.Recv()
+				if err != nil {
+					if err == io.EOF {
+						return stream.CloseSend()
+					}
+					return err
+				}
+				if stackID != "" && p.StackID != stackID {
+					continue
+				}
+
+				expires := "never"
+				if p.Expires > 0 {
+					exp := time.Unix(0, p.Expires)
+					expires = exp.Format(time.RFC3339)
+					if exp.Before(now) {
+						expires += " (expired)"
+					}
+				}
+
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", p.ID, p.Name, p.Profile.GetID(),
+					time.Unix(0, p.Created).Format(time.RFC3339), expires,
+					strings.Join(p.Hosts, ","))
+			}
+		},
+	}
+}
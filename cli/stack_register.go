@@ -11,7 +11,6 @@ import (
 	"fmt"
 
 	"github.com/euforia/hclencoder"
-	"github.com/sniperkit/snk.fork.thrap/manifest"
 	"github.com/sniperkit/snk.fork.thrap/thrapb"
 	"github.com/sniperkit/snk.fork.thrap/utils"
 	"gopkg.in/urfave/cli.v2"
@@ -23,7 +22,7 @@ func commandStackRegister() *cli.Command {
 		Usage: "Register a new stack",
 		Action: func(ctx *cli.Context) error {
 
-			stack, err := manifest.LoadManifest("")
+			stack, err := loadManifest("")
 			if err != nil {
 				return err
 			}
@@ -84,7 +83,7 @@ func commandStackCommit() *cli.Command {
 		Usage: "Commit stack definition",
 		Action: func(ctx *cli.Context) error {
 
-			stack, err := manifest.LoadManifest("")
+			stack, err := loadManifest("")
 			if err != nil {
 				return err
 			}
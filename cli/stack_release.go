@@ -0,0 +1,70 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sniperkit/snk.fork.thrap/core"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"gopkg.in/urfave/cli.v2"
+)
+
+func commandStackRelease() *cli.Command {
+	return &cli.Command{
+		Name:      "release",
+		Usage:     "Tag a new semantic version of the stack",
+		ArgsUsage: "[major|minor|patch]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "pre",
+				Usage: "create a pre-release with the `id` i.e. rc",
+			},
+			&cli.StringFlag{
+				Name:    "message",
+				Aliases: []string{"m"},
+				Usage:   "tag `message`",
+			},
+			&cli.BoolFlag{
+				Name:  "push",
+				Usage: "push the tag to origin",
+			},
+			&cli.BoolFlag{
+				Name:  "pub",
+				Usage: "build and publish artifacts for the release",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			if ctx.NArg() > 1 {
+				return fmt.Errorf("too many arguments")
+			}
+
+			lpath, err := utils.GetLocalPath("")
+			if err != nil {
+				return err
+			}
+
+			stack, stm, err := loadStackWithProfile(ctx)
+			if err != nil {
+				return err
+			}
+
+			opt := core.ReleaseOptions{
+				Workdir: lpath,
+				Bump:    ctx.Args().First(),
+				Pre:     ctx.String("pre"),
+				Message: ctx.String("message"),
+				Push:    ctx.Bool("push"),
+				Publish: ctx.Bool("pub"),
+			}
+
+			_, err = stm.Release(context.Background(), stack, opt)
+			return err
+		},
+	}
+}
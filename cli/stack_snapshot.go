@@ -0,0 +1,128 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"gopkg.in/urfave/cli.v2"
+)
+
+var errSnapshotNameRequired = errors.New("snapshot name required")
+
+func commandStackSnapshot() *cli.Command {
+	return &cli.Command{
+		Name:  "snapshot",
+		Usage: "Save and restore local datastore volumes",
+		Subcommands: []*cli.Command{
+			commandStackSnapshotSave(),
+			commandStackSnapshotRestore(),
+			commandStackSnapshotList(),
+		},
+	}
+}
+
+func commandStackSnapshotSave() *cli.Command {
+	return &cli.Command{
+		Name:      "save",
+		Usage:     "Save datastore volumes to a named snapshot",
+		ArgsUsage: "<name>",
+		Action: func(ctx *cli.Context) error {
+			name := ctx.Args().First()
+			if name == "" {
+				return errSnapshotNameRequired
+			}
+
+			stack, stm, err := loadStackWithProfile(ctx)
+			if err != nil {
+				return err
+			}
+
+			snap, err := stm.SaveSnapshot(context.Background(), stack, name)
+			if err != nil {
+				return err
+			}
+
+			comps := make([]string, 0, len(snap.Components))
+			for k := range snap.Components {
+				comps = append(comps, k)
+			}
+			sort.Strings(comps)
+			fmt.Printf("Saved snapshot %s: %s\n", name, strings.Join(comps, ", "))
+			return nil
+		},
+	}
+}
+
+func commandStackSnapshotRestore() *cli.Command {
+	return &cli.Command{
+		Name:      "restore",
+		Usage:     "Restore datastore volumes from a named snapshot",
+		ArgsUsage: "<name>",
+		Action: func(ctx *cli.Context) error {
+			name := ctx.Args().First()
+			if name == "" {
+				return errSnapshotNameRequired
+			}
+
+			stack, stm, err := loadStackWithProfile(ctx)
+			if err != nil {
+				return err
+			}
+
+			ar := stm.RestoreSnapshot(context.Background(), stack, name)
+			thrapb.ActionsResults{"Restore:": ar}.Print(os.Stdout)
+			for _, r := range ar {
+				if r.Error != nil {
+					return cli.Exit("", 1)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func commandStackSnapshotList() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List snapshots of the stack",
+		Action: func(ctx *cli.Context) error {
+			stack, stm, err := loadStackWithProfile(ctx)
+			if err != nil {
+				return err
+			}
+
+			snaps, err := stm.ListSnapshots(stack.ID)
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.StripEscape)
+			fmt.Fprintf(tw, " \tName\tCreated\tComponents\n")
+			fmt.Fprintf(tw, " \t----\t-------\t----------\n")
+			for _, snap := range snaps {
+				comps := make([]string, 0, len(snap.Components))
+				for k := range snap.Components {
+					comps = append(comps, k)
+				}
+				sort.Strings(comps)
+				fmt.Fprintf(tw, " \t%s\t%s\t%s\n", snap.Name,
+					snap.Created.Format(time.RFC3339), strings.Join(comps, ","))
+			}
+			tw.Flush()
+			return nil
+		},
+	}
+}
@@ -13,7 +13,6 @@ import (
 	"text/tabwriter"
 
 	"github.com/sniperkit/snk.fork.thrap/core"
-	"github.com/sniperkit/snk.fork.thrap/manifest"
 	"github.com/sniperkit/snk.fork.thrap/thrapb"
 	"github.com/sniperkit/snk.fork.thrap/utils"
 	"gopkg.in/urfave/cli.v2"
@@ -25,7 +24,7 @@ func commandStackStatus() *cli.Command {
 		Usage: "Show status",
 		Action: func(ctx *cli.Context) error {
 
-			stack, err := manifest.LoadManifest("")
+			stack, err := loadManifest("")
 			if err != nil {
 				return err
 			}
@@ -11,7 +11,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/sniperkit/snk.fork.thrap/manifest"
 	"github.com/sniperkit/snk.fork.thrap/thrapb"
 	"github.com/sniperkit/snk.fork.thrap/utils"
 	"gopkg.in/urfave/cli.v2"
@@ -23,7 +22,7 @@ func commandStackStop() *cli.Command {
 		Usage: "Stop stack components",
 		Action: func(ctx *cli.Context) error {
 
-			stack, err := manifest.LoadManifest("")
+			stack, err := loadManifest("")
 			if err != nil {
 				return err
 			}
@@ -70,7 +69,7 @@ func commandStackDestroy() *cli.Command {
 		Usage: "Destroy stack components",
 		Action: func(ctx *cli.Context) error {
 
-			stack, err := manifest.LoadManifest("")
+			stack, err := loadManifest("")
 			if err != nil {
 				return err
 			}
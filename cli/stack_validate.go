@@ -0,0 +1,102 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sniperkit/snk.fork.thrap/core"
+	"github.com/sniperkit/snk.fork.thrap/dockerfile"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"gopkg.in/urfave/cli.v2"
+)
+
+func commandStackValidate() *cli.Command {
+	return &cli.Command{
+		Name:  "validate",
+		Usage: "Validate the manifest and lint component dockerfiles",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "output `format` [ text | json | sarif ]",
+				Value: "text",
+			},
+			&cli.StringSliceFlag{
+				Name:  "severity",
+				Usage: "override a lint rule severity as `rule=level`",
+			},
+			&cli.BoolFlag{
+				Name:  "save",
+				Usage: "save the severity overrides to the project lint config",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			lpath, err := utils.GetLocalPath("")
+			if err != nil {
+				return err
+			}
+
+			stack, err := loadManifest("")
+			if err != nil {
+				return err
+			}
+			if errs := stack.Validate(); len(errs) > 0 {
+				return utils.FlattenErrors(errs)
+			}
+
+			opt := core.LintOptions{
+				Workdir:    lpath,
+				Severities: make(map[string]dockerfile.Severity),
+			}
+			for _, pair := range ctx.StringSlice("severity") {
+				if err = core.ParseLintSeverity(opt.Severities, pair); err != nil {
+					return err
+				}
+			}
+
+			if ctx.Bool("save") {
+				severities, err := core.ReadLintSeverities(lpath)
+				if err != nil {
+					return err
+				}
+				for rule, sev := range opt.Severities {
+					severities[rule] = sev
+				}
+				if err = core.WriteLintSeverities(lpath, severities); err != nil {
+					return err
+				}
+			}
+
+			findings, err := core.LintStack(stack, opt)
+			if err != nil {
+				return err
+			}
+
+			switch format := ctx.String("format"); format {
+			case "json":
+				err = dockerfile.WriteLintJSON(os.Stdout, findings)
+			case "sarif":
+				err = dockerfile.WriteLintSARIF(os.Stdout, findings)
+			case "text":
+				for _, f := range findings {
+					fmt.Println(f)
+				}
+				if len(findings) == 0 {
+					fmt.Printf("%s is valid\n", stack.ID)
+				}
+			default:
+				return fmt.Errorf("format not supported: %s", format)
+			}
+
+			if err == nil && dockerfile.HasLintErrors(findings) {
+				err = fmt.Errorf("%s: dockerfile lint errors found", stack.ID)
+			}
+			return err
+		},
+	}
+}
@@ -21,6 +21,8 @@ type CredsConfig struct {
 	VCS          map[string]map[string]string `hcl:"vcs"`
 	Secrets      map[string]map[string]string `hcl:"secrets"`
 	Orchestrator map[string]map[string]string `hcl:"orchestrator"`
+	// Webhook holds the secret shared with the vcs to verify deliveries
+	Webhook map[string]string `hcl:"webhook" hcle:"omitempty"`
 }
 
 // GetRegistryCreds returns creds for the registry by the id
@@ -43,6 +45,19 @@ func (cc *CredsConfig) GetOrchestratorCreds(id string) map[string]string {
 	return cc.Orchestrator[id]
 }
 
+// GetWebhookSecret returns the webhook secret or an empty string if not set
+func (cc *CredsConfig) GetWebhookSecret() string {
+	return cc.Webhook["secret"]
+}
+
+// SetWebhookSecret sets the webhook secret
+func (cc *CredsConfig) SetWebhookSecret(secret string) {
+	if cc.Webhook == nil {
+		cc.Webhook = make(map[string]string, 1)
+	}
+	cc.Webhook["secret"] = secret
+}
+
 // Merge merges other to this config.  Other takes precedence
 func (cc *CredsConfig) Merge(other *CredsConfig) {
 	if other == nil {
@@ -52,6 +67,9 @@ func (cc *CredsConfig) Merge(other *CredsConfig) {
 	cc.VCS = merge(cc.VCS, other.VCS)
 	cc.Secrets = merge(cc.Secrets, other.Secrets)
 	cc.Orchestrator = merge(cc.Orchestrator, other.Orchestrator)
+	if secret := other.GetWebhookSecret(); secret != "" {
+		cc.SetWebhookSecret(secret)
+	}
 }
 
 func merge(curr, newm map[string]map[string]string) map[string]map[string]string {
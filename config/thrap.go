@@ -9,6 +9,7 @@ package config
 import (
 	"io/ioutil"
 	"path/filepath"
+	"sort"
 
 	"github.com/euforia/hclencoder"
 	"github.com/euforia/pseudo/scope"
@@ -22,6 +23,7 @@ type ThrapConfig struct {
 	Orchestrator map[string]*OrchestratorConfig `hcl:"orchestrator"`
 	Registry     map[string]*RegistryConfig     `hcl:"registry"`
 	Secrets      map[string]*SecretsConfig      `hcl:"secrets"`
+	Webhook      *WebhookConfig                 `hcl:"webhook" hcle:"omitempty"`
 }
 
 // Clone returns a copy of the config
@@ -35,6 +37,7 @@ func (conf *ThrapConfig) Clone() *ThrapConfig {
 		Orchestrator: make(map[string]*OrchestratorConfig, len(conf.Orchestrator)),
 		Registry:     make(map[string]*RegistryConfig, len(conf.Registry)),
 		Secrets:      make(map[string]*SecretsConfig, len(conf.Secrets)),
+		Webhook:      conf.Webhook.Clone(),
 	}
 
 	for k, v := range conf.VCS {
@@ -61,6 +64,16 @@ func (conf *ThrapConfig) Merge(other *ThrapConfig) {
 	}
 
 	if other.VCS != nil {
+		// Only one provider can be the default
+		for _, v := range other.VCS {
+			if v.Default {
+				conf.SetDefaultVCS("")
+				break
+			}
+		}
+		if conf.VCS == nil {
+			conf.VCS = make(map[string]*VCSConfig, len(other.VCS))
+		}
 		for k, v := range other.VCS {
 			if cv, ok := conf.VCS[k]; ok {
 				cv.Merge(v)
@@ -100,14 +113,37 @@ func (conf *ThrapConfig) Merge(other *ThrapConfig) {
 		}
 	}
 
+	if other.Webhook != nil {
+		if conf.Webhook == nil {
+			conf.Webhook = &WebhookConfig{}
+		}
+		conf.Webhook.Merge(other.Webhook)
+	}
 }
 
 // DefaultVCS returns the first available vcs
 func (conf *ThrapConfig) DefaultVCS() *VCSConfig {
-	for _, v := range conf.VCS {
-		return v
+	ids := make([]string, 0, len(conf.VCS))
+	for k, v := range conf.VCS {
+		if v.Default {
+			return v
+		}
+		ids = append(ids, k)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	sort.Strings(ids)
+	return conf.VCS[ids[0]]
+}
+
+// SetDefaultVCS makes the vcs provider with the id the default, unsetting
+// any other
+func (conf *ThrapConfig) SetDefaultVCS(id string) {
+	for k, v := range conf.VCS {
+		v.Default = k == id
 	}
-	return nil
 }
 
 // DefaultOrchestrator returns the first available orchestrator
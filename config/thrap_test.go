@@ -170,3 +170,21 @@ func Test_ThrapConfig_Encode(t *testing.T) {
 	b, _ := hclencoder.Encode(conf)
 	t.Logf("%s", b)
 }
+
+func Test_ThrapConfig_DefaultVCS(t *testing.T) {
+	conf := &ThrapConfig{VCS: map[string]*VCSConfig{
+		"github": {ID: "github"},
+		"gitlab": {ID: "gitlab"},
+	}}
+	assert.Equal(t, "github", conf.DefaultVCS().ID)
+
+	conf.VCS["gitea"] = &VCSConfig{ID: "gitea"}
+	conf.SetDefaultVCS("gitlab")
+	assert.Equal(t, "gitlab", conf.DefaultVCS().ID)
+	assert.Equal(t, 3, len(conf.VCS))
+
+	// The default of the merged config wins
+	conf.Merge(&ThrapConfig{VCS: map[string]*VCSConfig{"gitea": {Default: true}}})
+	assert.Equal(t, "gitea", conf.DefaultVCS().ID)
+	assert.False(t, conf.VCS["gitlab"].Default)
+}
@@ -45,12 +45,26 @@ func (conf *VCSRepoConfig) Merge(other *VCSRepoConfig) {
 
 }
 
+// DefaultVCSAddr returns the public address of a hosted vcs provider.  An
+// empty string is returned for providers that are only self-hosted
+func DefaultVCSAddr(id string) string {
+	switch id {
+	case "github":
+		return "github.com"
+	case "gitlab":
+		return "gitlab.com"
+	}
+	return ""
+}
+
 // VCSConfig holds vcs configurations
 type VCSConfig struct {
 	ID       string         `hcl:"id" hcle:"omit"`
 	Addr     string         `hcl:"addr" hcle:"omitempty"`
 	Username string         `hcl:"username"`
 	Repo     *VCSRepoConfig `hcl:"repo" hcle:"omitempty"`
+	// Default is true for the provider used when several are configured
+	Default bool `hcl:"default" hcle:"omitempty"`
 }
 
 // Clone returns a copy of the config
@@ -64,6 +78,7 @@ func (conf *VCSConfig) Clone() *VCSConfig {
 		Addr:     conf.Addr,
 		Username: conf.Username,
 		Repo:     conf.Repo.Clone(),
+		Default:  conf.Default,
 	}
 }
 
@@ -84,6 +99,9 @@ func (conf *VCSConfig) Merge(other *VCSConfig) {
 	if other.Username != "" {
 		conf.Username = other.Username
 	}
+	if other.Default {
+		conf.Default = true
+	}
 
 	if conf.Repo == nil {
 		conf.Repo = &VCSRepoConfig{}
@@ -0,0 +1,48 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package config
+
+// WebhookConfig holds the settings of the agent vcs webhook receiver.  The
+// shared secret is kept in the creds
+type WebhookConfig struct {
+	// Public url the vcs delivers events to
+	URL string `hcl:"url"`
+	// Branches that trigger a build on push. Tags always do
+	Branches []string `hcl:"branches" hcle:"omitempty"`
+	// Profile builds are run with. Defaults to the default profile
+	Profile string `hcl:"profile" hcle:"omitempty"`
+}
+
+// Clone returns a copy of the config
+func (conf *WebhookConfig) Clone() *WebhookConfig {
+	if conf == nil {
+		return nil
+	}
+	return &WebhookConfig{
+		URL:      conf.URL,
+		Branches: append([]string{}, conf.Branches...),
+		Profile:  conf.Profile,
+	}
+}
+
+// Merge merges the other config into the one. Only non-empty fields are
+// considered
+func (conf *WebhookConfig) Merge(other *WebhookConfig) {
+	if other == nil {
+		return
+	}
+
+	if other.URL != "" {
+		conf.URL = other.URL
+	}
+	if len(other.Branches) > 0 {
+		conf.Branches = other.Branches
+	}
+	if other.Profile != "" {
+		conf.Profile = other.Profile
+	}
+}
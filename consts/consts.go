@@ -24,6 +24,26 @@ const (
 	EnvVarVersion = "STACK_VERSION"
 	// PacksDir is the directory name where packs are stored
 	PacksDir = "packs"
+	// CADir is the directory name where the local certificate authority is
+	// stored
+	CADir = "ca"
+	// SourcesDir is the directory name where remote stack repos are cloned
+	SourcesDir = "sources"
+	// AuditLogFile is the file audited actions are appended to
+	AuditLogFile = "audit.log"
+	// ScaffoldFile is the project file recording the pack version and
+	// digests of scaffolded files
+	ScaffoldFile = "scaffold.json"
+	// PackSourcesFile is the file pack sources are configured in
+	PackSourcesFile = "pack-sources.hcl"
+	// PackSourcesDir is the directory name where pack sources are installed
+	PackSourcesDir = "pack-sources"
+	// PacksLockFile is the project file pinning the pack sources
+	PacksLockFile = "packs.lock"
+	// LintFile is the project file configuring dockerfile lint severities
+	LintFile = "lint.hcl"
+	// ImagesLockFile is the project file pinning images to digests
+	ImagesLockFile = "images.lock"
 )
 
 const (
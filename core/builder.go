@@ -12,16 +12,23 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/docker/docker/api/types"
 	"github.com/pkg/errors"
+	"github.com/sniperkit/snk.fork.thrap/consts"
 	"github.com/sniperkit/snk.fork.thrap/crt"
+	"github.com/sniperkit/snk.fork.thrap/dockerfile"
 	"github.com/sniperkit/snk.fork.thrap/metrics"
 	"github.com/sniperkit/snk.fork.thrap/registry"
 	"github.com/sniperkit/snk.fork.thrap/thrapb"
 	"github.com/sniperkit/snk.fork.thrap/vars"
 )
 
+// Label of built images holding the base images pinned by the image lock
+const pinnedImagesLabel = "images"
+
 // BuildOptions are options to perform a build-publish
 type BuildOptions struct {
 	// Workdir is the root of a git repo.  This is used to decide
@@ -30,6 +37,27 @@ type BuildOptions struct {
 	// If true the build is published despite the auto-publish check,
 	// essentially a force publish
 	Publish bool
+	// If true components are built even if an image of their version exists
+	Rebuild bool
+	// Components versioned from their sources by SetComponentVersions.  Only
+	// these are skipped if an image of their version exists
+	Versioned map[string]bool
+	// Commit the sources were checked out at.  If set artifacts are also
+	// tagged with it
+	Commit string
+	// Branch the sources were checked out from.  Checkouts of a commit are
+	// detached so this is what the publish policy checks against
+	Branch string
+	// Signed override allowing publishing despite publish policy violations
+	Override *thrapb.PublishOverride
+	// Verifies the override was signed by its registered identity.  Defaults
+	// to the identities of the data dir i.e. when run by the agent
+	VerifyOverride func(*thrapb.PublishOverride) error
+	// If true dockerfiles are linted before building failing on any errors
+	Lint bool
+	// Preview builds are tagged per branch and are exempt from the publish
+	// policy of the profile
+	Preview bool
 }
 
 // CompBuildResult is the result of a component build
@@ -45,6 +73,9 @@ type CompBuildResult struct {
 	Log *crt.DockerBuildLog
 	// Whether the image was published or not
 	Published bool
+	// True if the build was skipped as an image of the component version
+	// already exists
+	Cached bool
 }
 
 // HasError returns true if the build result contains an error
@@ -77,13 +108,17 @@ type stackBuilder struct {
 	results map[string]*CompBuildResult
 	// Overall build status
 	failed bool
+	// Components whose build is skipped if their version image exists
+	cached map[string]bool
+	// Additional tag of all artifacts with the commit built
+	commit string
 }
 
 func newStackBuilder(c *crt.Docker, reg registry.Registry, stack *thrapb.Stack) *stackBuilder {
 	return &stackBuilder{
 		reg:       reg,
 		crt:       c,
-		run:       &bdCommon{c},
+		run:       &bdCommon{crt: c},
 		totalTime: &metrics.Runtime{},
 		buildTime: &metrics.Runtime{},
 		results:   make(map[string]*CompBuildResult, len(stack.Components)),
@@ -186,12 +221,37 @@ func (bldr *stackBuilder) doBuild(ctx context.Context, comp *thrapb.Component) {
 		Log:     crt.NewDockerBuildLog(os.Stdout),
 	}
 
+	// Component versions only change with their sources so an existing
+	// image built from the same locked base images is up to date
+	image := bldr.stack.ArtifactName(comp.ID) + ":" + comp.Version
+	if bldr.cached[comp.ID] && bldr.isCached(ctx, comp, image) {
+		fmt.Printf("\nBuilding %s: cached (%s)\n", comp.ID, comp.Version)
+		result.Cached = true
+		result.Error = bldr.tagCommit(ctx, comp, image)
+		result.Runtime.End()
+		bldr.results[comp.ID] = result
+		if result.Error != nil {
+			bldr.failed = true
+		}
+		return
+	}
+
 	fmt.Printf("\nBuilding %s:\n\n", comp.ID)
 
 	req := bldr.makeBuildRequest(comp, result.Log)
 
-	// Blocking
-	result.Error = bldr.crt.Build(ctx, req)
+	req.Dockerfile, result.Error = bldr.pinnedDockerfile(comp)
+	if result.Error == nil {
+		// Record the pinned base images so lock changes invalidate the cache
+		var pinned string
+		if pinned, result.Error = bldr.pinnedImages(comp); pinned != "" {
+			req.BuildOpts.Labels[pinnedImagesLabel] = pinned
+		}
+	}
+	if result.Error == nil {
+		// Blocking
+		result.Error = bldr.crt.Build(ctx, req)
+	}
 	result.Runtime.End()
 	result.Labels = req.BuildOpts.Labels
 
@@ -203,13 +263,92 @@ func (bldr *stackBuilder) doBuild(ctx context.Context, comp *thrapb.Component) {
 	}
 }
 
+// isCached returns true if the image exists and was built from the base
+// images currently pinned by the image lock
+func (bldr *stackBuilder) isCached(ctx context.Context, comp *thrapb.Component, image string) bool {
+	labels, err := bldr.crt.ImageLabels(ctx, image)
+	if err != nil {
+		return false
+	}
+	pinned, err := bldr.pinnedImages(comp)
+	return err == nil && labels[pinnedImagesLabel] == pinned
+}
+
+// pinnedImages returns the sorted base images of the component pinned by the
+// image lock as a comma separated list.  It is empty if none are pinned
+func (bldr *stackBuilder) pinnedImages(comp *thrapb.Component) (string, error) {
+	if bldr.run.images == nil {
+		return "", nil
+	}
+
+	_, fpath := componentDockerfile(comp, "")
+	raw, err := dockerfile.ParseFile(fpath)
+	if err != nil {
+		return "", err
+	}
+
+	var out []string
+	for _, image := range dockerfile.ParseRaw(raw).BaseImages() {
+		if pinned := bldr.run.images.Pin(image); pinned != image {
+			out = append(out, pinned)
+		}
+	}
+	sort.Strings(out)
+	return strings.Join(out, ","), nil
+}
+
+// pinnedDockerfile returns the component dockerfile with its base images
+// pinned to the locked digests.  nil is returned if none are locked
+func (bldr *stackBuilder) pinnedDockerfile(comp *thrapb.Component) ([]byte, error) {
+	if bldr.run.images == nil {
+		return nil, nil
+	}
+
+	_, fpath := componentDockerfile(comp, "")
+	raw, err := dockerfile.ParseFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	df := dockerfile.ParseRaw(raw)
+	if df.PinImages(bldr.run.images.Pin) == 0 {
+		return nil, nil
+	}
+	fmt.Printf("  Base images pinned by %s\n\n", consts.ImagesLockFile)
+
+	return []byte(df.String()), nil
+}
+
+// tagCommit adds the commit tags to an existing image of the component
+func (bldr *stackBuilder) tagCommit(ctx context.Context, comp *thrapb.Component, image string) error {
+	if bldr.commit == "" {
+		return nil
+	}
+
+	for _, tag := range bldr.getBuildTags(comp) {
+		if !strings.HasSuffix(tag, ":"+bldr.commit) {
+			continue
+		}
+		if err := bldr.crt.ImageTag(ctx, image, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (bldr *stackBuilder) getBuildTags(comp *thrapb.Component) []string {
 	// Local tags
 	base := bldr.stack.ArtifactName(comp.ID)
 	out := []string{base, base + ":" + comp.Version}
+	if bldr.commit != "" {
+		out = append(out, base+":"+bldr.commit)
+	}
 	// Registry tags
 	if rbase := bldr.reg.ImageName(base); rbase != base {
 		out = append(out, rbase, rbase+":"+comp.Version)
+		if bldr.commit != "" {
+			out = append(out, rbase+":"+bldr.commit)
+		}
 	}
 
 	return out
@@ -235,6 +374,9 @@ func (bldr *stackBuilder) makeBuildRequest(comp *thrapb.Component, output io.Wri
 			},
 		},
 	}
+	if bldr.commit != "" {
+		req.BuildOpts.Labels["commit"] = bldr.commit
+	}
 
 	if comp.HasEnvVars() {
 		args := make(map[string]*string, len(comp.Env.Vars))
@@ -257,6 +399,8 @@ func (bldr *stackBuilder) makeBuildRequest(comp *thrapb.Component, output io.Wri
 // build and deploy common functions
 type bdCommon struct {
 	crt *crt.Docker
+	// Digests of images pinned by the project.  Optional
+	images *registry.ImageLock
 }
 
 // startServices starts services needed to perform the build that themselves do not need
@@ -275,7 +419,7 @@ func (c *bdCommon) startServices(ctx context.Context, stack *thrapb.Stack) (*met
 		}
 
 		// Pull image if we do not locally have it
-		imageID := comp.Name + ":" + comp.Version
+		imageID := c.images.Pin(componentImage(comp))
 		if !c.crt.HaveImage(ctx, imageID) {
 			err = c.crt.ImagePull(ctx, imageID)
 			if err != nil {
@@ -309,6 +453,9 @@ func (c *bdCommon) startContainer(ctx context.Context, sid string, comp *thrapb.
 	if len(comp.Version) > 0 {
 		cfg.Container.Image += ":" + comp.Version
 	}
+	if !comp.IsBuildable() {
+		cfg.Container.Image = c.images.Pin(cfg.Container.Image)
+	}
 
 	if comp.HasEnvVars() {
 		cfg.Container.Env = make([]string, 0, len(comp.Env.Vars))
@@ -0,0 +1,46 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sniperkit/snk.fork.thrap/registry"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_stackBuilder_pinnedImages(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "pinned-")
+	defer os.RemoveAll(tmpdir)
+
+	df := "FROM golang:1.10 AS build\nFROM alpine:3.8\nCOPY --from=build /app /app\n"
+	ioutil.WriteFile(filepath.Join(tmpdir, "Dockerfile"), []byte(df), 0644)
+	comp := &thrapb.Component{
+		Build: &thrapb.Build{Context: tmpdir, Dockerfile: "Dockerfile"},
+	}
+
+	bldr := &stackBuilder{run: &bdCommon{}}
+	pinned, err := bldr.pinnedImages(comp)
+	assert.Nil(t, err)
+	assert.Equal(t, "", pinned)
+
+	bldr.run.images = registry.NewImageLock()
+	bldr.run.images.Images["alpine:3.8"] = "sha256:123"
+	bldr.run.images.Images["golang:1.10"] = "sha256:456"
+	pinned, err = bldr.pinnedImages(comp)
+	assert.Nil(t, err)
+	assert.Equal(t, "alpine:3.8@sha256:123,golang:1.10@sha256:456", pinned)
+
+	// Changed digests change the cache key
+	bldr.run.images.Images["alpine:3.8"] = "sha256:789"
+	again, _ := bldr.pinnedImages(comp)
+	assert.NotEqual(t, pinned, again)
+}
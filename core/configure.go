@@ -62,13 +62,29 @@ func ConfigureGlobal(opts ConfigureOptions) error {
 		}
 	}
 
-	if opts.VCS.Username != "" {
-		if _, ok := conf.VCS[opts.VCS.ID]; !ok {
+	vc, ok := conf.VCS[opts.VCS.ID]
+	if !ok {
+		if !vcs.IsSupported(opts.VCS.ID) {
 			return fmt.Errorf("unknown vcs provider: '%s'", opts.VCS.ID)
 		}
-		conf.VCS[opts.VCS.ID].Username = opts.VCS.Username
+		vc = &config.VCSConfig{ID: opts.VCS.ID, Addr: config.DefaultVCSAddr(opts.VCS.ID)}
+		if conf.VCS == nil {
+			conf.VCS = make(map[string]*config.VCSConfig)
+		}
+		conf.VCS[opts.VCS.ID] = vc
+	}
+	// The last configured provider is the default alongside the others
+	conf.SetDefaultVCS(opts.VCS.ID)
+	if opts.VCS.Addr != "" {
+		vc.Addr = opts.VCS.Addr
+	}
+	if opts.VCS.Username != "" {
+		vc.Username = opts.VCS.Username
 	}
-	configureHomeVars(conf.VCS[opts.VCS.ID], opts.NoPrompt)
+	if err = configureVCSAddr(vc, opts.NoPrompt); err != nil {
+		return err
+	}
+	configureHomeVars(vc, opts.NoPrompt)
 
 	err = config.WriteThrapConfig(conf, varsfile)
 	if err != nil {
@@ -133,7 +149,34 @@ func configureHomeVars(conf *config.VCSConfig, noprompt bool) {
 
 }
 
+// configureVCSAddr prompts for the address of self-hosted providers
+func configureVCSAddr(conf *config.VCSConfig, noprompt bool) error {
+	if conf.Addr != "" {
+		return nil
+	}
+	if noprompt {
+		return fmt.Errorf("%s address required", conf.ID)
+	}
+
+	prompt := fmt.Sprintf("%s address: ", conf.ID)
+	utils.PromptUntilNoError(prompt, os.Stdout, os.Stdin, func(input []byte) error {
+		if len(input) == 0 {
+			return fmt.Errorf("%s address required", conf.ID)
+		}
+		conf.Addr = string(input)
+		return nil
+	})
+	return nil
+}
+
 func configureVCSCreds(conf *config.CredsConfig, vcsID string, noprompt bool) {
+	if conf.VCS == nil {
+		conf.VCS = make(map[string]map[string]string)
+	}
+	if conf.VCS[vcsID] == nil {
+		conf.VCS[vcsID] = map[string]string{"token": ""}
+	}
+
 	token := conf.VCS[vcsID]["token"]
 	if token != "" || noprompt {
 		return
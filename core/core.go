@@ -9,11 +9,9 @@ package core
 import (
 	"crypto/ecdsa"
 	"log"
-	"path/filepath"
 
 	"github.com/pkg/errors"
 	"github.com/sniperkit/snk.fork.thrap/config"
-	"github.com/sniperkit/snk.fork.thrap/consts"
 	"github.com/sniperkit/snk.fork.thrap/crt"
 	"github.com/sniperkit/snk.fork.thrap/orchestrator"
 	"github.com/sniperkit/snk.fork.thrap/packs"
@@ -61,10 +59,14 @@ type Core struct {
 
 	sst StackStorage
 	ist IdentityStorage
+	pst PreviewStorage
 
 	// Load keypair. Currently 1 per core
 	kp *ecdsa.PrivateKey
 
+	// Absolute path to the data directory
+	dataDir string
+
 	// Logger
 	log *log.Logger
 }
@@ -88,7 +90,7 @@ func NewCore(conf *Config) (*Core, error) {
 		return nil, err
 	}
 
-	err = c.initPacks(filepath.Join(conf.DataDir, consts.PacksDir))
+	err = c.initPacks(conf.DataDir)
 	if err != nil {
 		return nil, err
 	}
@@ -122,13 +124,17 @@ func (core *Core) Stack(profile *thrapb.Profile) (*Stack, error) {
 	}
 
 	stack := &Stack{
-		crt:   core.crt,
-		orch:  orch,
-		conf:  core.conf.Clone(),
-		vcs:   core.vcs,
-		packs: core.packs,
-		sst:   core.sst,
-		log:   core.log,
+		crt:     core.crt,
+		profile: profile,
+		orch:    orch,
+		conf:    core.conf.Clone(),
+		vcs:     core.vcs,
+		creds:   core.creds,
+		packs:   core.packs,
+		sst:     core.sst,
+		ist:     core.ist,
+		log:     core.log,
+		dataDir: core.dataDir,
 	}
 
 	// The registry may be empty for local builds
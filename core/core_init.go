@@ -38,6 +38,7 @@ func (core *Core) loadConfigs(conf *Config) error {
 	if !utils.FileExists(conf.DataDir) {
 		return errDataDirMissing
 	}
+	core.dataDir = conf.DataDir
 
 	cfile := filepath.Join(conf.DataDir, consts.ConfigFile)
 	gconf, err := config.ReadThrapConfig(cfile)
@@ -67,14 +68,21 @@ func (core *Core) initKeyPair(dir string) (err error) {
 	return
 }
 
-func (core *Core) initPacks(dir string) error {
-	pks, err := packs.New(dir)
+// initPacks loads the default packs with the installed pack sources layered
+// over them.  The default packs are only cloned when no other source is
+// installed so air-gapped machines can work off of installed sources
+func (core *Core) initPacks(dataDir string) error {
+	pks, _, err := packs.NewLayered(
+		filepath.Join(dataDir, consts.PacksDir),
+		filepath.Join(dataDir, consts.PackSourcesFile),
+		filepath.Join(dataDir, consts.PackSourcesDir),
+	)
 	if err != nil {
 		return err
 	}
 
 	core.packs = pks
-	if !utils.FileExists(pks.Dir()) {
+	if !utils.FileExists(pks.Dir()) && len(pks.Layers()) == 0 {
 		err = core.packs.Load(defaultPacksRepoURL)
 	}
 	return err
@@ -98,7 +106,10 @@ func (core *Core) initVCS() (err error) {
 	vc := core.conf.DefaultVCS()
 	vconf := &vcs.Config{
 		Provider: vc.ID,
-		Conf:     map[string]interface{}{"username": vc.Username},
+		Conf: map[string]interface{}{
+			"username": vc.Username,
+			"addr":     vc.Addr,
+		},
 	}
 
 	vcreds := core.creds.GetVCSCreds(vc.ID)
@@ -191,6 +202,7 @@ func (core *Core) initStores(datadir string) error {
 
 	core.sst = store.NewBadgerStackStorage(db)
 	core.ist = store.NewBadgerIdentityStorage(db)
+	core.pst = store.NewBadgerPreviewStorage(db)
 
 	return nil
 }
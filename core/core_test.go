@@ -102,7 +102,7 @@ func Test_Core_Build(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	stack, err := manifest.LoadManifest("../test-fixtures/thrap.hcl")
+	stack, _, err := manifest.LoadManifest("../test-fixtures/thrap.hcl")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -155,7 +155,7 @@ func Test_Core_populateFromImageConf(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	stack, err := manifest.LoadManifest("../test-fixtures/thrap.hcl")
+	stack, _, err := manifest.LoadManifest("../test-fixtures/thrap.hcl")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -196,7 +196,7 @@ func Test_Core_Assembler(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	stack, err := manifest.LoadManifest("../thrap.yml")
+	stack, _, err := manifest.LoadManifest("../thrap.yml")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -94,6 +94,23 @@ func (idt *Identity) Get(id string) (*thrapb.Identity, error) {
 	return ident, err
 }
 
+// VerifyOverride verifies the publish override was signed by the confirmed
+// identity it names.  It returns the identity on success
+func (idt *Identity) VerifyOverride(o *thrapb.PublishOverride) (*thrapb.Identity, error) {
+	ident, err := idt.store.Get(o.Identity)
+	if err != nil {
+		return nil, errors.Wrap(err, o.Identity)
+	}
+	if len(ident.Signature) == 0 {
+		return nil, errOverrideUnconfirmed
+	}
+
+	if !utils.VerifySignature(ident.PublicKey, o.SigHash(sha256.New()), o.Signature) {
+		return nil, errOverrideSignature
+	}
+	return ident, nil
+}
+
 // Iter iterates over each identity with the matching prefix
 func (idt *Identity) Iter(prefix string, f func(*thrapb.Identity) error) error {
 	return idt.store.Iter(prefix, f)
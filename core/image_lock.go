@@ -0,0 +1,256 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/docker/docker/api/types"
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/dockerfile"
+	"github.com/sniperkit/snk.fork.thrap/packs"
+	"github.com/sniperkit/snk.fork.thrap/registry"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"github.com/sniperkit/snk.fork.thrap/vcs"
+)
+
+// LockImagesOptions are options to lock the images of a stack
+type LockImagesOptions struct {
+	// Workdir build contexts are relative to and the lock is written in
+	Workdir string
+}
+
+// LockedImage is an image resolved to a digest
+type LockedImage struct {
+	// Image reference as written
+	Image string
+	// Components using the image either as a base or to run
+	Components []string
+	// Digest the image resolved to
+	Digest string
+	// Previously locked digest if it changed
+	Previous string
+	// Newest tag satisfying the pack version constraints if newer than the
+	// image tag
+	Newer string
+}
+
+func imagesLockPath(workdir string) string {
+	return filepath.Join(workdir, consts.WorkDir, consts.ImagesLockFile)
+}
+
+// readImageLock reads the image lock of the project in workdir.  nil is
+// returned if the project has none
+func readImageLock(workdir string) (*registry.ImageLock, error) {
+	lock, err := registry.ReadImageLock(imagesLockPath(workdir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return lock, err
+}
+
+// componentImage returns the image a non-buildable component runs
+func componentImage(comp *thrapb.Component) string {
+	if comp.Version == "" {
+		return comp.Name
+	}
+	return comp.Name + ":" + comp.Version
+}
+
+// componentDockerfile returns the build context and dockerfile path of a
+// buildable component resolved against the workdir
+func componentDockerfile(comp *thrapb.Component, workdir string) (string, string) {
+	ctxDir := comp.Build.Context
+	if !filepath.IsAbs(ctxDir) {
+		ctxDir = filepath.Join(workdir, ctxDir)
+	}
+	return ctxDir, filepath.Join(ctxDir, comp.Build.Dockerfile)
+}
+
+// stackImages returns the external images of the stack mapped to the ids of
+// the components using them.  These are the base images of buildable
+// components and the images of all others.  Images already pinned to a
+// digest are skipped
+func stackImages(stack *thrapb.Stack, workdir string) (map[string][]string, error) {
+	out := make(map[string][]string)
+	add := func(image, id string) {
+		if ref, err := registry.ParseImageRef(image); err == nil && ref.Digest == "" {
+			out[image] = append(out[image], id)
+		}
+	}
+
+	ids := make([]string, 0, len(stack.Components))
+	for id := range stack.Components {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		comp := stack.Components[id]
+		if !comp.IsBuildable() {
+			add(componentImage(comp), id)
+			continue
+		}
+
+		_, fpath := componentDockerfile(comp, workdir)
+		raw, err := dockerfile.ParseFile(fpath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", id, err)
+		}
+		for _, image := range dockerfile.ParseRaw(raw).BaseImages() {
+			add(image, id)
+		}
+	}
+
+	return out, nil
+}
+
+// packVersions returns the version constraints of the pack images by image
+// name.  Web and datastore packs constrain their image and dev packs their
+// dev images
+func packVersions(pks *packs.Packs) map[string][]string {
+	out := make(map[string][]string)
+
+	for _, set := range []*packs.BasePacks{pks.Web(), pks.Datastore()} {
+		ids, _ := set.List()
+		for _, id := range ids {
+			if pack, err := set.Load(id); err == nil && pack.Image != "" {
+				out[pack.Image] = pack.Versions
+			}
+		}
+	}
+
+	dev := pks.Dev()
+	ids, _ := dev.List()
+	for _, id := range ids {
+		pack, err := dev.Load(id)
+		if err != nil {
+			continue
+		}
+		for _, image := range pack.DevImages {
+			out[image] = pack.Versions
+		}
+	}
+
+	return out
+}
+
+// imageResolver returns a resolver authenticating with the credentials of
+// the configured registries.  The profile registry also provides its auth
+// config for registries such as ecr issuing temporary credentials
+func (st *Stack) imageResolver() *registry.Resolver {
+	res := registry.NewResolver(nil)
+
+	for id, rc := range st.conf.Registry {
+		creds := st.creds.GetRegistryCreds(id)
+		if creds["user"] == "" {
+			continue
+		}
+		addr := rc.Addr
+		if u, ok := rc.Config["url"].(string); ok && addr == "" {
+			addr = u
+		}
+		res.SetAuth(addr, types.AuthConfig{Username: creds["user"], Password: creds["password"]})
+	}
+
+	if st.reg != nil {
+		auth, err := st.reg.GetAuthConfig()
+		if err == nil && auth.ServerAddress != "" {
+			res.SetAuth(auth.ServerAddress, auth)
+		}
+	}
+
+	return res
+}
+
+// LockImages resolves the base images of buildable components and the images
+// of all other components to digests using the registry api and writes them
+// to the project lock.  Builds then use the locked digests.  Newer tags of
+// images satisfying the pack version constraints are reported with each
+// image
+func (st *Stack) LockImages(stack *thrapb.Stack, opt LockImagesOptions) ([]*LockedImage, error) {
+	if errs := stack.Validate(); len(errs) > 0 {
+		return nil, utils.FlattenErrors(errs)
+	}
+
+	images, err := stackImages(stack, opt.Workdir)
+	if err != nil {
+		return nil, err
+	}
+
+	pks, cleanup, err := st.lockedPacks(opt.Workdir)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	versions := packVersions(pks)
+
+	prev, err := readImageLock(opt.Workdir)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		res    = st.imageResolver()
+		lock   = registry.NewImageLock()
+		tags   = make(map[string][]string)
+		sorted = make([]string, 0, len(images))
+		out    = make([]*LockedImage, 0, len(images))
+	)
+	for image := range images {
+		sorted = append(sorted, image)
+	}
+	sort.Strings(sorted)
+
+	for _, image := range sorted {
+		ref, _ := registry.ParseImageRef(image)
+		li := &LockedImage{Image: image, Components: images[image]}
+
+		if li.Digest, err = res.Digest(ref); err != nil {
+			return nil, fmt.Errorf("%s: %v", image, err)
+		}
+		lock.Images[image] = li.Digest
+		if prev != nil {
+			if dgst, ok := prev.Images[image]; ok && dgst != li.Digest {
+				li.Previous = dgst
+			}
+		}
+
+		if constraints, ok := versions[ref.Name]; ok {
+			if _, ok = tags[ref.Name]; !ok {
+				if tags[ref.Name], err = res.Tags(ref); err != nil {
+					return nil, fmt.Errorf("%s: %v", image, err)
+				}
+			}
+			if li.Newer, err = registry.NewestTag(ref.Tag, tags[ref.Name], constraints); err != nil {
+				return nil, fmt.Errorf("%s: %v", ref.Name, err)
+			}
+		}
+
+		out = append(out, li)
+	}
+
+	b, err := lock.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	fpath := imagesLockPath(opt.Workdir)
+	if err = os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+		return nil, err
+	}
+	if err = ioutil.WriteFile(fpath, b, 0644); err != nil {
+		return nil, err
+	}
+
+	// The lock must be committed for others to build from the same digests
+	return out, vcs.UntrackIgnores(opt.Workdir, consts.WorkDir+"/"+consts.ImagesLockFile)
+}
@@ -0,0 +1,200 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+	"github.com/sniperkit/snk.fork.thrap/asm"
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/crt"
+	"github.com/sniperkit/snk.fork.thrap/packs"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	git "gopkg.in/src-d/go-git.v4"
+)
+
+const (
+	packTestStackID = "packtest"
+	// Default time to wait for the smoke command
+	defaultPackSmokeTimeout = 30 * time.Second
+)
+
+var errPackSmokeFailed = errors.New("pack smoke command failed")
+
+// PackTestOptions are options to test a dev pack
+type PackTestOptions struct {
+	// Directory containing the packs by type i.e. dev/<id>
+	Dir string
+	// Dev pack id
+	ID string
+	// Language version to render.  Defaults to the pack default
+	Version string
+	// Smoke command run in the built image.  Defaults to the image command
+	Cmd []string
+	// Time to wait for the smoke command to exit.  The image command passes
+	// if still running after the timeout
+	Timeout time.Duration
+	// Keep the rendered project and built image instead of removing them
+	Keep bool
+	// Build and smoke command output
+	Output io.Writer
+}
+
+// PackTestResult is the result of a pack test
+type PackTestResult struct {
+	// Directory the pack was rendered to.  Only available if kept
+	Dir string
+	// Image built from the rendered project
+	Image string
+	// Exit code of the smoke command
+	ExitCode int64
+}
+
+// TestPack renders the dev pack into a temporary project the same way
+// stack init does, builds its image with the container runtime and runs the
+// smoke command in it.  An error is returned if any step fails or the smoke
+// command exits non-zero
+func (core *Core) TestPack(ctx context.Context, opt PackTestOptions) (*PackTestResult, error) {
+	tmpdir, err := ioutil.TempDir("", "thrap-packtest-")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PackTestResult{Image: "thrap-packtest-" + opt.ID + ":latest"}
+	if opt.Keep {
+		result.Dir = tmpdir
+	} else {
+		defer os.RemoveAll(tmpdir)
+	}
+
+	// Only the pack under test is available
+	pks, err := packs.New(filepath.Join(tmpdir, consts.PacksDir))
+	if err != nil {
+		return nil, err
+	}
+	pks.AddLayer("test", &packs.Source{Type: packs.SourceDir, URL: opt.Dir}, opt.Dir)
+
+	comp, err := core.renderPackTest(pks, tmpdir, opt)
+	if err != nil {
+		return result, err
+	}
+
+	req := &crt.BuildRequest{
+		Output:     opt.Output,
+		ContextDir: filepath.Join(tmpdir, comp.Build.Context),
+		BuildOpts: &types.ImageBuildOptions{
+			Tags:        []string{result.Image},
+			Dockerfile:  comp.Build.Dockerfile,
+			Remove:      true,
+			ForceRemove: true,
+		},
+	}
+	if err = core.crt.Build(ctx, req); err != nil {
+		return result, err
+	}
+	if !opt.Keep {
+		defer core.crt.ImageRemove(ctx, result.Image)
+	}
+
+	result.ExitCode, err = core.runPackSmoke(ctx, result.Image, opt)
+	if err == nil && result.ExitCode != 0 {
+		err = fmt.Errorf("%v: exit code %d", errPackSmokeFailed, result.ExitCode)
+	}
+	return result, err
+}
+
+// renderPackTest renders a single component stack using the pack into dir
+// and returns the component
+func (core *Core) renderPackTest(pks *packs.Packs, dir string, opt PackTestOptions) (*thrapb.Component, error) {
+	lang := thrapb.LanguageID(opt.ID)
+	if opt.Version != "" {
+		lang = thrapb.LanguageID(opt.ID + ":" + opt.Version)
+	}
+
+	stack, err := asm.NewBasicStack(&asm.BasicStackConfig{
+		Name:      packTestStackID,
+		Language:  lang,
+		DataStore: "none",
+		WebServer: "none",
+	}, pks)
+	if err != nil {
+		return nil, err
+	}
+	if errs := stack.Validate(); len(errs) > 0 {
+		return nil, utils.FlattenErrors(errs)
+	}
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	stasm, err := asm.NewStackAsm(stack, dir, core.vcs, repo, nil, pks)
+	if err == nil {
+		err = stasm.AssembleMaterialize()
+	}
+
+	return stack.Components[consts.DefaultAPICompID], err
+}
+
+// runPackSmoke runs the smoke command in the image and returns its exit code.
+// Without a smoke command the image command is expected to be a service and
+// passes if still running after the timeout, after which it is stopped
+func (core *Core) runPackSmoke(ctx context.Context, image string, opt PackTestOptions) (int64, error) {
+	cfg := &thrapb.Container{
+		Name: packTestStackID + "-" + opt.ID,
+		Container: &container.Config{
+			Image: image,
+			Cmd:   opt.Cmd,
+		},
+		Host: &container.HostConfig{},
+	}
+
+	// Remove a container left behind by a previous run
+	core.crt.Remove(ctx, cfg.Name)
+
+	if _, err := core.crt.Run(ctx, cfg); err != nil {
+		return -1, err
+	}
+	defer core.crt.Remove(ctx, cfg.Name)
+
+	timeout := opt.Timeout
+	if timeout <= 0 {
+		timeout = defaultPackSmokeTimeout
+	}
+	wctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	code, err := core.crt.Wait(wctx, cfg.Name)
+	if err != nil {
+		if ctx.Err() != nil || wctx.Err() != context.DeadlineExceeded {
+			return -1, err
+		}
+		if len(opt.Cmd) > 0 {
+			return -1, fmt.Errorf("%v: still running after %v", errPackSmokeFailed, timeout)
+		}
+		if err = core.crt.Stop(ctx, cfg.Name); err != nil {
+			return -1, err
+		}
+		code = 0
+	}
+
+	if opt.Output != nil {
+		err = core.crt.Logs(ctx, cfg.Name, opt.Output, opt.Output)
+	}
+	return code, err
+}
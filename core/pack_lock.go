@@ -0,0 +1,110 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/packs"
+)
+
+// PackFileChange is a pack file changed by a project lock update
+type PackFileChange struct {
+	*packs.LockChange
+	// Contents at the previously locked revision.  nil if added or not
+	// available
+	Old []byte
+	// Contents at the newly locked revision. nil if deleted
+	New []byte
+}
+
+func packsLockPath(workdir string) string {
+	return filepath.Join(workdir, consts.WorkDir, consts.PacksLockFile)
+}
+
+// lockedPacks returns the packs at the revisions locked by the project in
+// workdir along with a cleanup function to call when done.  Projects without
+// a lock use the current packs
+func (st *Stack) lockedPacks(workdir string) (*packs.Packs, func(), error) {
+	noop := func() {}
+
+	lock, err := packs.ReadLock(packsLockPath(workdir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return st.packs, noop, nil
+		}
+		return nil, noop, err
+	}
+
+	tmpdir, err := ioutil.TempDir("", "thrap-locked-packs-")
+	if err != nil {
+		return nil, noop, err
+	}
+	cleanup := func() { os.RemoveAll(tmpdir) }
+
+	pks, err := st.packs.Locked(lock, tmpdir)
+	if err != nil {
+		cleanup()
+		return nil, noop, err
+	}
+	return pks, cleanup, nil
+}
+
+// UpdatePackLock locks the project in workdir to the current revision of the
+// installed pack sources.  It returns the pack files that changed since the
+// previous lock
+func (st *Stack) UpdatePackLock(workdir string) ([]*PackFileChange, error) {
+	fpath := packsLockPath(workdir)
+
+	prev, err := packs.ReadLock(fpath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		prev = &packs.Lock{Sources: make(map[string]*packs.LockedSource)}
+	}
+
+	lock, err := st.packs.Lock()
+	if err != nil {
+		return nil, err
+	}
+
+	tmpdir, err := ioutil.TempDir("", "thrap-locked-packs-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpdir)
+
+	prevDirs := st.packs.LockedDirs(prev, tmpdir)
+
+	diff := packs.DiffLocks(prev, lock)
+	changes := make([]*PackFileChange, len(diff))
+	for i, d := range diff {
+		ch := &PackFileChange{LockChange: d}
+		if dir, ok := prevDirs[d.Source]; ok && d.Status != "A" {
+			ch.Old, _ = ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(d.Path)))
+		}
+		if dir, ok := st.packs.SourceDir(d.Source); ok && d.Status != "D" {
+			if ch.New, err = ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(d.Path))); err != nil {
+				return nil, err
+			}
+		}
+		changes[i] = ch
+	}
+
+	b, err := lock.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+		return nil, err
+	}
+	return changes, ioutil.WriteFile(fpath, b, 0644)
+}
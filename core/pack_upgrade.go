@@ -0,0 +1,92 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/sniperkit/snk.fork.thrap/asm"
+	"github.com/sniperkit/snk.fork.thrap/packs"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+)
+
+var errNoScaffoldRecord = errors.New("no scaffold record found. Project not scaffolded from packs")
+
+// PackUpgradeOptions are options to upgrade scaffolded files of a project
+// to the current packs
+type PackUpgradeOptions struct {
+	// Project directory
+	Workdir string
+	// Only return the upgrades without writing them
+	Dryrun bool
+}
+
+// UpgradePacks upgrades the files scaffolded from packs in the project to
+// the version of the packs locked by the project, or the current version if
+// the project has no lock.  Edits made to the files are merged with
+// the changes made to the packs.  Conflicting changes are written with
+// conflict markers.  It returns the files upgraded
+func (st *Stack) UpgradePacks(stack *thrapb.Stack, opt PackUpgradeOptions) ([]*asm.FileUpgrade, error) {
+	rec, err := asm.ReadScaffoldRecord(opt.Workdir)
+	if err != nil {
+		return nil, err
+	}
+	if len(rec.Components) == 0 {
+		return nil, errNoScaffoldRecord
+	}
+
+	pks, cleanup, err := st.lockedPacks(opt.Workdir)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	scopeVars := st.conf.VCS[st.vcs.ID()].ScopeVars("vcs.")
+	stasm, err := asm.NewStackAsm(stack, opt.Workdir, st.vcs, nil, scopeVars, pks)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpdir, err := ioutil.TempDir("", "thrap-packs-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpdir)
+
+	snapshots := make(map[string]*packs.Packs)
+	snapshot := func(source, version string) (*packs.Packs, error) {
+		key := filepath.Join(source, version)
+		if pks, ok := snapshots[key]; ok {
+			return pks, nil
+		}
+		pks, err := st.packs.Snapshot(source, version, filepath.Join(tmpdir, key))
+		if err == nil {
+			snapshots[key] = pks
+		}
+		return pks, err
+	}
+
+	upgrades, err := stasm.UpgradeScaffold(rec, snapshot)
+	if err != nil || opt.Dryrun {
+		return upgrades, err
+	}
+
+	for _, up := range upgrades {
+		fpath := filepath.Join(opt.Workdir, up.Path)
+		if err = os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+			return nil, err
+		}
+		if err = ioutil.WriteFile(fpath, up.Upgraded, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	return upgrades, rec.Write(opt.Workdir)
+}
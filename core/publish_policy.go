@@ -0,0 +1,243 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"github.com/sniperkit/snk.fork.thrap/vcs"
+)
+
+var (
+	errPublishPolicy         = errors.New("publish policy violated")
+	errOverrideMismatch      = errors.New("override does not match the stack, profile or commit")
+	errOverrideNotAllowed    = errors.New("identity not allowed to override the publish policy")
+	errOverrideUnconfirmed   = errors.New("override identity registration not confirmed")
+	errOverrideSignature     = errors.New("override signature verification failed")
+	errOverrideReasonMissing = errors.New("override reason required")
+)
+
+// NewPublishOverride returns a new unsigned override of the publish policy.
+// It is signed by a registered identity to publish artifacts of a commit
+// despite violating the policy.  Accepted overrides are appended to the
+// audit log of the data directory
+func NewPublishOverride(identity, stack, profile, commit, reason string) (*thrapb.PublishOverride, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, errOverrideReasonMissing
+	}
+
+	return &thrapb.PublishOverride{
+		Identity:  identity,
+		Stack:     stack,
+		Profile:   profile,
+		Commit:    commit,
+		Reason:    reason,
+		Timestamp: time.Now().UnixNano(),
+	}, nil
+}
+
+// checkPublish returns true if artifacts can be published.  Without a
+// publish policy on the profile only a clean worktree is required unless
+// publish was explicitly requested.  Policy violations can only be bypassed
+// by a signed override.  Previews are not subject to the policy
+func (st *Stack) checkPublish(stack *thrapb.Stack, opt BuildOptions) (bool, error) {
+	policy := st.profile.GetPublish()
+	if policy == nil || opt.Preview {
+		return st.checkWorktree(opt)
+	}
+
+	status, err := st.vcs.Status(vcs.Option{Path: opt.Workdir})
+	if err != nil {
+		return false, err
+	}
+	head, err := vcs.ReadHead(opt.Workdir)
+	if err != nil {
+		return false, err
+	}
+	if head.Branch == "" {
+		head.Branch = opt.Branch
+	}
+
+	pgpKeys, err := readKeysFile(policy.GPGKeyring)
+	if err != nil {
+		return false, err
+	}
+	sshKeys, err := readKeysFile(policy.SSHAllowedKeys)
+	if err != nil {
+		return false, err
+	}
+
+	violations := evalPublishPolicy(policy, head, !status.IsClean(), pgpKeys, sshKeys)
+	if len(violations) == 0 {
+		return true, nil
+	}
+
+	fmt.Printf("\nPublish policy violations (profile=%s commit=%s):\n\n", st.profile.ID, head.Commit)
+	for _, v := range violations {
+		fmt.Printf("  - %s\n", v)
+	}
+	fmt.Println()
+
+	if opt.Override != nil {
+		verify := opt.VerifyOverride
+		if verify == nil {
+			verify = st.verifyOverrideSignature
+		}
+		if err = st.verifyOverride(stack, head, policy, opt.Override, verify); err != nil {
+			return false, err
+		}
+		fmt.Printf("** Publish policy overridden by %s: %s **\n", opt.Override.Identity, opt.Override.Reason)
+		return true, st.auditOverride(opt.Override, violations)
+	}
+
+	if opt.Publish {
+		return false, errPublishPolicy
+	}
+
+	fmt.Println("Artifacts will not be published!")
+	return false, nil
+}
+
+// evalPublishPolicy returns the rules of the policy the head violates
+func evalPublishPolicy(policy *thrapb.PublishPolicy, head *vcs.Head, dirty bool, pgpKeys, sshKeys []byte) []string {
+	var out []string
+
+	if dirty {
+		out = append(out, "worktree has uncommitted changes")
+	}
+
+	if len(policy.Branches) > 0 || len(policy.Tags) > 0 {
+		if !matchesAny(policy.Branches, head.Branch) && !matchesAny(policy.Tags, head.Tags...) {
+			ref := head.Branch
+			if ref == "" {
+				ref = "detached"
+			}
+			if len(head.Tags) > 0 {
+				ref += " tags=" + strings.Join(head.Tags, ",")
+			}
+			out = append(out, fmt.Sprintf("HEAD (%s) is not an allowed branch or tag", ref))
+		}
+	}
+
+	if policy.SignedCommits {
+		switch {
+		case head.Signature == "":
+			out = append(out, "HEAD commit is not signed")
+		case len(pgpKeys) == 0 && len(sshKeys) == 0:
+			// Any signature would pass without keys to verify against
+			out = append(out, "HEAD commit signature can not be verified: no gpg_keyring or ssh_allowed_keys configured")
+		default:
+			if err := head.VerifySignature(pgpKeys, sshKeys); err != nil {
+				out = append(out, fmt.Sprintf("HEAD commit signature: %v", err))
+			}
+		}
+	}
+
+	if policy.Pushed && !head.Pushed {
+		out = append(out, "HEAD is not pushed to the remote")
+	}
+
+	return out
+}
+
+// matchesAny returns true if any of the names matches any glob pattern
+func matchesAny(patterns []string, names ...string) bool {
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verifyOverride verifies the override is for this stack, profile and commit
+// and that the identity is allowed to override the policy.  The signature of
+// the identity is checked by verify
+func (st *Stack) verifyOverride(stack *thrapb.Stack, head *vcs.Head, policy *thrapb.PublishPolicy, o *thrapb.PublishOverride, verify func(*thrapb.PublishOverride) error) error {
+	if o.Stack != stack.ID || o.Profile != st.profile.ID || o.Commit != head.Commit {
+		return errOverrideMismatch
+	}
+
+	if len(policy.Overriders) > 0 {
+		var allowed bool
+		for _, id := range policy.Overriders {
+			if id == o.Identity {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return errOverrideNotAllowed
+		}
+	}
+
+	return verify(o)
+}
+
+// verifyOverrideSignature verifies the override against the identities
+// registered in the data dir
+func (st *Stack) verifyOverrideSignature(o *thrapb.PublishOverride) error {
+	idt := &Identity{store: st.ist}
+	_, err := idt.VerifyOverride(o)
+	return err
+}
+
+// auditOverride appends the accepted override and the violations it
+// bypassed to the audit log
+func (st *Stack) auditOverride(o *thrapb.PublishOverride, violations []string) error {
+	entry := struct {
+		Time       time.Time `json:"time"`
+		Action     string    `json:"action"`
+		Violations []string  `json:"violations"`
+		*thrapb.PublishOverride
+	}{time.Now(), "publish-override", violations, o}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	fpath := filepath.Join(st.dataDir, consts.AuditLogFile)
+	fh, err := os.OpenFile(fpath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	_, err = fh.Write(append(b, '\n'))
+	return err
+}
+
+// readKeysFile returns the contents of a key file.  An empty path returns
+// no keys
+func readKeysFile(fpath string) ([]byte, error) {
+	if fpath == "" {
+		return nil, nil
+	}
+
+	fpath, err := utils.GetAbsPath(fpath)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(fpath)
+}
@@ -0,0 +1,141 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"github.com/sniperkit/snk.fork.thrap/vcs"
+	"github.com/stretchr/testify/assert"
+)
+
+type testIdentityStorage map[string]*thrapb.Identity
+
+func (s testIdentityStorage) Get(id string) (*thrapb.Identity, error) {
+	if ident, ok := s[id]; ok {
+		return ident, nil
+	}
+	return nil, errors.New("identity not found")
+}
+
+func (s testIdentityStorage) Create(ident *thrapb.Identity) (*thrapb.Identity, error) {
+	s[ident.ID] = ident
+	return ident, nil
+}
+
+func (s testIdentityStorage) Update(ident *thrapb.Identity) (*thrapb.Identity, error) {
+	s[ident.ID] = ident
+	return ident, nil
+}
+
+func (s testIdentityStorage) Iter(prefix string, f func(*thrapb.Identity) error) error {
+	return nil
+}
+
+func Test_evalPublishPolicy(t *testing.T) {
+	policy := &thrapb.PublishPolicy{
+		Branches: []string{"master", "release/*"},
+		Tags:     []string{"v*"},
+		Pushed:   true,
+	}
+
+	head := &vcs.Head{Branch: "release/1.0", Pushed: true}
+	assert.Empty(t, evalPublishPolicy(policy, head, false, nil, nil))
+
+	// Allowed by tag on a detached head
+	head = &vcs.Head{Tags: []string{"v1.2.0"}, Pushed: true}
+	assert.Empty(t, evalPublishPolicy(policy, head, false, nil, nil))
+
+	policy.SignedCommits = true
+	head = &vcs.Head{Branch: "feature/x"}
+	violations := evalPublishPolicy(policy, head, true, nil, nil)
+	assert.Equal(t, 4, len(violations))
+	assert.Contains(t, violations[1], "feature/x")
+
+	// Signatures can not pass without keys to verify them
+	head = &vcs.Head{Branch: "master", Signature: "-----BEGIN PGP SIGNATURE-----", Pushed: true}
+	violations = evalPublishPolicy(policy, head, false, nil, nil)
+	assert.Equal(t, 1, len(violations))
+	assert.Contains(t, violations[0], "no gpg_keyring or ssh_allowed_keys")
+
+	// Signatures are verified when keys are configured
+	head = &vcs.Head{Branch: "master", Signature: "-----BEGIN SSH SIGNATURE-----", Pushed: true}
+	violations = evalPublishPolicy(policy, head, false, nil, []byte("garbage"))
+	assert.Equal(t, 1, len(violations))
+	assert.Contains(t, violations[0], "HEAD commit signature:")
+}
+
+func Test_PublishOverride(t *testing.T) {
+	kp, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	pubkey := make([]byte, 64)
+	x, y := kp.PublicKey.X.Bytes(), kp.PublicKey.Y.Bytes()
+	copy(pubkey[32-len(x):32], x)
+	copy(pubkey[64-len(y):], y)
+
+	stack := &thrapb.Stack{ID: "myapp"}
+	head := &vcs.Head{Commit: "abc"}
+	policy := &thrapb.PublishPolicy{}
+
+	ist := testIdentityStorage{"ops@example.com": &thrapb.Identity{
+		ID:        "ops@example.com",
+		PublicKey: pubkey,
+		Signature: []byte("confirmed"),
+	}}
+	st := &Stack{profile: &thrapb.Profile{ID: "live"}, ist: ist}
+	sign := func(o *thrapb.PublishOverride) {
+		o.Signature, err = utils.Sign(kp, o.SigHash(sha256.New()))
+		assert.Nil(t, err)
+	}
+	verify := func(o *thrapb.PublishOverride) error {
+		return st.verifyOverride(stack, head, policy, o, st.verifyOverrideSignature)
+	}
+
+	_, err = NewPublishOverride("ops@example.com", "myapp", "live", "abc", " ")
+	assert.Equal(t, errOverrideReasonMissing, err)
+
+	o, err := NewPublishOverride("ops@example.com", "myapp", "live", "abc", "hotfix")
+	assert.Nil(t, err)
+	sign(o)
+	assert.Nil(t, verify(o))
+
+	policy.Overriders = []string{"lead@example.com"}
+	assert.Equal(t, errOverrideNotAllowed, verify(o))
+	policy.Overriders = nil
+
+	o.Reason = "tampered"
+	assert.Equal(t, errOverrideSignature, verify(o))
+
+	o.Commit = "def"
+	assert.Equal(t, errOverrideMismatch, verify(o))
+
+	ist["ops@example.com"].Signature = nil
+	o, _ = NewPublishOverride("ops@example.com", "myapp", "live", "abc", "hotfix")
+	sign(o)
+	assert.Equal(t, errOverrideUnconfirmed, verify(o))
+}
+
+func Test_verifyOverride_delegates(t *testing.T) {
+	st := &Stack{profile: &thrapb.Profile{ID: "live"}}
+	o := &thrapb.PublishOverride{Identity: "ops@example.com", Stack: "myapp", Profile: "live", Commit: "abc"}
+
+	var called bool
+	err := st.verifyOverride(&thrapb.Stack{ID: "myapp"}, &vcs.Head{Commit: "abc"}, &thrapb.PublishPolicy{}, o, func(*thrapb.PublishOverride) error {
+		called = true
+		return errOverrideSignature
+	})
+	assert.True(t, called)
+	assert.Equal(t, errOverrideSignature, err)
+}
@@ -22,6 +22,10 @@ import (
 
 type PublishOptions struct {
 	TagLatest bool
+	// Components not to publish
+	Skip map[string]bool
+	// Additional tags pushed for all components including skipped ones
+	Tags []string
 }
 
 type artifactPublisher struct {
@@ -43,7 +47,7 @@ func (pub *artifactPublisher) Publish(ctx context.Context, stack *thrapb.Stack,
 		return nil, runtime, err
 	}
 
-	reqs := pub.buildPushRequests(stack, opts.TagLatest)
+	reqs := pub.buildPushRequests(stack, opts)
 	resps := make(map[string]error, len(reqs))
 
 	for image, req := range reqs {
@@ -98,7 +102,7 @@ func (pub *artifactPublisher) getRegistryAuth() string {
 	return base64.URLEncoding.EncodeToString(b)
 }
 
-func (pub *artifactPublisher) buildPushRequests(stack *thrapb.Stack, tagLatest bool) map[string]*crt.PushRequest {
+func (pub *artifactPublisher) buildPushRequests(stack *thrapb.Stack, opts PublishOptions) map[string]*crt.PushRequest {
 	reqs := make(map[string]*crt.PushRequest, len(stack.Components)*2)
 	for id, comp := range stack.Components {
 		if !comp.IsBuildable() {
@@ -106,8 +110,22 @@ func (pub *artifactPublisher) buildPushRequests(stack *thrapb.Stack, tagLatest b
 		}
 
 		name := stack.ArtifactName(id)
+		for _, tag := range opts.Tags {
+			reqs[name+":"+tag] = &crt.PushRequest{
+				Image:  name,
+				Tag:    tag,
+				Output: os.Stdout,
+				Options: types.ImagePushOptions{
+					RegistryAuth: pub.getRegistryAuth(),
+				},
+			}
+		}
+
+		if opts.Skip[id] {
+			continue
+		}
 
-		if tagLatest {
+		if opts.TagLatest {
 			reqs[name+":latest"] = &crt.PushRequest{
 				Image:  name + ":latest",
 				Output: os.Stdout,
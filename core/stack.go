@@ -10,7 +10,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"text/tabwriter"
@@ -64,9 +63,15 @@ type Stack struct {
 	// code version control provider
 	vcs vcs.VCS
 
+	// credentials of the core
+	creds *config.CredsConfig
+
 	// registry loaded based on profile
 	reg registry.Registry
 
+	// profile the instance was loaded with
+	profile *thrapb.Profile
+
 	// orchestrator loaded based on profile
 	orch orchestrator.Orchestrator
 
@@ -76,6 +81,12 @@ type Stack struct {
 	// stack store
 	sst StackStorage
 
+	// identity store used to verify signed requests
+	ist IdentityStorage
+
+	// data directory of the core
+	dataDir string
+
 	log *log.Logger
 }
 
@@ -160,17 +171,37 @@ func (st *Stack) Init(stconf *asm.BasicStackConfig, opt ConfigureOptions) (*thra
 	if err == nil {
 		err = stasm.WriteManifest()
 	}
+	if err == nil {
+		err = st.writePackLock(stasm)
+	}
 
 	return stack, err
 }
 
+// writePackLock locks the project to the packs it was scaffolded from
+func (st *Stack) writePackLock(stasm *asm.StackAsm) error {
+	lock, err := st.packs.Lock()
+	if err == nil {
+		err = stasm.WritePackLock(lock)
+	}
+	return err
+}
+
 func (st *Stack) scopeVars(stack *thrapb.Stack) scope.Variables {
+	return st.scopeVarsWithHost(stack, func(comp *thrapb.Component) string {
+		return comp.ID + "." + stack.ID
+	})
+}
+
+// scopeVarsWithHost returns the scope vars of the stack with container
+// addresses built from the host of each component
+func (st *Stack) scopeVarsWithHost(stack *thrapb.Stack, host func(*thrapb.Component) string) scope.Variables {
 	svars := stack.ScopeVars()
-	for k, v := range stack.Components {
+	for _, v := range stack.Components {
 
 		ipvar := ast.Variable{
 			Type:  ast.TypeString,
-			Value: k + "." + stack.ID,
+			Value: host(v),
 		}
 
 		// Set container ip var
@@ -188,24 +219,6 @@ func (st *Stack) scopeVars(stack *thrapb.Stack) scope.Variables {
 	return svars
 }
 
-// Log writes the log for a running component to the writers
-func (st *Stack) Log(ctx context.Context, id string, stdout, stderr io.Writer) error {
-	return st.crt.Logs(ctx, id, stdout, stderr)
-}
-
-// Logs writes all running component logs for the stack
-func (st *Stack) Logs(ctx context.Context, stack *thrapb.Stack, stdout, stderr io.Writer) error {
-	var err error
-	for _, comp := range stack.Components {
-		er := st.crt.Logs(ctx, comp.ID+"."+stack.ID, stdout, stderr)
-		if er != nil {
-			err = er
-		}
-	}
-
-	return err
-}
-
 // Status returns a CompStatus slice containing the status of each component
 // in the stack
 func (st *Stack) Status(ctx context.Context, stack *thrapb.Stack) []*thrapb.CompStatus {
@@ -249,6 +262,12 @@ func (st *Stack) Build(ctx context.Context, stack *thrapb.Stack, opt BuildOption
 		return utils.FlattenErrors(errs)
 	}
 
+	if opt.Lint {
+		if err := lintGate(stack, opt.Workdir); err != nil {
+			return err
+		}
+	}
+
 	var (
 		totalTime = (&metrics.Runtime{}).Start()
 		pubTime   = &metrics.Runtime{}
@@ -266,6 +285,17 @@ func (st *Stack) Build(ctx context.Context, stack *thrapb.Stack, opt BuildOption
 	}
 
 	bldr := newStackBuilder(st.crt, st.reg, stack)
+	bldr.commit = opt.Commit
+	if bldr.run.images, err = readImageLock(opt.Workdir); err != nil {
+		return err
+	}
+	// Versions are only derived from sources within a repo
+	if !opt.Rebuild {
+		_, verr := st.vcs.Status(vcs.Option{Path: opt.Workdir})
+		if verr == nil {
+			bldr.cached = opt.Versioned
+		}
+	}
 	err = bldr.Build(ctx)
 	if err != nil {
 		return err
@@ -309,8 +339,8 @@ func (st *Stack) Build(ctx context.Context, stack *thrapb.Stack, opt BuildOption
 		return err
 	}
 
-	// We can publish if the worktree is clean
-	canPublish, err = st.checkWorktree(opt)
+	// We can publish if the worktree is clean and the profile policy is met
+	canPublish, err = st.checkPublish(stack, opt)
 	if err != nil {
 		return err
 	}
@@ -320,13 +350,34 @@ func (st *Stack) Build(ctx context.Context, stack *thrapb.Stack, opt BuildOption
 
 	if canPublish {
 		publisher := &artifactPublisher{crt: st.crt, reg: st.reg}
-		pubResults, pubTime, err = publisher.Publish(ctx, stack, PublishOptions{})
+		pubOpts := PublishOptions{Skip: st.publishedComponents(stack, bldResults)}
+		if opt.Commit != "" {
+			pubOpts.Tags = []string{opt.Commit}
+		}
+		pubResults, pubTime, err = publisher.Publish(ctx, stack, pubOpts)
 		// pubResults, pubTime = st.publishArtifacts(stack)
 	}
 
 	return err
 }
 
+// publishedComponents returns the cached components whose version has
+// already been published to the registry
+func (st *Stack) publishedComponents(stack *thrapb.Stack, results map[string]*CompBuildResult) map[string]bool {
+	out := make(map[string]bool)
+	for id, r := range results {
+		if !r.Cached {
+			continue
+		}
+		comp := stack.Components[id]
+		if _, err := st.reg.GetManifest(stack.ArtifactName(id), comp.Version); err == nil {
+			out[id] = true
+			fmt.Printf("Skipping publish %s:%s: already published\n", id, comp.Version)
+		}
+	}
+	return out
+}
+
 // Deploy deploys all components of the stack.
 func (st *Stack) Deploy(stack *thrapb.Stack, opts orchestrator.RequestOptions) error {
 	if errs := stack.Validate(); len(errs) > 0 {
@@ -361,6 +412,21 @@ func (st *Stack) Deploy(stack *thrapb.Stack, opts orchestrator.RequestOptions) e
 	if opts.Dryrun {
 		b, _ := json.MarshalIndent(j, "", "  ")
 		fmt.Printf("%s\n", b)
+		return nil
+	}
+
+	// Seeding is only supported against the local docker runtime
+	if st.Local() {
+		wd, _ := os.Getwd()
+		ar := st.Seed(ctx, stack, wd)
+		if len(ar) > 0 {
+			thrapb.ActionsResults{"Seed:": ar}.Print(os.Stdout)
+		}
+		for _, r := range ar {
+			if r.Error != nil {
+				return errors.Wrap(r.Error, "seed "+r.Resource)
+			}
+		}
 	}
 	// fmt.Printf("%+v\n", resp)
 	// fmt.Printf("%+v\n", obj)
@@ -368,6 +434,11 @@ func (st *Stack) Deploy(stack *thrapb.Stack, opts orchestrator.RequestOptions) e
 	return nil
 }
 
+// Local returns true if stacks are deployed to the local container runtime
+func (st *Stack) Local() bool {
+	return st.orch.ID() == "docker"
+}
+
 func (st *Stack) checkArtifactsExist(stack *thrapb.Stack) error {
 
 	var (
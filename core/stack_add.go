@@ -0,0 +1,103 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/sniperkit/snk.fork.thrap/asm"
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/manifest"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	git "gopkg.in/src-d/go-git.v4"
+)
+
+var errYAMLManifestRequired = errors.New(consts.DefaultManifestFile + " manifest required")
+
+// AddComponent adds a component to the existing stack in the project
+// directory.  Files for the component are scaffolded from its pack, env vars
+// are wired into the components depending on it and the manifest is updated
+// in place preserving user edits and comments.  All changes are committed.
+// It returns the added component
+func (st *Stack) AddComponent(stack *thrapb.Stack, c *asm.ComponentConfig, workdir string) (*thrapb.Component, error) {
+	mfile := filepath.Join(workdir, consts.DefaultManifestFile)
+	if !utils.FileExists(mfile) {
+		return nil, errYAMLManifestRequired
+	}
+	mdata, err := ioutil.ReadFile(mfile)
+	if err != nil {
+		return nil, err
+	}
+
+	pks, cleanup, err := st.lockedPacks(workdir)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	wired, err := asm.AddComponent(stack, c, pks)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", c.ID, err)
+	}
+	comp := stack.Components[c.ID]
+
+	// Only populate the new component from its image
+	st.populateFromImageConf(&thrapb.Stack{
+		ID:         stack.ID,
+		Components: map[string]*thrapb.Component{c.ID: comp},
+	})
+
+	// Update the manifest before validation fills in defaults
+	mdata, err = manifest.AddComponentYAML(mdata, c.ID, comp)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(wired))
+	for id := range wired {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if mdata, err = manifest.AddComponentEnvVarsYAML(mdata, id, wired[id]); err != nil {
+			return nil, err
+		}
+	}
+
+	if errs := stack.Validate(); len(errs) > 0 {
+		return nil, utils.FlattenErrors(errs)
+	}
+
+	repo, err := git.PlainOpen(workdir)
+	if err != nil {
+		return nil, err
+	}
+
+	scopeVars := st.conf.VCS[st.vcs.ID()].ScopeVars("vcs.")
+	stasm, err := asm.NewStackAsm(stack, workdir, st.vcs, repo, scopeVars, pks)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = stasm.AssembleComponent(c.ID); err != nil {
+		return nil, err
+	}
+	if err = stasm.Materialize(); err != nil {
+		return nil, err
+	}
+	if err = stasm.UpdateManifest(mdata); err != nil {
+		return nil, err
+	}
+
+	err = stasm.Commit("Add component " + c.ID)
+	return comp, err
+}
@@ -0,0 +1,270 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/vcs"
+)
+
+// ChangelogFile is the changelog file in the root of the repo
+const ChangelogFile = "CHANGELOG.md"
+
+// Sections in display order keyed by conventional commit type.  All other
+// types are listed under the last section
+var changelogSections = []struct {
+	typ   string
+	title string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance"},
+	{"refactor", "Refactoring"},
+	{"docs", "Documentation"},
+	{"test", "Tests"},
+	{"build", "Build"},
+	{"ci", "CI"},
+	{"chore", "Chores"},
+	{"", "Other Changes"},
+}
+
+// ChangelogOptions are options to generate a changelog
+type ChangelogOptions struct {
+	// Workdir is the root of the git repo
+	Workdir string
+	// Revision the changes start after. Defaults to the prior version
+	From string
+	// Revision the changes end at. Defaults to HEAD
+	To string
+}
+
+// ChangelogEntry is a single change
+type ChangelogEntry struct {
+	Hash     string `json:"hash"`
+	Scope    string `json:"scope,omitempty"`
+	Subject  string `json:"subject"`
+	Author   string `json:"author"`
+	Breaking bool   `json:"breaking,omitempty"`
+	// Components whose build context the change touched
+	Components []string `json:"components,omitempty"`
+}
+
+// ChangelogSection is a group of changes of the same type
+type ChangelogSection struct {
+	Type    string            `json:"type"`
+	Title   string            `json:"title"`
+	Entries []*ChangelogEntry `json:"entries"`
+}
+
+// Changelog is the set of changes of a version grouped by type
+type Changelog struct {
+	Version  string              `json:"version"`
+	From     string              `json:"from,omitempty"`
+	Date     time.Time           `json:"date"`
+	Breaking []*ChangelogEntry   `json:"breaking,omitempty"`
+	Sections []*ChangelogSection `json:"sections"`
+}
+
+// WriteMarkdown writes the changelog as markdown to the writer
+func (cl *Changelog) WriteMarkdown(w io.Writer) {
+	fmt.Fprintf(w, "## %s (%s)\n\n", cl.Version, cl.Date.Format("2006-01-02"))
+
+	if len(cl.Breaking) > 0 {
+		fmt.Fprintf(w, "### Breaking Changes\n\n")
+		for _, e := range cl.Breaking {
+			writeMarkdownEntry(w, e)
+		}
+		fmt.Fprintln(w)
+	}
+
+	for _, s := range cl.Sections {
+		fmt.Fprintf(w, "### %s\n\n", s.Title)
+		for _, e := range s.Entries {
+			writeMarkdownEntry(w, e)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// Markdown returns the changelog as markdown
+func (cl *Changelog) Markdown() string {
+	buf := new(bytes.Buffer)
+	cl.WriteMarkdown(buf)
+	return buf.String()
+}
+
+func writeMarkdownEntry(w io.Writer, e *ChangelogEntry) {
+	fmt.Fprint(w, "- ")
+	if e.Scope != "" {
+		fmt.Fprintf(w, "**%s:** ", e.Scope)
+	}
+	fmt.Fprintf(w, "%s (%s)", e.Subject, e.Hash[:8])
+	if len(e.Components) > 0 {
+		fmt.Fprintf(w, " [%s]", strings.Join(e.Components, ", "))
+	}
+	fmt.Fprintln(w)
+}
+
+// GenerateChangelog returns the changes between two revisions of the stack
+// repo grouped by conventional commit type and attributed to the components
+// by their build context
+func GenerateChangelog(stack *thrapb.Stack, opt ChangelogOptions) (*Changelog, error) {
+	cs, err := vcs.ReadChanges(opt.Workdir, opt.From, opt.To)
+	if err != nil {
+		return nil, err
+	}
+
+	cl := &Changelog{
+		Version: cs.To,
+		From:    cs.From,
+		Date:    time.Now(),
+	}
+
+	// Name the head by its tag if it has one
+	if opt.To == "" || opt.To == "HEAD" {
+		cl.Version = "Unreleased"
+		if rv := vcs.GetRepoVersion(opt.Workdir); rv.Count == 0 && !rv.TagHash.IsZero() {
+			cl.Version = rv.Tag
+		}
+	}
+
+	var (
+		contexts = componentContexts(stack, opt.Workdir)
+		sections = make(map[string]*ChangelogSection, len(changelogSections))
+	)
+
+	for _, ch := range cs.Changes {
+		entry := &ChangelogEntry{
+			Hash:       ch.Hash,
+			Scope:      ch.Scope,
+			Subject:    ch.Subject,
+			Author:     ch.Author,
+			Breaking:   ch.Breaking,
+			Components: attributeFiles(contexts, ch.Files),
+		}
+		if entry.Breaking {
+			cl.Breaking = append(cl.Breaking, entry)
+		}
+
+		typ := ch.Type
+		if !isChangelogSection(typ) {
+			typ = ""
+		}
+		sec, ok := sections[typ]
+		if !ok {
+			sec = &ChangelogSection{Type: typ}
+			sections[typ] = sec
+		}
+		sec.Entries = append(sec.Entries, entry)
+	}
+
+	for _, s := range changelogSections {
+		if sec, ok := sections[s.typ]; ok {
+			sec.Title = s.title
+			cl.Sections = append(cl.Sections, sec)
+		}
+	}
+
+	return cl, nil
+}
+
+// WriteChangelog prepends the changelog to the changelog file in the
+// workdir keeping any top level heading first
+func WriteChangelog(workdir string, cl *Changelog) error {
+	fpath := filepath.Join(workdir, ChangelogFile)
+
+	existing, err := ioutil.ReadFile(fpath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var head, rest string
+	if bytes.HasPrefix(existing, []byte("# ")) {
+		parts := strings.SplitN(string(existing), "\n", 2)
+		head = parts[0] + "\n\n"
+		if len(parts) > 1 {
+			rest = strings.TrimLeft(parts[1], "\n")
+		}
+	} else {
+		head = "# Changelog\n\n"
+		rest = string(existing)
+	}
+
+	return ioutil.WriteFile(fpath, []byte(head+cl.Markdown()+rest), 0644)
+}
+
+func isChangelogSection(typ string) bool {
+	for _, s := range changelogSections {
+		if s.typ == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// componentContexts returns the build context of each buildable component
+// relative to the workdir using forward slashes
+func componentContexts(stack *thrapb.Stack, workdir string) map[string]string {
+	out := make(map[string]string)
+	for id, comp := range stack.Components {
+		if !comp.IsBuildable() {
+			continue
+		}
+
+		ctx := comp.Build.Context
+		if filepath.IsAbs(ctx) {
+			if rel, err := filepath.Rel(workdir, ctx); err == nil {
+				ctx = rel
+			}
+		}
+		out[id] = filepath.ToSlash(filepath.Clean(ctx))
+	}
+	return out
+}
+
+// attributeFiles returns the sorted components owning any of the files.  A
+// component whose context is the repo root only owns files not within the
+// context of another component
+func attributeFiles(contexts map[string]string, files []string) []string {
+	owners := make(map[string]struct{})
+
+	for _, f := range files {
+		var claimed bool
+		for id, ctx := range contexts {
+			if ctx != "." && strings.HasPrefix(f, ctx+"/") {
+				owners[id] = struct{}{}
+				claimed = true
+			}
+		}
+		if claimed {
+			continue
+		}
+
+		for id, ctx := range contexts {
+			if ctx == "." {
+				owners[id] = struct{}{}
+			}
+		}
+	}
+
+	out := make([]string, 0, len(owners))
+	for id := range owners {
+		out = append(out, id)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,65 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_attributeFiles(t *testing.T) {
+	contexts := map[string]string{
+		"api":  "api",
+		"web":  "services/web",
+		"root": ".",
+	}
+
+	assert.Equal(t, []string{"api"}, attributeFiles(contexts, []string{"api/main.go"}))
+	assert.Equal(t, []string{"api", "web"},
+		attributeFiles(contexts, []string{"api/main.go", "services/web/index.html"}))
+	assert.Equal(t, []string{"root"}, attributeFiles(contexts, []string{"README.md"}))
+	assert.Equal(t, []string{"root"}, attributeFiles(contexts, []string{"apidocs/x.md"}))
+	assert.Empty(t, attributeFiles(map[string]string{"api": "api"}, []string{"README.md"}))
+}
+
+func Test_WriteChangelog(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "chl-")
+	defer os.RemoveAll(tmpdir)
+
+	cl := &Changelog{
+		Version: "v1.1.0",
+		Date:    time.Date(2018, 8, 1, 0, 0, 0, 0, time.UTC),
+		Sections: []*ChangelogSection{
+			{Type: "feat", Title: "Features", Entries: []*ChangelogEntry{
+				{Hash: "0123456789abcdef", Scope: "api", Subject: "add endpoint", Components: []string{"api"}},
+			}},
+		},
+	}
+
+	md := cl.Markdown()
+	assert.True(t, strings.HasPrefix(md, "## v1.1.0 (2018-08-01)\n"))
+	assert.Contains(t, md, "### Features\n\n- **api:** add endpoint (01234567) [api]\n")
+
+	err := WriteChangelog(tmpdir, cl)
+	assert.Nil(t, err)
+
+	cl.Version = "v1.2.0"
+	err = WriteChangelog(tmpdir, cl)
+	assert.Nil(t, err)
+
+	b, _ := ioutil.ReadFile(filepath.Join(tmpdir, ChangelogFile))
+	content := string(b)
+	assert.True(t, strings.HasPrefix(content, "# Changelog\n\n## v1.2.0"))
+	assert.True(t, strings.Index(content, "## v1.2.0") < strings.Index(content, "## v1.1.0"))
+	assert.Equal(t, 1, strings.Count(content, "# Changelog"))
+}
@@ -0,0 +1,424 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+	"github.com/sniperkit/snk.fork.thrap/crt"
+	"github.com/sniperkit/snk.fork.thrap/orchestrator"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+)
+
+const (
+	// SnapshotsDir is the directory name under the data dir where snapshots
+	// are stored per stack
+	snapshotsDir = "snapshots"
+	// snapshotManifestFile holds the snapshot metadata
+	snapshotManifestFile = "snapshot.json"
+	// seedsDir is the directory name under the data dir holding a marker per
+	// set of seeded datastore volumes
+	seedsDir = "seeds"
+	// seedMarkerFile is written to the seed target once a seed succeeds for
+	// datastores without volumes
+	seedMarkerFile = ".thrap-seeded"
+)
+
+var (
+	errSnapshotNotFound = errors.New("snapshot not found")
+	errNoDatastoreVols  = errors.New("no datastore volumes to snapshot")
+)
+
+// Snapshot holds the metadata of a saved datastore snapshot
+type Snapshot struct {
+	Name    string
+	Stack   string
+	Created time.Time
+	// Volume targets per component. Each volume archive is stored as
+	// <component>-<index>.tar
+	Components map[string][]string
+}
+
+// Seed runs the seed of each datastore component that has one, unless it
+// has already been seeded.  Seed files are resolved relative to workdir
+func (st *Stack) Seed(ctx context.Context, stack *thrapb.Stack, workdir string) []*thrapb.ActionResult {
+	ar := make([]*thrapb.ActionResult, 0)
+
+	for _, comp := range stack.Components {
+		if !comp.HasSeed() {
+			continue
+		}
+
+		r := &thrapb.ActionResult{Action: "seed", Resource: comp.ID}
+		r.Data, r.Error = st.seedComponent(ctx, stack, comp, workdir)
+		ar = append(ar, r)
+	}
+
+	return ar
+}
+
+// seedComponent returns true if the seed was run and false if the datastore
+// was already seeded
+func (st *Stack) seedComponent(ctx context.Context, stack *thrapb.Stack, comp *thrapb.Component, workdir string) (bool, error) {
+	var (
+		cid  = comp.ID + "." + stack.ID
+		seed = comp.Seed
+		out  bytes.Buffer
+	)
+
+	mounts, err := st.dataMounts(ctx, cid)
+	if err != nil {
+		return false, err
+	}
+
+	// The marker lives alongside the data it describes.  Volumes outlive the
+	// container so their marker is kept in the data dir keyed by volume
+	marker := st.seedMarker(mounts)
+	if marker != "" {
+		if _, err = os.Stat(marker); err == nil {
+			return false, nil
+		}
+	} else {
+		code, err := st.shellExec(ctx, cid, "test -f "+path.Join(seed.Target, seedMarkerFile), &out)
+		if err == nil && code == 0 {
+			return false, nil
+		}
+	}
+
+	if len(seed.Files) > 0 {
+		err = st.crt.CopyFilesToContainer(ctx, cid, workdir, seed.Files, seed.Target)
+	} else {
+		_, err = st.shellExec(ctx, cid, "mkdir -p "+seed.Target, &out)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// The datastore may still be starting.  Only seed once it is healthy so
+	// a failing seed command is reported rather than retried
+	err = orchestrator.WaitContainerHealthy(ctx, st.crt, stack, comp, time.Duration(seed.Timeout)*time.Second)
+	if err != nil {
+		return false, err
+	}
+
+	out.Reset()
+	code, err := st.shellExec(ctx, cid, "cd "+seed.Target+" && "+seed.Cmd, &out)
+	if err != nil {
+		return false, err
+	}
+	if code != 0 {
+		return false, fmt.Errorf("seed exited with code=%d: %s", code, bytes.TrimSpace(out.Bytes()))
+	}
+
+	if marker == "" {
+		_, err = st.shellExec(ctx, cid, "touch "+path.Join(seed.Target, seedMarkerFile), &out)
+		return true, err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(marker), 0755); err == nil {
+		err = ioutil.WriteFile(marker, []byte(cid+"\n"), 0644)
+	}
+	return true, err
+}
+
+// seedMarker returns the host path of the seed marker for the given volume
+// mounts.  An empty string is returned if there are none
+func (st *Stack) seedMarker(mounts []types.MountPoint) string {
+	if len(mounts) == 0 {
+		return ""
+	}
+
+	keys := make([]string, len(mounts))
+	for i, m := range mounts {
+		if m.Name != "" {
+			keys[i] = m.Name
+		} else {
+			keys[i] = m.Source
+		}
+	}
+	sort.Strings(keys)
+
+	sum := sha1.Sum([]byte(strings.Join(keys, "\n")))
+	return filepath.Join(st.dataDir, seedsDir, hex.EncodeToString(sum[:]))
+}
+
+// dataMounts returns the volume mounts of the container, including those
+// declared by the image, sorted by destination.  Bind mounts are host
+// directories and are never snapshotted or cleared
+func (st *Stack) dataMounts(ctx context.Context, cid string) ([]types.MountPoint, error) {
+	cont, err := st.crt.Inspect(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	mounts := make([]types.MountPoint, 0, len(cont.Mounts))
+	for _, m := range cont.Mounts {
+		if m.Type == "volume" {
+			mounts = append(mounts, m)
+		}
+	}
+	sort.Slice(mounts, func(i, j int) bool {
+		return mounts[i].Destination < mounts[j].Destination
+	})
+	return mounts, nil
+}
+
+func (st *Stack) shellExec(ctx context.Context, cid, cmd string, out io.Writer) (int, error) {
+	req := &crt.ExecRequest{
+		Cmd:    []string{"sh", "-c", cmd},
+		Stdout: out,
+		Stderr: out,
+	}
+	return st.crt.Exec(ctx, cid, req)
+}
+
+// snapshotDir returns the directory for the named stack snapshot
+func (st *Stack) snapshotDir(stackID, name string) string {
+	return filepath.Join(st.dataDir, snapshotsDir, stackID, name)
+}
+
+// SaveSnapshot archives the volumes of all datastore containers in the stack
+// under the given name, replacing any existing snapshot by that name
+func (st *Stack) SaveSnapshot(ctx context.Context, stack *thrapb.Stack, name string) (*Snapshot, error) {
+	snap := &Snapshot{
+		Name:       name,
+		Stack:      stack.ID,
+		Created:    time.Now(),
+		Components: make(map[string][]string),
+	}
+
+	dir := st.snapshotDir(stack.ID, name)
+	tmpdir := dir + ".tmp"
+	os.RemoveAll(tmpdir)
+	if err := os.MkdirAll(tmpdir, 0755); err != nil {
+		return nil, err
+	}
+
+	for _, comp := range stack.Components {
+		if comp.Type != thrapb.CompTypeDatastore {
+			continue
+		}
+
+		// Mounts include the volumes declared by the image and not only
+		// those in the manifest
+		cid := comp.ID + "." + stack.ID
+		mounts, err := st.dataMounts(ctx, cid)
+		if err != nil {
+			os.RemoveAll(tmpdir)
+			return nil, errors.Wrap(err, comp.ID)
+		}
+		if len(mounts) == 0 {
+			continue
+		}
+
+		targets := make([]string, 0, len(mounts))
+		for i, m := range mounts {
+			fname := filepath.Join(tmpdir, fmt.Sprintf("%s-%d.tar", comp.ID, i))
+			if err = st.saveVolume(ctx, cid, m.Destination, fname); err != nil {
+				os.RemoveAll(tmpdir)
+				return nil, errors.Wrap(err, comp.ID)
+			}
+			targets = append(targets, m.Destination)
+		}
+		snap.Components[comp.ID] = targets
+	}
+
+	if len(snap.Components) == 0 {
+		os.RemoveAll(tmpdir)
+		return nil, errNoDatastoreVols
+	}
+
+	b, _ := json.MarshalIndent(snap, "", "  ")
+	err := ioutil.WriteFile(filepath.Join(tmpdir, snapshotManifestFile), b, 0644)
+	if err == nil {
+		os.RemoveAll(dir)
+		err = os.Rename(tmpdir, dir)
+	}
+	return snap, err
+}
+
+func (st *Stack) saveVolume(ctx context.Context, cid, target, fname string) error {
+	rc, err := st.crt.CopyFromContainer(ctx, cid, target)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	fh, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	_, err = io.Copy(fh, rc)
+	return err
+}
+
+// RestoreSnapshot stops each datastore in the snapshot, restores its volumes
+// and starts it again
+func (st *Stack) RestoreSnapshot(ctx context.Context, stack *thrapb.Stack, name string) []*thrapb.ActionResult {
+	snap, err := st.GetSnapshot(stack.ID, name)
+	if err != nil {
+		return []*thrapb.ActionResult{{Action: "restore", Resource: name, Error: err}}
+	}
+
+	dir := st.snapshotDir(stack.ID, name)
+	ar := make([]*thrapb.ActionResult, 0, len(snap.Components))
+	for compID, targets := range snap.Components {
+		r := &thrapb.ActionResult{Action: "restore", Resource: compID}
+		r.Error = st.restoreComponent(ctx, compID+"."+stack.ID, compID, targets, dir)
+		ar = append(ar, r)
+	}
+	return ar
+}
+
+func (st *Stack) restoreComponent(ctx context.Context, cid, compID string, targets []string, dir string) error {
+	// Only volumes are cleared and restored.  Refuse anything else such as a
+	// target now bind mounted from the host
+	mounts, err := st.dataMounts(ctx, cid)
+	if err != nil {
+		return err
+	}
+	for _, target := range targets {
+		if !hasMountTarget(mounts, target) {
+			return fmt.Errorf("not a volume mount: %s", target)
+		}
+	}
+
+	if err = st.crt.Stop(ctx, cid); err != nil {
+		return err
+	}
+
+	for i, target := range targets {
+		fname := filepath.Join(dir, fmt.Sprintf("%s-%d.tar", compID, i))
+		if err = st.restoreVolume(ctx, cid, target, fname); err != nil {
+			break
+		}
+	}
+
+	// Always attempt to bring the datastore back
+	if er := st.crt.Start(ctx, cid); er != nil && err == nil {
+		err = er
+	}
+	return err
+}
+
+// hasMountTarget returns true if one of the mounts has the target as its
+// destination
+func hasMountTarget(mounts []types.MountPoint, target string) bool {
+	for _, m := range mounts {
+		if m.Destination == target {
+			return true
+		}
+	}
+	return false
+}
+
+func (st *Stack) restoreVolume(ctx context.Context, cid, target, fname string) error {
+	fh, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	// Files created after the snapshot must not survive the restore
+	if err = st.clearVolume(ctx, cid, target); err != nil {
+		return errors.Wrap(err, target)
+	}
+
+	// Archives are rooted at the base of the target
+	return st.crt.CopyToContainer(ctx, cid, path.Dir(target), fh)
+}
+
+// clearVolume removes the contents of the target volume of the stopped
+// container.  This is done in a one-shot container sharing its volumes, as
+// the container itself cannot exec while stopped
+func (st *Stack) clearVolume(ctx context.Context, cid, target string) error {
+	cont, err := st.crt.Inspect(ctx, cid)
+	if err != nil {
+		return err
+	}
+
+	cfg := &thrapb.Container{
+		Name: cid + "-restore",
+		Container: &container.Config{
+			Image:      cont.Config.Image,
+			User:       "0",
+			Entrypoint: []string{"sh", "-c", `find "$0" -mindepth 1 -delete`, target},
+		},
+		Host: &container.HostConfig{VolumesFrom: []string{cid}},
+	}
+
+	// Remove leftovers from a previously interrupted run
+	st.crt.Remove(ctx, cfg.Name)
+	defer st.crt.Remove(context.Background(), cfg.Name)
+
+	if _, err = st.crt.Run(ctx, cfg); err != nil {
+		return err
+	}
+
+	code, err := st.crt.Wait(ctx, cfg.Name)
+	if err == nil && code != 0 {
+		var out bytes.Buffer
+		st.crt.Logs(context.Background(), cfg.Name, &out, &out)
+		err = fmt.Errorf("clear exited with code=%d: %s", code, bytes.TrimSpace(out.Bytes()))
+	}
+	return err
+}
+
+// GetSnapshot returns the metadata of the named snapshot for the stack
+func (st *Stack) GetSnapshot(stackID, name string) (*Snapshot, error) {
+	b, err := ioutil.ReadFile(filepath.Join(st.snapshotDir(stackID, name), snapshotManifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Wrap(errSnapshotNotFound, name)
+		}
+		return nil, err
+	}
+
+	var snap Snapshot
+	err = json.Unmarshal(b, &snap)
+	return &snap, err
+}
+
+// ListSnapshots returns all snapshots of the stack
+func (st *Stack) ListSnapshots(stackID string) ([]*Snapshot, error) {
+	files, err := ioutil.ReadDir(filepath.Join(st.dataDir, snapshotsDir, stackID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	out := make([]*Snapshot, 0, len(files))
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		if snap, err := st.GetSnapshot(stackID, f.Name()); err == nil {
+			out = append(out, snap)
+		}
+	}
+	return out, nil
+}
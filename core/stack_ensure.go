@@ -24,6 +24,10 @@ func (st *Stack) EnsureResources(stack *thrapb.Stack) thrapb.ActionsResults {
 	results["registry"] = st.ensureRegistryRepos(stack)
 	// Secrets
 	results["secrets"] = st.ensureSecrets(stack)
+	// Build trigger
+	if st.vcs != nil && st.conf.Webhook != nil && st.conf.Webhook.URL != "" {
+		results["webhook"] = []*thrapb.ActionResult{st.ensureWebhook(stack)}
+	}
 
 	return results
 }
@@ -78,28 +82,15 @@ func (st *Stack) ensureSecrets(stack *thrapb.Stack) []*thrapb.ActionResult {
 
 func (st *Stack) createVcsRepo(stack *thrapb.Stack) *thrapb.ActionResult {
 
-	er := &thrapb.ActionResult{
-		Action:   "create",
-		Resource: st.vcs.ID(),
-	}
-
+	er := &thrapb.ActionResult{Action: "create", Resource: "vcs"}
 	if st.vcs == nil {
 		er.Error = errProviderNotConfigured
 		return er
 	}
-
-	repo := &vcs.Repository{
-		Name:        stack.ID,
-		Description: stack.Description,
-	}
-
-	vc := st.conf.VCS[st.vcs.ID()]
-	if vc.Repo.Owner != "" {
-		repo.Owner = vc.Repo.Owner
-	}
+	er.Resource = st.vcs.ID()
 
 	var vcsOpt vcs.Option
-	_, created, err := st.vcs.Create(repo, vcsOpt)
+	_, created, err := st.vcs.Create(st.vcsRepository(stack), vcsOpt)
 	if err == nil {
 		if created {
 			er.Data = "created"
@@ -107,6 +98,21 @@ func (st *Stack) createVcsRepo(stack *thrapb.Stack) *thrapb.ActionResult {
 			er.Data = "exists"
 		}
 	}
+	er.Error = err
 
 	return er
 }
+
+// vcsRepository returns the vcs repository of the stack
+func (st *Stack) vcsRepository(stack *thrapb.Stack) *vcs.Repository {
+	repo := &vcs.Repository{
+		Name:        stack.ID,
+		Description: stack.Description,
+	}
+
+	vc := st.conf.VCS[st.vcs.ID()]
+	if vc != nil && vc.Repo != nil && vc.Repo.Owner != "" {
+		repo.Owner = vc.Repo.Owner
+	}
+	return repo
+}
@@ -0,0 +1,85 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/sniperkit/snk.fork.thrap/orchestrator"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+)
+
+// Exec runs a command inside a running component of the stack returning
+// the command exit code
+func (st *Stack) Exec(ctx context.Context, stack *thrapb.Stack, compID string, req *orchestrator.ExecRequest) (int, error) {
+	return st.orch.Exec(ctx, stack, compID, req)
+}
+
+// PortForward listens on the local address and forwards each connection to
+// the component port referenced by label.  It blocks until the context is
+// cancelled
+func (st *Stack) PortForward(ctx context.Context, stack *thrapb.Stack, compID, local, label string) error {
+	remote, err := st.orch.PortAddr(ctx, stack, compID, label)
+	if err != nil {
+		return err
+	}
+
+	// Only a port was given
+	if !strings.Contains(local, ":") {
+		local = "127.0.0.1:" + local
+	}
+
+	ln, err := net.Listen("tcp", local)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	fmt.Printf("Forwarding %s -> %s.%s (%s)\n", ln.Addr(), compID, label, remote)
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		go st.forwardConn(conn, remote)
+	}
+}
+
+func (st *Stack) forwardConn(conn net.Conn, remote string) {
+	defer conn.Close()
+
+	rconn, err := net.Dial("tcp", remote)
+	if err != nil {
+		st.log.Printf("port-forward dial %s: %v", remote, err)
+		return
+	}
+	defer rconn.Close()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(rconn, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(conn, rconn)
+		done <- struct{}{}
+	}()
+	<-done
+}
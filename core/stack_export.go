@@ -0,0 +1,86 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"github.com/sniperkit/snk.fork.thrap/manifest"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+)
+
+// ExportOptions are options to export a stack to another tool
+type ExportOptions struct {
+	// Format is one of manifest.ExportFormats
+	Format string
+	// Dir files are written to
+	Dir string
+	// Env var names exported as secret references regardless of their name
+	// or value
+	SecretVars []string
+}
+
+// Export renders the evaluated stack in the format to files in the options
+// directory.  Container addresses are resolved for the target and buildable
+// components use the image names of the profile registry.  Env vars named
+// like secrets, holding credentials before or after evaluation, or listed in
+// the options are exported as references.  It returns the paths of the files
+// written
+func (st *Stack) Export(stack *thrapb.Stack, opt ExportOptions) ([]string, error) {
+	if errs := stack.Validate(); len(errs) > 0 {
+		return nil, utils.FlattenErrors(errs)
+	}
+
+	exp, err := manifest.NewExporter(opt.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make(manifest.SecretEnvVars)
+	markSecretEnvVars(stack, secrets, opt.SecretVars)
+
+	svars := st.scopeVarsWithHost(stack, func(comp *thrapb.Component) string {
+		return exp.Host(stack.ID, comp)
+	})
+	for _, comp := range stack.Components {
+		if err = st.evalComponent(comp, svars); err != nil {
+			return nil, err
+		}
+	}
+	markSecretEnvVars(stack, secrets, nil)
+
+	for id, comp := range stack.Components {
+		if !comp.IsBuildable() {
+			continue
+		}
+		comp.Name = stack.ArtifactName(id)
+		if st.reg != nil {
+			comp.Name = st.reg.ImageName(comp.Name)
+		}
+	}
+
+	return exp.Export(stack, opt.Dir, secrets)
+}
+
+// markSecretEnvVars adds the env vars of the stack components with values
+// holding secrets or with one of the names to secrets
+func markSecretEnvVars(stack *thrapb.Stack, secrets manifest.SecretEnvVars, names []string) {
+	for id, comp := range stack.Components {
+		if !comp.HasEnvVars() {
+			continue
+		}
+		for k, v := range comp.Env.Vars {
+			if manifest.IsSecretEnvValue(v) {
+				secrets.Add(id, k)
+			}
+		}
+		for _, k := range names {
+			if _, ok := comp.Env.Vars[k]; ok {
+				secrets.Add(id, k)
+			}
+		}
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+)
+
+const ingressDomain = "localhost"
+
+// IngressOptions holds the listen addresses for the local ingress proxy
+type IngressOptions struct {
+	// Plain http listen address. Disabled if empty
+	HTTPAddr string
+	// TLS listen address. Disabled if empty
+	HTTPSAddr string
+}
+
+// DefaultIngressOptions returns the default ingress listen addresses
+func DefaultIngressOptions() IngressOptions {
+	return IngressOptions{
+		HTTPAddr:  "127.0.0.1:8080",
+		HTTPSAddr: "127.0.0.1:8443",
+	}
+}
+
+// ingress is a host based reverse proxy terminating tls with certificates
+// issued by the local certificate authority
+type ingress struct {
+	routes map[string]*httputil.ReverseProxy
+	ca     *utils.CertAuthority
+
+	mu    sync.Mutex
+	certs map[string]*tls.Certificate
+}
+
+func (ing *ingress) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	proxy, ok := ing.routes[strings.ToLower(host)]
+	if !ok {
+		http.Error(w, "no route for host: "+host, http.StatusNotFound)
+		return
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+func (ing *ingress) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := strings.ToLower(hello.ServerName)
+	if _, ok := ing.routes[name]; !ok {
+		return nil, fmt.Errorf("no route for host: %s", name)
+	}
+
+	ing.mu.Lock()
+	defer ing.mu.Unlock()
+
+	if cert, ok := ing.certs[name]; ok {
+		return cert, nil
+	}
+
+	cert, err := ing.ca.Issue(name)
+	if err == nil {
+		ing.certs[name] = cert
+	}
+	return cert, err
+}
+
+// IngressHost returns the hostname the ingress uses for the component.  If
+// label is not empty the hostname for that specific port is returned
+func IngressHost(stackID, compID, label string) string {
+	host := compID + "." + stackID + "." + ingressDomain
+	if label != "" {
+		host = label + "." + host
+	}
+	return strings.ToLower(host)
+}
+
+// Ingress runs a local reverse proxy routing <component>.<stack>.localhost
+// to the head and external components of the stack.  Each port is also
+// reachable at <label>.<component>.<stack>.localhost.  TLS is terminated
+// using a certificate authority stored in the data directory.  It blocks
+// until the context is cancelled
+func (st *Stack) Ingress(ctx context.Context, stack *thrapb.Stack, opts IngressOptions) error {
+	ca, err := utils.LoadOrCreateCA(filepath.Join(st.dataDir, consts.CADir))
+	if err != nil {
+		return err
+	}
+
+	ing := &ingress{
+		routes: make(map[string]*httputil.ReverseProxy),
+		ca:     ca,
+		certs:  make(map[string]*tls.Certificate),
+	}
+
+	table := make(map[string]string)
+	for _, comp := range stack.Components {
+		if !comp.Head && !comp.External {
+			continue
+		}
+
+		for label := range comp.Ports {
+			addr, err := st.orch.PortAddr(ctx, stack, comp.ID, label)
+			if err != nil {
+				return err
+			}
+
+			target := &url.URL{Scheme: "http", Host: addr}
+			host := IngressHost(stack.ID, comp.ID, label)
+			ing.routes[host] = httputil.NewSingleHostReverseProxy(target)
+			table[host] = addr
+
+			if label == defaultIngressLabel(comp) {
+				host = IngressHost(stack.ID, comp.ID, "")
+				ing.routes[host] = httputil.NewSingleHostReverseProxy(target)
+				table[host] = addr
+			}
+		}
+	}
+
+	if len(ing.routes) == 0 {
+		return fmt.Errorf("no head or external components with ports: %s", stack.ID)
+	}
+
+	var servers []*http.Server
+	errCh := make(chan error, 2)
+
+	if opts.HTTPAddr != "" {
+		srv := &http.Server{Addr: opts.HTTPAddr, Handler: ing}
+		servers = append(servers, srv)
+		go func() { errCh <- srv.ListenAndServe() }()
+	}
+
+	if opts.HTTPSAddr != "" {
+		srv := &http.Server{
+			Addr:      opts.HTTPSAddr,
+			Handler:   ing,
+			TLSConfig: &tls.Config{GetCertificate: ing.getCertificate},
+		}
+		servers = append(servers, srv)
+		go func() { errCh <- srv.ListenAndServeTLS("", "") }()
+	}
+
+	printIngressRoutes(table, opts)
+	fmt.Printf("CA certificate: %s\n\n", filepath.Join(st.dataDir, consts.CADir, "ca.pem"))
+
+	select {
+	case <-ctx.Done():
+	case err = <-errCh:
+	}
+
+	for _, srv := range servers {
+		srv.Close()
+	}
+
+	if err == http.ErrServerClosed {
+		err = nil
+	}
+	return err
+}
+
+// defaultIngressLabel returns the port label served at the bare component
+// hostname.  This is the only port, or the http or default labeled one.
+func defaultIngressLabel(comp *thrapb.Component) string {
+	if len(comp.Ports) == 1 {
+		for k := range comp.Ports {
+			return k
+		}
+	}
+
+	for _, l := range []string{"http", "default"} {
+		if _, ok := comp.Ports[l]; ok {
+			return l
+		}
+	}
+	return ""
+}
+
+func printIngressRoutes(table map[string]string, opts IngressOptions) {
+	hosts := make([]string, 0, len(table))
+	for h := range table {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+
+	fmt.Printf("\nIngress:\n\n")
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.StripEscape)
+	fmt.Fprintf(tw, " \tHost\tBackend\n")
+	fmt.Fprintf(tw, " \t----\t-------\n")
+	for _, h := range hosts {
+		fmt.Fprintf(tw, " \t%s\t%s\n", h, table[h])
+	}
+	tw.Flush()
+	fmt.Println()
+
+	if opts.HTTPAddr != "" {
+		fmt.Printf("  http:  %s\n", opts.HTTPAddr)
+	}
+	if opts.HTTPSAddr != "" {
+		fmt.Printf("  https: %s\n", opts.HTTPSAddr)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,188 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/dockerfile"
+	"github.com/sniperkit/snk.fork.thrap/manifest"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/vcs"
+)
+
+var errLintFailed = errors.New("dockerfile lint failed")
+
+// LintOptions are options to lint the dockerfiles of a stack
+type LintOptions struct {
+	// Workdir build contexts are relative to
+	Workdir string
+	// Severities overriding the project and default severities
+	Severities map[string]dockerfile.Severity
+}
+
+// lintConfig is the project lint configuration file
+type lintConfig struct {
+	Severities map[string]string `hcl:"severities"`
+}
+
+func lintConfigPath(workdir string) string {
+	return filepath.Join(workdir, consts.WorkDir, consts.LintFile)
+}
+
+// ReadLintSeverities reads the rule severities configured by the project in
+// workdir.  Projects without a configuration return an empty map
+func ReadLintSeverities(workdir string) (map[string]dockerfile.Severity, error) {
+	out := make(map[string]dockerfile.Severity)
+
+	b, err := ioutil.ReadFile(lintConfigPath(workdir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return out, nil
+		}
+		return nil, err
+	}
+
+	var conf lintConfig
+	if err = hcl.Unmarshal(b, &conf); err != nil {
+		return nil, err
+	}
+
+	for rule, s := range conf.Severities {
+		if err = setLintSeverity(out, rule, s); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// WriteLintSeverities writes the rule severities to the project lint
+// configuration in workdir replacing any existing one.  The configuration is
+// shared with the project so the .gitignore of projects ignoring it is
+// updated to track it
+func WriteLintSeverities(workdir string, severities map[string]dockerfile.Severity) error {
+	rules := make([]string, 0, len(severities))
+	for rule := range severities {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	var buf bytes.Buffer
+	buf.WriteString("severities {\n")
+	for _, rule := range rules {
+		fmt.Fprintf(&buf, "  %s = %q\n", rule, severities[rule])
+	}
+	buf.WriteString("}\n")
+
+	fpath := lintConfigPath(workdir)
+	if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(fpath, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return vcs.UntrackIgnores(workdir, consts.WorkDir+"/"+consts.LintFile)
+}
+
+// ParseLintSeverity parses a rule=level pair setting it in severities
+func ParseLintSeverity(severities map[string]dockerfile.Severity, pair string) error {
+	kv := strings.SplitN(pair, "=", 2)
+	if len(kv) != 2 {
+		return fmt.Errorf("%s: severity must be of the form rule=level", pair)
+	}
+	return setLintSeverity(severities, kv[0], kv[1])
+}
+
+func setLintSeverity(severities map[string]dockerfile.Severity, rule, level string) error {
+	if _, ok := dockerfile.DefaultSeverities[rule]; !ok {
+		return fmt.Errorf("%s: unknown lint rule", rule)
+	}
+	sev, err := dockerfile.ParseSeverity(level)
+	if err != nil {
+		return fmt.Errorf("%s: %v", rule, err)
+	}
+	severities[rule] = sev
+	return nil
+}
+
+// LintStack checks the dockerfile of each buildable component against best
+// practices.  Severities configured by the project are applied followed by
+// those in the options.  Findings are ordered by component id and have their
+// file set relative to the workdir
+func LintStack(stack *thrapb.Stack, opt LintOptions) ([]*dockerfile.LintFinding, error) {
+	severities, err := ReadLintSeverities(opt.Workdir)
+	if err != nil {
+		return nil, err
+	}
+	for rule, sev := range opt.Severities {
+		severities[rule] = sev
+	}
+
+	ids := make([]string, 0, len(stack.Components))
+	for id, comp := range stack.Components {
+		if comp.IsBuildable() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	var findings []*dockerfile.LintFinding
+	for _, id := range ids {
+		comp := stack.Components[id]
+
+		ctxDir, fpath := componentDockerfile(comp, opt.Workdir)
+
+		raw, err := dockerfile.ParseFile(fpath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", id, err)
+		}
+		df := dockerfile.ParseRaw(raw)
+
+		rel := fpath
+		if r, err := filepath.Rel(opt.Workdir, fpath); err == nil {
+			rel = filepath.ToSlash(r)
+		}
+
+		results := dockerfile.Lint(df, dockerfile.LintOptions{
+			ContextDir:    ctxDir,
+			HealthChecked: len(comp.HealthChecks) > 0,
+			Secret:        manifest.IsSecretEnvVar,
+			Severities:    severities,
+		})
+		for _, f := range results {
+			f.File = rel
+		}
+		findings = append(findings, results...)
+	}
+
+	return findings, nil
+}
+
+// lintGate lints the stack failing if any findings are errors
+func lintGate(stack *thrapb.Stack, workdir string) error {
+	findings, err := LintStack(stack, LintOptions{Workdir: workdir})
+	if err != nil {
+		return err
+	}
+
+	for _, f := range findings {
+		fmt.Println(f)
+	}
+	if dockerfile.HasLintErrors(findings) {
+		return errLintFailed
+	}
+	return nil
+}
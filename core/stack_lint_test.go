@@ -0,0 +1,46 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sniperkit/snk.fork.thrap/dockerfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LintSeverities(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "lint-")
+	defer os.RemoveAll(tmpdir)
+
+	ignores := filepath.Join(tmpdir, ".gitignore")
+	ioutil.WriteFile(ignores, []byte(".thrap\n"), 0644)
+
+	sev, err := ReadLintSeverities(tmpdir)
+	assert.Nil(t, err)
+	assert.Empty(t, sev)
+
+	sev[dockerfile.RuleUserRoot] = dockerfile.SeverityError
+	sev[dockerfile.RuleHealthCheckMissing] = dockerfile.SeverityOff
+	err = WriteLintSeverities(tmpdir, sev)
+	assert.Nil(t, err)
+
+	// Written configs are tracked
+	b, _ := ioutil.ReadFile(ignores)
+	assert.Contains(t, string(b), "!.thrap/lint.hcl")
+
+	// Reading leaves the ignores as is
+	ioutil.WriteFile(ignores, []byte(".thrap\n"), 0644)
+	got, err := ReadLintSeverities(tmpdir)
+	assert.Nil(t, err)
+	assert.Equal(t, sev, got)
+	b, _ = ioutil.ReadFile(ignores)
+	assert.Equal(t, ".thrap\n", string(b))
+}
@@ -0,0 +1,111 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/sniperkit/snk.fork.thrap/orchestrator"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+)
+
+// LogOptions holds options to read and format stack logs
+type LogOptions struct {
+	orchestrator.LogOptions
+	// Only lines matching the expression are written
+	Grep *regexp.Regexp
+	// Write each line as a json object
+	JSON bool
+	// Prefix each line with its timestamp when available
+	Timestamps bool
+}
+
+// Logs writes the logs of the requested stack components to the writers,
+// each line prefixed with the component id.  Lines from all components are
+// interleaved as they arrive. If following, it returns once the context is
+// cancelled.  Failing to write json lines stops reading and returns the error
+func (st *Stack) Logs(ctx context.Context, stack *thrapb.Stack, opts LogOptions, stdout, stderr io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		lines = make(chan *orchestrator.LogLine, 64)
+		errCh = make(chan error, 1)
+	)
+
+	go func() {
+		errCh <- st.orch.Logs(ctx, stack, opts.LogOptions, lines)
+		close(lines)
+	}()
+
+	var (
+		width = logPrefixWidth(stack, opts.Components)
+		enc   = json.NewEncoder(stdout)
+		werr  error
+	)
+
+	for line := range lines {
+		// Drain until the orchestrator returns after a failed write
+		if werr != nil {
+			continue
+		}
+		if opts.Grep != nil && !opts.Grep.MatchString(line.Text) {
+			continue
+		}
+
+		if opts.JSON {
+			if werr = enc.Encode(line); werr != nil {
+				cancel()
+			}
+			continue
+		}
+
+		w := stdout
+		if line.Stream == "stderr" {
+			w = stderr
+		}
+		writeLogLine(w, line, width, opts.Timestamps)
+	}
+
+	err := <-errCh
+	if werr != nil {
+		return werr
+	}
+	return err
+}
+
+func writeLogLine(w io.Writer, line *orchestrator.LogLine, width int, timestamps bool) {
+	if timestamps && line.Time != nil {
+		fmt.Fprintf(w, "%-*s | %s %s\n", width, line.Component, line.Time.Format(time.RFC3339), line.Text)
+	} else {
+		fmt.Fprintf(w, "%-*s | %s\n", width, line.Component, line.Text)
+	}
+}
+
+// logPrefixWidth returns the longest component id length so log prefixes
+// are aligned
+func logPrefixWidth(stack *thrapb.Stack, ids []string) int {
+	if len(ids) == 0 {
+		ids = make([]string, 0, len(stack.Components))
+		for id := range stack.Components {
+			ids = append(ids, id)
+		}
+	}
+
+	var width int
+	for _, id := range ids {
+		if len(id) > width {
+			width = len(id)
+		}
+	}
+	return width
+}
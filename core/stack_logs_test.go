@@ -0,0 +1,48 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sniperkit/snk.fork.thrap/orchestrator"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/stretchr/testify/assert"
+)
+
+// followOrch follows logs sending lines until the context is cancelled
+type followOrch struct {
+	orchestrator.Orchestrator
+}
+
+func (o *followOrch) Logs(ctx context.Context, stack *thrapb.Stack, opts orchestrator.LogOptions, lines chan<- *orchestrator.LogLine) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case lines <- &orchestrator.LogLine{Component: "api", Text: "line"}:
+		}
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func Test_Stack_Logs_writeError(t *testing.T) {
+	st := &Stack{orch: &followOrch{}}
+	stack := &thrapb.Stack{Components: map[string]*thrapb.Component{"api": &thrapb.Component{}}}
+
+	opts := LogOptions{JSON: true}
+	opts.Follow = true
+	err := st.Logs(context.Background(), stack, opts, failWriter{}, failWriter{})
+	assert.EqualError(t, err, "broken pipe")
+}
@@ -0,0 +1,287 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/sniperkit/snk.fork.thrap/orchestrator"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+)
+
+// Max length of the branch portion of a preview id
+const maxPreviewSlug = 32
+
+var (
+	errPreviewNameInvalid = errors.New("preview name must contain a letter or digit")
+	errPreviewIncomplete  = errors.New("preview id, stack and profile required")
+)
+
+// PreviewOptions are options to bring up a preview environment
+type PreviewOptions struct {
+	// Branch or pull request name the preview is of
+	Name string
+	// Workdir is the root of the git repo
+	Workdir string
+	// Time after the last deploy the preview is destroyed. Zero never
+	// expires
+	TTL time.Duration
+	// Components versioned from their sources.  See BuildOptions
+	Versioned map[string]bool
+	// Only report the deployment
+	Dryrun bool
+}
+
+// PreviewID returns the isolated stack id of a preview of the stack for the
+// branch i.e. myapp-feature-login for the branch feature/login
+func PreviewID(stackID, name string) (string, error) {
+	slug := previewSlug(name)
+	if slug == "" {
+		return "", errPreviewNameInvalid
+	}
+	return stackID + "-" + slug, nil
+}
+
+// previewSlug returns the branch name lowercased with runs of characters
+// that are not letters or digits replaced by a single dash
+func previewSlug(name string) string {
+	name = strings.TrimPrefix(name, "refs/heads/")
+	name = strings.ToLower(name)
+
+	var (
+		b    bytes.Buffer
+		dash bool
+	)
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+
+	slug := b.String()
+	if len(slug) > maxPreviewSlug {
+		slug = strings.TrimRight(slug[:maxPreviewSlug], "-")
+	}
+	return slug
+}
+
+// previewVersion scopes the version to the branch as a pre-release so the
+// tag remains a valid version i.e. v1.2.0-feature-login or
+// v1.2.0-3-0a1b2c3d.feature-login
+func previewVersion(version, slug string) string {
+	if strings.Contains(version, "-") {
+		return version + "." + slug
+	}
+	return version + "-" + slug
+}
+
+// Preview builds, publishes and deploys an isolated copy of the stack for the
+// branch using the orchestrator of the profile.  Artifacts are tagged with
+// the branch and host ports are dynamically allocated so previews of multiple
+// branches can run side by side.  The returned preview is to be recorded with
+// the agent so it can be listed and destroyed once it expires
+func (st *Stack) Preview(ctx context.Context, stack *thrapb.Stack, opt PreviewOptions) (*thrapb.Preview, error) {
+	id, err := PreviewID(stack.ID, opt.Name)
+	if err != nil {
+		return nil, err
+	}
+	slug := previewSlug(opt.Name)
+
+	pstack := proto.Clone(stack).(*thrapb.Stack)
+	pstack.ID = id
+	for _, comp := range pstack.Components {
+		if comp.IsBuildable() && comp.Version != "" {
+			comp.Version = previewVersion(comp.Version, slug)
+		}
+	}
+
+	fmt.Printf("Preview: %s\n\n", id)
+
+	if !opt.Dryrun {
+		if err = st.ensurePreviewRepos(pstack); err != nil {
+			return nil, err
+		}
+
+		bopt := BuildOptions{
+			Workdir:   opt.Workdir,
+			Publish:   true,
+			Versioned: opt.Versioned,
+			Preview:   true,
+		}
+		err = st.Build(ctx, pstack, bopt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ropts := orchestrator.RequestOptions{Dryrun: opt.Dryrun, DynamicPorts: true}
+	if err = st.Deploy(pstack, ropts); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	preview := &thrapb.Preview{
+		ID:      id,
+		Name:    opt.Name,
+		StackID: stack.ID,
+		Profile: st.profile,
+		Stack:   pstack,
+		Hosts:   previewHosts(pstack),
+		Created: now.UnixNano(),
+	}
+	if opt.TTL > 0 {
+		preview.Expires = now.Add(opt.TTL).UnixNano()
+	}
+
+	return preview, nil
+}
+
+// ensurePreviewRepos creates the registry repositories of the preview
+// artifacts if they do not exist
+func (st *Stack) ensurePreviewRepos(stack *thrapb.Stack) error {
+	if st.reg == nil {
+		return nil
+	}
+
+	for id, comp := range stack.Components {
+		if !comp.IsBuildable() {
+			continue
+		}
+
+		name := stack.ArtifactName(id)
+		if _, err := st.reg.Get(name); err == nil {
+			continue
+		}
+		if _, err := st.reg.Create(name); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+// previewHosts returns the sorted ingress hostnames of the head and external
+// components of the stack
+func previewHosts(stack *thrapb.Stack) []string {
+	hosts := make([]string, 0)
+	for _, comp := range stack.Components {
+		if (!comp.Head && !comp.External) || len(comp.Ports) == 0 {
+			continue
+		}
+		hosts = append(hosts, IngressHost(stack.ID, comp.ID, ""))
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// RecordPreview records the deployed preview in the store so it is listed
+// and destroyed once it expires.  Redeploys keep the original creation time
+func (core *Core) RecordPreview(preview *thrapb.Preview) (*thrapb.Preview, error) {
+	if preview.ID == "" || preview.Stack == nil || preview.Profile == nil {
+		return nil, errPreviewIncomplete
+	}
+
+	if prev, err := core.pst.Get(preview.ID); err == nil {
+		preview.Created = prev.Created
+	}
+	return preview, core.pst.Set(preview)
+}
+
+// IterPreviews iterates over each preview whose id has the prefix
+func (core *Core) IterPreviews(prefix string, f func(*thrapb.Preview) error) error {
+	return core.pst.Iter(prefix, f)
+}
+
+// Previews returns all previews of the stack.  If the stack id is empty the
+// previews of all stacks are returned
+func (core *Core) Previews(stackID string) ([]*thrapb.Preview, error) {
+	out := make([]*thrapb.Preview, 0)
+	err := core.pst.Iter("", func(p *thrapb.Preview) error {
+		if stackID == "" || p.StackID == stackID {
+			out = append(out, p)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// DestroyPreview destroys the preview deployment using the orchestrator of
+// the profile it was deployed with and removes it from the store
+func (core *Core) DestroyPreview(ctx context.Context, id string) ([]*thrapb.ActionResult, error) {
+	preview, err := core.pst.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := core.Stack(preview.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	ar := st.Destroy(ctx, preview.Stack)
+	return ar, core.pst.Delete(id)
+}
+
+// ReapPreviews destroys all previews expired as of the given time returning
+// the ids of those destroyed
+func (core *Core) ReapPreviews(ctx context.Context, now time.Time) ([]string, error) {
+	previews, err := core.Previews("")
+	if err != nil {
+		return nil, err
+	}
+
+	reaped := make([]string, 0)
+	for _, p := range previews {
+		if p.Expires == 0 || p.Expires > now.UnixNano() {
+			continue
+		}
+
+		if _, err = core.DestroyPreview(ctx, p.ID); err != nil {
+			core.log.Printf("Failed to destroy preview %s: %v", p.ID, err)
+			continue
+		}
+		reaped = append(reaped, p.ID)
+	}
+
+	return reaped, nil
+}
+
+// RunPreviewReaper destroys expired previews at the interval until the
+// context is cancelled
+func (core *Core) RunPreviewReaper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			reaped, err := core.ReapPreviews(ctx, now)
+			if err != nil {
+				core.log.Println("Preview reaper:", err)
+				continue
+			}
+			for _, id := range reaped {
+				core.log.Println("Preview expired:", id)
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PreviewID(t *testing.T) {
+	id, err := PreviewID("myapp", "feature/Login_Page")
+	assert.Nil(t, err)
+	assert.Equal(t, "myapp-feature-login-page", id)
+
+	id, _ = PreviewID("myapp", "refs/heads/--fix--")
+	assert.Equal(t, "myapp-fix", id)
+
+	id, _ = PreviewID("myapp", "a-very-long-branch-name-that-goes-past-the-limit")
+	assert.Equal(t, "myapp-a-very-long-branch-name-that-goe", id)
+
+	_, err = PreviewID("myapp", "/-/")
+	assert.Equal(t, errPreviewNameInvalid, err)
+}
+
+func Test_previewVersion(t *testing.T) {
+	for in, out := range map[string]string{
+		"v1.2.0":                           "v1.2.0-pr-12",
+		"v1.2.0-3-0a1b2c3d":                "v1.2.0-3-0a1b2c3d.pr-12",
+		"v0.0.0-5-0a1b2c3d-dirty.4e5f6a7b": "v0.0.0-5-0a1b2c3d-dirty.4e5f6a7b.pr-12",
+	} {
+		ver := previewVersion(in, "pr-12")
+		assert.Equal(t, out, ver)
+		_, err := version.NewVersion(ver)
+		assert.Nil(t, err, ver)
+	}
+}
+
+func Test_previewHosts(t *testing.T) {
+	stack := &thrapb.Stack{
+		ID: "myapp-pr-12",
+		Components: map[string]*thrapb.Component{
+			"api": {ID: "api", Head: true, Ports: map[string]int32{"http": 8080}},
+			"db":  {ID: "db", External: true, Ports: map[string]int32{"default": 5432}},
+			"nop": {ID: "nop", Head: true},
+			"wrk": {ID: "wrk", Ports: map[string]int32{"default": 9000}},
+		},
+	}
+	assert.Equal(t, []string{"api.myapp-pr-12.localhost", "db.myapp-pr-12.localhost"}, previewHosts(stack))
+}
+
+type testPreviewStorage map[string]*thrapb.Preview
+
+func (s testPreviewStorage) Get(id string) (*thrapb.Preview, error) {
+	if p, ok := s[id]; ok {
+		return p, nil
+	}
+	return nil, errors.New("preview not found")
+}
+
+func (s testPreviewStorage) Set(p *thrapb.Preview) error {
+	s[p.ID] = p
+	return nil
+}
+
+func (s testPreviewStorage) Iter(prefix string, f func(*thrapb.Preview) error) error {
+	for id, p := range s {
+		if strings.HasPrefix(id, prefix) {
+			if err := f(p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s testPreviewStorage) Delete(id string) error {
+	delete(s, id)
+	return nil
+}
+
+func Test_RecordPreview(t *testing.T) {
+	core := &Core{pst: testPreviewStorage{}}
+
+	_, err := core.RecordPreview(&thrapb.Preview{ID: "myapp-pr-12"})
+	assert.Equal(t, errPreviewIncomplete, err)
+
+	preview := &thrapb.Preview{
+		ID:      "myapp-pr-12",
+		StackID: "myapp",
+		Profile: thrapb.DefaultProfile(),
+		Stack:   &thrapb.Stack{ID: "myapp-pr-12"},
+		Created: 1,
+	}
+	_, err = core.RecordPreview(preview)
+	assert.Nil(t, err)
+
+	// Redeploys keep the creation time
+	redeploy := *preview
+	redeploy.Created = 2
+	p, err := core.RecordPreview(&redeploy)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), p.Created)
+
+	previews, err := core.Previews("myapp")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(previews))
+}
@@ -0,0 +1,134 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sniperkit/snk.fork.thrap/manifest"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/vcs"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+var errWorktreeDirty = errors.New("worktree has uncommitted changes")
+
+// ReleaseOptions are options to cut a new stack release
+type ReleaseOptions struct {
+	// Workdir is the root of the git repo
+	Workdir string
+	// Version part to bump. One of major, minor or patch
+	Bump string
+	// Optional pre-release identifier i.e. rc
+	Pre string
+	// Tag message.  Defaults to 'Release <version>'
+	Message string
+	// Push the tag to the origin remote
+	Push bool
+	// Build and publish artifacts for the new version
+	Publish bool
+}
+
+// Release creates an annotated tag with the next semantic version of the
+// stack, optionally pushing it and publishing the stack artifacts for the
+// new version.  The worktree must be clean.  It returns the new version
+func (st *Stack) Release(ctx context.Context, stack *thrapb.Stack, opt ReleaseOptions) (string, error) {
+	status, err := st.vcs.Status(vcs.Option{Path: opt.Workdir})
+	if err != nil {
+		return "", err
+	}
+	if !status.IsClean() {
+		return "", errWorktreeDirty
+	}
+
+	rver, err := vcs.ReadRepoVersion(opt.Workdir)
+	if err != nil {
+		return "", err
+	}
+
+	tag, err := rver.Next(opt.Bump, opt.Pre)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", rver.Tag, err)
+	}
+
+	msg := opt.Message
+	if msg == "" {
+		msg = "Release " + tag
+	}
+
+	tagger := &object.Signature{
+		Name:  st.vcs.GlobalUser(),
+		Email: st.vcs.GlobalEmail(),
+		When:  time.Now(),
+	}
+	if _, err = vcs.CreateTag(opt.Workdir, tag, msg, tagger); err != nil {
+		return "", err
+	}
+	fmt.Printf("Tagged %s (from %s)\n", tag, rver)
+
+	if opt.Push {
+		var token string
+		if st.creds != nil {
+			token = st.creds.GetVCSCreds(st.vcs.ID())["token"]
+		}
+		if err = vcs.PushTag(opt.Workdir, "origin", tag, token); err != nil {
+			return tag, err
+		}
+		fmt.Printf("Pushed %s\n", tag)
+
+		if err = st.publishReleaseNotes(stack, opt, tag); err != nil {
+			return tag, err
+		}
+	}
+
+	if opt.Publish {
+		// Reload so all components without a fixed version are stamped with
+		// the release tag rather than the version of their build sources
+		var mfile string
+		if mfile, err = manifest.FindManifest(opt.Workdir); err != nil {
+			return tag, err
+		}
+		// Warnings were reported when the stack was first loaded
+		if stack, _, err = manifest.LoadManifest(mfile); err != nil {
+			return tag, err
+		}
+		stack.Version = tag
+		err = st.Build(ctx, stack, BuildOptions{Workdir: opt.Workdir, Publish: true})
+	}
+
+	return tag, err
+}
+
+// publishReleaseNotes attaches the changelog of the tag to the release if
+// supported by the vcs provider
+func (st *Stack) publishReleaseNotes(stack *thrapb.Stack, opt ReleaseOptions, tag string) error {
+	rm, ok := st.vcs.(vcs.ReleaseManager)
+	if !ok {
+		return nil
+	}
+
+	cl, err := GenerateChangelog(stack, ChangelogOptions{Workdir: opt.Workdir, To: tag})
+	if err != nil {
+		return err
+	}
+
+	rel := &vcs.Release{
+		Tag:        tag,
+		Name:       tag,
+		Notes:      cl.Markdown(),
+		Prerelease: opt.Pre != "",
+	}
+	if _, err = rm.PublishRelease(st.vcsRepository(stack), rel); err != nil {
+		return err
+	}
+
+	fmt.Printf("Published release notes %s\n", tag)
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/manifest"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/vcs"
+)
+
+// SourceOptions are options to build a stack from a remote git repo
+type SourceOptions struct {
+	// Git url of the stack repo
+	URL string
+	// Branch, tag or commit to build. Defaults to the remote HEAD
+	Ref string
+	// Publish even if the auto-publish check fails
+	Publish bool
+	// Build components even if an image of their version exists
+	Rebuild bool
+	// Lint dockerfiles before building
+	Lint bool
+	// Override returns a signed override of the publish policy for the stack
+	// and commit checked out.  Optional
+	Override func(stack *thrapb.Stack, commit string) (*thrapb.PublishOverride, error)
+	// Verifies the override signature. See BuildOptions
+	VerifyOverride func(*thrapb.PublishOverride) error
+}
+
+// Source is a checkout of a remote stack repo
+type Source struct {
+	// Directory of the checkout
+	Dir string
+	// Commit checked out
+	Commit string
+	// Branch checked out. Empty if the ref is not a branch
+	Branch string
+	// Components versioned from their sources by LoadManifest
+	Versioned map[string]bool
+}
+
+// LoadManifest loads the stack manifest of the checkout with the component
+// versions set and build contexts resolved against the checkout.  Schema
+// warnings are returned for the caller to report
+func (src *Source) LoadManifest() (*thrapb.Stack, manifest.SchemaErrors, error) {
+	mfile, err := manifest.FindManifest(src.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	stack, warns, err := manifest.LoadManifest(mfile)
+	if err != nil {
+		return nil, nil, err
+	}
+	src.Versioned = manifest.SetComponentVersions(stack, src.Dir)
+
+	for _, comp := range stack.Components {
+		if comp.IsBuildable() && !filepath.IsAbs(comp.Build.Context) {
+			comp.Build.Context = filepath.Join(src.Dir, comp.Build.Context)
+		}
+	}
+	return stack, warns, nil
+}
+
+// CheckoutSource checks out the ref of the repo at url in the source cache
+// of the data directory.  Later checkouts of the same url reuse the clone
+// only fetching new objects.  Private http repos are authenticated using the
+// token of the configured vcs
+func (st *Stack) CheckoutSource(ctx context.Context, url, ref string) (*Source, error) {
+	root := filepath.Join(st.dataDir, consts.SourcesDir)
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+
+	var token string
+	if st.vcs != nil {
+		token = st.creds.GetVCSCreds(st.vcs.ID())["token"]
+	}
+
+	dir := vcs.SourceDir(root, url)
+	hash, branch, err := vcs.SyncSource(ctx, dir, url, ref, token)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", url, err)
+	}
+
+	return &Source{Dir: dir, Commit: hash.String(), Branch: branch}, nil
+}
+
+// BuildSource checks out the ref of the remote repo and builds the stack
+// from the checkout.  Artifacts are additionally tagged with the commit
+// built.  It returns the stack that was built
+func (st *Stack) BuildSource(ctx context.Context, opt SourceOptions) (*thrapb.Stack, error) {
+	src, err := st.CheckoutSource(ctx, opt.URL, opt.Ref)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Source: %s@%s\n\n", opt.URL, src.Commit)
+
+	stack, warns, err := src.LoadManifest()
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range warns {
+		st.log.Printf("Warning: %v", w)
+	}
+
+	bopt := BuildOptions{
+		Workdir:   src.Dir,
+		Publish:   opt.Publish,
+		Rebuild:   opt.Rebuild,
+		Versioned: src.Versioned,
+		Commit:    src.Commit,
+		Branch:    src.Branch,
+		Lint:      opt.Lint,
+
+		VerifyOverride: opt.VerifyOverride,
+	}
+	if opt.Override != nil {
+		if bopt.Override, err = opt.Override(stack, src.Commit); err != nil {
+			return nil, err
+		}
+	}
+	return stack, st.Build(ctx, stack, bopt)
+}
@@ -26,3 +26,12 @@ type IdentityStorage interface {
 	Update(*thrapb.Identity) (*thrapb.Identity, error)
 	Iter(string, func(*thrapb.Identity) error) error
 }
+
+// PreviewStorage is a preview environment storage interface
+type PreviewStorage interface {
+	Get(id string) (*thrapb.Preview, error)
+	// Set creates or replaces a preview
+	Set(*thrapb.Preview) error
+	Iter(string, func(*thrapb.Preview) error) error
+	Delete(id string) error
+}
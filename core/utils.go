@@ -91,6 +91,9 @@ func printBuildResults(stack *thrapb.Stack, results map[string]*CompBuildResult,
 
 		if r.Error == nil {
 			status = "succeeded"
+			if r.Cached {
+				status = "cached"
+			}
 			art = stack.ArtifactName(k) + ":" + stack.Components[k].Version
 		} else {
 			status = "failed"
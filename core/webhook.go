@@ -0,0 +1,437 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sniperkit/snk.fork.thrap/config"
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/store"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/vcs"
+)
+
+const (
+	// WebhookPath is the path the agent receives vcs webhooks on
+	WebhookPath = "/webhook"
+
+	// Max webhook payload size accepted
+	maxWebhookPayload = 5 << 20
+	// Number of builds that may be queued
+	webhookQueueSize = 16
+	// Random bytes in a generated webhook secret
+	webhookSecretSize = 32
+)
+
+var (
+	errWebhookSecretMissing = errors.New("webhook secret not configured")
+	errWebhookSignature     = errors.New("invalid webhook signature")
+	errWebhookProvider      = errors.New("unknown webhook provider")
+)
+
+// WebhookEvent is a push to a branch or tag parsed from a vcs webhook
+type WebhookEvent struct {
+	// vcs provider id
+	Provider string
+	// Name of the repo. This is the stack id
+	Repo     string
+	CloneURL string
+	// Full ref i.e. refs/heads/master or refs/tags/v1.0.0
+	Ref    string
+	Branch string
+	Tag    string
+	Commit string
+}
+
+// webhookPayload is the union of the push payload fields used from each
+// provider
+type webhookPayload struct {
+	Ref         string `json:"ref"`
+	After       string `json:"after"`
+	CheckoutSHA string `json:"checkout_sha"`
+	Deleted     bool   `json:"deleted"`
+	Repository  struct {
+		Name       string `json:"name"`
+		CloneURL   string `json:"clone_url"`
+		GitHTTPURL string `json:"git_http_url"`
+	} `json:"repository"`
+}
+
+// ParseWebhook verifies the request signature against the secret and parses
+// the push payload.  A nil event is returned for events other than a push
+func ParseWebhook(r *http.Request, body []byte, secret string) (*WebhookEvent, error) {
+	if secret == "" {
+		return nil, errWebhookSecretMissing
+	}
+
+	var (
+		provider string
+		event    string
+		valid    bool
+	)
+
+	// Gitea also sends github headers so it is checked first
+	switch {
+	case r.Header.Get("X-Gitea-Event") != "":
+		provider, event = "gitea", r.Header.Get("X-Gitea-Event")
+		valid = validHMAC(sha256.New, secret, body, r.Header.Get("X-Gitea-Signature"))
+
+	case r.Header.Get("X-GitHub-Event") != "":
+		provider, event = "github", r.Header.Get("X-GitHub-Event")
+		if sig := r.Header.Get("X-Hub-Signature-256"); sig != "" {
+			valid = validHMAC(sha256.New, secret, body, strings.TrimPrefix(sig, "sha256="))
+		} else {
+			sig = r.Header.Get("X-Hub-Signature")
+			valid = validHMAC(sha1.New, secret, body, strings.TrimPrefix(sig, "sha1="))
+		}
+
+	case r.Header.Get("X-Gitlab-Event") != "":
+		// Gitlab sends the secret as is
+		provider, event = "gitlab", r.Header.Get("X-Gitlab-Event")
+		token := r.Header.Get("X-Gitlab-Token")
+		valid = subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+
+	default:
+		return nil, errWebhookProvider
+	}
+
+	if !valid {
+		return nil, errWebhookSignature
+	}
+
+	switch event {
+	case "push", "Push Hook", "Tag Push Hook":
+	default:
+		return nil, nil
+	}
+
+	var pl webhookPayload
+	if err := json.Unmarshal(body, &pl); err != nil {
+		return nil, err
+	}
+
+	// Branch or tag deletions
+	if pl.Deleted || strings.Trim(pl.After, "0") == "" {
+		return nil, nil
+	}
+
+	ev := &WebhookEvent{
+		Provider: provider,
+		Repo:     pl.Repository.Name,
+		CloneURL: pl.Repository.CloneURL,
+		Ref:      pl.Ref,
+		Commit:   pl.After,
+	}
+	if ev.CloneURL == "" {
+		ev.CloneURL = pl.Repository.GitHTTPURL
+	}
+	if pl.CheckoutSHA != "" {
+		ev.Commit = pl.CheckoutSHA
+	}
+
+	switch {
+	case strings.HasPrefix(pl.Ref, "refs/heads/"):
+		ev.Branch = strings.TrimPrefix(pl.Ref, "refs/heads/")
+	case strings.HasPrefix(pl.Ref, "refs/tags/"):
+		ev.Tag = strings.TrimPrefix(pl.Ref, "refs/tags/")
+	default:
+		return nil, nil
+	}
+
+	return ev, nil
+}
+
+// validHMAC returns true if the hex signature matches the hmac of the body
+func validHMAC(h func() hash.Hash, secret string, body []byte, signature string) bool {
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) == 0 {
+		return false
+	}
+
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+// WebhookURL returns the url of the webhook receiver listening on addr.  An
+// unspecified host is replaced with the hostname of the machine
+func WebhookURL(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		if host, err = os.Hostname(); err != nil {
+			return "", err
+		}
+	}
+
+	return "http://" + net.JoinHostPort(host, port) + WebhookPath, nil
+}
+
+// webhookSecret returns the webhook secret from the creds.  On first use a
+// random secret is generated.  The secret is stored in the creds file of the
+// data dir so stacks register the webhook with the secret the agent verifies
+func webhookSecret(dataDir string, creds *config.CredsConfig) (string, error) {
+	secret := creds.GetWebhookSecret()
+	if secret == "" {
+		b := make([]byte, webhookSecretSize)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		secret = hex.EncodeToString(b)
+	}
+
+	credsFile := filepath.Join(dataDir, consts.CredsFile)
+	stored, err := config.ReadCredsConfig(credsFile)
+	if err != nil {
+		return "", err
+	}
+	if stored.GetWebhookSecret() != secret {
+		stored.SetWebhookSecret(secret)
+		if err = config.WriteCredsConfig(stored, credsFile); err != nil {
+			return "", err
+		}
+	}
+
+	creds.SetWebhookSecret(secret)
+	return secret, nil
+}
+
+// storeWebhookConfig writes the webhook url and branches to the global config
+// so stacks registered on this machine add the webhook
+func storeWebhookConfig(dataDir string, conf *config.WebhookConfig) error {
+	cfile := filepath.Join(dataDir, consts.ConfigFile)
+	gconf, err := config.ReadThrapConfig(cfile)
+	if err != nil {
+		return err
+	}
+
+	if gconf.Webhook != nil && gconf.Webhook.URL == conf.URL {
+		return nil
+	}
+	gconf.Webhook = conf.Clone()
+	return config.WriteThrapConfig(gconf, cfile)
+}
+
+// WebhookHandler receives vcs push webhooks and queues a build and publish of
+// the pushed ref.  Builds are run one at a time
+type WebhookHandler struct {
+	core   *Core
+	conf   *config.WebhookConfig
+	secret string
+	queue  chan *WebhookEvent
+}
+
+// NewWebhookHandler returns a handler using the webhook config and secret of
+// the core.  A secret is generated if none is set.  The url and secret are
+// stored in the data dir for the stacks to register
+func NewWebhookHandler(core *Core) (*WebhookHandler, error) {
+	conf := core.conf.Webhook
+	if conf == nil || conf.URL == "" {
+		return nil, errors.New("webhook url not configured")
+	}
+
+	// Fail early rather than on the first delivery
+	if _, err := core.webhookProfile(); err != nil {
+		return nil, err
+	}
+
+	secret, err := webhookSecret(core.dataDir, core.creds)
+	if err != nil {
+		return nil, err
+	}
+	if err = storeWebhookConfig(core.dataDir, conf); err != nil {
+		return nil, err
+	}
+
+	return &WebhookHandler{
+		core:   core,
+		conf:   conf,
+		secret: secret,
+		queue:  make(chan *WebhookEvent, webhookQueueSize),
+	}, nil
+}
+
+func (wh *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookPayload))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ev, err := ParseWebhook(r, body, wh.secret)
+	if err != nil {
+		code := http.StatusBadRequest
+		if err == errWebhookSignature {
+			code = http.StatusUnauthorized
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	if ev == nil || !wh.shouldBuild(ev) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Only registered stacks are built
+	if _, err = wh.core.sst.Get(ev.Repo); err != nil {
+		http.Error(w, "stack not registered: "+ev.Repo, http.StatusNotFound)
+		return
+	}
+
+	select {
+	case wh.queue <- ev:
+		wh.core.log.Printf("webhook queued build stack=%s ref=%s commit=%s", ev.Repo, ev.Ref, ev.Commit)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "build queue full", http.StatusServiceUnavailable)
+	}
+}
+
+// shouldBuild returns true for all tags and pushes to configured branches
+func (wh *WebhookHandler) shouldBuild(ev *WebhookEvent) bool {
+	if ev.Tag != "" {
+		return true
+	}
+
+	branches := wh.conf.Branches
+	if len(branches) == 0 {
+		branches = []string{"master"}
+	}
+	for _, b := range branches {
+		if b == ev.Branch {
+			return true
+		}
+	}
+	return false
+}
+
+// Run processes queued builds until the context is cancelled
+func (wh *WebhookHandler) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-wh.queue:
+			if err := wh.core.BuildRef(ctx, ev); err != nil {
+				wh.core.log.Printf("webhook build failed stack=%s ref=%s: %v", ev.Repo, ev.Ref, err)
+			} else {
+				wh.core.log.Printf("webhook build succeeded stack=%s ref=%s", ev.Repo, ev.Ref)
+			}
+		}
+	}
+}
+
+// BuildRef checks out the pushed commit in the source cache and runs the
+// stack build and publish pipeline from the checkout
+func (core *Core) BuildRef(ctx context.Context, ev *WebhookEvent) error {
+	prof, err := core.webhookProfile()
+	if err != nil {
+		return err
+	}
+
+	st, err := core.Stack(prof)
+	if err != nil {
+		return err
+	}
+
+	ref := ev.Commit
+	if ref == "" {
+		ref = ev.Ref
+	}
+
+	src, err := st.CheckoutSource(ctx, ev.CloneURL, ref)
+	if err != nil {
+		return err
+	}
+
+	stack, warns, err := src.LoadManifest()
+	if err != nil {
+		return err
+	}
+	for _, w := range warns {
+		st.log.Printf("Warning: %v", w)
+	}
+	if stack.ID != ev.Repo {
+		return fmt.Errorf("stack id mismatch: %s != %s", stack.ID, ev.Repo)
+	}
+
+	opt := BuildOptions{
+		Workdir:   src.Dir,
+		Publish:   true,
+		Versioned: src.Versioned,
+		Commit:    src.Commit,
+		Branch:    ev.Branch,
+	}
+	return st.Build(ctx, stack, opt)
+}
+
+// webhookProfile returns the profile webhook builds are run with from the
+// profiles in the data dir.  The default profile is used unless one is
+// configured
+func (core *Core) webhookProfile() (*thrapb.Profile, error) {
+	profs, err := store.ReadHCLFileProfileStorage(filepath.Join(core.dataDir, consts.ProfilesFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profiles: %v", err)
+	}
+
+	id := core.conf.Webhook.Profile
+	if id == "" {
+		id = profs.Default
+	}
+	if prof := profs.Get(id); prof != nil {
+		return prof, nil
+	}
+	return nil, fmt.Errorf("profile not found: %s", id)
+}
+
+// ensureWebhook adds the configured webhook to the stack vcs repo
+func (st *Stack) ensureWebhook(stack *thrapb.Stack) *thrapb.ActionResult {
+	er := &thrapb.ActionResult{Action: "create", Resource: "webhook"}
+
+	hm, ok := st.vcs.(vcs.HookManager)
+	if !ok {
+		er.Error = fmt.Errorf("webhooks not supported by vcs: %s", st.vcs.ID())
+		return er
+	}
+
+	secret, err := webhookSecret(st.dataDir, st.creds)
+	if err != nil {
+		er.Error = err
+		return er
+	}
+
+	hook := &vcs.Hook{URL: st.conf.Webhook.URL, Secret: secret}
+	er.Resource = hook.URL
+	_, er.Error = hm.AddHook(st.vcsRepository(stack), hook)
+	return er
+}
@@ -0,0 +1,178 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sniperkit/snk.fork.thrap/config"
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/store"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPushPayload = `{
+  "ref": "refs/heads/master",
+  "after": "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c",
+  "repository": {
+    "name": "api-test",
+    "clone_url": "https://git.example.com/me/api-test.git"
+  }
+}`
+
+func testSign(h func() hash.Hash, secret, body string) string {
+	mac := hmac.New(h, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func Test_ParseWebhook(t *testing.T) {
+	secret := "s3cr3t"
+
+	// Github
+	r := httptest.NewRequest("POST", WebhookPath, nil)
+	r.Header.Set("X-GitHub-Event", "push")
+	r.Header.Set("X-Hub-Signature", "sha1="+testSign(sha1.New, secret, testPushPayload))
+	ev, err := ParseWebhook(r, []byte(testPushPayload), secret)
+	assert.Nil(t, err)
+	assert.Equal(t, "github", ev.Provider)
+	assert.Equal(t, "api-test", ev.Repo)
+	assert.Equal(t, "master", ev.Branch)
+	assert.Equal(t, "", ev.Tag)
+	assert.Equal(t, "https://git.example.com/me/api-test.git", ev.CloneURL)
+
+	_, err = ParseWebhook(r, []byte(testPushPayload), "wrong")
+	assert.Equal(t, errWebhookSignature, err)
+	_, err = ParseWebhook(r, []byte(testPushPayload), "")
+	assert.Equal(t, errWebhookSecretMissing, err)
+
+	// Non-push events are ignored
+	r.Header.Set("X-GitHub-Event", "ping")
+	ev, err = ParseWebhook(r, []byte(testPushPayload), secret)
+	assert.Nil(t, err)
+	assert.Nil(t, ev)
+
+	// Gitea
+	r = httptest.NewRequest("POST", WebhookPath, nil)
+	r.Header.Set("X-Gitea-Event", "push")
+	r.Header.Set("X-GitHub-Event", "push")
+	r.Header.Set("X-Gitea-Signature", testSign(sha256.New, secret, testPushPayload))
+	ev, err = ParseWebhook(r, []byte(testPushPayload), secret)
+	assert.Nil(t, err)
+	assert.Equal(t, "gitea", ev.Provider)
+
+	// Gitlab tag
+	tagPayload := `{"ref":"refs/tags/v1.0.0","after":"abc1","checkout_sha":"abc2",
+		"repository":{"name":"api-test","git_http_url":"https://gitlab.example.com/me/api-test.git"}}`
+	r = httptest.NewRequest("POST", WebhookPath, nil)
+	r.Header.Set("X-Gitlab-Event", "Tag Push Hook")
+	r.Header.Set("X-Gitlab-Token", secret)
+	ev, err = ParseWebhook(r, []byte(tagPayload), secret)
+	assert.Nil(t, err)
+	assert.Equal(t, "gitlab", ev.Provider)
+	assert.Equal(t, "v1.0.0", ev.Tag)
+	assert.Equal(t, "abc2", ev.Commit)
+	assert.Equal(t, "https://gitlab.example.com/me/api-test.git", ev.CloneURL)
+
+	r.Header.Set("X-Gitlab-Token", "wrong")
+	_, err = ParseWebhook(r, []byte(tagPayload), secret)
+	assert.Equal(t, errWebhookSignature, err)
+
+	// Unknown
+	r = httptest.NewRequest("POST", WebhookPath, nil)
+	_, err = ParseWebhook(r, []byte(testPushPayload), secret)
+	assert.Equal(t, errWebhookProvider, err)
+}
+
+func Test_WebhookURL(t *testing.T) {
+	u, err := WebhookURL("10.0.0.1:8080")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://10.0.0.1:8080"+WebhookPath, u)
+
+	host, _ := os.Hostname()
+	u, err = WebhookURL(":8080")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://"+host+":8080"+WebhookPath, u)
+
+	u, err = WebhookURL("0.0.0.0:8080")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://"+host+":8080"+WebhookPath, u)
+
+	_, err = WebhookURL("8080")
+	assert.NotNil(t, err)
+}
+
+func Test_webhookSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thrap-webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	credsFile := filepath.Join(dir, consts.CredsFile)
+	err = config.WriteCredsConfig(config.DefaultCredsConfig(), credsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Generated on first use and stored
+	secret, err := webhookSecret(dir, &config.CredsConfig{})
+	assert.Nil(t, err)
+	assert.Equal(t, webhookSecretSize*2, len(secret))
+
+	stored, err := config.ReadCredsConfig(credsFile)
+	assert.Nil(t, err)
+	assert.Equal(t, secret, stored.GetWebhookSecret())
+
+	again, err := webhookSecret(dir, stored)
+	assert.Nil(t, err)
+	assert.Equal(t, secret, again)
+
+	// Supplied secrets replace the stored one
+	creds := &config.CredsConfig{}
+	creds.SetWebhookSecret("supplied")
+	secret, err = webhookSecret(dir, creds)
+	assert.Nil(t, err)
+	assert.Equal(t, "supplied", secret)
+	stored, _ = config.ReadCredsConfig(credsFile)
+	assert.Equal(t, "supplied", stored.GetWebhookSecret())
+}
+
+func Test_webhookProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thrap-webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	core := &Core{dataDir: dir, conf: &config.ThrapConfig{Webhook: &config.WebhookConfig{}}}
+	_, err = core.webhookProfile()
+	assert.NotNil(t, err)
+
+	err = store.NewHCLFileProfileStorage(filepath.Join(dir, consts.ProfilesFile)).Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prof, err := core.webhookProfile()
+	assert.Nil(t, err)
+	assert.Equal(t, thrapb.DefaultProfile().ID, prof.ID)
+
+	core.conf.Webhook.Profile = "missing"
+	_, err = core.webhookProfile()
+	assert.Contains(t, err.Error(), "profile not found: missing")
+}
@@ -7,10 +7,15 @@ Sniperkit-Bot
 package crt
 
 import (
+	"archive/tar"
 	"errors"
 	"io"
 	"math/rand"
 	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
@@ -31,6 +36,8 @@ type BuildRequest struct {
 	TarOpts    *archive.TarOptions
 	BuildOpts  *types.ImageBuildOptions
 	Output     io.Writer
+	// Dockerfile contents replacing the one in the context.  Optional
+	Dockerfile []byte
 }
 
 // PushRequest is a container image push request
@@ -41,6 +48,32 @@ type PushRequest struct {
 	Options types.ImagePushOptions
 }
 
+// LogsRequest is a request to read the logs of a container
+type LogsRequest struct {
+	// Keep streaming logs until the context is cancelled
+	Follow bool
+	// Only return logs after this time
+	Since time.Time
+	// Prefix each line with its RFC3339Nano timestamp
+	Timestamps bool
+	Stdout     io.Writer
+	Stderr     io.Writer
+}
+
+// ExecRequest is a request to run a command in a running container
+type ExecRequest struct {
+	Cmd []string
+	// Allocate a tty. Stdout and stderr are not multiplexed when set
+	TTY bool
+	// Initial tty size
+	Height uint
+	Width  uint
+	// Optional input to the command
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
 // Docker implements a docker backed orchestrator
 type Docker struct {
 	cli *client.Client
@@ -63,12 +96,83 @@ func (orch *Docker) Stop(ctx context.Context, containerID string) error {
 	return orch.cli.ContainerStop(ctx, containerID, &dur)
 }
 
+// Start starts an existing container
+func (orch *Docker) Start(ctx context.Context, containerID string) error {
+	return orch.cli.ContainerStart(ctx, containerID, types.ContainerStartOptions{})
+}
+
+// Wait blocks until the container stops returning its exit code
+func (orch *Docker) Wait(ctx context.Context, containerID string) (int64, error) {
+	respCh, errCh := orch.cli.ContainerWait(ctx, containerID, container.WaitConditionNotRunning)
+	select {
+	case resp := <-respCh:
+		if resp.Error != nil {
+			return resp.StatusCode, errors.New(resp.Error.Message)
+		}
+		return resp.StatusCode, nil
+	case err := <-errCh:
+		return -1, err
+	}
+}
+
+// CopyFromContainer returns a tar archive of the path in the container
+func (orch *Docker) CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, error) {
+	rc, _, err := orch.cli.CopyFromContainer(ctx, containerID, srcPath)
+	return rc, err
+}
+
+// CopyToContainer extracts the tar archive content into the directory in
+// the container.  The directory must exist
+func (orch *Docker) CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader) error {
+	opts := types.CopyToContainerOptions{AllowOverwriteDirWithFile: false}
+	return orch.cli.CopyToContainer(ctx, containerID, dstPath, content, opts)
+}
+
+// CopyFilesToContainer copies files or directories, relative to srcDir, into
+// the directory in the container.  Missing parent directories are created
+func (orch *Docker) CopyFilesToContainer(ctx context.Context, containerID, srcDir string, files []string, dstDir string) error {
+	var (
+		dst     = strings.TrimPrefix(path.Clean(dstDir), "/")
+		include = make([]string, 0, len(files))
+		rebase  = make(map[string]string, len(files))
+	)
+
+	for _, f := range files {
+		f = filepath.Clean(f)
+		include = append(include, f)
+		rebase[f] = path.Join(dst, filepath.ToSlash(f))
+	}
+
+	opts := &archive.TarOptions{IncludeFiles: include, RebaseNames: rebase}
+	rdc, err := archive.TarWithOptions(srcDir, opts)
+	if err != nil {
+		return err
+	}
+	defer rdc.Close()
+
+	return orch.CopyToContainer(ctx, containerID, "/", rdc)
+}
+
 // HaveImage returns true if we locally have the image
 func (orch *Docker) HaveImage(ctx context.Context, imageID string) bool {
 	_, _, err := orch.cli.ImageInspectWithRaw(ctx, imageID)
 	return err == nil
 }
 
+// ImageLabels returns the labels of the image
+func (orch *Docker) ImageLabels(ctx context.Context, imageID string) (map[string]string, error) {
+	info, _, err := orch.cli.ImageInspectWithRaw(ctx, imageID)
+	if err != nil || info.Config == nil {
+		return nil, err
+	}
+	return info.Config.Labels, nil
+}
+
+// ImageTag adds the target tag to the source image
+func (orch *Docker) ImageTag(ctx context.Context, source, target string) error {
+	return orch.cli.ImageTag(ctx, source, target)
+}
+
 // Run creates and runs a container with the given config
 func (orch *Docker) Run(ctx context.Context, cfg *thrapb.Container) ([]string, error) {
 
@@ -81,6 +185,56 @@ func (orch *Docker) Run(ctx context.Context, cfg *thrapb.Container) ([]string, e
 	return resp.Warnings, err
 }
 
+// Exec runs a command in a running container returning its exit code once it
+// completes
+func (orch *Docker) Exec(ctx context.Context, containerID string, req *ExecRequest) (int, error) {
+	conf := types.ExecConfig{
+		Cmd:          req.Cmd,
+		Tty:          req.TTY,
+		AttachStdin:  req.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+
+	resp, err := orch.cli.ContainerExecCreate(ctx, containerID, conf)
+	if err != nil {
+		return -1, err
+	}
+
+	hijack, err := orch.cli.ContainerExecAttach(ctx, resp.ID, conf)
+	if err != nil {
+		return -1, err
+	}
+	defer hijack.Close()
+
+	if req.TTY && req.Height > 0 && req.Width > 0 {
+		opts := types.ResizeOptions{Height: req.Height, Width: req.Width}
+		orch.cli.ContainerExecResize(ctx, resp.ID, opts)
+	}
+
+	if req.Stdin != nil {
+		go func() {
+			io.Copy(hijack.Conn, req.Stdin)
+			hijack.CloseWrite()
+		}()
+	}
+
+	if req.TTY {
+		_, err = io.Copy(req.Stdout, hijack.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(req.Stdout, req.Stderr, hijack.Reader)
+	}
+	if err != nil {
+		return -1, err
+	}
+
+	insp, err := orch.cli.ContainerExecInspect(ctx, resp.ID)
+	if err != nil {
+		return -1, err
+	}
+	return insp.ExitCode, nil
+}
+
 // Inspect returns information about the container by id
 func (orch *Docker) Inspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
 	return orch.cli.ContainerInspect(ctx, containerID)
@@ -106,6 +260,13 @@ func (orch *Docker) ListImagesWithLabel(ctx context.Context, label string) ([]ty
 	return orch.cli.ImageList(ctx, opts)
 }
 
+// ImageRemove forcibly removes the image and its untagged parents
+func (orch *Docker) ImageRemove(ctx context.Context, imageID string) error {
+	opts := types.ImageRemoveOptions{Force: true, PruneChildren: true}
+	_, err := orch.cli.ImageRemove(ctx, imageID, opts)
+	return err
+}
+
 // Remove forcibly stops and removes a container
 func (orch *Docker) Remove(ctx context.Context, cid string) error {
 	opts := types.ContainerRemoveOptions{Force: true}
@@ -114,9 +275,21 @@ func (orch *Docker) Remove(ctx context.Context, cid string) error {
 
 // Logs returns logs for a single container
 func (orch *Docker) Logs(ctx context.Context, containerID string, stdout, stderr io.Writer) error {
+	return orch.ContainerLogs(ctx, containerID, &LogsRequest{Stdout: stdout, Stderr: stderr})
+}
+
+// ContainerLogs writes the logs of a single container to the writers in the
+// request.  If follow is requested it blocks until the container exits or the
+// context is cancelled
+func (orch *Docker) ContainerLogs(ctx context.Context, containerID string, req *LogsRequest) error {
 	opts := types.ContainerLogsOptions{
 		ShowStderr: true,
 		ShowStdout: true,
+		Follow:     req.Follow,
+		Timestamps: req.Timestamps,
+	}
+	if !req.Since.IsZero() {
+		opts.Since = strconv.FormatInt(req.Since.Unix(), 10)
 	}
 
 	clogs, err := orch.cli.ContainerLogs(ctx, containerID, opts)
@@ -125,7 +298,7 @@ func (orch *Docker) Logs(ctx context.Context, containerID string, stdout, stderr
 	}
 	defer clogs.Close()
 
-	_, err = stdcopy.StdCopy(stdout, stderr, clogs)
+	_, err = stdcopy.StdCopy(req.Stdout, req.Stderr, clogs)
 	return err
 }
 
@@ -151,6 +324,9 @@ func (orch *Docker) Build(ctx context.Context, req *BuildRequest) error {
 	if err != nil {
 		return err
 	}
+	if len(req.Dockerfile) > 0 {
+		rdc = replaceDockerfile(rdc, req.BuildOpts.Dockerfile, req.Dockerfile)
+	}
 	defer rdc.Close()
 
 	resp, err := orch.cli.ImageBuild(ctx, rdc, *req.BuildOpts)
@@ -164,6 +340,24 @@ func (orch *Docker) Build(ctx context.Context, req *BuildRequest) error {
 	return err
 }
 
+// replaceDockerfile replaces the dockerfile in the build context tar stream
+// adding it if excluded
+func replaceDockerfile(rdc io.ReadCloser, name string, contents []byte) io.ReadCloser {
+	if name == "" {
+		name = "Dockerfile"
+	}
+	name = filepath.ToSlash(filepath.Clean(name))
+
+	return archive.ReplaceFileTarWrapper(rdc, map[string]archive.TarModifierFunc{
+		name: func(path string, hdr *tar.Header, _ io.Reader) (*tar.Header, []byte, error) {
+			if hdr == nil {
+				hdr = &tar.Header{Name: path, Mode: 0644, Typeflag: tar.TypeReg}
+			}
+			return hdr, contents, nil
+		},
+	})
+}
+
 // ImagePull pulls in image from the docker registry using docker. This uses
 // dockers built in mechanism to communicate to the registry
 func (orch *Docker) ImagePull(ctx context.Context, ref string) error {
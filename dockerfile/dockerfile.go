@@ -8,11 +8,14 @@ package dockerfile
 
 import (
 	"bytes"
+	"io"
 	"io/ioutil"
 
 	"github.com/pkg/errors"
 )
 
+var errStageNotFound = errors.New("stage not found")
+
 // Stage is a group of instructions containing a single FROM statement
 type Stage []Instruction
 
@@ -48,23 +51,46 @@ func (stage Stage) GetOp(op string) (Instruction, int) {
 	return nil, -1
 }
 
-// Dockerfile holds a completely parsed dockerfile
+// Dockerfile holds a completely parsed dockerfile.  Instructions parsed from
+// source are written back byte for byte unless modified
 type Dockerfile struct {
 	Stages []Stage
+
+	// Source of parsed instructions
+	src map[Instruction]*node
+	// Blank lines at the end of the source
+	trailing []byte
 }
 
-// StepCount returns the total number of steps in the dockerfile
+// StepCount returns the total number of steps in the dockerfile.  Comments
+// are not steps
 func (df *Dockerfile) StepCount() int {
 	var c int
 	for _, st := range df.Stages {
-		c += len(st)
+		for _, inst := range st {
+			if inst != nil && inst.Key() != KeyComment {
+				c++
+			}
+		}
 	}
 	return c
 }
 
+// Line returns the line the instruction was parsed from or 0 if it was not
+// parsed
+func (df *Dockerfile) Line(inst Instruction) int {
+	if n, ok := df.src[inst]; ok {
+		return n.line
+	}
+	return 0
+}
+
 // AddInstruction adds an instuction to the given stage it handles the addition
 // of instruction based on the type instruction applying the associated logic
 func (df *Dockerfile) AddInstruction(stage int, inst Instruction) error {
+	if stage < 0 || stage >= len(df.Stages) {
+		return errStageNotFound
+	}
 	st := df.Stages[stage]
 
 	key := inst.Key()
@@ -81,13 +107,15 @@ func (df *Dockerfile) AddInstruction(stage int, inst Instruction) error {
 		nst = append(st, inst)
 
 	case KeyArg:
-		// Only add if does not exist
+		// Only add if does not exist in the stage.  Args before FROM are
+		// global and not in scope of the stage
 		ai := inst.(*Arg)
-		for _, s := range st {
-			if s.Key() != KeyArg {
+		_, j := st.GetOp(KeyFrom)
+		for _, s := range st[j+1:] {
+			if s == nil || s.Key() != KeyArg {
 				continue
 			}
-			if s.(*Arg).Name == ai.Name {
+			if a, ok := s.(*Arg); ok && a.Name == ai.Name {
 				return nil
 			}
 		}
@@ -117,41 +145,82 @@ func (df *Dockerfile) insertInstruction(st Stage, inst Instruction) Stage {
 	return nst
 }
 
+// String returns the dockerfile.  Unmodified parsed instructions are written
+// as they were in the source including blank lines, continuations and
+// heredocs.  New or modified instructions are formatted on a single line
 func (df *Dockerfile) String() string {
-	var str string
+	var buf bytes.Buffer
 	for _, stage := range df.Stages {
 		for _, s := range stage {
 			// Nil instruction treated as new line
 			if s == nil {
-				str += "\n"
+				buf.WriteString("\n")
 				continue
 			}
-			str += s.Key() + " " + s.String() + "\n"
 
+			// Source without a trailing new line is followed by more
+			if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
+				buf.WriteByte('\n')
+			}
+
+			n, ok := df.src[s]
+			if ok {
+				buf.Write(n.leading)
+				if s.String() == n.parsed {
+					buf.Write(n.raw)
+					continue
+				}
+			}
+
+			buf.WriteString(s.Key())
+			if str := s.String(); str != "" {
+				buf.WriteString(" " + str)
+			}
+			buf.WriteString("\n")
+		}
+
+		// Stages of dockerfiles not parsed from source are separated by a
+		// blank line
+		if df.src == nil {
+			buf.WriteString("\n")
 		}
-		str += "\n"
 	}
+	buf.Write(df.trailing)
 
-	return str
+	return buf.String()
 }
 
 // RawDockerfile contains parsed elements of a dockerfile
 type RawDockerfile struct {
 	Stages []RawInstructions
+
+	// Blank lines at the end of the source
+	trailing []byte
 }
 
-// ParseRaw parses a raw dockerfile to concrete dockerfile
+// ParseRaw parses a raw dockerfile to concrete dockerfile.  Instructions
+// that cannot be parsed are kept raw
 func ParseRaw(raw *RawDockerfile) *Dockerfile {
-	df := &Dockerfile{Stages: make([]Stage, len(raw.Stages))}
+	df := &Dockerfile{
+		Stages:   make([]Stage, len(raw.Stages)),
+		src:      make(map[Instruction]*node),
+		trailing: raw.trailing,
+	}
 
 	for j, stage := range raw.Stages {
 		df.Stages[j] = make(Stage, len(stage))
 		for i, s := range stage {
-			ki, err := ParseInstruction(s)
-			if err == nil {
-				df.Stages[j][i] = ki
-			} else {
-				df.Stages[j][i] = stage[i]
+			var inst Instruction = s
+			if ki, err := ParseInstruction(s); err == nil {
+				inst = ki
+			}
+			df.Stages[j][i] = inst
+
+			// Raw data changed after parsing no longer matches the source
+			if s.src != nil && string(s.Data) == s.src.data {
+				n := *s.src
+				n.parsed = inst.String()
+				df.src[inst] = &n
 			}
 		}
 	}
@@ -170,54 +239,33 @@ func ParseFile(fpath string) (d *RawDockerfile, err error) {
 	return
 }
 
-// ParseBytes parses dockerfile bytes to a set of instructions
+// ParseBytes parses dockerfile bytes to a set of instructions.  Each FROM
+// starts a new stage.  Comments and global args before the first FROM are
+// part of the first stage
 func ParseBytes(b []byte) (*RawDockerfile, error) {
 	var (
-		df    = &RawDockerfile{Stages: make([]RawInstructions, 0)}
-		lines = bytes.Split(b, []byte("\n"))
-		out   = make(RawInstructions, 0, len(lines))
-		buf   = make([]byte, 0)
+		df  = &RawDockerfile{Stages: make([]RawInstructions, 0)}
+		lx  = newLexer(b)
+		out = make(RawInstructions, 0)
 	)
 
-	for _, line := range lines {
-
-		if len(line) == 0 {
-			continue
+	for {
+		inst, err := lx.next()
+		if err == io.EOF {
+			break
 		}
-
-		if line[0] == '#' {
-			out = append(out, &RawInstruction{Op: KeyComment, Data: line[1:]})
-			continue
+		if err != nil {
+			return nil, err
 		}
 
-		i := bytes.IndexByte(line, ' ')
-		if i == 0 {
-			buf = append(buf, append([]byte("\n"), line...)...)
-			continue
+		if inst.Op == KeyFrom && out.HasOp(KeyFrom) {
+			df.Stages = append(df.Stages, out)
+			out = make(RawInstructions, 0)
 		}
-
-		if op, ok := isCmd(line[:i]); ok {
-
-			if len(buf) > 0 {
-				out[len(out)-1].Data = append(out[len(out)-1].Data, buf...)
-				buf = make([]byte, 0)
-			}
-
-			if op == KeyFrom && len(out) != 0 {
-				stage := out
-				df.Stages = append(df.Stages, stage)
-				out = make(RawInstructions, 0, len(lines))
-			}
-
-			inst := &RawInstruction{string(line[:i]), line[i+1:]}
-			out = append(out, inst)
-
-		} else {
-			buf = append(buf, append([]byte("\n"), line...)...)
-		}
-
+		out = append(out, inst)
 	}
 
 	df.Stages = append(df.Stages, out)
+	df.trailing = lx.leading
 	return df, nil
 }
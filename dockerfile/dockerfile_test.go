@@ -97,6 +97,25 @@ func Test_isCmd(t *testing.T) {
 
 }
 
+func Test_ParseExpose(t *testing.T) {
+	for in, want := range map[string][]*ExposedPort{
+		"80":                 {{Port: "80"}},
+		"80/tcp 443/udp":     {{Port: "80", Proto: "tcp"}, {Port: "443", Proto: "udp"}},
+		"8000-8010":          {{Port: "8000-8010"}},
+		"$PORT ${ADMIN}/tcp": {{Port: "$PORT"}, {Port: "${ADMIN}", Proto: "tcp"}},
+	} {
+		e, err := ParseExpose([]byte(in))
+		fatal(t, err)
+		assert.Equal(t, want, e.Ports, in)
+		assert.Equal(t, in, e.String())
+	}
+
+	for _, in := range []string{"", "http", "80/http", "0", "70000", "90-80", "80/tcp/udp"} {
+		_, err := ParseExpose([]byte(in))
+		assert.NotNil(t, err, in)
+	}
+}
+
 func Test_KeyVolume(t *testing.T) {
 	v := &Volume{[]string{"/foo", "/bar"}}
 	t.Log(v)
@@ -0,0 +1,87 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package dockerfile
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Matches $VAR and ${VAR} referencing args
+var argRefRegex = regexp.MustCompile(`\$\{?([a-zA-Z_][a-zA-Z0-9_]*)\}?`)
+
+// BaseImages returns the external images of the FROM instructions in order
+// with global args expanded.  Stage references, scratch and images that can
+// not be expanded are skipped
+func (df *Dockerfile) BaseImages() []string {
+	var out []string
+	df.eachBaseImage(func(from *From, image string) {
+		out = append(out, image)
+	})
+	return out
+}
+
+// PinImages replaces the image of each FROM with that returned by pin, given
+// the expanded image.  It returns the number of images changed
+func (df *Dockerfile) PinImages(pin func(image string) string) int {
+	var n int
+	df.eachBaseImage(func(from *From, image string) {
+		if pinned := pin(image); pinned != image {
+			from.Image = pinned
+			n++
+		}
+	})
+	return n
+}
+
+func (df *Dockerfile) eachBaseImage(f func(from *From, image string)) {
+	var (
+		globals = make(map[string]string)
+		stages  = make(map[string]bool)
+		// Args before the first FROM are global
+		global = true
+	)
+
+	for _, stage := range df.Stages {
+		for _, inst := range stage {
+			switch v := inst.(type) {
+			case *Arg:
+				if global {
+					globals[v.Name] = v.Default
+				}
+			case *From:
+				image := expandArgs(v.Image, globals)
+				isStage := stages[strings.ToLower(image)]
+				global = false
+				if v.As != "" {
+					stages[strings.ToLower(v.As)] = true
+				}
+				if image != "" && image != "scratch" && !isStage {
+					f(v, image)
+				}
+			}
+		}
+	}
+}
+
+// expandArgs replaces references to args with their values.  An empty string
+// is returned if any are unknown
+func expandArgs(s string, args map[string]string) string {
+	unknown := false
+	out := argRefRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		name := argRefRegex.FindStringSubmatch(ref)[1]
+		v, ok := args[name]
+		if !ok || v == "" {
+			unknown = true
+		}
+		return v
+	})
+	if unknown {
+		return ""
+	}
+	return out
+}
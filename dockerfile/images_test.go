@@ -0,0 +1,39 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Dockerfile_BaseImages(t *testing.T) {
+	src := `ARG GO_VERSION=1.11
+ARG BASE
+FROM golang:${GO_VERSION} AS build
+ARG GO_VERSION=1.12
+FROM build AS test
+FROM ${BASE}
+FROM scratch
+FROM alpine:3.8
+`
+	raw, err := ParseBytes([]byte(src))
+	fatal(t, err)
+	df := ParseRaw(raw)
+	assert.Equal(t, []string{"golang:1.11", "alpine:3.8"}, df.BaseImages())
+
+	n := df.PinImages(func(image string) string {
+		if image == "golang:1.11" {
+			return image + "@sha256:abc"
+		}
+		return image
+	})
+	assert.Equal(t, 1, n)
+	assert.Contains(t, df.String(), "\nFROM golang:1.11@sha256:abc as build\n")
+	assert.Contains(t, df.String(), "\nFROM alpine:3.8\n")
+}
@@ -16,6 +16,10 @@ type Instruction interface {
 type RawInstruction struct {
 	Op   string
 	Data []byte
+	// Heredocs of a RUN, COPY or ADD instruction
+	Heredocs []*Heredoc
+
+	src *node
 }
 
 // Key returns the op key
@@ -24,7 +28,7 @@ func (ri *RawInstruction) Key() string {
 }
 
 func (ri *RawInstruction) String() string {
-	return string(ri.Data)
+	return string(ri.Data) + heredocsString(ri.Heredocs)
 }
 
 // RawInstructions are a set of instructions in order
@@ -41,3 +45,26 @@ func (insts RawInstructions) HasOp(op string) bool {
 
 	return false
 }
+
+// Heredoc is a here-document of a RUN, COPY or ADD instruction i.e.
+// RUN <<EOF
+type Heredoc struct {
+	// Name is the word terminating the document
+	Name string
+	// Content of the document including the trailing new line
+	Content string
+	// Chomp strips leading tabs from the lines i.e. <<-EOF
+	Chomp bool
+}
+
+func (doc *Heredoc) String() string {
+	return "\n" + doc.Content + doc.Name
+}
+
+func heredocsString(docs []*Heredoc) string {
+	var out string
+	for _, doc := range docs {
+		out += doc.String()
+	}
+	return out
+}
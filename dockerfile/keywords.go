@@ -50,13 +50,27 @@ const (
 	KeyCmd = "CMD"
 	// KeyEntrypoint is the dockerfile operation
 	KeyEntrypoint = "ENTRYPOINT"
+	// KeyMaintainer is the deprecated dockerfile operation
+	KeyMaintainer = "MAINTAINER"
 )
 
+var keywords = map[string]bool{
+	KeyFrom: true, KeyExpose: true, KeyRun: true, KeyCopy: true, KeyAdd: true,
+	KeyLabel: true, KeyEnv: true, KeyWorkDir: true, KeyVolume: true, KeyUser: true,
+	KeyArg: true, KeyShell: true, KeyHealthCheck: true, KeyStopSignal: true,
+	KeyOnBuild: true, KeyCmd: true, KeyEntrypoint: true, KeyMaintainer: true,
+}
+
 var (
 	errInvalidInstruction   = errors.New("invalid instruction")
 	errUnsupportInstruction = errors.New("unsupported instruction")
 )
 
+// isKeyword returns true if the upper cased op is a dockerfile instruction
+func isKeyword(op string) bool {
+	return keywords[op]
+}
+
 // ParseInstruction parses a raw instruction into a concrete instruction
 func ParseInstruction(r *RawInstruction) (Instruction, error) {
 	switch r.Op {
@@ -65,15 +79,52 @@ func ParseInstruction(r *RawInstruction) (Instruction, error) {
 		return ParseFrom(r.Data)
 	case KeyArg:
 		return ParseArg(r.Data)
+	case KeyEnv:
+		return ParseEnv(r.Data)
+	case KeyLabel:
+		return ParseLabel(r.Data)
 	case KeyWorkDir:
 		return ParseWorkDir(r.Data)
 	case KeyExpose:
 		return ParseExpose(r.Data)
-	case KeyCopy:
-		return ParseCopy(r.Data)
+	case KeyVolume:
+		return ParseVolume(r.Data)
+	case KeyUser:
+		return ParseUser(r.Data)
+	case KeyStopSignal:
+		return ParseStopSignal(r.Data)
+	case KeyShell:
+		return ParseShell(r.Data)
+	case KeyCmd:
+		return ParseCmd(r.Data)
+	case KeyEntrypoint:
+		return ParseEntryPoint(r.Data)
+	case KeyHealthCheck:
+		return ParseHealthCheck(r.Data)
+	case KeyOnBuild:
+		return ParseOnBuild(r.Data)
 	case KeyComment:
 		return ParseComment(r.Data)
 
+	case KeyRun:
+		run, err := ParseRun(r.Data)
+		if err == nil {
+			run.Heredocs = r.Heredocs
+		}
+		return run, err
+	case KeyCopy:
+		c, err := ParseCopy(r.Data)
+		if err == nil {
+			c.Heredocs = r.Heredocs
+		}
+		return c, err
+	case KeyAdd:
+		add, err := ParseAdd(r.Data)
+		if err == nil {
+			add.Heredocs = r.Heredocs
+		}
+		return add, err
+
 	default:
 		return nil, errUnsupportInstruction
 	}
@@ -82,16 +133,23 @@ func ParseInstruction(r *RawInstruction) (Instruction, error) {
 // Arg is the ARG keyword in a dockerfile
 type Arg struct {
 	Name string
+	// Default value if any
+	Default string
 }
 
 // ParseArg parses a byte slice to a Arg struct
 func ParseArg(b []byte) (*Arg, error) {
-	p := strings.Split(string(b), " ")
-	if len(p) != 1 {
+	words := splitWords(string(b))
+	if len(words) != 1 {
 		return nil, errors.New("must have one arg name")
 	}
 
-	return &Arg{Name: string(b)}, nil
+	kv := strings.SplitN(words[0], "=", 2)
+	arg := &Arg{Name: kv[0]}
+	if len(kv) == 2 {
+		arg.Default = kv[1]
+	}
+	return arg, nil
 }
 
 // Key returns the instruction key
@@ -100,7 +158,10 @@ func (e *Arg) Key() string {
 }
 
 func (e *Arg) String() string {
-	return e.Name
+	if e.Default == "" {
+		return e.Name
+	}
+	return e.Name + "=" + quoteValue(e.Default)
 }
 
 // Env is the ENV keyword in a dockerfile
@@ -108,6 +169,16 @@ type Env struct {
 	Vars map[string]string
 }
 
+// ParseEnv parses an ENV instruction in either the key=value or the legacy
+// key value form
+func ParseEnv(b []byte) (*Env, error) {
+	vars, err := parseKVInstruction(b)
+	if err != nil {
+		return nil, errors.Wrap(err, KeyEnv)
+	}
+	return &Env{Vars: vars}, nil
+}
+
 // Key returns the instruction key
 func (e *Env) Key() string {
 	return KeyEnv
@@ -123,12 +194,30 @@ func (e *Env) Clone() *Env {
 }
 
 func (e *Env) String() string {
-	var out string
-	for k, v := range e.Vars {
-		out += k + `="` + v + `" `
+	return formatKV(e.Vars)
+}
+
+// Label is the LABEL keyword in a dockerfile
+type Label struct {
+	Labels map[string]string
+}
+
+// ParseLabel parses a LABEL instruction
+func ParseLabel(b []byte) (*Label, error) {
+	labels, err := parseKVInstruction(b)
+	if err != nil {
+		return nil, errors.Wrap(err, KeyLabel)
 	}
+	return &Label{Labels: labels}, nil
+}
+
+// Key returns the instruction key
+func (l *Label) Key() string {
+	return KeyLabel
+}
 
-	return out[:len(out)-1]
+func (l *Label) String() string {
+	return formatKV(l.Labels)
 }
 
 // WorkDir is the WORKDIR keyword in a dockerfile
@@ -153,6 +242,61 @@ func ParseWorkDir(b []byte) (*WorkDir, error) {
 	return &WorkDir{Path: string(b)}, nil
 }
 
+// User is the USER keyword in a dockerfile
+type User struct {
+	Name  string
+	Group string
+}
+
+// ParseUser parses a USER instruction of the form user[:group]
+func ParseUser(b []byte) (*User, error) {
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		return nil, errors.Wrap(errInvalidInstruction, KeyUser)
+	}
+	parts := strings.SplitN(s, ":", 2)
+	user := &User{Name: parts[0]}
+	if len(parts) == 2 {
+		user.Group = parts[1]
+	}
+	return user, nil
+}
+
+// Key returns the instruction key
+func (user *User) Key() string {
+	return KeyUser
+}
+
+func (user *User) String() string {
+	if user.Group == "" {
+		return user.Name
+	}
+	return user.Name + ":" + user.Group
+}
+
+// StopSignal is the STOPSIGNAL keyword in a dockerfile
+type StopSignal struct {
+	Signal string
+}
+
+// ParseStopSignal parses a STOPSIGNAL instruction
+func ParseStopSignal(b []byte) (*StopSignal, error) {
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		return nil, errors.Wrap(errInvalidInstruction, KeyStopSignal)
+	}
+	return &StopSignal{Signal: s}, nil
+}
+
+// Key returns the instruction key
+func (sig *StopSignal) Key() string {
+	return KeyStopSignal
+}
+
+func (sig *StopSignal) String() string {
+	return sig.Signal
+}
+
 // Comment is a comment in a docker file.  This is a single line in itself
 type Comment struct {
 	Text string
@@ -167,24 +311,39 @@ func (cmt *Comment) Key() string {
 	return KeyComment
 }
 
-// ParseComment parse a comment in a dockerfile
+// ParseComment parse a comment in a dockerfile.  The leading # is optional
 func ParseComment(b []byte) (*Comment, error) {
 	s := strings.TrimSpace(string(b))
-	if s[0] != '#' {
-		return nil, errors.New("invalid comment")
-	}
 	return &Comment{
-		Text: strings.TrimSpace(s[1:]),
+		Text: strings.TrimSpace(strings.TrimPrefix(s, "#")),
 	}, nil
 }
 
 // Run is the RUN keyword in a dockerfile
 type Run struct {
+	// Options i.e. --mount=type=cache,target=/root/.cache
+	Options []string
+	// Command in shell form
 	Command string
+	// Args in exec form.  Used instead of Command when set
+	Args []string
+	// Heredocs referenced by the command
+	Heredocs []*Heredoc
+}
+
+// ParseRun parses a RUN instruction
+func ParseRun(b []byte) (*Run, error) {
+	opts, rest := splitOptions(string(b))
+	run := &Run{Options: opts}
+	run.Command, run.Args = parseCommand(rest)
+	if run.Command == "" && run.Args == nil {
+		return nil, errors.Wrap(errInvalidInstruction, KeyRun)
+	}
+	return run, nil
 }
 
 func (run *Run) String() string {
-	return run.Command
+	return joinOptions(run.Options, formatCommand(run.Command, run.Args)) + heredocsString(run.Heredocs)
 }
 
 // Key returns the instruction key
@@ -194,12 +353,21 @@ func (run *Run) Key() string {
 
 // Cmd is a parse docker CMD instruction
 type Cmd struct {
+	// Command in shell form
 	Command string
-	Args    []string
+	// Args in exec form.  Used instead of Command when set
+	Args []string
+}
+
+// ParseCmd parses a CMD instruction
+func ParseCmd(b []byte) (*Cmd, error) {
+	cmd := &Cmd{}
+	cmd.Command, cmd.Args = parseCommand(string(b))
+	return cmd, nil
 }
 
 func (cmd *Cmd) String() string {
-	return cmd.Command + " " + strings.Join(cmd.Args, " ")
+	return formatCommand(cmd.Command, cmd.Args)
 }
 
 // Key returns the instruction key
@@ -209,11 +377,21 @@ func (cmd *Cmd) Key() string {
 
 // EntryPoint is the ENTRYPOINT keyword in a dockerfile
 type EntryPoint struct {
+	// Command in shell form
 	Command string
+	// Args in exec form.  Used instead of Command when set
+	Args []string
+}
+
+// ParseEntryPoint parses an ENTRYPOINT instruction
+func ParseEntryPoint(b []byte) (*EntryPoint, error) {
+	ep := &EntryPoint{}
+	ep.Command, ep.Args = parseCommand(string(b))
+	return ep, nil
 }
 
 func (ep *EntryPoint) String() string {
-	return ep.Command
+	return formatCommand(ep.Command, ep.Args)
 }
 
 // Key returns the instruction key
@@ -221,11 +399,128 @@ func (ep *EntryPoint) Key() string {
 	return KeyEntrypoint
 }
 
+// Shell is the SHELL keyword in a dockerfile
+type Shell struct {
+	Args []string
+}
+
+// ParseShell parses a SHELL instruction.  Only the exec form is valid
+func ParseShell(b []byte) (*Shell, error) {
+	args, ok := parseExecForm(string(b))
+	if !ok || len(args) == 0 {
+		return nil, errors.Wrap(errInvalidInstruction, KeyShell)
+	}
+	return &Shell{Args: args}, nil
+}
+
+// Key returns the instruction key
+func (sh *Shell) Key() string {
+	return KeyShell
+}
+
+func (sh *Shell) String() string {
+	return formatExecForm(sh.Args)
+}
+
+// HealthCheck is the HEALTHCHECK keyword in a dockerfile
+type HealthCheck struct {
+	// None disables any inherited health check
+	None bool
+	// Options i.e. --interval=30s
+	Options []string
+	// Command in shell form
+	Command string
+	// Args in exec form.  Used instead of Command when set
+	Args []string
+}
+
+// ParseHealthCheck parses a HEALTHCHECK instruction i.e. NONE or
+// [options] CMD command
+func ParseHealthCheck(b []byte) (*HealthCheck, error) {
+	opts, rest := splitOptions(string(b))
+	word, cmd := splitWord([]byte(rest))
+
+	switch strings.ToUpper(word) {
+	case "NONE":
+		if len(opts) > 0 || len(cmd) > 0 {
+			return nil, errors.Wrap(errInvalidInstruction, KeyHealthCheck)
+		}
+		return &HealthCheck{None: true}, nil
+
+	case KeyCmd:
+		hc := &HealthCheck{Options: opts}
+		hc.Command, hc.Args = parseCommand(string(cmd))
+		if hc.Command == "" && hc.Args == nil {
+			return nil, errors.Wrap(errInvalidInstruction, KeyHealthCheck)
+		}
+		return hc, nil
+
+	}
+
+	return nil, errors.Wrap(errInvalidInstruction, KeyHealthCheck)
+}
+
+// Key returns the instruction key
+func (hc *HealthCheck) Key() string {
+	return KeyHealthCheck
+}
+
+func (hc *HealthCheck) String() string {
+	if hc.None {
+		return "NONE"
+	}
+	return joinOptions(hc.Options, KeyCmd+" "+formatCommand(hc.Command, hc.Args))
+}
+
+// OnBuild is the ONBUILD keyword in a dockerfile wrapping the instruction
+// run by downstream builds
+type OnBuild struct {
+	Instruction Instruction
+}
+
+// ParseOnBuild parses an ONBUILD instruction and the instruction it wraps
+func ParseOnBuild(b []byte) (*OnBuild, error) {
+	word, rest := splitWord(b)
+	op := strings.ToUpper(word)
+	if !isKeyword(op) || op == KeyOnBuild || op == KeyFrom || op == KeyMaintainer {
+		return nil, errors.Wrap(errInvalidInstruction, KeyOnBuild)
+	}
+
+	raw := &RawInstruction{Op: op, Data: rest}
+	inst, err := ParseInstruction(raw)
+	if err != nil {
+		return &OnBuild{Instruction: raw}, nil
+	}
+	return &OnBuild{Instruction: inst}, nil
+}
+
+// Key returns the instruction key
+func (ob *OnBuild) Key() string {
+	return KeyOnBuild
+}
+
+func (ob *OnBuild) String() string {
+	return ob.Instruction.Key() + " " + ob.Instruction.String()
+}
+
 // Volume is the VOLUME keyword in a dockerfile
 type Volume struct {
 	Paths []string
 }
 
+// ParseVolume parses a VOLUME instruction in either the exec or the space
+// separated form
+func ParseVolume(b []byte) (*Volume, error) {
+	paths, ok := parseExecForm(string(b))
+	if !ok {
+		paths = strings.Fields(string(b))
+	}
+	if len(paths) == 0 {
+		return nil, errors.Wrap(errInvalidInstruction, KeyVolume)
+	}
+	return &Volume{Paths: paths}, nil
+}
+
 // Key returns the instruction key
 func (vol *Volume) Key() string {
 	return KeyVolume
@@ -240,66 +535,92 @@ func (vol *Volume) String() string {
 
 // Expose line
 type Expose struct {
-	Port  uint16
+	Ports []*ExposedPort
+}
+
+// ExposedPort is a port or port range i.e. 8000-8010 with an optional
+// protocol.  Ports may also be variables i.e. $PORT
+type ExposedPort struct {
+	Port  string
 	Proto string
 }
 
+func (ep *ExposedPort) String() string {
+	if ep.Proto == "" {
+		return ep.Port
+	}
+	return ep.Port + "/" + ep.Proto
+}
+
 // Key returns the instruction key
 func (expose *Expose) Key() string {
 	return KeyExpose
 }
 
 func (expose *Expose) String() string {
-	if expose.Port == 0 {
-		return ""
+	ports := make([]string, len(expose.Ports))
+	for i, p := range expose.Ports {
+		ports[i] = p.String()
 	}
-
-	out := strconv.FormatUint(uint64(expose.Port), 10)
-	if len(expose.Proto) > 0 {
-		out += "/" + expose.Proto
-	}
-	return out
+	return strings.Join(ports, " ")
 }
 
-// ParseExpose parses an expose instruction
+// ParseExpose parses an expose instruction of one or more ports
 func ParseExpose(b []byte) (*Expose, error) {
-	parts := strings.Split(string(b), "/")
-	l := len(parts)
-
-	var (
-		e   Expose
-		p   uint64
-		err error
-	)
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return nil, errors.Wrap(errInvalidInstruction, KeyExpose)
+	}
 
-	switch l {
-	case 1:
-		p, err = strconv.ParseUint(parts[0], 10, 16)
-		if err == nil {
-			e.Port = uint16(p)
+	e := &Expose{Ports: make([]*ExposedPort, 0, len(fields))}
+	for _, field := range fields {
+		parts := strings.Split(field, "/")
+		if len(parts) > 2 || !isExposePort(parts[0]) {
+			return nil, errors.Wrap(errInvalidInstruction, KeyExpose)
 		}
 
-	case 2:
-		e.Proto = parts[1]
-		p, err = strconv.ParseUint(parts[0], 10, 16)
-		if err == nil {
-			e.Port = uint16(p)
+		ep := &ExposedPort{Port: parts[0]}
+		if len(parts) == 2 {
+			switch strings.ToLower(parts[1]) {
+			case "tcp", "udp", "sctp":
+				ep.Proto = parts[1]
+			default:
+				return nil, errors.Wrap(errInvalidInstruction, KeyExpose)
+			}
 		}
+		e.Ports = append(e.Ports, ep)
+	}
 
-	default:
-		return nil, errors.Wrap(errInvalidInstruction, KeyExpose)
+	return e, nil
+}
 
+// isExposePort returns true if s is a port, port range or variable
+func isExposePort(s string) bool {
+	if strings.HasPrefix(s, "$") {
+		return len(s) > 1
 	}
 
-	return &e, nil
+	bounds := strings.SplitN(s, "-", 2)
+	var last uint64
+	for _, bound := range bounds {
+		p, err := strconv.ParseUint(bound, 10, 16)
+		if err != nil || p == 0 || p < last {
+			return false
+		}
+		last = p
+	}
+	return true
 }
 
 // Copy line
 type Copy struct {
-	Source      string
+	// Sources in order.  The last argument is the Destination
+	Sources     []string
 	Destination string
 	// Addtional copy options format: --option=value
 	Options []string
+	// Heredocs used as sources
+	Heredocs []*Heredoc
 }
 
 // Key returns the instruction key
@@ -307,37 +628,70 @@ func (copy *Copy) Key() string {
 	return KeyCopy
 }
 
+// Option returns the value of the option i.e. Option("from") returns builder
+// for --from=builder
+func (copy *Copy) Option(name string) string {
+	return optionValue(copy.Options, name)
+}
+
 func (copy *Copy) String() string {
-	return strings.Join(copy.Options, " ") + " " +
-		copy.Source + " " +
-		copy.Destination
+	args := append(append([]string{}, copy.Sources...), copy.Destination)
+	// Paths with spaces require the exec form
+	for _, arg := range args {
+		if strings.ContainsAny(arg, " \t") {
+			return joinOptions(copy.Options, formatExecForm(args)) + heredocsString(copy.Heredocs)
+		}
+	}
+	return joinOptions(copy.Options, strings.Join(args, " ")) + heredocsString(copy.Heredocs)
 }
 
 // ParseCopy parses a COPY instruction
 func ParseCopy(b []byte) (*Copy, error) {
-	parts := strings.Split(string(b), " ")
-	l := len(parts)
+	opts, rest := splitOptions(string(b))
 
+	args, ok := parseExecForm(rest)
+	if !ok {
+		args = strings.Fields(rest)
+	}
+
+	l := len(args)
 	if l < 2 {
 		return nil, errors.Wrap(errInvalidInstruction, KeyCopy)
 	}
 
-	c := &Copy{
-		Source:      parts[l-2],
-		Destination: parts[l-1],
-	}
+	return &Copy{
+		Options:     opts,
+		Sources:     args[:l-1],
+		Destination: args[l-1],
+	}, nil
+}
 
-	if len(parts) > 2 {
-		c.Options = parts[:l-2]
-	}
+// Add is the ADD keyword in a dockerfile.  Sources may also be urls and
+// local archives
+type Add struct {
+	Copy
+}
 
-	return c, nil
+// Key returns the instruction key
+func (add *Add) Key() string {
+	return KeyAdd
+}
+
+// ParseAdd parses an ADD instruction
+func ParseAdd(b []byte) (*Add, error) {
+	c, err := ParseCopy(b)
+	if err != nil {
+		return nil, errors.Wrap(errInvalidInstruction, KeyAdd)
+	}
+	return &Add{Copy: *c}, nil
 }
 
 // From line
 type From struct {
 	Image string
 	As    string
+	// Options i.e. --platform=linux/amd64
+	Options []string
 }
 
 // Key returns the instruction key
@@ -347,26 +701,27 @@ func (from *From) Key() string {
 
 func (from *From) String() string {
 	if len(from.As) == 0 {
-		return from.Image
+		return joinOptions(from.Options, from.Image)
 	}
 
-	return from.Image + " as " + from.As
+	return joinOptions(from.Options, from.Image+" as "+from.As)
 }
 
 // ParseFrom parses returns a from object
 func ParseFrom(b []byte) (*From, error) {
+	opts, rest := splitOptions(string(b))
+
 	var (
-		s     = string(b)
-		parts = strings.Split(s, " ")
+		parts = strings.Fields(rest)
 		l     = len(parts)
 		from  *From
 	)
 
-	switch l {
-	case 1:
+	switch {
+	case l == 1:
 		from = &From{Image: parts[0]}
 
-	case 3:
+	case l == 3 && strings.EqualFold(parts[1], "as"):
 		from = &From{
 			Image: parts[0],
 			As:    parts[2],
@@ -376,5 +731,40 @@ func ParseFrom(b []byte) (*From, error) {
 		return nil, errors.Wrap(errInvalidInstruction, KeyFrom)
 	}
 
+	from.Options = opts
 	return from, nil
 }
+
+// parseKVInstruction parses key=value pairs or the legacy key value form of
+// a single pair
+func parseKVInstruction(b []byte) (map[string]string, error) {
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		return nil, errInvalidInstruction
+	}
+
+	key, rest := splitWord([]byte(s))
+	if !strings.Contains(key, "=") {
+		if len(rest) == 0 {
+			return nil, errInvalidInstruction
+		}
+		return map[string]string{key: string(rest)}, nil
+	}
+
+	for _, word := range splitWords(s) {
+		if !strings.Contains(word, "=") {
+			return nil, errInvalidInstruction
+		}
+	}
+	return parseKV(b), nil
+}
+
+// formatKV returns key="value" pairs sorted by key
+func formatKV(m map[string]string) string {
+	keys := sortedKeys(m)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + `="` + escapeQuoted(m[k]) + `"`
+	}
+	return strings.Join(pairs, " ")
+}
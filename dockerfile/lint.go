@@ -0,0 +1,275 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package dockerfile
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+)
+
+// Severity is the severity of a lint finding
+type Severity string
+
+const (
+	// SeverityError fails validation and pre-build lint gates
+	SeverityError Severity = "error"
+	// SeverityWarning is reported without failing
+	SeverityWarning Severity = "warning"
+	// SeverityInfo is reported without failing
+	SeverityInfo Severity = "info"
+	// SeverityOff disables the rule
+	SeverityOff Severity = "off"
+)
+
+// Lint rules
+const (
+	// RuleFromUnpinned is a FROM image without a tag or digest
+	RuleFromUnpinned = "from-unpinned"
+	// RuleFromLatest is a FROM image with the latest tag
+	RuleFromLatest = "from-latest"
+	// RuleUserRoot is a final stage running as root
+	RuleUserRoot = "user-root"
+	// RuleAddURL is an ADD of a remote url
+	RuleAddURL = "add-url"
+	// RuleHealthCheckMissing is an image without a HEALTHCHECK for a
+	// component declaring no health checks
+	RuleHealthCheckMissing = "healthcheck-missing"
+	// RuleSecretEnv is a secret set with ENV or ARG
+	RuleSecretEnv = "secret-env"
+	// RuleAptCache is an apt-get install not removing the package lists
+	RuleAptCache = "apt-cache"
+	// RuleCopyAll is a COPY of the whole context without a .dockerignore
+	RuleCopyAll = "copy-all"
+)
+
+var errSeverityInvalid = errors.New("severity must be one of error, warning, info or off")
+
+// DefaultSeverities are the severities of all lint rules
+var DefaultSeverities = map[string]Severity{
+	RuleFromUnpinned:       SeverityError,
+	RuleFromLatest:         SeverityWarning,
+	RuleUserRoot:           SeverityWarning,
+	RuleAddURL:             SeverityWarning,
+	RuleHealthCheckMissing: SeverityInfo,
+	RuleSecretEnv:          SeverityError,
+	RuleAptCache:           SeverityWarning,
+	RuleCopyAll:            SeverityWarning,
+}
+
+// LintRuleDescriptions describe each lint rule
+var LintRuleDescriptions = map[string]string{
+	RuleFromUnpinned:       "Base images should be pinned to a tag or digest",
+	RuleFromLatest:         "Base images should not use the mutable latest tag",
+	RuleUserRoot:           "Images should not run as root",
+	RuleAddURL:             "Use RUN with curl or wget and verify downloads rather than ADD of a url",
+	RuleHealthCheckMissing: "Images should declare a HEALTHCHECK when the component declares none",
+	RuleSecretEnv:          "Secrets set with ENV or ARG are stored in the image",
+	RuleAptCache:           "Remove /var/lib/apt/lists in the RUN installing packages",
+	RuleCopyAll:            "Copying the whole context requires a .dockerignore",
+}
+
+// LintFinding is a single problem found in a dockerfile
+type LintFinding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// File set by the caller linting multiple dockerfiles
+	File string `json:"file,omitempty"`
+	// Line of the instruction.  0 if not parsed from source
+	Line int `json:"line,omitempty"`
+}
+
+func (f *LintFinding) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s [%s]", f.File, f.Line, f.Severity, f.Message, f.Rule)
+}
+
+// LintOptions are options to lint a dockerfile
+type LintOptions struct {
+	// ContextDir is the build context.  Used to find the .dockerignore
+	ContextDir string
+	// HealthChecked is true if the component declares health checks
+	HealthChecked bool
+	// Secret returns true if an ENV or ARG name holds a secret.  The check
+	// is skipped if not set
+	Secret func(name string) bool
+	// Severities overriding the DefaultSeverities
+	Severities map[string]Severity
+}
+
+// ParseSeverity parses a severity name
+func ParseSeverity(s string) (Severity, error) {
+	sev := Severity(strings.ToLower(strings.TrimSpace(s)))
+	switch sev {
+	case SeverityError, SeverityWarning, SeverityInfo, SeverityOff:
+		return sev, nil
+	}
+	return "", errSeverityInvalid
+}
+
+// HasLintErrors returns true if any finding is of error severity
+func HasLintErrors(findings []*LintFinding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint checks the dockerfile against best practices returning the findings
+// in the order of the instructions
+func Lint(df *Dockerfile, opt LintOptions) []*LintFinding {
+	l := &linter{df: df, opt: opt, bases: make(map[*From]string)}
+	df.eachBaseImage(func(from *From, image string) {
+		l.bases[from] = image
+	})
+	for i, stage := range df.Stages {
+		l.lintStage(stage, i == len(df.Stages)-1)
+	}
+	return l.findings
+}
+
+type linter struct {
+	df  *Dockerfile
+	opt LintOptions
+	// Expanded external images of the FROM instructions
+	bases    map[*From]string
+	findings []*LintFinding
+}
+
+func (l *linter) report(rule string, inst Instruction, format string, args ...interface{}) {
+	sev, ok := l.opt.Severities[rule]
+	if !ok {
+		sev = DefaultSeverities[rule]
+	}
+	if sev == SeverityOff {
+		return
+	}
+
+	l.findings = append(l.findings, &LintFinding{
+		Rule:     rule,
+		Severity: sev,
+		Message:  fmt.Sprintf(format, args...),
+		Line:     l.df.Line(inst),
+	})
+}
+
+func (l *linter) lintStage(stage Stage, final bool) {
+	var (
+		from   Instruction
+		user   *User
+		health bool
+	)
+
+	for _, inst := range stage {
+		switch v := inst.(type) {
+		case *From:
+			from = v
+			l.lintFrom(v)
+		case *Arg:
+			l.lintSecret(inst, v.Name)
+		case *Env:
+			for _, k := range sortedKeys(v.Vars) {
+				l.lintSecret(inst, k)
+			}
+		case *User:
+			user = v
+		case *HealthCheck:
+			health = true
+		case *Add:
+			for _, src := range v.Sources {
+				if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+					l.report(RuleAddURL, inst, "ADD of url %s", src)
+				}
+			}
+		case *Copy:
+			l.lintCopy(v)
+		case *Run:
+			l.lintRun(v)
+		}
+	}
+
+	if !final || from == nil {
+		return
+	}
+
+	if user == nil {
+		l.report(RuleUserRoot, from, "final stage has no USER and runs as root")
+	} else if user.Name == "root" || user.Name == "0" {
+		l.report(RuleUserRoot, user, "final stage runs as root")
+	}
+
+	if !health && !l.opt.HealthChecked {
+		l.report(RuleHealthCheckMissing, from, "no HEALTHCHECK and the component declares no health checks")
+	}
+}
+
+func (l *linter) lintFrom(from *From) {
+	// Stages, scratch and digests need no tag
+	image, ok := l.bases[from]
+	if !ok || strings.Contains(image, "@") {
+		return
+	}
+
+	switch imageTag(image) {
+	case "":
+		l.report(RuleFromUnpinned, from, "image %s is not pinned to a tag or digest", image)
+	case "latest":
+		l.report(RuleFromLatest, from, "image %s uses the latest tag", image)
+	}
+}
+
+func (l *linter) lintSecret(inst Instruction, name string) {
+	if l.opt.Secret != nil && l.opt.Secret(name) {
+		l.report(RuleSecretEnv, inst, "%s %s is stored in the image", inst.Key(), name)
+	}
+}
+
+func (l *linter) lintCopy(c *Copy) {
+	if c.Option("from") != "" || len(c.Sources) != 1 || l.opt.ContextDir == "" {
+		return
+	}
+	if src := c.Sources[0]; src != "." && src != "./" {
+		return
+	}
+	if !utils.FileExists(filepath.Join(l.opt.ContextDir, DockerIgnoresFile)) {
+		l.report(RuleCopyAll, c, "COPY of the whole context without a %s", DockerIgnoresFile)
+	}
+}
+
+func (l *linter) lintRun(run *Run) {
+	cmd := run.Command + heredocsString(run.Heredocs)
+	if run.Args != nil {
+		cmd = strings.Join(run.Args, " ")
+	}
+
+	if !strings.Contains(cmd, "apt-get install") && !strings.Contains(cmd, "apt install") {
+		return
+	}
+	// Cache mounts are not part of the image
+	for _, opt := range run.Options {
+		if strings.HasPrefix(opt, "--mount=") && strings.Contains(opt, "/var/lib/apt") {
+			return
+		}
+	}
+	if !strings.Contains(cmd, "/var/lib/apt/lists") {
+		l.report(RuleAptCache, run, "apt package lists are not removed")
+	}
+}
+
+// imageTag returns the tag of the image reference if any
+func imageTag(image string) string {
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
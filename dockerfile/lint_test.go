@@ -0,0 +1,142 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package dockerfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testLintDockerfile = `ARG BASE=node
+FROM ${BASE} AS build
+ARG NPM_TOKEN
+RUN apt-get update && apt-get install -y git
+COPY . .
+
+FROM build AS test
+RUN npm test
+
+FROM alpine:latest
+ENV DB_PASSWORD=secret APP_PORT=80
+ADD https://example.com/app.tar.gz /app
+RUN apt-get update && apt-get install -y curl && rm -rf /var/lib/apt/lists/*
+COPY --from=build /src /app
+`
+
+func testLint(t *testing.T, src string, opt LintOptions) []*LintFinding {
+	raw, err := ParseBytes([]byte(src))
+	fatal(t, err)
+	return Lint(ParseRaw(raw), opt)
+}
+
+func lintRules(findings []*LintFinding) []string {
+	out := make([]string, len(findings))
+	for i, f := range findings {
+		out[i] = f.Rule
+	}
+	return out
+}
+
+func Test_Lint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lint")
+	fatal(t, err)
+	defer os.RemoveAll(dir)
+
+	opt := LintOptions{
+		ContextDir: dir,
+		Secret: func(name string) bool {
+			return strings.HasSuffix(name, "_TOKEN") || strings.HasSuffix(name, "_PASSWORD")
+		},
+	}
+	findings := testLint(t, testLintDockerfile, opt)
+	assert.Equal(t, []string{
+		RuleFromUnpinned, RuleSecretEnv, RuleAptCache, RuleCopyAll,
+		RuleFromLatest, RuleSecretEnv, RuleAddURL, RuleUserRoot, RuleHealthCheckMissing,
+	}, lintRules(findings))
+
+	assert.Equal(t, 2, findings[0].Line)
+	assert.Equal(t, "image node is not pinned to a tag or digest", findings[0].Message)
+	assert.Equal(t, SeverityError, findings[0].Severity)
+	assert.Equal(t, "ENV DB_PASSWORD is stored in the image", findings[5].Message)
+	assert.Equal(t, 10, findings[7].Line)
+	assert.True(t, HasLintErrors(findings))
+
+	fatal(t, ioutil.WriteFile(filepath.Join(dir, DockerIgnoresFile), []byte(".git\n"), 0644))
+	opt.HealthChecked = true
+	opt.Secret = nil
+	opt.Severities = map[string]Severity{
+		RuleFromUnpinned: SeverityOff,
+		RuleFromLatest:   SeverityError,
+	}
+	findings = testLint(t, testLintDockerfile, opt)
+	assert.Equal(t, []string{RuleAptCache, RuleFromLatest, RuleAddURL, RuleUserRoot}, lintRules(findings))
+	assert.Equal(t, SeverityError, findings[1].Severity)
+}
+
+func Test_Lint_clean(t *testing.T) {
+	src := `FROM golang:1.11 AS build
+RUN --mount=type=cache,target=/var/lib/apt apt-get install -y git
+
+FROM alpine@sha256:abc
+USER app
+HEALTHCHECK CMD ["wget", "-q", "http://localhost"]
+COPY --from=build /go/bin/app /app
+`
+	findings := testLint(t, src, LintOptions{})
+	assert.Equal(t, 0, len(findings))
+	assert.False(t, HasLintErrors(findings))
+
+	findings = testLint(t, "FROM alpine:3.8\nUSER root\n", LintOptions{HealthChecked: true})
+	assert.Equal(t, []string{RuleUserRoot}, lintRules(findings))
+	assert.Equal(t, "final stage runs as root", findings[0].Message)
+}
+
+func Test_ParseSeverity(t *testing.T) {
+	sev, err := ParseSeverity(" Warning ")
+	fatal(t, err)
+	assert.Equal(t, SeverityWarning, sev)
+
+	_, err = ParseSeverity("fatal")
+	assert.Equal(t, errSeverityInvalid, err)
+}
+
+func Test_WriteLint(t *testing.T) {
+	findings := []*LintFinding{
+		{Rule: RuleFromLatest, Severity: SeverityWarning, Message: "latest", File: "Dockerfile", Line: 1},
+		{Rule: RuleHealthCheckMissing, Severity: SeverityInfo, Message: "no healthcheck"},
+	}
+
+	var buf bytes.Buffer
+	fatal(t, WriteLintJSON(&buf, findings))
+	var out []*LintFinding
+	fatal(t, json.Unmarshal(buf.Bytes(), &out))
+	assert.Equal(t, findings, out)
+
+	buf.Reset()
+	fatal(t, WriteLintJSON(&buf, nil))
+	assert.Equal(t, "[]\n", buf.String())
+
+	buf.Reset()
+	fatal(t, WriteLintSARIF(&buf, findings))
+	var log sarifLog
+	fatal(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, sarifVersion, log.Version)
+	run := log.Runs[0]
+	assert.Equal(t, len(LintRuleDescriptions), len(run.Tool.Driver.Rules))
+	assert.Equal(t, "warning", run.Results[0].Level)
+	assert.Equal(t, "Dockerfile", run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 1, run.Results[0].Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, "note", run.Results[1].Level)
+	assert.Nil(t, run.Results[1].Locations)
+}
@@ -0,0 +1,220 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package dockerfile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const defaultEscape = '\\'
+
+var (
+	// Parser directive i.e. # escape=`
+	directiveRegex = regexp.MustCompile(`^#\s*([a-zA-Z][a-zA-Z0-9]*)\s*=\s*(.+?)\s*$`)
+	// Heredoc marker i.e. <<EOF, <<-EOF or <<"EOF"
+	heredocRegex = regexp.MustCompile(`<<(-?)\s*(["']?)([a-zA-Z_][a-zA-Z0-9_]*)(["']?)`)
+)
+
+// Parser directives recognized at the top of a dockerfile
+var directives = map[string]bool{"syntax": true, "escape": true, "check": true}
+
+// Instructions that may carry heredocs
+var heredocOps = map[string]bool{KeyRun: true, KeyCopy: true, KeyAdd: true}
+
+// node is the source an instruction was parsed from.  It is used to write
+// unmodified instructions back byte for byte
+type node struct {
+	// Blank lines preceding the instruction
+	leading []byte
+	// Instruction source including continuations, heredoc bodies and the
+	// line ending
+	raw []byte
+	// Data of the raw instruction when lexed
+	data string
+	// Arguments as formatted by the parsed instruction
+	parsed string
+	// Line the instruction starts on
+	line int
+}
+
+// lexer splits dockerfile source into raw instructions joining continuation
+// lines and reading heredoc bodies
+type lexer struct {
+	lines  [][]byte
+	pos    int
+	escape byte
+	// true while parser directives may still appear
+	directives bool
+	// blank lines not yet attached to an instruction
+	leading []byte
+}
+
+func newLexer(b []byte) *lexer {
+	lx := &lexer{escape: defaultEscape, directives: true}
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			lx.lines = append(lx.lines, b)
+			break
+		}
+		lx.lines = append(lx.lines, b[:i+1])
+		b = b[i+1:]
+	}
+	return lx
+}
+
+// next returns the next instruction or comment.  It returns io.EOF when the
+// source is exhausted
+func (lx *lexer) next() (*RawInstruction, error) {
+	for lx.pos < len(lx.lines) {
+		lnum := lx.pos + 1
+		line := lx.lines[lx.pos]
+		lx.pos++
+		text := bytes.TrimSpace(line)
+
+		if lx.directives {
+			if !lx.directive(text) {
+				lx.directives = false
+			}
+		}
+
+		if len(text) == 0 {
+			lx.leading = append(lx.leading, line...)
+			continue
+		}
+
+		if text[0] == '#' {
+			return lx.emit(KeyComment, text[1:], line, lnum), nil
+		}
+
+		word, rest := splitWord(text)
+		op := strings.ToUpper(word)
+		if !isKeyword(op) {
+			if _, ok := isCmd([]byte(word)); !ok {
+				return nil, fmt.Errorf("line %d: unknown instruction: %s", lnum, word)
+			}
+		}
+
+		data, raw := lx.continuation(append([]byte{}, rest...), append([]byte{}, line...))
+
+		ri := lx.emit(op, data, raw, lnum)
+		if heredocOps[op] {
+			docs, err := lx.heredocs(ri)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lnum, err)
+			}
+			ri.Heredocs = docs
+		}
+		return ri, nil
+	}
+
+	return nil, io.EOF
+}
+
+// directive sets the escape character if the line is a parser directive
+// returning false otherwise
+func (lx *lexer) directive(text []byte) bool {
+	m := directiveRegex.FindSubmatch(text)
+	if m == nil || !directives[strings.ToLower(string(m[1]))] {
+		return false
+	}
+	if strings.ToLower(string(m[1])) == "escape" && len(m[2]) == 1 {
+		lx.escape = m[2][0]
+	}
+	return true
+}
+
+// continuation joins lines ending with the escape character skipping
+// comments and blank lines within the instruction
+func (lx *lexer) continuation(data, raw []byte) ([]byte, []byte) {
+	for {
+		data = bytes.TrimRight(data, " \t")
+		if len(data) == 0 || data[len(data)-1] != lx.escape || lx.pos >= len(lx.lines) {
+			return data, raw
+		}
+		data = data[:len(data)-1]
+
+		for lx.pos < len(lx.lines) {
+			line := lx.lines[lx.pos]
+			lx.pos++
+			raw = append(raw, line...)
+
+			text := bytes.TrimSpace(line)
+			if len(text) == 0 || text[0] == '#' {
+				continue
+			}
+			data = append(data, bytes.TrimRight(line, " \t\r\n")...)
+			break
+		}
+	}
+}
+
+// heredocs reads the bodies of the heredocs referenced by the instruction.
+// It returns an error if the source ends before a terminator
+func (lx *lexer) heredocs(ri *RawInstruction) ([]*Heredoc, error) {
+	var docs []*Heredoc
+	for _, m := range heredocRegex.FindAllSubmatch(ri.Data, -1) {
+		// Mismatched quotes are not a heredoc
+		if string(m[2]) != string(m[4]) {
+			continue
+		}
+
+		var (
+			doc        = &Heredoc{Name: string(m[3]), Chomp: len(m[1]) > 0}
+			content    []byte
+			terminated bool
+		)
+		for lx.pos < len(lx.lines) {
+			line := lx.lines[lx.pos]
+			lx.pos++
+			ri.src.raw = append(ri.src.raw, line...)
+
+			text := bytes.TrimRight(line, "\r\n")
+			if doc.Chomp {
+				text = bytes.TrimLeft(text, "\t")
+			}
+			if string(text) == doc.Name {
+				terminated = true
+				break
+			}
+			content = append(content, line...)
+		}
+		if !terminated {
+			return nil, fmt.Errorf("unterminated heredoc: %s", doc.Name)
+		}
+		doc.Content = string(content)
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// emit returns the raw instruction attaching the pending blank lines
+func (lx *lexer) emit(op string, data, raw []byte, lnum int) *RawInstruction {
+	ri := &RawInstruction{Op: op, Data: append([]byte{}, data...)}
+	ri.src = &node{
+		leading: lx.leading,
+		raw:     append([]byte{}, raw...),
+		data:    string(data),
+		line:    lnum,
+	}
+	lx.leading = nil
+	return ri
+}
+
+// splitWord returns the first whitespace separated word of b and the
+// remainder with leading whitespace removed
+func splitWord(b []byte) (string, []byte) {
+	i := bytes.IndexAny(b, " \t")
+	if i < 0 {
+		return string(b), nil
+	}
+	return string(b[:i]), bytes.TrimLeft(b[i:], " \t")
+}
@@ -0,0 +1,169 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package dockerfile
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testComplexDockerfile = `# syntax=docker/dockerfile:1.4
+ARG GO_VERSION=1.11
+
+# Build
+FROM --platform=$BUILDPLATFORM golang:${GO_VERSION} AS builder
+WORKDIR /src
+RUN --mount=type=cache,target=/root/.cache \
+    # fetch deps first
+    go mod download && \
+
+    go build -o /app .
+COPY --from=deps --chown=app:app ["go.mod", "go.sum", "./"]
+run <<EOF
+set -e
+echo built
+EOF
+COPY <<-CONF /etc/app.conf
+	key=value
+	CONF
+
+FROM alpine:3.8
+LABEL maintainer="ops@example.com" version=1
+ENV PATH /app/bin:$PATH
+USER app:app
+HEALTHCHECK --interval=30s CMD ["wget", "-q", "http://localhost/health"]
+ONBUILD COPY . /src
+SHELL ["/bin/sh", "-c"]
+STOPSIGNAL SIGTERM
+MAINTAINER someone
+EXPOSE 80 443
+CMD ./app --serve
+ENTRYPOINT ["/app"]
+
+`
+
+func Test_Parse_roundTrip(t *testing.T) {
+	for _, fpath := range []string{testDockerfile, testMultiStageDockerfile} {
+		b, err := ioutil.ReadFile(fpath)
+		fatal(t, err)
+
+		raw, err := ParseBytes(b)
+		fatal(t, err)
+		assert.Equal(t, string(b), ParseRaw(raw).String(), fpath)
+	}
+
+	for _, src := range []string{
+		testComplexDockerfile,
+		strings.Replace(testComplexDockerfile, "\n", "\r\n", -1),
+		strings.TrimSpace(testComplexDockerfile),
+	} {
+		raw, err := ParseBytes([]byte(src))
+		fatal(t, err)
+		assert.Equal(t, src, ParseRaw(raw).String())
+	}
+}
+
+func Test_Parse_complex(t *testing.T) {
+	raw, err := ParseBytes([]byte(testComplexDockerfile))
+	fatal(t, err)
+	assert.Equal(t, 2, len(raw.Stages))
+
+	df := ParseRaw(raw)
+	assert.Equal(t, "builder", df.Stages[0].ID())
+
+	from, i := df.Stages[0].GetOp(KeyFrom)
+	assert.Equal(t, 3, i)
+	assert.Equal(t, &From{Image: "golang:${GO_VERSION}", As: "builder", Options: []string{"--platform=$BUILDPLATFORM"}}, from)
+	assert.Equal(t, 5, df.Line(from))
+
+	run, _ := df.Stages[0].GetOp(KeyRun)
+	assert.Equal(t, []string{"--mount=type=cache,target=/root/.cache"}, run.(*Run).Options)
+	assert.Equal(t, "go mod download &&     go build -o /app .", run.(*Run).Command)
+
+	var heredocs []*Heredoc
+	for _, inst := range df.Stages[0] {
+		switch v := inst.(type) {
+		case *Run:
+			heredocs = append(heredocs, v.Heredocs...)
+		case *Copy:
+			heredocs = append(heredocs, v.Heredocs...)
+			if len(v.Heredocs) == 0 {
+				assert.Equal(t, "deps", v.Option("from"))
+				assert.Equal(t, []string{"go.mod", "go.sum"}, v.Sources)
+			}
+		}
+	}
+	assert.Equal(t, []*Heredoc{
+		{Name: "EOF", Content: "set -e\necho built\n"},
+		{Name: "CONF", Content: "\tkey=value\n", Chomp: true},
+	}, heredocs)
+
+	final := df.Stages[1]
+	label, _ := final.GetOp(KeyLabel)
+	assert.Equal(t, map[string]string{"maintainer": "ops@example.com", "version": "1"}, label.(*Label).Labels)
+	env, _ := final.GetOp(KeyEnv)
+	assert.Equal(t, map[string]string{"PATH": "/app/bin:$PATH"}, env.(*Env).Vars)
+	user, _ := final.GetOp(KeyUser)
+	assert.Equal(t, &User{Name: "app", Group: "app"}, user)
+	hc, _ := final.GetOp(KeyHealthCheck)
+	assert.Equal(t, []string{"wget", "-q", "http://localhost/health"}, hc.(*HealthCheck).Args)
+	ob, _ := final.GetOp(KeyOnBuild)
+	assert.Equal(t, KeyCopy, ob.(*OnBuild).Instruction.Key())
+	cmd, _ := final.GetOp(KeyCmd)
+	assert.Equal(t, "./app --serve", cmd.(*Cmd).Command)
+
+	expose, _ := final.GetOp(KeyExpose)
+	assert.Equal(t, []*ExposedPort{{Port: "80"}, {Port: "443"}}, expose.(*Expose).Ports)
+}
+
+func Test_Parse_escapeDirective(t *testing.T) {
+	src := "# escape=`\nFROM microsoft/nanoserver\nRUN dir c:\\ `\n    && echo done\n"
+	raw, err := ParseBytes([]byte(src))
+	fatal(t, err)
+
+	df := ParseRaw(raw)
+	run, _ := df.Stages[0].GetOp(KeyRun)
+	assert.Equal(t, `dir c:\     && echo done`, run.(*Run).Command)
+	assert.Equal(t, src, df.String())
+}
+
+func Test_Parse_unknownInstruction(t *testing.T) {
+	_, err := ParseBytes([]byte("FROM alpine\nRUN apk add curl\napk add git\n"))
+	assert.EqualError(t, err, "line 3: unknown instruction: apk")
+
+	_, err = ParseBytes([]byte("apk add git\n"))
+	assert.EqualError(t, err, "line 1: unknown instruction: apk")
+}
+
+func Test_Parse_unterminatedHeredoc(t *testing.T) {
+	_, err := ParseBytes([]byte("FROM alpine\nRUN <<EOF\necho one\nEF\nCMD [\"sh\"]\n"))
+	assert.EqualError(t, err, "line 2: unterminated heredoc: EOF")
+}
+
+func Test_Dockerfile_edit(t *testing.T) {
+	raw, err := ParseBytes([]byte(testComplexDockerfile))
+	fatal(t, err)
+	df := ParseRaw(raw)
+
+	from, _ := df.Stages[1].GetOp(KeyFrom)
+	from.(*From).Image = "alpine:3.9"
+	fatal(t, df.AddInstruction(1, &Env{Vars: map[string]string{"A": "${A}"}}))
+	fatal(t, df.AddInstruction(0, &Arg{Name: "A"}))
+	// Global args are not in scope of the stage
+	fatal(t, df.AddInstruction(0, &Arg{Name: "GO_VERSION"}))
+	assert.Equal(t, errStageNotFound, df.AddInstruction(2, &Arg{Name: "A"}))
+
+	out := df.String()
+	assert.Contains(t, out, "AS builder\nARG GO_VERSION\nARG A\nWORKDIR /src\n")
+	assert.Contains(t, out, "\nFROM alpine:3.9\nENV A=\"${A}\"\nLABEL maintainer=")
+	// Untouched instructions keep their source
+	assert.Contains(t, out, "RUN --mount=type=cache,target=/root/.cache \\\n    # fetch deps first\n")
+	assert.Contains(t, out, "COPY <<-CONF /etc/app.conf\n\tkey=value\n\tCONF\n")
+}
@@ -6,6 +6,13 @@ Sniperkit-Bot
 
 package dockerfile
 
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
 func isCmd(cmd []byte) (string, bool) {
 	if len(cmd) == 0 {
 		return "", false
@@ -20,47 +27,172 @@ func isCmd(cmd []byte) (string, bool) {
 	return string(cmd), true
 }
 
+// parseKV parses key=value pairs.  Values may be quoted
 func parseKV(b []byte) map[string]string {
+	out := make(map[string]string)
+	for _, word := range splitWords(string(b)) {
+		kv := strings.SplitN(word, "=", 2)
+		if len(kv) == 2 && kv[0] != "" {
+			out[kv[0]] = kv[1]
+		}
+	}
+	return out
+}
+
+// splitWords splits s on whitespace removing quotes and escapes.  Variables
+// are not expanded
+func splitWords(s string) []string {
 	var (
-		l   int
-		key string
-		q   bool
-		out = make(map[string]string)
+		words   []string
+		word    []rune
+		inWord  bool
+		quote   rune
+		escaped bool
 	)
 
-	for i, c := range b {
-		switch c {
-		case ' ':
-			if q {
-				continue
-			}
-			if len(key) > 0 {
-				out[key] = string(b[l:i])
-				key = ""
-			}
-			l = i + 1
-			// ????
-
-		case '=':
-			key = string(b[l:i])
-			l = i + 1
-
-		case '"':
-			if q {
-				out[key] = string(b[l:i])
-				q = false
-				key = ""
+	for _, r := range s {
+		switch {
+		case escaped:
+			word = append(word, r)
+			escaped = false
+
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+
+		case quote != 0:
+			if r == quote {
+				quote = 0
 			} else {
-				q = true
+				word = append(word, r)
+			}
+
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, string(word))
+				word = nil
+				inWord = false
 			}
-			l = i + 1
 
+		default:
+			word = append(word, r)
+			inWord = true
 		}
 	}
 
-	if len(key) > 0 {
-		out[key] = string(b[l:])
+	if inWord {
+		words = append(words, string(word))
 	}
+	return words
+}
 
-	return out
+// escapeQuoted escapes s for use within double quotes
+func escapeQuoted(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return strings.Replace(s, `"`, `\"`, -1)
+}
+
+// quoteValue double quotes s if it contains whitespace or quotes
+func quoteValue(s string) string {
+	if strings.ContainsAny(s, " \t\n\"'\\") {
+		return `"` + escapeQuoted(s) + `"`
+	}
+	return s
+}
+
+// splitOptions returns the leading --option=value words of s and the
+// remainder
+func splitOptions(s string) ([]string, string) {
+	var opts []string
+	s = strings.TrimSpace(s)
+	for strings.HasPrefix(s, "--") {
+		word, rest := splitWord([]byte(s))
+		opts = append(opts, word)
+		s = string(rest)
+	}
+	return opts, s
+}
+
+// joinOptions prefixes s with the options
+func joinOptions(opts []string, s string) string {
+	if len(opts) == 0 {
+		return s
+	}
+	return strings.Join(opts, " ") + " " + s
+}
+
+// optionValue returns the value of the named option.  Options without a
+// value return true
+func optionValue(opts []string, name string) string {
+	for _, opt := range opts {
+		opt = strings.TrimPrefix(opt, "--")
+		if opt == name {
+			return "true"
+		}
+		if strings.HasPrefix(opt, name+"=") {
+			return opt[len(name)+1:]
+		}
+	}
+	return ""
+}
+
+// parseCommand returns the command in shell form or the args in exec form
+func parseCommand(s string) (string, []string) {
+	if args, ok := parseExecForm(s); ok {
+		return "", args
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// parseExecForm parses a json array of strings.  It returns false if s is
+// not one in which case the shell form applies
+func parseExecForm(s string) ([]string, bool) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "[") {
+		return nil, false
+	}
+
+	var args []string
+	if err := json.Unmarshal([]byte(s), &args); err != nil {
+		return nil, false
+	}
+	if args == nil {
+		args = []string{}
+	}
+	return args, true
+}
+
+// formatExecForm returns args as a json array
+func formatExecForm(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.Encode(arg)
+		quoted[i] = strings.TrimSpace(buf.String())
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// formatCommand returns the exec form if args are set otherwise the command
+func formatCommand(cmd string, args []string) string {
+	if args != nil {
+		return formatExecForm(args)
+	}
+	return cmd
+}
+
+// sortedKeys returns the keys of the map sorted
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
@@ -0,0 +1,511 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"gopkg.in/yaml.v2"
+)
+
+var errComposeNoServices = errors.New("compose file has no services")
+
+// Reasons compose keys are not mapped onto the stack.  Keys not listed are
+// reported as not supported
+var composeUnmapped = map[string]string{
+	"networks":       "components share the stack network",
+	"deploy":         "replicas, resource limits and placement are not supported",
+	"profiles":       "use thrap profiles to vary a stack per environment",
+	"secrets":        "use the component secrets template",
+	"configs":        "add configuration files to the component build",
+	"entrypoint":     "set the entrypoint in the dockerfile",
+	"restart":        "restarts are managed by the orchestrator",
+	"container_name": "containers are named <component>.<stack>",
+	"links":          "components are reachable by their container ip vars",
+	"extends":        "merge the extended service before importing",
+}
+
+// Image name prefixes used to guess component types.  Everything else is an
+// api
+var (
+	composeDatastoreImages = []string{
+		"postgres", "mysql", "mariadb", "mongo", "redis", "memcached",
+		"cassandra", "elasticsearch", "couchdb", "neo4j", "influxdb",
+		"rabbitmq", "zookeeper", "kafka", "minio", "etcd", "consul",
+	}
+	composeWebImages = []string{
+		"nginx", "httpd", "haproxy", "traefik", "caddy", "envoy",
+	}
+)
+
+var (
+	composeURLRe    = regexp.MustCompile(`(https?)://[^/\s:'"]+(?::(\d+))?(/[^\s'"]*)?`)
+	composeInterpRe = regexp.MustCompile(`\$\{?[A-Za-z_][A-Za-z0-9_]*`)
+)
+
+// ComposeIssue is a compose key that could not be mapped onto the stack
+type ComposeIssue struct {
+	// Service the key belongs to.  Empty for top-level keys
+	Service string
+	// Key path i.e. deploy or healthcheck.retries
+	Key    string
+	Reason string
+}
+
+func (ci *ComposeIssue) String() string {
+	if ci.Service == "" {
+		return ci.Key + ": " + ci.Reason
+	}
+	return ci.Service + ": " + ci.Key + ": " + ci.Reason
+}
+
+type composeImporter struct {
+	stack  *thrapb.Stack
+	issues []*ComposeIssue
+}
+
+func (ci *composeImporter) issue(svc, key, reason string) {
+	ci.issues = append(ci.issues, &ComposeIssue{Service: svc, Key: key, Reason: reason})
+}
+
+func (ci *composeImporter) unmapped(svc, key string) {
+	reason, ok := composeUnmapped[key]
+	if !ok {
+		reason = "not supported"
+	}
+	ci.issue(svc, key, reason)
+}
+
+// ImportCompose returns a stack with the name from a docker compose file.
+// Each service becomes a component of a guessed type.  It also returns the
+// keys that could not be mapped onto the stack
+func ImportCompose(in []byte, name string) (*thrapb.Stack, []*ComposeIssue, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(in, &doc); err != nil {
+		return nil, nil, err
+	}
+
+	services, ok := doc["services"].(map[interface{}]interface{})
+	if !ok || len(services) == 0 {
+		return nil, nil, errComposeNoServices
+	}
+
+	ci := &composeImporter{
+		stack: &thrapb.Stack{
+			ID:         name,
+			Name:       name,
+			Components: make(map[string]*thrapb.Component, len(services)),
+		},
+	}
+
+	for _, key := range sortedAnyKeys(doc) {
+		switch {
+		case key == "version", key == "services":
+		case key == "volumes":
+			// Named volumes are mapped by the services using them
+			for _, vol := range sortedAnyKeys(composeMap(doc[key])) {
+				if len(composeMap(composeMap(doc[key])[vol])) > 0 {
+					ci.issue("", "volumes."+vol, "volume drivers and options are not supported")
+				}
+			}
+		case strings.HasPrefix(key, "x-"):
+			ci.issue("", key, "extension fields are not supported")
+		default:
+			ci.unmapped("", key)
+		}
+	}
+
+	ids := sortedAnyKeys(services)
+	for _, id := range ids {
+		ci.stack.Components[id] = ci.component(id, composeMap(services[id]))
+	}
+	ci.setHead(ids)
+
+	return ci.stack, ci.issues, nil
+}
+
+// component maps the compose service onto a component
+func (ci *composeImporter) component(id string, svc map[string]interface{}) *thrapb.Component {
+	comp := &thrapb.Component{ID: id, Name: id}
+
+	if image, ok := svc["image"]; ok {
+		comp.Name, comp.Version = splitImage(composeString(image))
+		if _, err := version.NewVersion(comp.Version); err != nil && svc["build"] == nil {
+			ci.issue(id, "image", fmt.Sprintf("tag %q is not a version, set one before deploying", comp.Version))
+		}
+	}
+	if build, ok := svc["build"]; ok {
+		comp.Build = ci.build(id, build)
+	}
+	comp.Type = guessCompType(comp)
+
+	// Ports first as health checks reference them by label
+	var ports []int32
+	ports = append(ports, ci.ports(id, "ports", svc["ports"])...)
+	ports = append(ports, ci.ports(id, "expose", svc["expose"])...)
+	comp.Ports = thrapb.PortLabels(ports)
+
+	for _, key := range sortedAnyKeys(svc) {
+		val := svc[key]
+		switch key {
+		case "image", "build", "ports", "expose":
+		case "environment":
+			ci.environment(id, comp, val)
+		case "env_file":
+			files := composeStrings(val)
+			if len(files) == 0 {
+				break
+			}
+			ensureEnv(comp).File = files[0]
+			if len(files) > 1 {
+				ci.issue(id, key, "only the first env file is used: "+files[0])
+			}
+		case "volumes":
+			comp.Volumes = ci.volumes(id, val)
+		case "healthcheck":
+			ci.healthCheck(id, comp, composeMap(val))
+		case "depends_on":
+			ci.dependsOn(id, comp, val)
+		case "command":
+			args := composeStrings(val)
+			if _, ok := val.(string); ok {
+				args = strings.Fields(args[0])
+			}
+			if len(args) > 0 {
+				comp.Cmd, comp.Args = args[0], args[1:]
+			}
+		default:
+			ci.unmapped(id, key)
+		}
+	}
+
+	return comp
+}
+
+func (ci *composeImporter) build(id string, val interface{}) *thrapb.Build {
+	b := &thrapb.Build{Context: consts.DefaultBuildContext, Dockerfile: "Dockerfile"}
+	if s, ok := val.(string); ok {
+		b.Context = s
+		return b
+	}
+
+	m := composeMap(val)
+	for _, key := range sortedAnyKeys(m) {
+		switch key {
+		case "context":
+			b.Context = composeString(m[key])
+		case "dockerfile":
+			b.Dockerfile = composeString(m[key])
+		default:
+			ci.unmapped(id, "build."+key)
+		}
+	}
+	return b
+}
+
+// ports returns the container ports of the compose port specs i.e. 8080,
+// 8081:80, 127.0.0.1:8081:80/udp or the long syntax.  Host port mappings are
+// reported as components are reached by their container address
+func (ci *composeImporter) ports(id, key string, val interface{}) []int32 {
+	list, _ := val.([]interface{})
+	out := make([]int32, 0, len(list))
+	for _, v := range list {
+		var target, published string
+		if m, ok := v.(map[interface{}]interface{}); ok {
+			target, published = composeString(m["target"]), composeString(m["published"])
+		} else {
+			parts := strings.Split(composeString(v), ":")
+			target = parts[len(parts)-1]
+			if len(parts) > 1 {
+				published = parts[len(parts)-2]
+			}
+		}
+
+		if i := strings.Index(target, "/"); i > 0 {
+			if target[i+1:] != "tcp" {
+				ci.issue(id, key, "protocol dropped: "+target)
+			}
+			target = target[:i]
+		}
+		p, err := strconv.ParseInt(target, 10, 32)
+		if err != nil {
+			ci.issue(id, key, "port ranges are not supported: "+target)
+			continue
+		}
+		if published != "" && published != target {
+			ci.issue(id, key, fmt.Sprintf("host port %s dropped, %s is used", published, target))
+		}
+		out = append(out, int32(p))
+	}
+	return out
+}
+
+func (ci *composeImporter) environment(id string, comp *thrapb.Component, val interface{}) {
+	var (
+		vars = make(map[string]string)
+		// vars without a value are taken from the host by compose
+		unset []string
+	)
+	if m, ok := val.(map[interface{}]interface{}); ok {
+		for k, v := range m {
+			if v == nil {
+				unset = append(unset, composeString(k))
+			} else {
+				vars[composeString(k)] = composeString(v)
+			}
+		}
+	} else {
+		for _, kv := range composeStrings(val) {
+			if i := strings.Index(kv, "="); i > 0 {
+				vars[kv[:i]] = kv[i+1:]
+			} else {
+				unset = append(unset, kv)
+			}
+		}
+	}
+
+	sort.Strings(unset)
+	for _, k := range unset {
+		ci.issue(id, "environment."+k, "values from the host environment are not supported")
+	}
+
+	env := ensureEnv(comp)
+	for _, k := range sortedKeys(vars) {
+		v := vars[k]
+		if composeInterpRe.MatchString(v) {
+			ci.issue(id, "environment."+k, "compose interpolation left as is: "+v)
+		}
+		env.Vars[k] = v
+	}
+}
+
+// volumes maps the short i.e. ./data:/data:ro and long volume syntax
+func (ci *composeImporter) volumes(id string, val interface{}) []*thrapb.Volume {
+	list, _ := val.([]interface{})
+	out := make([]*thrapb.Volume, 0, len(list))
+	for _, v := range list {
+		vol := &thrapb.Volume{}
+		if m, ok := v.(map[interface{}]interface{}); ok {
+			vol.Source, vol.Target = composeString(m["source"]), composeString(m["target"])
+			if typ := composeString(m["type"]); typ == "tmpfs" {
+				ci.issue(id, "volumes", "tmpfs is not supported: "+vol.Target)
+				continue
+			}
+			if ro, _ := m["read_only"].(bool); ro {
+				ci.issue(id, "volumes", "read only mode dropped: "+vol.Target)
+			}
+		} else {
+			parts := strings.Split(composeString(v), ":")
+			switch len(parts) {
+			case 1:
+				vol.Target = parts[0]
+			case 3:
+				ci.issue(id, "volumes", "mode "+parts[2]+" dropped: "+parts[1])
+				fallthrough
+			default:
+				vol.Source, vol.Target = parts[0], parts[1]
+			}
+		}
+		out = append(out, vol)
+	}
+	return out
+}
+
+// healthCheck maps http checks i.e. curl -f http://localhost:8080/health.
+// Other commands can not be expressed as a component health check
+func (ci *composeImporter) healthCheck(id string, comp *thrapb.Component, hc map[string]interface{}) {
+	if disable, _ := hc["disable"].(bool); disable {
+		return
+	}
+
+	check := &thrapb.HealthCheck{Method: "GET"}
+	for _, key := range sortedAnyKeys(hc) {
+		switch key {
+		case "test":
+			test := strings.Join(composeStrings(hc[key]), " ")
+			m := composeURLRe.FindStringSubmatch(test)
+			if m == nil {
+				ci.issue(id, "healthcheck.test", "only http checks are supported: "+test)
+				return
+			}
+			check.Protocol, check.Path = m[1], m[3]
+			if check.Path == "" {
+				check.Path = "/"
+			}
+			port := m[2]
+			if port == "" {
+				port = "80"
+				if check.Protocol == "https" {
+					port = "443"
+				}
+			}
+			for label, p := range comp.Ports {
+				if strconv.Itoa(int(p)) == port {
+					check.PortLabel = label
+				}
+			}
+			if check.PortLabel == "" {
+				ci.issue(id, "healthcheck.test", "port "+port+" is not exposed")
+				return
+			}
+
+		case "interval", "timeout":
+			d, err := time.ParseDuration(composeString(hc[key]))
+			if err != nil {
+				ci.issue(id, "healthcheck."+key, err.Error())
+				continue
+			}
+			if key == "interval" {
+				check.Interval = int64(d)
+			} else {
+				check.Timeout = int64(d)
+			}
+
+		default:
+			ci.unmapped(id, "healthcheck."+key)
+		}
+	}
+
+	if check.Protocol != "" {
+		comp.HealthChecks = append(comp.HealthChecks, check)
+	}
+}
+
+// dependsOn adds the container ip var of each dependency the same way
+// stack init wires components
+func (ci *composeImporter) dependsOn(id string, comp *thrapb.Component, val interface{}) {
+	var deps []string
+	if m, ok := val.(map[interface{}]interface{}); ok {
+		deps = sortedAnyKeys(m)
+		for _, dep := range deps {
+			if len(composeMap(m[dep])) > 0 {
+				ci.issue(id, "depends_on."+dep, "conditions are not supported")
+			}
+		}
+	} else {
+		deps = composeStrings(val)
+	}
+
+	env := ensureEnv(comp)
+	for _, dep := range deps {
+		key := strings.ToUpper(dep) + "_CONTAINER_IP"
+		if _, ok := env.Vars[key]; !ok {
+			env.Vars[key] = "${" + consts.CompVarPrefixKey + "." + dep + ".container.ip}"
+		}
+	}
+}
+
+// setHead sets the first web component as the head otherwise the first
+// buildable one
+func (ci *composeImporter) setHead(ids []string) {
+	for _, typ := range []thrapb.CompType{thrapb.CompTypeWeb, thrapb.CompTypeAPI} {
+		for _, id := range ids {
+			comp := ci.stack.Components[id]
+			if comp.Type == typ && (typ == thrapb.CompTypeWeb || comp.IsBuildable()) {
+				comp.Head = true
+				return
+			}
+		}
+	}
+}
+
+// guessCompType guesses the type from the image name.  Components built from
+// source are apis
+func guessCompType(comp *thrapb.Component) thrapb.CompType {
+	if comp.IsBuildable() {
+		return thrapb.CompTypeAPI
+	}
+
+	base := comp.Name[strings.LastIndex(comp.Name, "/")+1:]
+	for _, name := range composeDatastoreImages {
+		if strings.HasPrefix(base, name) {
+			return thrapb.CompTypeDatastore
+		}
+	}
+	for _, name := range composeWebImages {
+		if strings.HasPrefix(base, name) {
+			return thrapb.CompTypeWeb
+		}
+	}
+	return thrapb.CompTypeAPI
+}
+
+// splitImage splits an image into its name and tag.  The tag defaults to
+// latest
+func splitImage(image string) (string, string) {
+	if i := strings.Index(image, "@"); i > 0 {
+		return image[:i], image[i+1:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, "latest"
+}
+
+func ensureEnv(comp *thrapb.Component) *thrapb.Envionment {
+	if comp.Env == nil {
+		comp.Env = &thrapb.Envionment{}
+	}
+	if comp.Env.Vars == nil {
+		comp.Env.Vars = make(map[string]string)
+	}
+	return comp.Env
+}
+
+func composeString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// composeStrings returns a string or list of strings as a list
+func composeStrings(v interface{}) []string {
+	list, ok := v.([]interface{})
+	if !ok {
+		return []string{composeString(v)}
+	}
+	out := make([]string, len(list))
+	for i, s := range list {
+		out[i] = composeString(s)
+	}
+	return out
+}
+
+func composeMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[interface{}]interface{})
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[composeString(k)] = v
+	}
+	return out
+}
+
+func sortedAnyKeys(m interface{}) []string {
+	var keys []string
+	switch mm := m.(type) {
+	case map[string]interface{}:
+		for k := range mm {
+			keys = append(keys, k)
+		}
+	case map[interface{}]interface{}:
+		for k := range mm {
+			keys = append(keys, composeString(k))
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
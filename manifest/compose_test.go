@@ -0,0 +1,155 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package manifest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/stretchr/testify/assert"
+)
+
+var testCompose = `version: "3.7"
+services:
+  app:
+    build:
+      context: ./app
+      dockerfile: app.Dockerfile
+      args:
+        GO_VERSION: "1.11"
+    image: example/app:1.2.0
+    ports:
+      - "8081:8080"
+      - 9090/udp
+    environment:
+      - LOG_LEVEL=debug
+      - DB_PASSWORD
+      - DB_URL=postgres://${DB_USER}@db/app
+    env_file: .env
+    depends_on:
+      - db
+    healthcheck:
+      test: ["CMD", "curl", "-f", "http://localhost:8080/health"]
+      interval: 30s
+      timeout: 5s
+      retries: 3
+    command: ./app --serve
+    networks: [backend]
+    deploy:
+      resources:
+        limits:
+          memory: 512M
+    profiles: [dev]
+  db:
+    image: postgres:10.4
+    environment:
+      POSTGRES_DB: app
+    volumes:
+      - data:/var/lib/postgresql/data
+      - ./init.sql:/docker-entrypoint-initdb.d/init.sql:ro
+    healthcheck:
+      test: pg_isready
+  www:
+    image: nginx
+    ports: ["80"]
+    depends_on:
+      app:
+        condition: service_healthy
+volumes:
+  data: {}
+networks:
+  backend: {}
+`
+
+func Test_ImportCompose(t *testing.T) {
+	stack, issues, err := ImportCompose([]byte(testCompose), "legacy")
+	assert.Nil(t, err)
+	assert.Equal(t, "legacy", stack.ID)
+	assert.Equal(t, 3, len(stack.Components))
+
+	app := stack.Components["app"]
+	assert.Equal(t, thrapb.CompTypeAPI, app.Type)
+	assert.Equal(t, "example/app", app.Name)
+	assert.Equal(t, "1.2.0", app.Version)
+	assert.Equal(t, &thrapb.Build{Context: "./app", Dockerfile: "app.Dockerfile"}, app.Build)
+	assert.Equal(t, map[string]int32{"port8080": 8080, "port9090": 9090}, app.Ports)
+	assert.Equal(t, ".env", app.Env.File)
+	assert.Equal(t, "debug", app.Env.Vars["LOG_LEVEL"])
+	assert.Equal(t, "${comp.db.container.ip}", app.Env.Vars["DB_CONTAINER_IP"])
+	_, ok := app.Env.Vars["DB_PASSWORD"]
+	assert.False(t, ok)
+	assert.Equal(t, "./app", app.Cmd)
+	assert.Equal(t, []string{"--serve"}, app.Args)
+	assert.Equal(t, []*thrapb.HealthCheck{{
+		Protocol:  "http",
+		Path:      "/health",
+		Method:    "GET",
+		PortLabel: "port8080",
+		Interval:  int64(30 * time.Second),
+		Timeout:   int64(5 * time.Second),
+	}}, app.HealthChecks)
+	assert.False(t, app.Head)
+
+	db := stack.Components["db"]
+	assert.Equal(t, thrapb.CompTypeDatastore, db.Type)
+	assert.Equal(t, "postgres", db.Name)
+	assert.Equal(t, "10.4", db.Version)
+	assert.Equal(t, "app", db.Env.Vars["POSTGRES_DB"])
+	assert.Equal(t, []*thrapb.Volume{
+		{Source: "data", Target: "/var/lib/postgresql/data"},
+		{Source: "./init.sql", Target: "/docker-entrypoint-initdb.d/init.sql"},
+	}, db.Volumes)
+	assert.Equal(t, 0, len(db.HealthChecks))
+
+	www := stack.Components["www"]
+	assert.Equal(t, thrapb.CompTypeWeb, www.Type)
+	assert.Equal(t, "latest", www.Version)
+	assert.Equal(t, map[string]int32{"default": 80}, www.Ports)
+	assert.True(t, www.Head)
+
+	reported := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		reported[issue.Service+" "+issue.Key] = true
+	}
+	for _, key := range []string{
+		" networks",
+		"app build.args",
+		"app ports",
+		"app environment.DB_PASSWORD",
+		"app environment.DB_URL",
+		"app healthcheck.retries",
+		"app networks",
+		"app deploy",
+		"app profiles",
+		"db volumes",
+		"db healthcheck.test",
+		"www image",
+		"www depends_on.app",
+	} {
+		assert.True(t, reported[key], key)
+	}
+	assert.Equal(t, 14, len(issues))
+}
+
+func Test_ImportCompose_noServices(t *testing.T) {
+	_, _, err := ImportCompose([]byte("version: '3'\n"), "x")
+	assert.Equal(t, errComposeNoServices, err)
+}
+
+func Test_ImportCompose_emptyEnvFile(t *testing.T) {
+	src := `services:
+  app:
+    image: app:1.0
+    env_file: []
+`
+	stack, _, err := ImportCompose([]byte(src), "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, stack.Components["app"].Env)
+}
@@ -0,0 +1,327 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"gopkg.in/yaml.v2"
+)
+
+const defaultYAMLIndent = 2
+
+var (
+	errComponentExists   = errors.New("component already exists")
+	errComponentNotFound = errors.New("component not found")
+	errFlowStyle         = errors.New("flow style not supported, use block style")
+)
+
+// yamlDoc is a yaml document edited line by line so comments and formatting
+// of the untouched parts are preserved
+type yamlDoc struct {
+	lines []string
+}
+
+func newYAMLDoc(in []byte) *yamlDoc {
+	s := strings.TrimSuffix(string(in), "\n")
+	if s == "" {
+		return &yamlDoc{}
+	}
+	return &yamlDoc{lines: strings.Split(s, "\n")}
+}
+
+func (doc *yamlDoc) Bytes() []byte {
+	return []byte(strings.Join(doc.lines, "\n") + "\n")
+}
+
+// findKey returns the line of the key at the indent between lines from and
+// to or -1 if not found.  Keys may be quoted
+func (doc *yamlDoc) findKey(from, to, indent int, key string) int {
+	for i := from; i < to; i++ {
+		line := doc.lines[i]
+		if isYAMLNoop(line) || yamlIndent(line) != indent {
+			continue
+		}
+		if k, _, ok := yamlKey(line); ok && k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkBlock returns an error if the value of the key at line i is not a
+// block i.e. a flow style map.  Only blocks can be edited line by line
+func (doc *yamlDoc) checkBlock(i int) error {
+	key, value, _ := yamlKey(doc.lines[i])
+	if j := strings.Index(value, " #"); j >= 0 {
+		value = value[:j]
+	}
+	if strings.TrimSpace(value) != "" {
+		return fmt.Errorf("%s: %v", key, errFlowStyle)
+	}
+	return nil
+}
+
+// isFlow returns true if the document is a flow style map
+func (doc *yamlDoc) isFlow() bool {
+	for _, line := range doc.lines {
+		if !isYAMLNoop(line) {
+			return strings.HasPrefix(strings.TrimSpace(line), "{")
+		}
+	}
+	return false
+}
+
+// blockEnd returns the line after the last line belonging to the block of
+// the key at line i.  Trailing blank and comment lines not indented past the
+// key are considered part of what follows
+func (doc *yamlDoc) blockEnd(i int) int {
+	indent := yamlIndent(doc.lines[i])
+
+	end := i + 1
+	for j := i + 1; j < len(doc.lines); j++ {
+		line := doc.lines[j]
+		if isYAMLNoop(line) {
+			continue
+		}
+		if yamlIndent(line) <= indent {
+			break
+		}
+		end = j + 1
+	}
+
+	for end < len(doc.lines) && strings.TrimSpace(doc.lines[end]) != "" &&
+		strings.HasPrefix(strings.TrimSpace(doc.lines[end]), "#") && yamlIndent(doc.lines[end]) > indent {
+		end++
+	}
+
+	return end
+}
+
+// childIndent returns the indent of the children of the key at line i
+// defaulting to one level past the key
+func (doc *yamlDoc) childIndent(i, end int) int {
+	for j := i + 1; j < end; j++ {
+		if !isYAMLNoop(doc.lines[j]) {
+			return yamlIndent(doc.lines[j])
+		}
+	}
+	return yamlIndent(doc.lines[i]) + defaultYAMLIndent
+}
+
+func (doc *yamlDoc) insert(at int, lines ...string) {
+	out := make([]string, 0, len(doc.lines)+len(lines))
+	out = append(out, doc.lines[:at]...)
+	out = append(out, lines...)
+	doc.lines = append(out, doc.lines[at:]...)
+}
+
+// AddComponentYAML adds the component to the components of the yaml manifest
+// leaving everything else as is, including comments
+func AddComponentYAML(in []byte, id string, comp *thrapb.Component) ([]byte, error) {
+	doc := newYAMLDoc(in)
+	if doc.isFlow() {
+		return nil, errFlowStyle
+	}
+
+	b, err := yaml.Marshal(map[string]*thrapb.Component{id: comp})
+	if err != nil {
+		return nil, err
+	}
+
+	ci := doc.findKey(0, len(doc.lines), 0, "components")
+	if ci < 0 {
+		doc.lines = append(doc.lines, "", "components:")
+		doc.lines = append(doc.lines, indentYAML(b, defaultYAMLIndent, defaultYAMLIndent)...)
+		return doc.Bytes(), nil
+	}
+	if err = doc.checkBlock(ci); err != nil {
+		return nil, err
+	}
+
+	end := doc.blockEnd(ci)
+	indent := doc.childIndent(ci, end)
+	if doc.findKey(ci+1, end, indent, id) >= 0 {
+		return nil, errComponentExists
+	}
+
+	lines := indentYAML(b, indent, indent)
+	if end > ci+1 {
+		// Separate from the previous component
+		lines = append([]string{""}, lines...)
+	}
+	doc.insert(end, lines...)
+
+	return doc.Bytes(), nil
+}
+
+// AddComponentEnvVarsYAML adds the env vars to the component in the yaml
+// manifest.  Vars that already exist are not changed.  The blocks leading to
+// the vars must be in block style
+func AddComponentEnvVarsYAML(in []byte, id string, vars map[string]string) ([]byte, error) {
+	if len(vars) == 0 {
+		return in, nil
+	}
+
+	doc := newYAMLDoc(in)
+	if doc.isFlow() {
+		return nil, errFlowStyle
+	}
+
+	ci := doc.findKey(0, len(doc.lines), 0, "components")
+	if ci < 0 {
+		return nil, errComponentNotFound
+	}
+	if err := doc.checkBlock(ci); err != nil {
+		return nil, err
+	}
+	cend := doc.blockEnd(ci)
+
+	// Component block
+	i := doc.findKey(ci+1, cend, doc.childIndent(ci, cend), id)
+	if i < 0 {
+		return nil, fmt.Errorf("%s: %v", id, errComponentNotFound)
+	}
+	if err := doc.checkBlock(i); err != nil {
+		return nil, err
+	}
+	end := doc.blockEnd(i)
+	indent := doc.childIndent(i, end)
+	step := indent - yamlIndent(doc.lines[i])
+
+	// Env block
+	ei := doc.findKey(i+1, end, indent, "env")
+	if ei < 0 {
+		lines := []string{strings.Repeat(" ", indent) + "env:"}
+		lines = append(lines, varsYAML(vars, indent+step, step)...)
+		doc.insert(end, lines...)
+		return doc.Bytes(), nil
+	}
+	if err := doc.checkBlock(ei); err != nil {
+		return nil, err
+	}
+	eend := doc.blockEnd(ei)
+	eindent := doc.childIndent(ei, eend)
+
+	// Vars block
+	vi := doc.findKey(ei+1, eend, eindent, "vars")
+	if vi < 0 {
+		doc.insert(eend, varsYAML(vars, eindent, step)...)
+		return doc.Bytes(), nil
+	}
+	if err := doc.checkBlock(vi); err != nil {
+		return nil, err
+	}
+	vend := doc.blockEnd(vi)
+	vindent := doc.childIndent(vi, vend)
+
+	var lines []string
+	for _, k := range sortedKeys(vars) {
+		if doc.findKey(vi+1, vend, vindent, k) >= 0 {
+			continue
+		}
+		b, err := yaml.Marshal(map[string]string{k: vars[k]})
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, indentYAML(b, vindent, step)...)
+	}
+	doc.insert(vend, lines...)
+
+	return doc.Bytes(), nil
+}
+
+// varsYAML returns the lines of a vars block indented by n spaces
+func varsYAML(vars map[string]string, n, step int) []string {
+	b, _ := yaml.Marshal(map[string]map[string]string{"vars": vars})
+	return indentYAML(b, n, step)
+}
+
+// indentYAML splits the marshalled yaml into lines indented by n spaces
+// converting the marshalled indent levels to step spaces.  The contents of
+// block scalars are only shifted
+func indentYAML(b []byte, n, step int) []string {
+	var (
+		lines = strings.Split(string(bytes.TrimSuffix(b, []byte("\n"))), "\n")
+		// indent of the key of the current block scalar or -1
+		scalar = -1
+		shift  int
+	)
+
+	for i, line := range lines {
+		ind := yamlIndent(line)
+		if scalar >= 0 && (strings.TrimSpace(line) == "" || ind > scalar) {
+			lines[i] = strings.Repeat(" ", n+shift) + line
+			continue
+		}
+		scalar = -1
+
+		shift = ind/defaultYAMLIndent*step - ind
+		lines[i] = strings.Repeat(" ", n+shift) + line
+
+		s := strings.TrimSpace(line)
+		if strings.HasSuffix(s, "|") || strings.HasSuffix(s, "|-") ||
+			strings.HasSuffix(s, ">") || strings.HasSuffix(s, ">-") {
+			scalar = ind
+		}
+	}
+	return lines
+}
+
+// yamlKey returns the unquoted key of the mapping entry on the line and the
+// rest of the line following the colon
+func yamlKey(line string) (string, string, bool) {
+	s := strings.TrimSpace(line)
+	if s == "" {
+		return "", "", false
+	}
+
+	if q := s[0]; q == '"' || q == '\'' {
+		end := strings.IndexByte(s[1:], q) + 1
+		if end < 1 || !strings.HasPrefix(s[end+1:], ":") {
+			return "", "", false
+		}
+		key := s[1:end]
+		if q == '"' {
+			if uq, err := strconv.Unquote(s[:end+1]); err == nil {
+				key = uq
+			}
+		}
+		return key, s[end+2:], true
+	}
+
+	i := strings.Index(s, ":")
+	if i < 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(s[:i]), s[i+1:], true
+}
+
+func yamlIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// isYAMLNoop returns true for blank and comment lines
+func isYAMLNoop(line string) bool {
+	s := strings.TrimSpace(line)
+	return s == "" || strings.HasPrefix(s, "#")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
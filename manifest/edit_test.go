@@ -0,0 +1,116 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+var testEditYAML = `# My stack
+name: foo
+
+# Components of the stack
+components:
+    api:
+        # Built from source
+        name: api
+        type: api
+        build:
+            dockerfile: api.dockerfile
+
+    www:
+        name: nginx
+        version: 1.15-alpine
+        type: web
+        env:
+            vars:
+                # Keep me
+                API_CONTAINER_IP: ${comp.api.container.ip}
+
+# Trailing comment
+`
+
+func Test_AddComponentYAML(t *testing.T) {
+	comp := &thrapb.Component{Name: "postgres", Version: "10", Type: thrapb.CompTypeDatastore}
+	out, err := AddComponentYAML([]byte(testEditYAML), "db", comp)
+	assert.Nil(t, err)
+
+	s := string(out)
+	assert.True(t, strings.HasPrefix(s, "# My stack\n"))
+	assert.Contains(t, s, "        # Built from source\n")
+	assert.Contains(t, s, "    db:\n        name: postgres\n")
+	assert.True(t, strings.HasSuffix(s, "\n\n# Trailing comment\n"))
+
+	var stack thrapb.Stack
+	assert.Nil(t, yaml.Unmarshal(out, &stack))
+	assert.Equal(t, 3, len(stack.Components))
+	assert.Equal(t, "10", stack.Components["db"].Version)
+
+	_, err = AddComponentYAML(out, "db", comp)
+	assert.Equal(t, errComponentExists, err)
+
+	// No components
+	out, err = AddComponentYAML([]byte("name: foo\n"), "db", comp)
+	assert.Nil(t, err)
+	stack = thrapb.Stack{}
+	assert.Nil(t, yaml.Unmarshal(out, &stack))
+	assert.NotNil(t, stack.Components["db"])
+}
+
+func Test_AddComponentEnvVarsYAML(t *testing.T) {
+	// No env block
+	out, err := AddComponentEnvVarsYAML([]byte(testEditYAML), "api", map[string]string{
+		"DB_CONTAINER_IP": "${comp.db.container.ip}",
+	})
+	assert.Nil(t, err)
+
+	var stack thrapb.Stack
+	assert.Nil(t, yaml.Unmarshal(out, &stack))
+	assert.Equal(t, "${comp.db.container.ip}", stack.Components["api"].Env.Vars["DB_CONTAINER_IP"])
+
+	// Existing vars block
+	out, err = AddComponentEnvVarsYAML(out, "www", map[string]string{
+		"API_CONTAINER_IP":  "changed",
+		"API2_CONTAINER_IP": "${comp.api2.container.ip}",
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, string(out), "                # Keep me\n")
+
+	stack = thrapb.Stack{}
+	assert.Nil(t, yaml.Unmarshal(out, &stack))
+	vars := stack.Components["www"].Env.Vars
+	assert.Equal(t, "${comp.api.container.ip}", vars["API_CONTAINER_IP"])
+	assert.Equal(t, "${comp.api2.container.ip}", vars["API2_CONTAINER_IP"])
+
+	_, err = AddComponentEnvVarsYAML(out, "nope", map[string]string{"A": "b"})
+	assert.NotNil(t, err)
+
+	// Quoted keys
+	quoted := "components:\n  \"api\":\n    env:\n      'vars':\n        \"A\": b\n"
+	out, err = AddComponentEnvVarsYAML([]byte(quoted), "api", map[string]string{"A": "changed", "C": "d"})
+	assert.Nil(t, err)
+	stack = thrapb.Stack{}
+	assert.Nil(t, yaml.Unmarshal(out, &stack))
+	assert.Equal(t, map[string]string{"A": "b", "C": "d"}, stack.Components["api"].Env.Vars)
+
+	// Flow style is rejected rather than corrupted
+	for _, in := range []string{
+		"components:\n  api:\n    env:\n      vars: {A: b}\n",
+		"components:\n  api:\n    env: {vars: {A: b}}\n",
+		"components:\n  api: {name: api}\n",
+		"components: {api: {name: api}}\n",
+		"{components: {api: {name: api}}}\n",
+	} {
+		_, err = AddComponentEnvVarsYAML([]byte(in), "api", map[string]string{"C": "d"})
+		assert.Contains(t, err.Error(), errFlowStyle.Error(), in)
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package manifest
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/vars"
+	"gopkg.in/yaml.v2"
+)
+
+// Export formats
+const (
+	ExportCompose  = "compose"
+	ExportK8s      = "k8s"
+	ExportHelm     = "helm"
+	ExportNomadHCL = "nomad-hcl"
+)
+
+var errExportFormat = errors.New("export format not supported")
+
+// ExportFormats are the supported export formats
+var ExportFormats = []string{ExportCompose, ExportK8s, ExportHelm, ExportNomadHCL}
+
+// Env var name parts considered secret.  Their values are exported as
+// references to a secret store rather than values
+var secretEnvVarParts = []string{
+	"PASSWORD", "PASSWD", "SECRET", "TOKEN", "API_KEY", "ACCESS_KEY", "PRIVATE_KEY", "CREDENTIAL",
+}
+
+// Scope variables holding credentials.  Env vars referencing them are secret
+var secretScopeVars = []string{"secrets.", vars.RegistryCreds, vars.VcsCreds}
+
+// SecretEnvVars are the env vars of each component by id exported as
+// references in addition to those named like secrets
+type SecretEnvVars map[string]map[string]bool
+
+// Add marks the env var of the component as secret
+func (sev SecretEnvVars) Add(compID, name string) {
+	if sev[compID] == nil {
+		sev[compID] = make(map[string]bool)
+	}
+	sev[compID][name] = true
+}
+
+// Exporter renders an evaluated stack to the files of another tool
+type Exporter interface {
+	// Host returns the address other components reach the component at.  It
+	// is used to resolve the container ip variables of the stack
+	Host(stackID string, comp *thrapb.Component) string
+	// Export writes the stack to dir returning the paths of the files
+	// written.  Env vars named like secrets, those in secrets and secret
+	// files are written as references
+	Export(stack *thrapb.Stack, dir string, secrets SecretEnvVars) ([]string, error)
+}
+
+// NewExporter returns an Exporter for the format
+func NewExporter(format string) (Exporter, error) {
+	switch format {
+	case ExportCompose:
+		return &composeExporter{}, nil
+	case ExportK8s:
+		return &k8sExporter{}, nil
+	case ExportHelm:
+		return &k8sExporter{helm: true}, nil
+	case ExportNomadHCL:
+		return &nomadExporter{}, nil
+	}
+	return nil, errExportFormat
+}
+
+// IsSecretEnvVar returns true if the env var name looks like it holds a
+// secret i.e. DB_PASSWORD
+func IsSecretEnvVar(name string) bool {
+	uname := strings.ToUpper(name)
+	for _, part := range secretEnvVarParts {
+		if strings.Contains(uname, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSecretEnvValue returns true if the env var value holds a secret.  This is
+// a url with a password i.e. postgres://app:pass@db/app or a reference to a
+// credentials scope variable i.e. ${registry.creds}
+func IsSecretEnvValue(value string) bool {
+	for _, name := range secretScopeVars {
+		if strings.Contains(value, "${"+name) {
+			return true
+		}
+	}
+
+	for _, field := range strings.Fields(value) {
+		u, err := url.Parse(field)
+		if err != nil || u.User == nil {
+			continue
+		}
+		if _, ok := u.User.Password(); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// splitEnvVars splits the component env vars into plain and secret ones.
+// Both are sorted by name
+func splitEnvVars(comp *thrapb.Component, secrets SecretEnvVars) (plain []string, secret []string) {
+	if !comp.HasEnvVars() {
+		return nil, nil
+	}
+	for _, k := range sortedKeys(comp.Env.Vars) {
+		if IsSecretEnvVar(k) || secrets[comp.ID][k] {
+			secret = append(secret, k)
+		} else {
+			plain = append(plain, k)
+		}
+	}
+	return plain, secret
+}
+
+// exportName returns a dns label for the component in the stack i.e.
+// mystack-api
+func exportName(stackID, compID string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, stackID+"-"+compID)
+	return strings.Trim(name, "-")
+}
+
+// exportImage returns the image of the component
+func exportImage(comp *thrapb.Component) string {
+	if comp.Version == "" {
+		return comp.Name
+	}
+	return comp.Name + ":" + comp.Version
+}
+
+// sortedCompIDs returns the component ids of the stack sorted
+func sortedCompIDs(stack *thrapb.Stack) []string {
+	ids := make([]string, 0, len(stack.Components))
+	for id := range stack.Components {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// sortedPortLabels returns the port labels of the component sorted
+func sortedPortLabels(comp *thrapb.Component) []string {
+	labels := make([]string, 0, len(comp.Ports))
+	for k := range comp.Ports {
+		labels = append(labels, k)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// writeExportFile writes b to the relative path in dir creating parent
+// directories
+func writeExportFile(dir, rel string, b []byte) (string, error) {
+	fpath := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+		return "", err
+	}
+	return fpath, ioutil.WriteFile(fpath, b, 0644)
+}
+
+// marshalYAMLDocs marshals each object as a document of a yaml stream
+func marshalYAMLDocs(objs ...interface{}) ([]byte, error) {
+	var out []byte
+	for i, obj := range objs {
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			out = append(out, []byte("---\n")...)
+		}
+		out = append(out, b...)
+	}
+	return out, nil
+}
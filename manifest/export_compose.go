@@ -0,0 +1,150 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package manifest
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"gopkg.in/yaml.v2"
+)
+
+const composeExportFile = "docker-compose.yml"
+
+type composeFile struct {
+	Version  string                     `yaml:"version"`
+	Services map[string]*composeService `yaml:"services"`
+	Secrets  map[string]*composeSecret  `yaml:"secrets,omitempty"`
+}
+
+type composeService struct {
+	Image       string              `yaml:"image"`
+	Command     []string            `yaml:"command,omitempty"`
+	Ports       []string            `yaml:"ports,omitempty"`
+	Expose      []string            `yaml:"expose,omitempty"`
+	EnvFile     []string            `yaml:"env_file,omitempty"`
+	Environment yaml.MapSlice       `yaml:"environment,omitempty"`
+	Volumes     []string            `yaml:"volumes,omitempty"`
+	Secrets     []*composeSecretRef `yaml:"secrets,omitempty"`
+	Healthcheck *composeHealthcheck `yaml:"healthcheck,omitempty"`
+}
+
+type composeSecret struct {
+	File string `yaml:"file"`
+}
+
+type composeSecretRef struct {
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+}
+
+type composeHealthcheck struct {
+	Test     []string `yaml:"test"`
+	Interval string   `yaml:"interval,omitempty"`
+	Timeout  string   `yaml:"timeout,omitempty"`
+}
+
+// composeExporter exports a docker compose file.  Components are reached by
+// their service name.  Secret env vars reference the environment compose is
+// run with and secret files reference files in the secrets directory
+type composeExporter struct{}
+
+func (exp *composeExporter) Host(stackID string, comp *thrapb.Component) string {
+	return comp.ID
+}
+
+func (exp *composeExporter) Export(stack *thrapb.Stack, dir string, secrets SecretEnvVars) ([]string, error) {
+	cf := &composeFile{
+		Version:  "3.7",
+		Services: make(map[string]*composeService, len(stack.Components)),
+	}
+
+	for _, id := range sortedCompIDs(stack) {
+		comp := stack.Components[id]
+		svc := &composeService{Image: exportImage(comp)}
+
+		if comp.Cmd != "" {
+			svc.Command = append([]string{comp.Cmd}, comp.Args...)
+		}
+
+		for _, label := range sortedPortLabels(comp) {
+			p := strconv.Itoa(int(comp.Ports[label]))
+			// Only the head is reachable from the host
+			if comp.Head {
+				svc.Ports = append(svc.Ports, p+":"+p)
+			} else {
+				svc.Expose = append(svc.Expose, p)
+			}
+		}
+
+		if comp.Env != nil && comp.Env.File != "" {
+			svc.EnvFile = []string{comp.Env.File}
+		}
+		plain, secret := splitEnvVars(comp, secrets)
+		for _, k := range plain {
+			svc.Environment = append(svc.Environment, yaml.MapItem{Key: k, Value: comp.Env.Vars[k]})
+		}
+		for _, k := range secret {
+			svc.Environment = append(svc.Environment, yaml.MapItem{Key: k, Value: "${" + k + "}"})
+		}
+
+		for _, vol := range comp.Volumes {
+			if vol.Source == "" {
+				svc.Volumes = append(svc.Volumes, vol.Target)
+			} else {
+				svc.Volumes = append(svc.Volumes, vol.Source+":"+vol.Target)
+			}
+		}
+
+		if comp.HasSecrets() {
+			name := exportName(stack.ID, id)
+			svc.Secrets = []*composeSecretRef{{Source: name, Target: comp.Secrets.Destination}}
+			if cf.Secrets == nil {
+				cf.Secrets = make(map[string]*composeSecret)
+			}
+			cf.Secrets[name] = &composeSecret{File: "./secrets/" + name + path.Ext(comp.Secrets.Destination)}
+		}
+
+		svc.Healthcheck = composeExportHealthcheck(comp)
+		cf.Services[id] = svc
+	}
+
+	b, err := yaml.Marshal(cf)
+	if err != nil {
+		return nil, err
+	}
+	fpath, err := writeExportFile(dir, composeExportFile, b)
+	if err != nil {
+		return nil, err
+	}
+	return []string{fpath}, nil
+}
+
+// composeExportHealthcheck returns the first http health check of the
+// component as a curl command
+func composeExportHealthcheck(comp *thrapb.Component) *composeHealthcheck {
+	for _, hc := range comp.HealthChecks {
+		port, ok := comp.Ports[hc.PortLabel]
+		if !ok || (hc.Protocol != "http" && hc.Protocol != "https") {
+			continue
+		}
+
+		url := fmt.Sprintf("%s://localhost:%d%s", hc.Protocol, port, hc.Path)
+		chk := &composeHealthcheck{Test: []string{"CMD", "curl", "-f", url}}
+		if hc.Interval > 0 {
+			chk.Interval = time.Duration(hc.Interval).String()
+		}
+		if hc.Timeout > 0 {
+			chk.Timeout = time.Duration(hc.Timeout).String()
+		}
+		return chk
+	}
+	return nil
+}
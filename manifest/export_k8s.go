@@ -0,0 +1,378 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package manifest
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultHelmChartVersion = "0.1.0"
+	defaultK8sVolumeSize    = "1Gi"
+
+	// Replaced with helm template expressions once marshalled
+	helmImagePlaceholder    = "__THRAP_IMAGE__"
+	helmReplicasPlaceholder = "__THRAP_REPLICAS__"
+)
+
+type k8sMeta struct {
+	Name   string            `yaml:"name"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+type k8sObject struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   k8sMeta     `yaml:"metadata"`
+	Spec       interface{} `yaml:"spec"`
+}
+
+type k8sDeploymentSpec struct {
+	Replicas interface{} `yaml:"replicas"`
+	Selector struct {
+		MatchLabels map[string]string `yaml:"matchLabels"`
+	} `yaml:"selector"`
+	Template struct {
+		Metadata k8sMeta    `yaml:"metadata"`
+		Spec     k8sPodSpec `yaml:"spec"`
+	} `yaml:"template"`
+}
+
+type k8sPodSpec struct {
+	Containers []*k8sContainer `yaml:"containers"`
+	Volumes    []*k8sVolume    `yaml:"volumes,omitempty"`
+}
+
+type k8sContainer struct {
+	Name           string              `yaml:"name"`
+	Image          string              `yaml:"image"`
+	Command        []string            `yaml:"command,omitempty"`
+	Args           []string            `yaml:"args,omitempty"`
+	Ports          []*k8sContainerPort `yaml:"ports,omitempty"`
+	Env            []*k8sEnvVar        `yaml:"env,omitempty"`
+	EnvFrom        []*k8sEnvFrom       `yaml:"envFrom,omitempty"`
+	VolumeMounts   []*k8sVolumeMount   `yaml:"volumeMounts,omitempty"`
+	ReadinessProbe *k8sProbe           `yaml:"readinessProbe,omitempty"`
+}
+
+type k8sContainerPort struct {
+	Name          string `yaml:"name"`
+	ContainerPort int32  `yaml:"containerPort"`
+}
+
+type k8sEnvVar struct {
+	Name      string        `yaml:"name"`
+	Value     string        `yaml:"value,omitempty"`
+	ValueFrom *k8sEnvSource `yaml:"valueFrom,omitempty"`
+}
+
+type k8sEnvSource struct {
+	SecretKeyRef *k8sKeyRef `yaml:"secretKeyRef"`
+}
+
+type k8sKeyRef struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+}
+
+type k8sEnvFrom struct {
+	SecretRef *k8sSecretRef `yaml:"secretRef"`
+}
+
+type k8sSecretRef struct {
+	Name     string `yaml:"name"`
+	Optional bool   `yaml:"optional,omitempty"`
+}
+
+type k8sVolumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	SubPath   string `yaml:"subPath,omitempty"`
+	ReadOnly  bool   `yaml:"readOnly,omitempty"`
+}
+
+type k8sVolume struct {
+	Name                  string        `yaml:"name"`
+	PersistentVolumeClaim *k8sClaimRef  `yaml:"persistentVolumeClaim,omitempty"`
+	Secret                *k8sSecretVol `yaml:"secret,omitempty"`
+}
+
+type k8sClaimRef struct {
+	ClaimName string `yaml:"claimName"`
+}
+
+type k8sSecretVol struct {
+	SecretName string `yaml:"secretName"`
+}
+
+type k8sProbe struct {
+	HTTPGet        *k8sHTTPGet   `yaml:"httpGet,omitempty"`
+	TCPSocket      *k8sTCPSocket `yaml:"tcpSocket,omitempty"`
+	PeriodSeconds  int64         `yaml:"periodSeconds,omitempty"`
+	TimeoutSeconds int64         `yaml:"timeoutSeconds,omitempty"`
+}
+
+type k8sHTTPGet struct {
+	Path   string `yaml:"path"`
+	Port   int32  `yaml:"port"`
+	Scheme string `yaml:"scheme"`
+}
+
+type k8sTCPSocket struct {
+	Port int32 `yaml:"port"`
+}
+
+type k8sServiceSpec struct {
+	Selector map[string]string `yaml:"selector"`
+	Ports    []*k8sServicePort `yaml:"ports"`
+}
+
+type k8sServicePort struct {
+	Name       string `yaml:"name"`
+	Port       int32  `yaml:"port"`
+	TargetPort int32  `yaml:"targetPort"`
+}
+
+type k8sPVCSpec struct {
+	AccessModes []string `yaml:"accessModes"`
+	Resources   struct {
+		Requests map[string]string `yaml:"requests"`
+	} `yaml:"resources"`
+}
+
+type helmChart struct {
+	APIVersion  string `yaml:"apiVersion"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Version     string `yaml:"version"`
+	AppVersion  string `yaml:"appVersion,omitempty"`
+}
+
+type helmCompValues struct {
+	Image    string `yaml:"image"`
+	Replicas int    `yaml:"replicas"`
+}
+
+// k8sExporter exports a deployment, service and volume claims per component
+// or a helm chart of them.  Components are reached by their service name.
+// Secret env vars reference keys of a secret named after the component,
+// env files a secret suffixed -env and secret files a secret suffixed -files
+type k8sExporter struct {
+	helm bool
+}
+
+func (exp *k8sExporter) Host(stackID string, comp *thrapb.Component) string {
+	return exportName(stackID, comp.ID)
+}
+
+func (exp *k8sExporter) Export(stack *thrapb.Stack, dir string, secrets SecretEnvVars) ([]string, error) {
+	var (
+		out []string
+		// Chart name and directory
+		chart  = exportName(stack.ID, "")
+		values = make(map[string]*helmCompValues, len(stack.Components))
+	)
+
+	for _, id := range sortedCompIDs(stack) {
+		comp := stack.Components[id]
+
+		var (
+			fname = id + ".yaml"
+			objs  []interface{}
+		)
+		if exp.helm {
+			fname = path.Join(chart, "templates", fname)
+			values[id] = &helmCompValues{Image: exportImage(comp), Replicas: 1}
+			objs = k8sComponentObjects(stack, comp, secrets, helmImagePlaceholder, helmReplicasPlaceholder)
+		} else {
+			objs = k8sComponentObjects(stack, comp, secrets, exportImage(comp), 1)
+		}
+
+		b, err := marshalYAMLDocs(objs...)
+		if err != nil {
+			return nil, err
+		}
+		if exp.helm {
+			b = helmTemplate(b, id)
+		}
+
+		fpath, err := writeExportFile(dir, fname, b)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, fpath)
+	}
+
+	if !exp.helm {
+		return out, nil
+	}
+
+	ver := stack.Version
+	if ver == "" {
+		ver = defaultHelmChartVersion
+	}
+	files := map[string]interface{}{
+		"Chart.yaml": &helmChart{
+			APIVersion:  "v1",
+			Name:        chart,
+			Description: stack.Description,
+			Version:     ver,
+			AppVersion:  ver,
+		},
+		"values.yaml": map[string]interface{}{"components": values},
+	}
+	for _, name := range []string{"Chart.yaml", "values.yaml"} {
+		b, err := yaml.Marshal(files[name])
+		if err != nil {
+			return nil, err
+		}
+		fpath, err := writeExportFile(dir, path.Join(chart, name), b)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, fpath)
+	}
+
+	return out, nil
+}
+
+// helmTemplate replaces the image and replica placeholders with values of
+// the component
+func helmTemplate(b []byte, id string) []byte {
+	vals := fmt.Sprintf(`(index .Values.components %q)`, id)
+	s := strings.Replace(string(b), helmImagePlaceholder, `"{{ `+vals+`.image }}"`, -1)
+	s = strings.Replace(s, helmReplicasPlaceholder, `{{ `+vals+`.replicas }}`, -1)
+	return []byte(s)
+}
+
+// k8sComponentObjects returns the deployment, service and volume claims of
+// the component
+func k8sComponentObjects(stack *thrapb.Stack, comp *thrapb.Component, secrets SecretEnvVars, image string, replicas interface{}) []interface{} {
+	name := exportName(stack.ID, comp.ID)
+	labels := map[string]string{"stack": stack.ID, "component": comp.ID}
+
+	cont := &k8sContainer{Name: comp.ID, Image: image}
+	if comp.Cmd != "" {
+		cont.Command = []string{comp.Cmd}
+		cont.Args = comp.Args
+	}
+	for _, label := range sortedPortLabels(comp) {
+		cont.Ports = append(cont.Ports, &k8sContainerPort{Name: label, ContainerPort: comp.Ports[label]})
+	}
+
+	plain, secret := splitEnvVars(comp, secrets)
+	for _, k := range plain {
+		cont.Env = append(cont.Env, &k8sEnvVar{Name: k, Value: comp.Env.Vars[k]})
+	}
+	for _, k := range secret {
+		cont.Env = append(cont.Env, &k8sEnvVar{
+			Name:      k,
+			ValueFrom: &k8sEnvSource{SecretKeyRef: &k8sKeyRef{Name: name, Key: k}},
+		})
+	}
+	if comp.Env != nil && comp.Env.File != "" {
+		cont.EnvFrom = []*k8sEnvFrom{{SecretRef: &k8sSecretRef{Name: name + "-env", Optional: true}}}
+	}
+
+	deploy := &k8sDeploymentSpec{Replicas: replicas}
+	deploy.Selector.MatchLabels = labels
+	deploy.Template.Metadata = k8sMeta{Name: name, Labels: labels}
+
+	pod := &deploy.Template.Spec
+	pod.Containers = []*k8sContainer{cont}
+
+	var claims []interface{}
+	for i, vol := range comp.Volumes {
+		vname := fmt.Sprintf("data-%d", i)
+		claim := fmt.Sprintf("%s-%d", name, i)
+
+		pod.Volumes = append(pod.Volumes, &k8sVolume{
+			Name:                  vname,
+			PersistentVolumeClaim: &k8sClaimRef{ClaimName: claim},
+		})
+		cont.VolumeMounts = append(cont.VolumeMounts, &k8sVolumeMount{Name: vname, MountPath: vol.Target})
+
+		spec := &k8sPVCSpec{AccessModes: []string{"ReadWriteOnce"}}
+		spec.Resources.Requests = map[string]string{"storage": defaultK8sVolumeSize}
+		claims = append(claims, &k8sObject{
+			APIVersion: "v1",
+			Kind:       "PersistentVolumeClaim",
+			Metadata:   k8sMeta{Name: claim, Labels: labels},
+			Spec:       spec,
+		})
+	}
+
+	if comp.HasSecrets() {
+		pod.Volumes = append(pod.Volumes, &k8sVolume{
+			Name:   "secrets",
+			Secret: &k8sSecretVol{SecretName: name + "-files"},
+		})
+		cont.VolumeMounts = append(cont.VolumeMounts, &k8sVolumeMount{
+			Name:      "secrets",
+			MountPath: comp.Secrets.Destination,
+			SubPath:   path.Base(comp.Secrets.Destination),
+			ReadOnly:  true,
+		})
+	}
+
+	cont.ReadinessProbe = k8sProbeFor(comp)
+
+	objs := []interface{}{&k8sObject{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   k8sMeta{Name: name, Labels: labels},
+		Spec:       deploy,
+	}}
+
+	if len(comp.Ports) > 0 {
+		svc := &k8sServiceSpec{Selector: labels}
+		for _, label := range sortedPortLabels(comp) {
+			p := comp.Ports[label]
+			svc.Ports = append(svc.Ports, &k8sServicePort{Name: label, Port: p, TargetPort: p})
+		}
+		objs = append(objs, &k8sObject{
+			APIVersion: "v1",
+			Kind:       "Service",
+			Metadata:   k8sMeta{Name: name, Labels: labels},
+			Spec:       svc,
+		})
+	}
+
+	return append(objs, claims...)
+}
+
+// k8sProbeFor returns a readiness probe from the first health check of the
+// component with a known port
+func k8sProbeFor(comp *thrapb.Component) *k8sProbe {
+	for _, hc := range comp.HealthChecks {
+		port, ok := comp.Ports[hc.PortLabel]
+		if !ok {
+			continue
+		}
+
+		probe := &k8sProbe{
+			PeriodSeconds:  int64(time.Duration(hc.Interval) / time.Second),
+			TimeoutSeconds: int64(time.Duration(hc.Timeout) / time.Second),
+		}
+		switch hc.Protocol {
+		case "http", "https":
+			probe.HTTPGet = &k8sHTTPGet{Path: hc.Path, Port: port, Scheme: strings.ToUpper(hc.Protocol)}
+		case "tcp":
+			probe.TCPSocket = &k8sTCPSocket{Port: port}
+		default:
+			continue
+		}
+		return probe
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+)
+
+// Prefix of the vault kv path secrets of a component are read from
+const nomadVaultPrefix = "secret/data/"
+
+// nomadExporter exports the nomad job of the stack as an hcl jobspec.
+// Components are reached by the same address as a thrap deploy.  Secret env
+// vars and secret files are rendered from vault at secret/data/<stack>/<comp>
+type nomadExporter struct{}
+
+func (exp *nomadExporter) Host(stackID string, comp *thrapb.Component) string {
+	return comp.ID + "." + stackID
+}
+
+func (exp *nomadExporter) Export(stack *thrapb.Stack, dir string, secrets SecretEnvVars) ([]string, error) {
+	job, err := MakeNomadJob(stack)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, grp := range job.TaskGroups {
+		for _, task := range grp.Tasks {
+			comp := nomadTaskComponent(stack, task)
+			if comp != nil {
+				nomadVaultSecrets(stack.ID, comp, secrets, task)
+			}
+		}
+	}
+
+	fpath, err := writeExportFile(dir, stack.ID+".nomad", encodeNomadJob(job))
+	if err != nil {
+		return nil, err
+	}
+	return []string{fpath}, nil
+}
+
+// nomadTaskComponent returns the component the task was made from
+func nomadTaskComponent(stack *thrapb.Stack, task *api.Task) *thrapb.Component {
+	for _, comp := range stack.Components {
+		if NomadTaskName(stack.ID, comp) == task.Name {
+			return comp
+		}
+	}
+	return nil
+}
+
+// nomadVaultSecrets replaces secret env vars of the task with a vault
+// template and adds a template rendering the secrets file of the component
+func nomadVaultSecrets(stackID string, comp *thrapb.Component, secrets SecretEnvVars, task *api.Task) {
+	vpath := nomadVaultPrefix + stackID + "/" + comp.ID
+
+	_, secret := splitEnvVars(comp, secrets)
+	if len(secret) > 0 {
+		var tmpl bytes.Buffer
+		fmt.Fprintf(&tmpl, "{{ with secret %q }}\n", vpath)
+		for _, k := range secret {
+			delete(task.Env, k)
+			fmt.Fprintf(&tmpl, "%s=\"{{ .Data.data.%s }}\"\n", k, k)
+		}
+		tmpl.WriteString("{{ end }}\n")
+
+		task.Templates = append(task.Templates, &api.Template{
+			DestPath:     strPtr("secrets/env"),
+			EmbeddedTmpl: strPtr(tmpl.String()),
+			Envvar:       boolPtr(true),
+		})
+	}
+
+	if comp.HasSecrets() {
+		dest := "secrets/" + path.Base(comp.Secrets.Destination)
+		task.Templates = append(task.Templates, &api.Template{
+			DestPath:     strPtr(dest),
+			EmbeddedTmpl: strPtr(nomadSecretsTemplate(vpath, comp.Secrets.Template)),
+		})
+		task.SetConfig("volumes", []string{dest + ":" + comp.Secrets.Destination})
+	}
+
+	if len(task.Templates) > 0 {
+		task.Vault = &api.Vault{Policies: []string{stackID}}
+	}
+}
+
+// nomadSecretsTemplate returns a template rendering all secrets at the vault
+// path in the given format
+func nomadSecretsTemplate(vpath, format string) string {
+	var body string
+	switch strings.ToLower(format) {
+	case "json":
+		body = "{{ .Data.data | toJSON }}"
+	case "yaml", "yml":
+		body = "{{ .Data.data | toYAML }}"
+	case "toml":
+		body = "{{ .Data.data | toTOML }}"
+	default:
+		body = "{{ range $k, $v := .Data.data }}{{ $k }}={{ $v }}\n{{ end }}"
+	}
+	return fmt.Sprintf("{{ with secret %q }}%s{{ end }}\n", vpath, body)
+}
+
+// encodeNomadJob returns the hcl jobspec of the fields set by MakeNomadJob.
+// Groups, tasks and keys are sorted for stable output
+func encodeNomadJob(job *api.Job) []byte {
+	w := &hclWriter{}
+
+	w.open("job", *job.ID)
+	w.attr("region", *job.Region)
+	w.attr("datacenters", job.Datacenters)
+	w.attr("type", *job.Type)
+	w.attr("priority", *job.Priority)
+
+	grps := append([]*api.TaskGroup{}, job.TaskGroups...)
+	sort.Slice(grps, func(i, j int) bool { return *grps[i].Name < *grps[j].Name })
+	for _, grp := range grps {
+		w.open("group", *grp.Name)
+		w.attr("count", *grp.Count)
+
+		tasks := append([]*api.Task{}, grp.Tasks...)
+		sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+		for _, task := range tasks {
+			encodeNomadTask(w, task)
+		}
+		w.close()
+	}
+
+	w.close()
+	return w.buf.Bytes()
+}
+
+func encodeNomadTask(w *hclWriter, task *api.Task) {
+	w.open("task", task.Name)
+	w.attr("driver", task.Driver)
+
+	w.open("config")
+	for _, k := range sortedAnyKeys(task.Config) {
+		if blocks, ok := task.Config[k].([]map[string]interface{}); ok {
+			for _, blk := range blocks {
+				w.open(k)
+				for _, bk := range sortedAnyKeys(blk) {
+					w.attr(bk, blk[bk])
+				}
+				w.close()
+			}
+			continue
+		}
+		w.attr(k, task.Config[k])
+	}
+	w.close()
+
+	if len(task.Env) > 0 {
+		w.open("env")
+		for _, k := range sortedKeys(task.Env) {
+			w.attr(k, task.Env[k])
+		}
+		w.close()
+	}
+
+	for _, tmpl := range task.Templates {
+		w.open("template")
+		w.attr("destination", *tmpl.DestPath)
+		w.attr("data", *tmpl.EmbeddedTmpl)
+		if tmpl.Envvar != nil && *tmpl.Envvar {
+			w.attr("env", true)
+		}
+		w.close()
+	}
+
+	if task.Vault != nil {
+		w.open("vault")
+		w.attr("policies", task.Vault.Policies)
+		w.close()
+	}
+
+	svcs := append([]*api.Service{}, task.Services...)
+	sort.Slice(svcs, func(i, j int) bool { return svcs[i].PortLabel < svcs[j].PortLabel })
+	for _, svc := range svcs {
+		w.open("service")
+		w.attr("name", svc.Name)
+		w.attr("tags", svc.Tags)
+		w.attr("port", svc.PortLabel)
+		for _, chk := range svc.Checks {
+			w.open("check")
+			w.attr("type", chk.Type)
+			if chk.Path != "" {
+				w.attr("path", chk.Path)
+			}
+			if chk.Method != "" {
+				w.attr("method", chk.Method)
+			}
+			w.attr("interval", chk.Interval)
+			w.attr("timeout", chk.Timeout)
+			w.close()
+		}
+		w.close()
+	}
+
+	if res := task.Resources; res != nil {
+		w.open("resources")
+		w.attr("cpu", *res.CPU)
+		w.attr("memory", *res.MemoryMB)
+		for _, nw := range res.Networks {
+			w.open("network")
+			w.attr("mbits", *nw.MBits)
+			for _, p := range nw.DynamicPorts {
+				w.open("port", p.Label)
+				w.close()
+			}
+			w.close()
+		}
+		w.close()
+	}
+
+	w.close()
+}
+
+// hclWriter writes indented hcl blocks and attributes
+type hclWriter struct {
+	buf   bytes.Buffer
+	depth int
+}
+
+func (w *hclWriter) indent() {
+	w.buf.WriteString(strings.Repeat("  ", w.depth))
+}
+
+// open starts a block with the optional label
+func (w *hclWriter) open(name string, label ...string) {
+	w.indent()
+	w.buf.WriteString(name)
+	for _, l := range label {
+		fmt.Fprintf(&w.buf, " %q", l)
+	}
+	w.buf.WriteString(" {\n")
+	w.depth++
+}
+
+func (w *hclWriter) close() {
+	w.depth--
+	w.indent()
+	w.buf.WriteString("}\n")
+}
+
+func (w *hclWriter) attr(key string, val interface{}) {
+	w.indent()
+	fmt.Fprintf(&w.buf, "%s = %s\n", key, hclValue(val))
+}
+
+// hclValue returns the hcl literal of the value
+func hclValue(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case time.Duration:
+		return fmt.Sprintf("%q", v.String())
+	case []string:
+		items := make([]string, len(v))
+		for i, s := range v {
+			items[i] = fmt.Sprintf("%q", s)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+func strPtr(s string) *string { return &s }
+
+func boolPtr(b bool) *bool { return &b }
@@ -0,0 +1,171 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package manifest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/stretchr/testify/assert"
+)
+
+func testExportStack() *thrapb.Stack {
+	return &thrapb.Stack{
+		ID:      "shop",
+		Version: "1.2.0",
+		Components: map[string]*thrapb.Component{
+			"api": &thrapb.Component{
+				ID:      "api",
+				Name:    "registry.local/shop/api",
+				Version: "0.3.0",
+				Type:    thrapb.CompTypeAPI,
+				Head:    true,
+				Build:   &thrapb.Build{Dockerfile: "api.dockerfile"},
+				Ports:   map[string]int32{"default": 8080},
+				Env: &thrapb.Envionment{Vars: map[string]string{
+					"DB_CONTAINER_IP": "db",
+					"DB_PASSWORD":     "hunter2",
+				}},
+				Secrets: &thrapb.Secrets{Destination: "/etc/shop/secrets.json", Template: "json"},
+				HealthChecks: []*thrapb.HealthCheck{
+					{Protocol: "http", Path: "/health", PortLabel: "default", Interval: 10e9, Timeout: 2e9},
+				},
+			},
+			"db": &thrapb.Component{
+				ID:      "db",
+				Name:    "postgres",
+				Version: "10.4",
+				Type:    thrapb.CompTypeDatastore,
+				Ports:   map[string]int32{"default": 5432},
+				Volumes: []*thrapb.Volume{{Target: "/var/lib/postgresql/data"}},
+			},
+		},
+	}
+}
+
+func Test_IsSecretEnvVar(t *testing.T) {
+	assert.True(t, IsSecretEnvVar("DB_PASSWORD"))
+	assert.True(t, IsSecretEnvVar("github_token"))
+	assert.True(t, IsSecretEnvVar("AWS_ACCESS_KEY_ID"))
+	assert.False(t, IsSecretEnvVar("DB_CONTAINER_IP"))
+}
+
+func Test_IsSecretEnvValue(t *testing.T) {
+	assert.True(t, IsSecretEnvValue("postgres://shop:hunter2@db:5432/shop"))
+	assert.True(t, IsSecretEnvValue("${registry.creds}"))
+	assert.True(t, IsSecretEnvValue("${secrets.db.password}"))
+	assert.False(t, IsSecretEnvValue("postgres://shop@db:5432/shop"))
+	assert.False(t, IsSecretEnvValue("${comp.db.container.ip}"))
+	assert.False(t, IsSecretEnvValue("debug"))
+}
+
+func Test_NewExporter(t *testing.T) {
+	for _, format := range ExportFormats {
+		_, err := NewExporter(format)
+		assert.Nil(t, err, format)
+	}
+	_, err := NewExporter("swarm")
+	assert.Equal(t, errExportFormat, err)
+}
+
+func Test_composeExporter(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "export")
+	defer os.RemoveAll(dir)
+
+	stack := testExportStack()
+	stack.Components["api"].Env.Vars["DATABASE_URL"] = "postgres://shop:s3cret@db/shop"
+	secrets := SecretEnvVars{}
+	secrets.Add("api", "DATABASE_URL")
+
+	exp, _ := NewExporter(ExportCompose)
+	files, err := exp.Export(stack, dir, secrets)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, composeExportFile)}, files)
+
+	b, _ := ioutil.ReadFile(files[0])
+	out := string(b)
+	assert.Contains(t, out, "image: registry.local/shop/api:0.3.0")
+	assert.Contains(t, out, "- 8080:8080")
+	assert.Contains(t, out, `DB_PASSWORD: ${DB_PASSWORD}`)
+	assert.NotContains(t, out, "hunter2")
+	assert.Contains(t, out, `DATABASE_URL: ${DATABASE_URL}`)
+	assert.NotContains(t, out, "s3cret")
+	assert.Contains(t, out, "file: ./secrets/shop-api.json")
+	assert.Contains(t, out, "- http://localhost:8080/health")
+	assert.Contains(t, out, "- \"5432\"")
+}
+
+func Test_k8sExporter(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "export")
+	defer os.RemoveAll(dir)
+
+	exp, _ := NewExporter(ExportK8s)
+	assert.Equal(t, "shop-api", exp.Host("shop", &thrapb.Component{ID: "api"}))
+
+	files, err := exp.Export(testExportStack(), dir, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(files))
+
+	b, _ := ioutil.ReadFile(filepath.Join(dir, "api.yaml"))
+	out := string(b)
+	assert.Contains(t, out, "image: registry.local/shop/api:0.3.0")
+	assert.Contains(t, out, "replicas: 1")
+	assert.Contains(t, out, "secretKeyRef")
+	assert.Contains(t, out, "secretName: shop-api-files")
+	assert.NotContains(t, out, "hunter2")
+	assert.Equal(t, 2, strings.Count(out, "kind: "))
+
+	b, _ = ioutil.ReadFile(filepath.Join(dir, "db.yaml"))
+	assert.Contains(t, string(b), "kind: PersistentVolumeClaim")
+}
+
+func Test_k8sExporter_helm(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "export")
+	defer os.RemoveAll(dir)
+
+	exp, _ := NewExporter(ExportHelm)
+	files, err := exp.Export(testExportStack(), dir, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(files))
+
+	b, _ := ioutil.ReadFile(filepath.Join(dir, "shop", "templates", "api.yaml"))
+	out := string(b)
+	assert.Contains(t, out, `image: "{{ (index .Values.components "api").image }}"`)
+	assert.Contains(t, out, `replicas: {{ (index .Values.components "api").replicas }}`)
+
+	b, _ = ioutil.ReadFile(filepath.Join(dir, "shop", "values.yaml"))
+	assert.Contains(t, string(b), "image: registry.local/shop/api:0.3.0")
+
+	b, _ = ioutil.ReadFile(filepath.Join(dir, "shop", "Chart.yaml"))
+	assert.Contains(t, string(b), "version: 1.2.0")
+}
+
+func Test_nomadExporter(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "export")
+	defer os.RemoveAll(dir)
+
+	exp, _ := NewExporter(ExportNomadHCL)
+	assert.Equal(t, "api.shop", exp.Host("shop", &thrapb.Component{ID: "api"}))
+
+	files, err := exp.Export(testExportStack(), dir, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "shop.nomad")}, files)
+
+	b, _ := ioutil.ReadFile(files[0])
+	out := string(b)
+	assert.Contains(t, out, `job "shop" {`)
+	assert.Contains(t, out, `task "shop.0.api" {`)
+	assert.Contains(t, out, `task "shop.db.db" {`)
+	assert.Contains(t, out, `{{ with secret \"secret/data/shop/api\" }}`)
+	assert.Contains(t, out, `volumes = ["secrets/secrets.json:/etc/shop/secrets.json"]`)
+	assert.Contains(t, out, `policies = ["shop"]`)
+	assert.NotContains(t, out, "hunter2")
+}
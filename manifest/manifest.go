@@ -20,37 +20,106 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
-// LoadManifest loads a hcl or yaml manifest
-func LoadManifest(mfile string) (*thrapb.Stack, error) {
+// FindManifest returns the path of the manifest in dir.  The yaml manifest
+// is preferred over the deprecated hcl one
+func FindManifest(dir string) (string, error) {
+	for _, name := range []string{consts.DefaultManifestFile, "thrap.hcl"} {
+		if mfile := filepath.Join(dir, name); utils.FileExists(mfile) {
+			return mfile, nil
+		}
+	}
+	return "", errors.New("no manifest found")
+}
 
+// LoadManifest loads a hcl or yaml manifest.  The manifest is validated
+// against the schema returning SchemaErrors with the position of each
+// unknown key or invalid value.  Deprecated keys are returned as warnings
+// for the caller to report.  If mfile is empty the manifest in the current
+// directory is loaded.  Component versions are not computed, see
+// SetComponentVersions
+func LoadManifest(mfile string) (*thrapb.Stack, SchemaErrors, error) {
+	var err error
 	if mfile == "" {
-		if utils.FileExists(consts.DefaultManifestFile) {
-			mfile = consts.DefaultManifestFile
-		} else if utils.FileExists("thrap.hcl") {
-			// This is deprecated
-			mfile = "thrap.hcl"
-		} else {
-			return nil, errors.New("no manifest found")
+		if mfile, err = FindManifest(""); err != nil {
+			return nil, nil, err
 		}
 	}
 
 	mpath, err := utils.GetLocalPath(mfile)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	b, err := ioutil.ReadFile(mpath)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	var st *thrapb.Stack
+	// Strictly validate as decoding ignores unknown keys
+	var (
+		st    *thrapb.Stack
+		warns SchemaErrors
+	)
 	if strings.HasSuffix(mfile, ".hcl") {
-		st, err = ParseHCL(mpath)
+		if warns, err = ValidateHCL(mfile, b); err == nil {
+			st, err = ParseHCLBytes(b)
+		}
 	} else {
-		st, err = ParseYAML(mpath)
+		if warns, err = ValidateYAML(mfile, b); err == nil {
+			st, err = ParseYAMLBytes(b)
+		}
 	}
 
 	if err == nil {
 		st.Version = vcs.GetRepoVersion(filepath.Dir(mpath)).String()
 	}
 
-	return st, err
+	return st, warns, err
+}
+
+// SetComponentVersions sets the version of each buildable component without
+// one to the version of the last commit touching its build context or
+// dockerfile in the repo at dir.  Components are left to default to the stack
+// version if it cannot be determined.  This walks the repo history so is only
+// called where component versions are used i.e. build, publish and deploy.
+// It must be called before the stack is validated as validation defaults
+// component versions to the stack version.  It returns the ids of the
+// components versioned from their sources
+func SetComponentVersions(st *thrapb.Stack, dir string) map[string]bool {
+	versioned := make(map[string]bool)
+	sets := make(map[string][]string)
+	for id, comp := range st.Components {
+		if comp.Version != "" || !comp.IsBuildable() {
+			continue
+		}
+
+		ctx := comp.Build.Context
+		if ctx == "" {
+			ctx = consts.DefaultBuildContext
+		}
+		if filepath.IsAbs(ctx) {
+			rel, err := filepath.Rel(dir, ctx)
+			if err != nil {
+				continue
+			}
+			ctx = rel
+		}
+
+		sets[id] = []string{ctx, filepath.Join(ctx, comp.Build.Dockerfile)}
+	}
+	if len(sets) == 0 {
+		return versioned
+	}
+
+	vers, err := vcs.GetPathsVersions(dir, sets)
+	if err != nil {
+		return versioned
+	}
+	for id, ver := range vers {
+		st.Components[id].Version = ver
+		versioned[id] = true
+	}
+	return versioned
 }
 
 // WriteYAMLManifest writes a manifest as yaml to the Writer
@@ -0,0 +1,73 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package manifest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/vcs"
+	"github.com/stretchr/testify/assert"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func Test_SetComponentVersions(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "mv-")
+	defer os.RemoveAll(tmpdir)
+
+	_, repo, err := vcs.SetupLocalGitRepo("test", "me", tmpdir, "foo.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, _ := repo.Worktree()
+	for _, file := range []string{"api/main.go", "nomad.dockerfile"} {
+		os.MkdirAll(filepath.Join(tmpdir, filepath.Dir(file)), 0755)
+		ioutil.WriteFile(filepath.Join(tmpdir, file), []byte(file), 0644)
+		wt.Add(file)
+	}
+	sig := &object.Signature{Name: "thrap", Email: "thrap", When: time.Now()}
+	_, err = wt.Commit("init", &git.CommitOptions{Author: sig})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st := &thrapb.Stack{
+		ID: "test",
+		Components: map[string]*thrapb.Component{
+			"api": &thrapb.Component{
+				Build: &thrapb.Build{Context: "api", Dockerfile: "Dockerfile"},
+			},
+			"nomad": &thrapb.Component{
+				Version: "0.8.4",
+				Build:   &thrapb.Build{Dockerfile: "nomad.dockerfile"},
+			},
+			"redis": &thrapb.Component{Name: "redis", Version: "4"},
+		},
+	}
+
+	versioned := SetComponentVersions(st, tmpdir)
+	assert.Equal(t, map[string]bool{"api": true}, versioned)
+	assert.NotEmpty(t, st.Components["api"].Version)
+	assert.Equal(t, "0.8.4", st.Components["nomad"].Version)
+}
+
+func Test_LoadManifest_warnings(t *testing.T) {
+	// Deprecated keys are returned rather than printed
+	_, warns, err := LoadManifest("../test-fixtures/thrap.yml")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(warns))
+	assert.Contains(t, warns.Error(), `"format": Deprecated`)
+
+	_, warns, err = LoadManifest("../thrap.yml")
+	assert.Nil(t, err)
+	assert.Empty(t, warns)
+}
@@ -73,8 +73,57 @@ func MakeNomadJob(stack *thrapb.Stack) (*api.Job, error) {
 	return job, nil
 }
 
+// NomadTaskName returns the name of the task the component is deployed as
+// within the stack job created by MakeNomadJob
+func NomadTaskName(stackID string, comp *thrapb.Component) string {
+	gid := "0"
+	if comp.Type == thrapb.CompTypeDatastore {
+		gid = "db"
+	}
+	return stackID + "." + gid + "." + comp.ID
+}
+
+// NomadDatastoreGroup returns the name of the task group datastores are
+// deployed in within the stack job created by MakeNomadJob
+func NomadDatastoreGroup(stackID string) string {
+	return stackID + ".db"
+}
+
+// NomadMigrateJobID returns the id of the batch job running the migration
+// of the component
+func NomadMigrateJobID(stackID string, comp *thrapb.Component) string {
+	return stackID + ".migrate." + comp.ID
+}
+
+// MakeNomadMigrateJob returns a batch job running the migration hook of the
+// component once.  The task is neither restarted nor rescheduled on failure
+func MakeNomadMigrateJob(stack *thrapb.Stack, comp *thrapb.Component) *api.Job {
+	id := NomadMigrateJobID(stack.ID, comp)
+	job := api.NewBatchJob(id, id, defaultRegion, defaultPriority)
+	job = job.AddDatacenter(defaultRegion)
+
+	attempts := 0
+	mode := "fail"
+	grp := api.NewTaskGroup(id, defaultGroupCount)
+	grp.RestartPolicy = &api.RestartPolicy{Attempts: &attempts, Mode: &mode}
+	grp.ReschedulePolicy = &api.ReschedulePolicy{Attempts: &attempts}
+
+	task := makeNomadTaskDocker(stack.ID, "migrate", comp)
+	// Nothing to register or health check for a one-shot task
+	task.Services = nil
+
+	if comp.Migrate.Cmd != "" {
+		task.SetConfig("command", comp.Migrate.Cmd)
+	}
+	if len(comp.Migrate.Args) > 0 {
+		task.SetConfig("args", comp.Migrate.Args)
+	}
+
+	return job.AddTaskGroup(grp.AddTask(task))
+}
+
 func makeNomadDatastoreGroup(id string, comp *thrapb.Component) *api.TaskGroup {
-	group := api.NewTaskGroup(id+".db", defaultGroupCount)
+	group := api.NewTaskGroup(NomadDatastoreGroup(id), defaultGroupCount)
 
 	group.Update = api.DefaultUpdateStrategy()
 	//group.Update.Merge(other)
@@ -15,7 +15,7 @@ import (
 )
 
 func Test_MakeNomadJob(t *testing.T) {
-	mf, err := LoadManifest("../thrap.yml")
+	mf, _, err := LoadManifest("../thrap.yml")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -31,7 +31,7 @@ func Test_MakeNomadJob(t *testing.T) {
 }
 
 func Test_MakeNomadJobYAML(t *testing.T) {
-	desc, err := LoadManifest("../test-fixtures/thrap.yml")
+	desc, _, err := LoadManifest("../test-fixtures/thrap.yml")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,215 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package manifest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+)
+
+// Manifest formats a schema can be generated for
+const (
+	SchemaYAML = "yaml"
+	SchemaHCL  = "hcl"
+)
+
+const (
+	schemaDraft = "http://json-schema.org/draft-07/schema#"
+	schemaRef   = "#/definitions/"
+)
+
+// Schema is a JSON Schema.  Only the subset needed to describe manifests is
+// supported
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	// Deprecated keys are accepted with a warning
+	Deprecated bool `json:"deprecated,omitempty"`
+
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	// false or the *Schema of all additional properties
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+	Required             []string    `json:"required,omitempty"`
+
+	Items *Schema  `json:"items,omitempty"`
+	Enum  []string `json:"enum,omitempty"`
+
+	Definitions map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// Allowed values of string types
+var schemaEnums = map[reflect.Type][]string{
+	reflect.TypeOf(thrapb.CompType("")): {
+		string(thrapb.CompTypeUnknown),
+		string(thrapb.CompTypeAPI),
+		string(thrapb.CompTypeWeb),
+		string(thrapb.CompTypeDatastore),
+		string(thrapb.CompTypeBatch),
+		string(thrapb.CompTypePeriodic),
+	},
+}
+
+// Descriptions of the manifest fields from thrap.proto by type and field name
+var schemaDescriptions = map[string]string{
+	"Stack.Name":             "Name of the stack",
+	"Stack.Components":       "Components of the stack by id",
+	"Stack.Dependencies":     "External services the stack depends on by id",
+	"Component.Name":         "Image name",
+	"Component.Version":      "Image version.  Calculated for buildable components if not set",
+	"Component.Type":         "Type of component",
+	"Component.Language":     "Language and optional version i.e. go:1.10.  Required if building",
+	"Component.Build":        "Build info if this is a buildable component",
+	"Component.Secrets":      "Secrets for this component",
+	"Component.Ports":        "Port map so ports can be referenced by variables",
+	"Component.External":     "If a dependency then true is an external dependency. If a component then true exposes it to the internet",
+	"Component.Head":         "True if this is the head of the stack i.e. consumable api or ui",
+	"Component.Env":          "Environment variables",
+	"Component.Config":       "Arbitrary key-value config map",
+	"Component.Volumes":      "List of volumes needed",
+	"Component.Cmd":          "Command to run the component",
+	"Component.Args":         "Args to the command",
+	"Component.HealthChecks": "All health checks",
+	"Component.Seed":         "Data loaded once into a datastore after it starts",
+	"Component.Migrate":      "Migration run to completion before the stack is deployed",
+	"Build.Dockerfile":       "Dockerfile relative to the context",
+	"Build.Context":          "Build context",
+	"Secrets.Destination":    "Destination path",
+	"Secrets.Template":       "Template the secrets are rendered with",
+	"Envionment.File":        "File to read environment variables from",
+	"Envionment.Vars":        "Key-value pairs of variables",
+	"HealthCheck.Protocol":   "udp, tcp, http or https",
+	"Seed.Files":             "Fixture files or scripts, relative to the project, copied into the container",
+	"Seed.Target":            "Container directory the files are copied to",
+	"Seed.Cmd":               "Shell command run from the target directory to load the fixtures",
+	"Seed.Timeout":           "Seconds to wait for the datastore to become healthy before running the command",
+	"Migrate.Cmd":            "Command run in a one-shot container of the component image in place of the image default command",
+	"Migrate.Timeout":        "Seconds to wait for the migration to complete",
+}
+
+// Keys no longer decoded that are still accepted, by type and key, with the
+// reason
+var schemaDeprecated = map[string]map[string]string{
+	"Secrets": {"format": "secrets are rendered with template"},
+}
+
+// GenerateSchema returns the JSON Schema of a manifest in the format from the
+// stack types and their tags.  Unknown keys are not allowed
+func GenerateSchema(format string) (*Schema, error) {
+	var tagName string
+	switch format {
+	case SchemaYAML:
+		tagName = "yaml"
+	case SchemaHCL:
+		tagName = "hcl"
+	default:
+		return nil, fmt.Errorf("schema format not supported: %s", format)
+	}
+
+	gen := &schemaGen{tag: tagName, defs: make(map[string]*Schema)}
+	stack := gen.typeSchema(reflect.TypeOf(thrapb.Stack{}))
+
+	root := &Schema{
+		Schema:      schemaDraft,
+		Title:       "thrap manifest",
+		Definitions: gen.defs,
+	}
+	if format == SchemaHCL {
+		// manifest "<id>" { ... }
+		root.Type = "object"
+		root.Properties = map[string]*Schema{
+			"manifest": {Type: "object", AdditionalProperties: stack},
+		}
+		root.AdditionalProperties = false
+		root.Required = []string{"manifest"}
+	} else {
+		root.Ref = stack.Ref
+	}
+
+	return root, nil
+}
+
+type schemaGen struct {
+	// Struct tag the keys are read from
+	tag  string
+	defs map[string]*Schema
+}
+
+func (gen *schemaGen) typeSchema(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return gen.typeSchema(t.Elem())
+
+	case reflect.Struct:
+		if _, ok := gen.defs[t.Name()]; !ok {
+			// Reserve the name for recursive types
+			gen.defs[t.Name()] = nil
+			gen.defs[t.Name()] = gen.structSchema(t)
+		}
+		return &Schema{Ref: schemaRef + t.Name()}
+
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: gen.typeSchema(t.Elem())}
+
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: gen.typeSchema(t.Elem())}
+
+	case reflect.String:
+		return &Schema{Type: "string", Enum: schemaEnums[t]}
+
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+
+	return &Schema{}
+}
+
+func (gen *schemaGen) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || strings.HasPrefix(field.Name, "XXX_") {
+			continue
+		}
+
+		key := strings.Split(field.Tag.Get(gen.tag), ",")[0]
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			// Decoders default to the lower case field name
+			key = strings.ToLower(field.Name)
+		}
+
+		fs := gen.typeSchema(field.Type)
+		fs.Description = schemaDescriptions[t.Name()+"."+field.Name]
+		s.Properties[key] = fs
+	}
+
+	for key, reason := range schemaDeprecated[t.Name()] {
+		s.Properties[key] = &Schema{Deprecated: true, Description: "Deprecated, " + reason}
+	}
+
+	return s
+}
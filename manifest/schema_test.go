@@ -0,0 +1,168 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package manifest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GenerateSchema(t *testing.T) {
+	s, err := GenerateSchema(SchemaYAML)
+	fatal(t, err)
+	assert.Equal(t, "#/definitions/Stack", s.Ref)
+
+	comp := s.Definitions["Component"]
+	assert.Equal(t, false, comp.AdditionalProperties)
+	assert.Equal(t, "#/definitions/Build", comp.Properties["build"].Ref)
+	assert.Equal(t, "integer", comp.Properties["ports"].AdditionalProperties.(*Schema).Type)
+	assert.Equal(t, "array", comp.Properties["healthchecks"].Type)
+	assert.Contains(t, comp.Properties["type"].Enum, "datastore")
+	// Ids are map keys in yaml
+	assert.Nil(t, comp.Properties["id"])
+
+	b, err := json.Marshal(s)
+	fatal(t, err)
+	assert.Contains(t, string(b), `"additionalProperties":false`)
+
+	s, err = GenerateSchema(SchemaHCL)
+	fatal(t, err)
+	assert.Equal(t, []string{"manifest"}, s.Required)
+	assert.NotNil(t, s.Definitions["Component"].Properties["id"])
+
+	_, err = GenerateSchema("toml")
+	assert.NotNil(t, err)
+}
+
+func Test_ValidateYAML_fixtures(t *testing.T) {
+	b, err := ioutil.ReadFile("../thrap.yml")
+	fatal(t, err)
+	warns, err := ValidateYAML("thrap.yml", b)
+	assert.Nil(t, err)
+	assert.Empty(t, warns)
+
+	// The deprecated secrets format is only warned about
+	b, err = ioutil.ReadFile("../test-fixtures/thrap.yml")
+	fatal(t, err)
+	warns, err = ValidateYAML("thrap.yml", b)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		`thrap.yml:34:9: components.api.secrets: "format": Deprecated, secrets are rendered with template`,
+	}, errorStrings(warns))
+
+	b, err = ioutil.ReadFile("../test-fixtures/thrap.hcl")
+	fatal(t, err)
+	warns, err = ValidateHCL("thrap.hcl", b)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(warns))
+
+	warns, err = ValidateHCL("test.hcl", []byte(testHCLManifest))
+	assert.Nil(t, err)
+	assert.Empty(t, warns)
+}
+
+func Test_ValidateYAML(t *testing.T) {
+	src := `name: test
+components:
+  api:
+    name: api
+    type: apii
+    head: "yes"
+    build:
+      dockerfle: api.dockerfile
+    secrets:
+      destination: secrets.hcl
+      template: |
+        name: not a key
+    args:
+    - run
+    - verbose: true
+    volumes:
+    - source: /data
+      tagret: /var/data
+    ports:
+      http: eighty
+depenencies:
+  consul:
+    name: consul
+`
+	_, err := ValidateYAML("thrap.yml", []byte(src))
+	errs, ok := err.(SchemaErrors)
+	if !ok {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{
+		`thrap.yml:5:5: components.api.type: invalid value "apii", must be one of "", "api", "web", "datastore", "batch", "periodic". Did you mean "api"?`,
+		`thrap.yml:6:5: components.api.head: expected boolean, got string`,
+		`thrap.yml:8:7: components.api.build: unknown key "dockerfle". Did you mean "dockerfile"?`,
+		`thrap.yml:15:5: components.api.args.1: expected string, got object`,
+		`thrap.yml:18:7: components.api.volumes.0: unknown key "tagret". Did you mean "target"?`,
+		`thrap.yml:20:7: components.api.ports.http: expected integer, got string`,
+		`thrap.yml:21:1: unknown key "depenencies". Did you mean "dependencies"?`,
+	}, errorStrings(errs))
+
+	_, err = ValidateYAML("thrap.yml", []byte("name: [test"))
+	assert.Contains(t, err.Error(), "thrap.yml: yaml:")
+}
+
+func Test_ValidateHCL(t *testing.T) {
+	src := `manifest "test" {
+  name = "test"
+  components {
+    api {
+      Name = "api"
+      type = "api"
+      prots { http = 80 }
+      volumes {
+        source = "/data"
+        target = "/var/data"
+      }
+      head = "yes"
+    }
+  }
+}
+stack "test" {}
+`
+	_, err := ValidateHCL("thrap.hcl", []byte(src))
+	errs, ok := err.(SchemaErrors)
+	if !ok {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{
+		`thrap.hcl:7:7: manifest.test.components.api: unknown key "prots". Did you mean "ports"?`,
+		`thrap.hcl:12:14: manifest.test.components.api.head: expected boolean, got string`,
+		`thrap.hcl:16:1: unknown key "stack"`,
+	}, errorStrings(errs))
+
+	_, err = ValidateHCL("thrap.hcl", []byte(`name = "test"`))
+	assert.Contains(t, err.Error(), `missing required key "manifest"`)
+}
+
+func Test_suggest(t *testing.T) {
+	keys := []string{"context", "dockerfile"}
+	assert.Equal(t, "context", suggest("contxt", keys))
+	assert.Equal(t, "dockerfile", suggest("Dockerfile", keys))
+	assert.Equal(t, "", suggest("format", keys))
+}
+
+func errorStrings(errs SchemaErrors) []string {
+	out := make([]string, len(errs))
+	for i, err := range errs {
+		out[i] = err.Error()
+	}
+	return out
+}
+
+func fatal(t *testing.T, err error) {
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,543 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/parser"
+	"github.com/hashicorp/hcl/hcl/token"
+	"gopkg.in/yaml.v2"
+)
+
+// Kinds of manifest values
+const (
+	kindObject  = "object"
+	kindArray   = "array"
+	kindString  = "string"
+	kindInteger = "integer"
+	kindNumber  = "number"
+	kindBoolean = "boolean"
+	kindNull    = "null"
+)
+
+// SchemaError is a manifest value not conforming to the schema
+type SchemaError struct {
+	File   string
+	Line   int
+	Column int
+	// Dot separated path to the value
+	Path    string
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	pos := e.File
+	if e.Line > 0 {
+		pos += fmt.Sprintf(":%d:%d", e.Line, e.Column)
+	}
+	if e.Path == "" {
+		return pos + ": " + e.Message
+	}
+	return pos + ": " + e.Path + ": " + e.Message
+}
+
+// SchemaErrors are all schema errors of a manifest ordered by position
+type SchemaErrors []*SchemaError
+
+func (errs SchemaErrors) Error() string {
+	out := make([]string, len(errs))
+	for i, err := range errs {
+		out[i] = err.Error()
+	}
+	return strings.Join(out, "\n")
+}
+
+// ValidateYAML strictly validates yaml manifest contents against the schema.
+// Deprecated keys are returned as warnings.  The file name is only used in
+// errors
+func ValidateYAML(file string, b []byte) (SchemaErrors, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+
+	schema, _ := GenerateSchema(SchemaYAML)
+	pos := indexYAML(b)
+	return validateSchema(file, schema, yamlNode(doc, "", pos), false)
+}
+
+// ValidateHCL strictly validates hcl manifest contents against the schema.
+// Deprecated keys are returned as warnings.  The file name is only used in
+// errors
+func ValidateHCL(file string, b []byte) (SchemaErrors, error) {
+	f, err := parser.Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+
+	root := &valueNode{kind: kindObject, line: 1, col: 1}
+	if list, ok := f.Node.(*ast.ObjectList); ok {
+		root.fields = hclFields(list)
+	}
+
+	schema, _ := GenerateSchema(SchemaHCL)
+	// hcl matches keys regardless of case
+	return validateSchema(file, schema, root, true)
+}
+
+// valueNode is a decoded manifest value with its position
+type valueNode struct {
+	kind      string
+	line, col int
+	// Scalar value
+	value  string
+	fields []*fieldNode
+	items  []*valueNode
+}
+
+type fieldNode struct {
+	key       string
+	line, col int
+	value     *valueNode
+}
+
+func validateSchema(file string, schema *Schema, root *valueNode, fold bool) (SchemaErrors, error) {
+	v := &schemaValidator{file: file, defs: schema.Definitions, fold: fold}
+	v.validate(schema, root, "")
+	v.warns.sort()
+	if len(v.errs) == 0 {
+		return v.warns, nil
+	}
+
+	v.errs.sort()
+	return v.warns, v.errs
+}
+
+// sort orders the errors by position
+func (errs SchemaErrors) sort() {
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Column < errs[j].Column
+	})
+}
+
+type schemaValidator struct {
+	file string
+	defs map[string]*Schema
+	// Case insensitive keys
+	fold  bool
+	errs  SchemaErrors
+	warns SchemaErrors
+}
+
+func (v *schemaValidator) errorf(line, col int, path, format string, args ...interface{}) {
+	v.errs = append(v.errs, &SchemaError{
+		File:    v.file,
+		Line:    line,
+		Column:  col,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *schemaValidator) validate(s *Schema, n *valueNode, path string) {
+	if s.Ref != "" {
+		s = v.defs[strings.TrimPrefix(s.Ref, schemaRef)]
+	}
+	// Empty values decode to the zero value
+	if s == nil || n.kind == kindNull {
+		return
+	}
+
+	switch s.Type {
+	case kindObject:
+		if n.kind != kindObject {
+			v.errorf(n.line, n.col, path, "expected %s, got %s", s.Type, n.kind)
+			return
+		}
+		v.validateObject(s, n, path)
+
+	case kindArray:
+		switch {
+		case n.kind == kindArray:
+			for i, item := range n.items {
+				v.validate(s.Items, item, joinPath(path, strconv.Itoa(i)))
+			}
+		case n.kind == kindObject && v.isObject(s.Items):
+			// A single hcl block of a list
+			v.validate(s.Items, n, path)
+		default:
+			v.errorf(n.line, n.col, path, "expected %s, got %s", s.Type, n.kind)
+		}
+
+	case kindString:
+		if n.kind == kindObject || n.kind == kindArray {
+			v.errorf(n.line, n.col, path, "expected %s, got %s", s.Type, n.kind)
+			return
+		}
+		if len(s.Enum) > 0 && !containsString(s.Enum, n.value) {
+			msg := fmt.Sprintf("invalid value %q, must be one of %s", n.value, strings.Join(quoteAll(s.Enum), ", "))
+			if sug := suggest(n.value, s.Enum); sug != "" {
+				msg += fmt.Sprintf(". Did you mean %q?", sug)
+			}
+			v.errorf(n.line, n.col, path, "%s", msg)
+		}
+
+	case kindInteger:
+		if n.kind == kindInteger {
+			return
+		}
+		if _, err := strconv.ParseInt(n.value, 10, 64); err != nil || n.kind != kindString {
+			v.errorf(n.line, n.col, path, "expected %s, got %s", s.Type, n.kind)
+		}
+
+	case kindNumber:
+		if n.kind != kindInteger && n.kind != kindNumber {
+			v.errorf(n.line, n.col, path, "expected %s, got %s", s.Type, n.kind)
+		}
+
+	case kindBoolean:
+		if n.kind != kindBoolean {
+			v.errorf(n.line, n.col, path, "expected %s, got %s", s.Type, n.kind)
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(s *Schema, n *valueNode, path string) {
+	keys := make([]string, 0, len(s.Properties))
+	for k := range s.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	seen := make(map[string]bool, len(n.fields))
+	for _, f := range n.fields {
+		fpath := joinPath(path, f.key)
+
+		if key, ok := v.property(keys, f.key); ok {
+			seen[key] = true
+			if ps := s.Properties[key]; ps.Deprecated {
+				v.warns = append(v.warns, &SchemaError{
+					File:    v.file,
+					Line:    f.line,
+					Column:  f.col,
+					Path:    path,
+					Message: fmt.Sprintf("%q: %s", f.key, ps.Description),
+				})
+				continue
+			}
+			v.validate(s.Properties[key], f.value, fpath)
+			continue
+		}
+
+		if as, ok := s.AdditionalProperties.(*Schema); ok {
+			v.validate(as, f.value, fpath)
+			continue
+		}
+
+		msg := fmt.Sprintf("unknown key %q", f.key)
+		if sug := suggest(f.key, keys); sug != "" {
+			msg += fmt.Sprintf(". Did you mean %q?", sug)
+		}
+		v.errorf(f.line, f.col, path, "%s", msg)
+	}
+
+	for _, key := range s.Required {
+		if !seen[key] {
+			v.errorf(n.line, n.col, path, "missing required key %q", key)
+		}
+	}
+}
+
+// property returns the schema key matching key
+func (v *schemaValidator) property(keys []string, key string) (string, bool) {
+	for _, k := range keys {
+		if k == key || (v.fold && strings.EqualFold(k, key)) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+func (v *schemaValidator) isObject(s *Schema) bool {
+	if s != nil && s.Ref != "" {
+		s = v.defs[strings.TrimPrefix(s.Ref, schemaRef)]
+	}
+	return s != nil && s.Type == kindObject
+}
+
+// suggest returns the candidate closest to s if it is close enough to be a
+// likely misspelling
+func suggest(s string, candidates []string) string {
+	var (
+		best  string
+		bestD = len(s)/3 + 2
+	)
+	for _, c := range candidates {
+		if c == "" {
+			continue
+		}
+		if d := levenshtein(strings.ToLower(s), c); d < bestD {
+			best, bestD = c, d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+func minInt(vals ...int) int {
+	m := vals[0]
+	for _, v := range vals[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteAll(list []string) []string {
+	out := make([]string, len(list))
+	for i, s := range list {
+		out[i] = strconv.Quote(s)
+	}
+	return out
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// yamlNode returns the node of a value decoded by yaml with the positions of
+// its keys
+func yamlNode(v interface{}, path string, pos yamlPositions) *valueNode {
+	n := &valueNode{}
+	n.line, n.col = pos.lookup(path)
+
+	switch val := v.(type) {
+	case nil:
+		n.kind = kindNull
+	case map[interface{}]interface{}:
+		n.kind = kindObject
+		for k, fv := range val {
+			key := fmt.Sprint(k)
+			fpath := joinPath(path, key)
+			f := &fieldNode{key: key, value: yamlNode(fv, fpath, pos)}
+			f.line, f.col = pos.lookup(fpath)
+			n.fields = append(n.fields, f)
+		}
+	case []interface{}:
+		n.kind = kindArray
+		for i, item := range val {
+			n.items = append(n.items, yamlNode(item, joinPath(path, strconv.Itoa(i)), pos))
+		}
+	case string:
+		n.kind, n.value = kindString, val
+	case int, int64, uint64:
+		n.kind, n.value = kindInteger, fmt.Sprint(val)
+	case float64:
+		n.kind, n.value = kindNumber, fmt.Sprint(val)
+	case bool:
+		n.kind, n.value = kindBoolean, fmt.Sprint(val)
+	default:
+		n.kind, n.value = kindString, fmt.Sprint(val)
+	}
+
+	return n
+}
+
+type yamlPos struct {
+	line, col int
+}
+
+type yamlPositions map[string]yamlPos
+
+// lookup returns the position of the path or its closest ancestor
+func (pos yamlPositions) lookup(path string) (int, int) {
+	for {
+		if p, ok := pos[path]; ok {
+			return p.line, p.col
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return 0, 0
+		}
+		path = path[:i]
+	}
+}
+
+// Matches a block mapping key at the start of a line
+var yamlKeyRegex = regexp.MustCompile(`^(?:"((?:[^"\\]|\\.)*)"|'([^']*)'|([^\s#"'\[\]{}&*!|>%@,-][^#]*?))\s*:(?:\s|$)`)
+
+// indexYAML indexes the line and column of each key and sequence item
+// of block style yaml by path.  yaml.v2 does not expose positions of decoded
+// values.  Flow style collections are not indexed
+func indexYAML(b []byte) yamlPositions {
+	type level struct {
+		indent int
+		path   string
+	}
+
+	var (
+		out    = make(yamlPositions)
+		stack  = []level{{indent: -1}}
+		counts = make(map[string]int)
+		// Indent of the key of a block scalar being skipped or -1
+		scalar = -1
+	)
+
+	for i, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimRight(line, "\r")
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+
+		if scalar >= 0 {
+			if content == "" || indent > scalar {
+				continue
+			}
+			scalar = -1
+		}
+		if content == "" || content[0] == '#' || strings.HasPrefix(content, "---") {
+			continue
+		}
+
+		// Sequence items
+		for content == "-" || strings.HasPrefix(content, "- ") {
+			for stack[len(stack)-1].indent > indent {
+				stack = stack[:len(stack)-1]
+			}
+			parent := stack[len(stack)-1].path
+			item := joinPath(parent, strconv.Itoa(counts[parent]))
+			counts[parent]++
+			out[item] = yamlPos{i + 1, indent + 1}
+			stack = append(stack, level{indent: indent + 1, path: item})
+
+			rest := strings.TrimLeft(content[1:], " ")
+			indent += len(content) - len(rest)
+			content = rest
+		}
+
+		m := yamlKeyRegex.FindStringSubmatch(content)
+		if m == nil {
+			continue
+		}
+		key := m[1] + m[2] + m[3]
+
+		for stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		path := joinPath(stack[len(stack)-1].path, key)
+		out[path] = yamlPos{i + 1, indent + 1}
+		stack = append(stack, level{indent: indent, path: path})
+
+		if value := strings.TrimSpace(content[len(m[0]):]); strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			scalar = indent
+		}
+	}
+
+	return out
+}
+
+// hclFields returns the fields of the object list.  Items with multiple keys
+// i.e. manifest "id" { } are nested objects
+func hclFields(list *ast.ObjectList) []*fieldNode {
+	out := make([]*fieldNode, 0, len(list.Items))
+	for _, item := range list.Items {
+		val := hclNode(item.Val)
+		for i := len(item.Keys) - 1; i > 0; i-- {
+			val = &valueNode{
+				kind:   kindObject,
+				line:   item.Keys[i].Pos().Line,
+				col:    item.Keys[i].Pos().Column,
+				fields: []*fieldNode{hclField(item.Keys[i], val)},
+			}
+		}
+		out = append(out, hclField(item.Keys[0], val))
+	}
+	return out
+}
+
+func hclField(key *ast.ObjectKey, val *valueNode) *fieldNode {
+	return &fieldNode{
+		key:   fmt.Sprint(key.Token.Value()),
+		line:  key.Pos().Line,
+		col:   key.Pos().Column,
+		value: val,
+	}
+}
+
+func hclNode(node ast.Node) *valueNode {
+	n := &valueNode{line: node.Pos().Line, col: node.Pos().Column}
+
+	switch val := node.(type) {
+	case *ast.ObjectType:
+		n.kind = kindObject
+		n.fields = hclFields(val.List)
+	case *ast.ListType:
+		n.kind = kindArray
+		for _, item := range val.List {
+			n.items = append(n.items, hclNode(item))
+		}
+	case *ast.LiteralType:
+		n.value = fmt.Sprint(val.Token.Value())
+		switch val.Token.Type {
+		case token.NUMBER:
+			n.kind = kindInteger
+		case token.FLOAT:
+			n.kind = kindNumber
+		case token.BOOL:
+			n.kind = kindBoolean
+		default:
+			n.kind = kindString
+		}
+	default:
+		n.kind = kindString
+	}
+
+	return n
+}
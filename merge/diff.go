@@ -0,0 +1,135 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package merge
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// number of unchanged lines around changes in a hunk
+const diffContext = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type diffOp struct {
+	kind opKind
+	line string
+	// line numbers in a and b
+	ai, bi int
+}
+
+// diffOps returns the edits turning a into b
+func diffOps(a, b []string) []diffOp {
+	m := match(a, b)
+	ops := make([]diffOp, 0, len(a)+len(b))
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && m[i] == j:
+			ops = append(ops, diffOp{opEqual, a[i], i, j})
+			i++
+			j++
+		case i < len(a) && m[i] < 0:
+			ops = append(ops, diffOp{opDelete, a[i], i, j})
+			i++
+		default:
+			ops = append(ops, diffOp{opInsert, b[j], i, j})
+			j++
+		}
+	}
+	return ops
+}
+
+// Unified returns the unified diff turning a into b using the names as file
+// headers.  It returns an empty string if both are the same
+func Unified(a, b, nameA, nameB string) string {
+	ops := diffOps(Lines(a), Lines(b))
+
+	var buf bytes.Buffer
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			i++
+			continue
+		}
+
+		// Hunk start including leading context
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		for start < i && ops[start].kind != opEqual {
+			start++
+		}
+
+		// Extend while changes are within twice the context
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			k := end
+			for k < len(ops) && ops[k].kind == opEqual && k-end < 2*diffContext {
+				k++
+			}
+			if k == len(ops) || ops[k].kind == opEqual {
+				break
+			}
+			end = k
+		}
+		stop := end + diffContext
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+
+		if buf.Len() == 0 {
+			fmt.Fprintf(&buf, "--- %s\n+++ %s\n", nameA, nameB)
+		}
+		writeHunk(&buf, ops[start:stop])
+		i = stop
+	}
+
+	return buf.String()
+}
+
+func writeHunk(buf *bytes.Buffer, ops []diffOp) {
+	var la, lb int
+	for _, op := range ops {
+		if op.kind != opInsert {
+			la++
+		}
+		if op.kind != opDelete {
+			lb++
+		}
+	}
+
+	sa, sb := ops[0].ai+1, ops[0].bi+1
+	if la == 0 {
+		sa--
+	}
+	if lb == 0 {
+		sb--
+	}
+	fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", sa, la, sb, lb)
+
+	for _, op := range ops {
+		buf.WriteByte(byte(op.kind))
+		buf.WriteString(op.line)
+		if !strings.HasSuffix(op.line, "\n") {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
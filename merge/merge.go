@@ -0,0 +1,181 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+// Package merge implements line based diffs and three-way merges of text
+package merge
+
+import (
+	"strings"
+)
+
+const (
+	markerOurs   = "<<<<<<<"
+	markerSep    = "======="
+	markerTheirs = ">>>>>>>"
+)
+
+// Lines splits text into lines keeping the line endings so joining the
+// lines returns the original text
+func Lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Result is the result of a three-way merge
+type Result struct {
+	// Merged text with conflict markers around conflicting changes
+	Text string
+	// Number of conflicts
+	Conflicts int
+}
+
+// Merge3 merges the changes made from base to ours and from base to theirs.
+// Changes to the same lines that differ are marked as conflicts using the
+// labels for each side
+func Merge3(base, ours, theirs, oursLabel, theirsLabel string) *Result {
+	var (
+		b = Lines(base)
+		o = Lines(ours)
+		t = Lines(theirs)
+
+		mo = match(b, o)
+		mt = match(b, t)
+
+		out    []string
+		result = &Result{}
+	)
+
+	// Previous sync point
+	var pb, po, pt int
+	for i := 0; i <= len(b); i++ {
+		var io, it int
+		if i == len(b) {
+			// Sentinel at the end of all
+			io, it = len(o), len(t)
+		} else {
+			io, it = mo[i], mt[i]
+			if io < 0 || it < 0 {
+				continue
+			}
+		}
+
+		out = append(out, mergeChunk(b[pb:i], o[po:io], t[pt:it], oursLabel, theirsLabel, result)...)
+		if i < len(b) {
+			out = append(out, b[i])
+		}
+		pb, po, pt = i+1, io+1, it+1
+	}
+
+	result.Text = strings.Join(out, "")
+	return result
+}
+
+// mergeChunk merges the lines between two sync points
+func mergeChunk(b, o, t []string, oursLabel, theirsLabel string, result *Result) []string {
+	switch {
+	case equal(o, b):
+		return t
+	case equal(t, b), equal(o, t):
+		return o
+	}
+
+	result.Conflicts++
+
+	out := make([]string, 0, len(o)+len(t)+3)
+	out = append(out, markerOurs+" "+oursLabel+"\n")
+	out = append(out, terminate(o)...)
+	out = append(out, markerSep+"\n")
+	out = append(out, terminate(t)...)
+	return append(out, markerTheirs+" "+theirsLabel+"\n")
+}
+
+// terminate makes sure the last line ends with a new line so markers start
+// on their own line
+func terminate(lines []string) []string {
+	if n := len(lines); n > 0 && !strings.HasSuffix(lines[n-1], "\n") {
+		cp := make([]string, n)
+		copy(cp, lines)
+		cp[n-1] += "\n"
+		return cp
+	}
+	return lines
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// match returns for each line of a the index of the line of b it matches in
+// a longest common subsequence or -1
+func match(a, b []string) []int {
+	m := make([]int, len(a))
+	for i := range m {
+		m[i] = -1
+	}
+
+	// Common prefix and suffix are matched directly
+	var pre int
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		m[pre] = pre
+		pre++
+	}
+	var suf int
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		m[len(a)-1-suf] = len(b) - 1 - suf
+		suf++
+	}
+
+	ra, rb := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	if len(ra) == 0 || len(rb) == 0 {
+		return m
+	}
+
+	// lcs[i][j] is the lcs length of ra[i:] and rb[j:]
+	lcs := make([][]int, len(ra)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(rb)+1)
+	}
+	for i := len(ra) - 1; i >= 0; i-- {
+		for j := len(rb) - 1; j >= 0; j-- {
+			if ra[i] == rb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < len(ra) && j < len(rb); {
+		switch {
+		case ra[i] == rb[j]:
+			m[pre+i] = pre + j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,122 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testBase = `FROM golang:1.10
+WORKDIR /go/src/app
+COPY . .
+RUN go build
+CMD ["app"]
+`
+
+func Test_Lines(t *testing.T) {
+	assert.Nil(t, Lines(""))
+	assert.Equal(t, []string{"a\n", "b"}, Lines("a\nb"))
+	assert.Equal(t, []string{"a\n", "b\n"}, Lines("a\nb\n"))
+}
+
+func Test_Merge3_clean(t *testing.T) {
+	ours := `FROM golang:1.10
+WORKDIR /go/src/app
+COPY . .
+RUN go build -o app
+CMD ["app"]
+`
+	theirs := `FROM golang:1.11
+WORKDIR /go/src/app
+COPY . .
+RUN go build
+CMD ["app"]
+`
+	r := Merge3(testBase, ours, theirs, "ours", "theirs")
+	assert.Equal(t, 0, r.Conflicts)
+	assert.Equal(t, `FROM golang:1.11
+WORKDIR /go/src/app
+COPY . .
+RUN go build -o app
+CMD ["app"]
+`, r.Text)
+
+	// Unchanged sides
+	r = Merge3(testBase, testBase, theirs, "ours", "theirs")
+	assert.Equal(t, theirs, r.Text)
+	r = Merge3(testBase, ours, testBase, "ours", "theirs")
+	assert.Equal(t, ours, r.Text)
+
+	// Same change on both sides
+	r = Merge3(testBase, theirs, theirs, "ours", "theirs")
+	assert.Equal(t, 0, r.Conflicts)
+	assert.Equal(t, theirs, r.Text)
+}
+
+func Test_Merge3_conflict(t *testing.T) {
+	ours := "FROM golang:1.10-alpine\n" + testBase[len("FROM golang:1.10\n"):]
+	theirs := "FROM golang:1.11\n" + testBase[len("FROM golang:1.10\n"):] + "EXPOSE 8080\n"
+
+	r := Merge3(testBase, ours, theirs, "api.dockerfile", "pack go@abc")
+	assert.Equal(t, 1, r.Conflicts)
+	assert.Equal(t, `<<<<<<< api.dockerfile
+FROM golang:1.10-alpine
+=======
+FROM golang:1.11
+>>>>>>> pack go@abc
+WORKDIR /go/src/app
+COPY . .
+RUN go build
+CMD ["app"]
+EXPOSE 8080
+`, r.Text)
+}
+
+func Test_Unified(t *testing.T) {
+	assert.Equal(t, "", Unified(testBase, testBase, "a", "b"))
+
+	b := `FROM golang:1.11
+WORKDIR /go/src/app
+COPY . .
+RUN go build
+CMD ["app"]
+EXPOSE 8080`
+
+	assert.Equal(t, `--- a/Dockerfile
++++ b/Dockerfile
+@@ -1,5 +1,6 @@
+-FROM golang:1.10
++FROM golang:1.11
+ WORKDIR /go/src/app
+ COPY . .
+ RUN go build
+ CMD ["app"]
++EXPOSE 8080
+\ No newline at end of file
+`, Unified(testBase, b, "a/Dockerfile", "b/Dockerfile"))
+
+	// Separate hunks
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b = "x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny\n"
+	assert.Equal(t, `--- a
++++ b
+@@ -1,4 +1,4 @@
+-1
++x
+ 2
+ 3
+ 4
+@@ -9,4 +9,4 @@
+ 9
+ 10
+ 11
+-12
++y
+`, Unified(a, b, "a", "b"))
+}
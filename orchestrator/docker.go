@@ -7,17 +7,27 @@ Sniperkit-Bot
 package orchestrator
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
 	"github.com/sniperkit/snk.fork.thrap/crt"
 	"github.com/sniperkit/snk.fork.thrap/thrapb"
 )
 
+// dockerHealthyTimeout is the max time services are waited on to become
+// healthy
+const dockerHealthyTimeout = 2 * time.Minute
+
 type DockerOrchestrator struct {
 	crt *crt.Docker
 }
@@ -35,7 +45,7 @@ func (orch *DockerOrchestrator) Init(conf map[string]interface{}) (err error) {
 
 // DeployComponent deploys a single component
 func (orch *DockerOrchestrator) DeployComponent(ctx context.Context, stackID string, comp *thrapb.Component, opts RequestOptions) error {
-	return orch.startContainer(ctx, stackID, comp)
+	return orch.startContainer(ctx, stackID, comp, opts)
 }
 
 // Deploy deploys the whole stack in the appropriate order
@@ -53,10 +63,24 @@ func (orch *DockerOrchestrator) Deploy(ctx context.Context, stack *thrapb.Stack,
 	}()
 
 	// Deploy services like db's etc
-	err = orch.startServices(ctx, stack)
+	err = orch.startServices(ctx, stack, opts)
 	if err != nil {
 		return
 	}
+
+	// Migrations run against the services once healthy and before the
+	// application starts
+	if len(migrationComponents(stack)) > 0 {
+		err = migrateServices(ctx, stack, dockerHealthyTimeout, time.Second, func(ctx context.Context, comp *thrapb.Component) (bool, error) {
+			return orch.serviceHealthy(ctx, stack, comp)
+		}, func(ctx context.Context, comp *thrapb.Component) error {
+			return orch.runMigration(ctx, stack.ID, comp)
+		})
+		if err != nil {
+			return
+		}
+	}
+
 	fmt.Printf("\nApplication:\n\n")
 
 	// Deploy non-head containers
@@ -69,7 +93,7 @@ func (orch *DockerOrchestrator) Deploy(ctx context.Context, stack *thrapb.Stack,
 			continue
 		}
 
-		err = orch.startContainer(ctx, stack.ID, comp)
+		err = orch.startContainer(ctx, stack.ID, comp, opts)
 		if err != nil {
 			return
 		}
@@ -86,7 +110,7 @@ func (orch *DockerOrchestrator) Deploy(ctx context.Context, stack *thrapb.Stack,
 			continue
 		}
 
-		err = orch.startContainer(ctx, stack.ID, comp)
+		err = orch.startContainer(ctx, stack.ID, comp, opts)
 		if err != nil {
 			break
 		}
@@ -136,6 +160,208 @@ func (orch *DockerOrchestrator) getCompStatus(ctx context.Context, id string) *t
 	return ss
 }
 
+// Logs concurrently streams the container logs of the requested components
+func (orch *DockerOrchestrator) Logs(ctx context.Context, stack *thrapb.Stack, opts LogOptions, lines chan<- *LogLine) error {
+	comps, err := opts.SelectComponents(stack)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(comps))
+	)
+
+	for i, comp := range comps {
+		wg.Add(1)
+		go func(i int, comp *thrapb.Component) {
+			defer wg.Done()
+
+			stdout := newLogLineWriter(comp.ID, "stdout", true, lines)
+			stderr := newLogLineWriter(comp.ID, "stderr", true, lines)
+			req := &crt.LogsRequest{
+				Follow:     opts.Follow,
+				Since:      opts.Since,
+				Timestamps: true,
+				Stdout:     stdout,
+				Stderr:     stderr,
+			}
+
+			err := orch.crt.ContainerLogs(ctx, comp.ID+"."+stack.ID, req)
+			stdout.Flush()
+			stderr.Flush()
+
+			// Cancelling is how a follow is stopped
+			if err != nil && ctx.Err() == nil {
+				errs[i] = fmt.Errorf("%s: %v", comp.ID, err)
+			}
+		}(i, comp)
+	}
+	wg.Wait()
+
+	for _, err = range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exec runs a command in the component container
+func (orch *DockerOrchestrator) Exec(ctx context.Context, stack *thrapb.Stack, compID string, req *ExecRequest) (int, error) {
+	if _, ok := stack.Components[compID]; !ok {
+		return -1, fmt.Errorf("component not found: %s", compID)
+	}
+
+	creq := &crt.ExecRequest{
+		Cmd:    req.Cmd,
+		TTY:    req.TTY,
+		Height: req.Height,
+		Width:  req.Width,
+		Stdin:  req.Stdin,
+		Stdout: req.Stdout,
+		Stderr: req.Stderr,
+	}
+	return orch.crt.Exec(ctx, compID+"."+stack.ID, creq)
+}
+
+// PortAddr returns the host address of the port if it has been published,
+// otherwise the address on the isolated stack network
+func (orch *DockerOrchestrator) PortAddr(ctx context.Context, stack *thrapb.Stack, compID, label string) (string, error) {
+	_, port, err := componentPort(stack, compID, label)
+	if err != nil {
+		return "", err
+	}
+
+	cont, err := orch.crt.Inspect(ctx, compID+"."+stack.ID)
+	if err != nil {
+		return "", err
+	}
+
+	cport := strconv.Itoa(int(port))
+	for p, bindings := range cont.NetworkSettings.Ports {
+		if p.Port() != cport || len(bindings) == 0 {
+			continue
+		}
+		ip := bindings[0].HostIP
+		if ip == "" || ip == "0.0.0.0" {
+			ip = "127.0.0.1"
+		}
+		return net.JoinHostPort(ip, bindings[0].HostPort), nil
+	}
+
+	nw, ok := cont.NetworkSettings.Networks[stack.ID]
+	if !ok || nw.IPAddress == "" {
+		return "", fmt.Errorf("container not on stack network: %s", compID)
+	}
+	return net.JoinHostPort(nw.IPAddress, cport), nil
+}
+
+// serviceHealthy reports whether the component container is healthy
+func (orch *DockerOrchestrator) serviceHealthy(ctx context.Context, stack *thrapb.Stack, comp *thrapb.Component) (bool, error) {
+	return ContainerHealthy(ctx, orch.crt, stack, comp)
+}
+
+// WaitContainerHealthy waits for the component container to become healthy
+// bounded by the timeout
+func WaitContainerHealthy(ctx context.Context, rt *crt.Docker, stack *thrapb.Stack, comp *thrapb.Component, timeout time.Duration) error {
+	c, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return waitHealthy(c, []*thrapb.Component{comp}, time.Second, func(ctx context.Context, comp *thrapb.Component) (bool, error) {
+		return ContainerHealthy(ctx, rt, stack, comp)
+	})
+}
+
+// ContainerHealthy reports whether the component container is healthy.  The
+// image healthcheck is used if it has one, otherwise the component health
+// checks.  Without either a running container is considered healthy
+func ContainerHealthy(ctx context.Context, rt *crt.Docker, stack *thrapb.Stack, comp *thrapb.Component) (bool, error) {
+	cont, err := rt.Inspect(ctx, comp.ID+"."+stack.ID)
+	if err != nil {
+		return false, err
+	}
+
+	state := cont.State
+	if state.Dead || state.Status == "exited" {
+		return false, fmt.Errorf("exited code=%d %s", state.ExitCode, state.Error)
+	}
+	if !state.Running {
+		return false, nil
+	}
+
+	if state.Health != nil {
+		switch state.Health.Status {
+		case types.Healthy:
+			return true, nil
+		case types.Unhealthy:
+			return false, errors.New("unhealthy")
+		}
+		return false, nil
+	}
+
+	for _, hc := range comp.HealthChecks {
+		if _, ok := comp.Ports[hc.PortLabel]; !ok {
+			return false, fmt.Errorf("health check port label not found: %s", hc.PortLabel)
+		}
+		if !checkHealth(ctx, rt, stack, comp, hc) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// checkHealth performs a single component health check from within the
+// container returning whether it passed.  Probing inside the container does
+// not depend on container addresses being reachable from the host
+func checkHealth(ctx context.Context, rt *crt.Docker, stack *thrapb.Stack, comp *thrapb.Component, hc *thrapb.HealthCheck) bool {
+	_, port, err := componentPort(stack, comp.ID, hc.PortLabel)
+	if err != nil {
+		return false
+	}
+
+	timeout := time.Duration(hc.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	c, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	code, err := rt.Exec(c, comp.ID+"."+stack.ID, &crt.ExecRequest{
+		Cmd:    healthProbeCmd(hc, port),
+		Stdout: &out,
+		Stderr: &out,
+	})
+	return err == nil && code == 0
+}
+
+// healthProbeCmd returns the command run inside a container to perform the
+// health check.  http checks use wget or curl whichever is available.  tcp
+// checks and http checks without either tool test for a listening socket on
+// the port
+func healthProbeCmd(hc *thrapb.HealthCheck, port int32) []string {
+	listening := fmt.Sprintf("grep -qi ':%04X [0-9A-F:]* 0A' /proc/net/tcp /proc/net/tcp6", port)
+
+	switch hc.Protocol {
+	case "http", "https":
+		method := hc.Method
+		if method == "" {
+			method = "GET"
+		}
+		url := fmt.Sprintf("%s://127.0.0.1:%d%s", hc.Protocol, port, hc.Path)
+		script := "if command -v curl >/dev/null 2>&1; then " +
+			fmt.Sprintf("curl -fsSk -o /dev/null -X %s '%s'; ", method, url) +
+			"elif command -v wget >/dev/null 2>&1 && [ '" + method + "' = GET ]; then " +
+			fmt.Sprintf("wget -q -O /dev/null '%s'; ", url) +
+			"else " + listening + "; fi"
+		return []string{"sh", "-c", script}
+
+	default:
+		return []string{"sh", "-c", listening}
+	}
+}
+
 // Destroy removes call components of the stack from the container runtime
 func (orch *DockerOrchestrator) Destroy(ctx context.Context, stack *thrapb.Stack) []*thrapb.ActionResult {
 	ar := make([]*thrapb.ActionResult, 0, len(stack.Components))
@@ -151,31 +377,81 @@ func (orch *DockerOrchestrator) Destroy(ctx context.Context, stack *thrapb.Stack
 	return ar
 }
 
-func (orch *DockerOrchestrator) startContainer(ctx context.Context, sid string, comp *thrapb.Component) error {
-	cfg := thrapb.NewContainer(sid, comp.ID)
+// runMigration runs the migration hook of the component in a one-shot
+// container on the stack network, removing it once it exits
+func (orch *DockerOrchestrator) runMigration(ctx context.Context, sid string, comp *thrapb.Component) error {
+	cfg := thrapb.NewContainer(sid, comp.ID+"-migrate")
+	cfg.Container.Image = componentImage(sid, comp)
+	cfg.Container.Env = componentEnv(comp)
+
+	if comp.Migrate.Cmd != "" {
+		cfg.Container.Cmd = append([]string{comp.Migrate.Cmd}, comp.Migrate.Args...)
+	} else if len(comp.Migrate.Args) > 0 {
+		cfg.Container.Cmd = comp.Migrate.Args
+	}
 
+	// Remove leftovers from a previously interrupted run
+	orch.crt.Remove(ctx, cfg.Name)
+	defer orch.crt.Remove(context.Background(), cfg.Name)
+
+	if _, err := orch.crt.Run(ctx, cfg); err != nil {
+		return err
+	}
+
+	code, err := orch.crt.Wait(ctx, cfg.Name)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		var out bytes.Buffer
+		orch.crt.Logs(context.Background(), cfg.Name, &out, &out)
+		return fmt.Errorf("code=%d: %s", code, bytes.TrimSpace(out.Bytes()))
+	}
+	return nil
+}
+
+// componentImage returns the image reference the component is run from
+func componentImage(sid string, comp *thrapb.Component) string {
+	var image string
 	if comp.IsBuildable() {
-		cfg.Container.Image = filepath.Join(sid, comp.Name)
+		image = filepath.Join(sid, comp.Name)
 	} else {
-		cfg.Container.Image = comp.Name
+		image = comp.Name
 	}
 
 	// Add image version if present
 	if len(comp.Version) > 0 {
-		cfg.Container.Image += ":" + comp.Version
+		image += ":" + comp.Version
 	}
+	return image
+}
 
-	if comp.HasEnvVars() {
-		cfg.Container.Env = make([]string, 0, len(comp.Env.Vars))
-		for k, v := range comp.Env.Vars {
-			cfg.Container.Env = append(cfg.Container.Env, k+"="+v)
-		}
+// componentEnv returns the component env vars in the container format
+func componentEnv(comp *thrapb.Component) []string {
+	if !comp.HasEnvVars() {
+		return nil
 	}
 
-	// Publish all ports for a head component.
-	// TODO: May need to map this to user defined host ports
-	if comp.Head {
-		cfg.Host.PublishAllPorts = true
+	env := make([]string, 0, len(comp.Env.Vars))
+	for k, v := range comp.Env.Vars {
+		env = append(env, k+"="+v)
+	}
+	return env
+}
+
+func (orch *DockerOrchestrator) startContainer(ctx context.Context, sid string, comp *thrapb.Component, opts RequestOptions) error {
+	cfg := thrapb.NewContainer(sid, comp.ID)
+	cfg.Container.Image = componentImage(sid, comp)
+	cfg.Container.Env = componentEnv(comp)
+
+	// External components are bound to the host using the same port numbers.
+	// Head components are bound to random loopback ports the ingress dials,
+	// as container addresses are not routable from every host
+	switch {
+	case comp.External:
+		publishPorts(cfg, comp, opts.DynamicPorts)
+	case comp.Head:
+		publishPorts(cfg, comp, true)
 	}
 
 	// Non-blocking
@@ -205,8 +481,28 @@ func (orch *DockerOrchestrator) startContainer(ctx context.Context, sid string,
 	return err
 }
 
+// publishPorts binds each component port to the same port on the host
+// loopback interface.  If dynamic a random free host port is used instead
+func publishPorts(cfg *thrapb.Container, comp *thrapb.Component, dynamic bool) {
+	cfg.Container.ExposedPorts = make(nat.PortSet, len(comp.Ports))
+	cfg.Host.PortBindings = make(nat.PortMap, len(comp.Ports))
+
+	for _, p := range comp.Ports {
+		port := nat.Port(strconv.Itoa(int(p)) + "/tcp")
+		cfg.Container.ExposedPorts[port] = struct{}{}
+
+		var hostPort string
+		if !dynamic {
+			hostPort = port.Port()
+		}
+		cfg.Host.PortBindings[port] = []nat.PortBinding{
+			{HostIP: "127.0.0.1", HostPort: hostPort},
+		}
+	}
+}
+
 // startServices starts services starts all non-build components
-func (orch *DockerOrchestrator) startServices(ctx context.Context, stack *thrapb.Stack) error {
+func (orch *DockerOrchestrator) startServices(ctx context.Context, stack *thrapb.Stack, opts RequestOptions) error {
 	var err error
 
 	fmt.Printf("\nServices:\n\n")
@@ -225,7 +521,7 @@ func (orch *DockerOrchestrator) startServices(ctx context.Context, stack *thrapb
 			}
 		}
 
-		if err = orch.startContainer(ctx, stack.ID, comp); err != nil {
+		if err = orch.startContainer(ctx, stack.ID, comp, opts); err != nil {
 			break
 		}
 
@@ -0,0 +1,43 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+)
+
+// ExecRequest is a request to run a command inside a running component
+type ExecRequest struct {
+	Cmd []string
+	// Allocate a tty for the command
+	TTY bool
+	// Initial tty size
+	Height uint
+	Width  uint
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// componentPort returns the component and port for the given port label
+func componentPort(stack *thrapb.Stack, compID, label string) (*thrapb.Component, int32, error) {
+	comp, ok := stack.Components[compID]
+	if !ok {
+		return nil, 0, fmt.Errorf("component not found: %s", compID)
+	}
+
+	port, ok := comp.Ports[label]
+	if !ok {
+		return comp, 0, fmt.Errorf("port label not found: %s.%s", compID, label)
+	}
+
+	return comp, port, nil
+}
@@ -0,0 +1,123 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package orchestrator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+)
+
+var errSinceNotSupported = errors.New("logs since a time not supported by orchestrator")
+
+// LogOptions holds the available options when reading component logs
+type LogOptions struct {
+	// Component ids to read logs for. All components are used if empty
+	Components []string
+	// Keep streaming logs until the context is cancelled
+	Follow bool
+	// Only return logs after this time.  Orchestrators that do not timestamp
+	// log lines return errSinceNotSupported
+	Since time.Time
+}
+
+// LogLine is a single line of log output from a component
+type LogLine struct {
+	Component string `json:"component"`
+	Stream    string `json:"stream"`
+	// Time the line was logged.  nil if the orchestrator does not timestamp
+	// lines
+	Time *time.Time `json:"time,omitempty"`
+	Text string     `json:"text"`
+}
+
+// SelectComponents returns the stack components the logs were requested
+// for. It returns an error if a requested component is not in the stack
+func (opts LogOptions) SelectComponents(stack *thrapb.Stack) ([]*thrapb.Component, error) {
+	if len(opts.Components) == 0 {
+		out := make([]*thrapb.Component, 0, len(stack.Components))
+		for _, comp := range stack.Components {
+			out = append(out, comp)
+		}
+		return out, nil
+	}
+
+	out := make([]*thrapb.Component, 0, len(opts.Components))
+	for _, id := range opts.Components {
+		comp, ok := stack.Components[id]
+		if !ok {
+			return nil, fmt.Errorf("component not found: %s", id)
+		}
+		out = append(out, comp)
+	}
+	return out, nil
+}
+
+// logLineWriter splits written data into lines and sends each one to the
+// channel as a LogLine
+type logLineWriter struct {
+	comp   string
+	stream string
+	// true if each line is prefixed with a RFC3339Nano timestamp
+	timestamped bool
+
+	buf   []byte
+	lines chan<- *LogLine
+}
+
+func newLogLineWriter(comp, stream string, timestamped bool, lines chan<- *LogLine) *logLineWriter {
+	return &logLineWriter{
+		comp:        comp,
+		stream:      stream,
+		timestamped: timestamped,
+		lines:       lines,
+	}
+}
+
+func (w *logLineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.send(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush sends any remaining partial line
+func (w *logLineWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.send(string(w.buf))
+		w.buf = nil
+	}
+}
+
+func (w *logLineWriter) send(text string) {
+	line := &LogLine{
+		Component: w.comp,
+		Stream:    w.stream,
+		Text:      strings.TrimSuffix(text, "\r"),
+	}
+
+	if w.timestamped {
+		if i := strings.IndexByte(line.Text, ' '); i > 0 {
+			if ts, err := time.Parse(time.RFC3339Nano, line.Text[:i]); err == nil {
+				line.Time = &ts
+				line.Text = line.Text[i+1:]
+			}
+		}
+	}
+
+	w.lines <- line
+}
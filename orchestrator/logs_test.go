@@ -0,0 +1,58 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_logLineWriter(t *testing.T) {
+	lines := make(chan *LogLine, 4)
+	w := newLogLineWriter("api", "stdout", true, lines)
+
+	w.Write([]byte("2018-08-11T20:25:29.898780201Z first line\n2018-08-11T20:25:30Z sec"))
+	w.Write([]byte("ond line\r\nno timestamp"))
+	w.Flush()
+	close(lines)
+
+	var out []*LogLine
+	for l := range lines {
+		out = append(out, l)
+	}
+
+	assert.Equal(t, 3, len(out))
+	assert.Equal(t, "api", out[0].Component)
+	assert.Equal(t, "stdout", out[0].Stream)
+	assert.Equal(t, "first line", out[0].Text)
+	assert.Equal(t, 2018, out[0].Time.Year())
+	assert.Equal(t, "second line", out[1].Text)
+	assert.Equal(t, "no timestamp", out[2].Text)
+	assert.Nil(t, out[2].Time)
+}
+
+func Test_LogOptions_SelectComponents(t *testing.T) {
+	stack := &thrapb.Stack{
+		Components: map[string]*thrapb.Component{
+			"api": &thrapb.Component{ID: "api"},
+			"db":  &thrapb.Component{ID: "db"},
+		},
+	}
+
+	comps, err := LogOptions{}.SelectComponents(stack)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(comps))
+
+	comps, err = LogOptions{Components: []string{"db"}}.SelectComponents(stack)
+	assert.Nil(t, err)
+	assert.Equal(t, "db", comps[0].ID)
+
+	_, err = LogOptions{Components: []string{"web"}}.SelectComponents(stack)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,124 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+)
+
+// migrationComponents returns the components with a migration hook sorted by
+// id
+func migrationComponents(stack *thrapb.Stack) []*thrapb.Component {
+	ids := make([]string, 0, len(stack.Components))
+	for id, comp := range stack.Components {
+		if comp.HasMigration() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	comps := make([]*thrapb.Component, len(ids))
+	for i, id := range ids {
+		comps[i] = stack.Components[id]
+	}
+	return comps
+}
+
+// runMigrations calls run for each component migration in order, bounded by
+// the migration timeout.  It stops at the first failure
+func runMigrations(ctx context.Context, stack *thrapb.Stack, run func(context.Context, *thrapb.Component) error) error {
+	comps := migrationComponents(stack)
+	if len(comps) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\nMigrations:\n\n")
+
+	for _, comp := range comps {
+		timeout := time.Duration(comp.Migrate.Timeout) * time.Second
+		c, cancel := context.WithTimeout(ctx, timeout)
+
+		start := time.Now()
+		err := run(c, comp)
+		cancel()
+
+		if err != nil {
+			fmt.Printf(" - %s:%s failed\n", comp.ID, comp.Version)
+			return fmt.Errorf("migration failed %s: %v", comp.ID, err)
+		}
+		fmt.Printf(" - %s:%s (%v)\n", comp.ID, comp.Version, time.Since(start).Round(time.Millisecond))
+	}
+
+	return nil
+}
+
+// healthFunc reports whether the component is healthy.  An error is returned
+// if it never will be
+type healthFunc func(context.Context, *thrapb.Component) (bool, error)
+
+// serviceComponents returns the non-buildable components sorted by id
+func serviceComponents(stack *thrapb.Stack) []*thrapb.Component {
+	ids := make([]string, 0, len(stack.Components))
+	for id, comp := range stack.Components {
+		if !comp.IsBuildable() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	comps := make([]*thrapb.Component, len(ids))
+	for i, id := range ids {
+		comps[i] = stack.Components[id]
+	}
+	return comps
+}
+
+// waitHealthy polls check every interval until all comps are healthy or the
+// context is done
+func waitHealthy(ctx context.Context, comps []*thrapb.Component, interval time.Duration, check healthFunc) error {
+	pending := comps
+	for {
+		var next []*thrapb.Component
+		for _, comp := range pending {
+			ok, err := check(ctx, comp)
+			if err != nil {
+				return fmt.Errorf("%s: %v", comp.ID, err)
+			}
+			if !ok {
+				next = append(next, comp)
+			}
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		pending = next
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: not healthy: %v", pending[0].ID, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// migrateServices waits for the services of the stack to be healthy, bounded
+// by timeout, before running the migrations
+func migrateServices(ctx context.Context, stack *thrapb.Stack, timeout, interval time.Duration, check healthFunc, run func(context.Context, *thrapb.Component) error) error {
+	c, cancel := context.WithTimeout(ctx, timeout)
+	err := waitHealthy(c, serviceComponents(stack), interval, check)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	return runMigrations(ctx, stack, run)
+}
@@ -0,0 +1,98 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_runMigrations(t *testing.T) {
+	stack := &thrapb.Stack{
+		ID: "test",
+		Components: map[string]*thrapb.Component{
+			"web": {ID: "web", Migrate: &thrapb.Migrate{Timeout: 1}},
+			"db":  {ID: "db"},
+			"api": {ID: "api", Migrate: &thrapb.Migrate{Timeout: 1}},
+		},
+	}
+
+	var ran []string
+	err := runMigrations(context.Background(), stack, func(ctx context.Context, comp *thrapb.Component) error {
+		ran = append(ran, comp.ID)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"api", "web"}, ran)
+
+	ran = nil
+	err = runMigrations(context.Background(), stack, func(ctx context.Context, comp *thrapb.Component) error {
+		ran = append(ran, comp.ID)
+		return errors.New("boom")
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"api"}, ran)
+}
+
+func Test_migrateServices_waitsHealthy(t *testing.T) {
+	stack := &thrapb.Stack{
+		ID: "test",
+		Components: map[string]*thrapb.Component{
+			"db":  {ID: "db", Name: "postgres"},
+			"api": {ID: "api", Build: &thrapb.Build{Dockerfile: "api.dockerfile"}, Migrate: &thrapb.Migrate{Timeout: 1}},
+		},
+	}
+
+	var events []string
+	checks := 0
+	check := func(ctx context.Context, comp *thrapb.Component) (bool, error) {
+		checks++
+		// db is slow to start and only healthy on the third poll
+		healthy := checks >= 3
+		if healthy {
+			events = append(events, "healthy:"+comp.ID)
+		}
+		return healthy, nil
+	}
+	run := func(ctx context.Context, comp *thrapb.Component) error {
+		events = append(events, "migrate:"+comp.ID)
+		return nil
+	}
+
+	err := migrateServices(context.Background(), stack, time.Second, time.Millisecond, check, run)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"healthy:db", "migrate:api"}, events)
+
+	// Never healthy
+	events = nil
+	err = migrateServices(context.Background(), stack, 20*time.Millisecond, time.Millisecond,
+		func(ctx context.Context, comp *thrapb.Component) (bool, error) { return false, nil }, run)
+	assert.NotNil(t, err)
+	assert.Empty(t, events)
+
+	// Exited service
+	err = migrateServices(context.Background(), stack, time.Second, time.Millisecond,
+		func(ctx context.Context, comp *thrapb.Component) (bool, error) { return false, errors.New("exited") }, run)
+	assert.NotNil(t, err)
+	assert.Empty(t, events)
+}
+
+func Test_healthProbeCmd(t *testing.T) {
+	cmd := healthProbeCmd(&thrapb.HealthCheck{Protocol: "tcp"}, 5432)
+	assert.Equal(t, []string{"sh", "-c", "grep -qi ':1538 [0-9A-F:]* 0A' /proc/net/tcp /proc/net/tcp6"}, cmd)
+
+	cmd = healthProbeCmd(&thrapb.HealthCheck{Protocol: "http", Path: "/health"}, 8080)
+	assert.Equal(t, "sh", cmd[0])
+	assert.Contains(t, cmd[2], "curl -fsSk -o /dev/null -X GET 'http://127.0.0.1:8080/health'")
+	assert.Contains(t, cmd[2], "wget -q -O /dev/null 'http://127.0.0.1:8080/health'")
+	assert.Contains(t, cmd[2], ":1F90 ")
+}
@@ -8,6 +8,11 @@ package orchestrator
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
 
 	nomad "github.com/hashicorp/nomad/api"
 	"github.com/sniperkit/snk.fork.thrap/manifest"
@@ -15,7 +20,11 @@ import (
 	//"github.com/hashicorp/nomad/nomad/structs"
 )
 
+// Max time to wait for datastores to be healthy before migrations
+const nomadHealthyTimeout = 5 * time.Minute
+
 type nomadOrchestrator struct {
+	config *nomad.Config
 	client *nomad.Client
 }
 
@@ -26,18 +35,16 @@ func (orch *nomadOrchestrator) ID() string {
 // Environment Variables:
 // NOMAD_ADDR
 func (orch *nomadOrchestrator) Init(conf map[string]interface{}) error {
-	var (
-		config = nomad.DefaultConfig()
-		err    error
-	)
+	var err error
+	orch.config = nomad.DefaultConfig()
 
 	if iaddr, ok := conf["addr"]; ok {
 		if addr, ok := iaddr.(string); ok {
-			config.Address = addr
+			orch.config.Address = addr
 		}
 	}
 
-	orch.client, err = nomad.NewClient(config)
+	orch.client, err = nomad.NewClient(orch.config)
 
 	return err
 }
@@ -63,16 +70,304 @@ func (orch *nomadOrchestrator) Deploy(ctx context.Context, st *thrapb.Stack, opt
 		return
 	}
 
+	if len(migrationComponents(st)) > 0 {
+		// Migrations need the datastores of the new version so those are
+		// rolled out and healthy first
+		if err = orch.deployDatastores(ctx, njob, q); err != nil {
+			return
+		}
+
+		// Migrations must succeed before the new version is rolled out
+		err = runMigrations(ctx, st, func(ctx context.Context, comp *thrapb.Component) error {
+			return orch.runMigration(ctx, st, comp)
+		})
+		if err != nil {
+			return
+		}
+	}
+
 	regOpts := &nomad.RegisterOptions{}
 	resp, _, err = jobs.RegisterOpts(njob, regOpts, q)
 
 	return
 }
 
+// deployDatastores registers the job with only the datastore group of njob
+// and waits for it to be healthy.  The application groups of the running
+// job, if any, are kept as is so they are not stopped
+func (orch *nomadOrchestrator) deployDatastores(ctx context.Context, njob *nomad.Job, q *nomad.WriteOptions) error {
+	current, err := orch.currentJob(*njob.ID)
+	if err != nil {
+		return err
+	}
+
+	group := manifest.NomadDatastoreGroup(*njob.ID)
+	stage := nomadDatastoreStage(njob, current, group)
+	if stage == nil {
+		return nil
+	}
+
+	if _, _, err = orch.client.Jobs().Register(stage, q); err != nil {
+		return err
+	}
+
+	c, cancel := context.WithTimeout(ctx, nomadHealthyTimeout)
+	defer cancel()
+	if err = orch.waitGroupHealthy(c, *njob.ID, group); err != nil {
+		return fmt.Errorf("datastores: %v", err)
+	}
+	return nil
+}
+
+// nomadDatastoreStage returns a copy of njob with its datastore group and the
+// other groups of the current job if any.  nil is returned if njob has no
+// datastore group
+func nomadDatastoreStage(njob, current *nomad.Job, group string) *nomad.Job {
+	stage := *njob
+	stage.TaskGroups = nil
+
+	for _, grp := range njob.TaskGroups {
+		if *grp.Name == group {
+			stage.TaskGroups = append(stage.TaskGroups, grp)
+		}
+	}
+	if len(stage.TaskGroups) == 0 {
+		return nil
+	}
+
+	if current != nil {
+		for _, grp := range current.TaskGroups {
+			if *grp.Name != group {
+				stage.TaskGroups = append(stage.TaskGroups, grp)
+			}
+		}
+	}
+
+	return &stage
+}
+
+// currentJob returns the job if it is registered and not dead, otherwise nil
+func (orch *nomadOrchestrator) currentJob(id string) (*nomad.Job, error) {
+	jobs := orch.client.Jobs()
+	q := &nomad.QueryOptions{}
+
+	stubs, _, err := jobs.PrefixList(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, stub := range stubs {
+		if stub.ID != id || stub.Status == "dead" {
+			continue
+		}
+		job, _, err := jobs.Info(id, q)
+		return job, err
+	}
+
+	return nil, nil
+}
+
+// waitGroupHealthy waits for all allocations of the task group desired to run
+// to be running and healthy
+func (orch *nomadOrchestrator) waitGroupHealthy(ctx context.Context, jobID, group string) error {
+	q := &nomad.QueryOptions{}
+	for {
+		stubs, _, err := orch.client.Jobs().Allocations(jobID, false, q)
+		if err != nil {
+			return err
+		}
+
+		var healthy, pending int
+		for _, stub := range stubs {
+			if stub.TaskGroup != group || stub.DesiredStatus != "run" {
+				continue
+			}
+
+			switch stub.ClientStatus {
+			case "failed", "lost":
+				return fmt.Errorf("allocation %s %s: %s", stub.ID, stub.ClientStatus, stub.ClientDescription)
+			case "running":
+				ds := stub.DeploymentStatus
+				if ds != nil && ds.Healthy != nil {
+					if !*ds.Healthy {
+						return fmt.Errorf("allocation %s unhealthy", stub.ID)
+					}
+					healthy++
+					continue
+				}
+			}
+			pending++
+		}
+		if healthy > 0 && pending == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// runMigration submits the migration batch job of the component and waits
+// for its allocation to complete.  The job is purged once done
+func (orch *nomadOrchestrator) runMigration(ctx context.Context, st *thrapb.Stack, comp *thrapb.Component) error {
+	njob := manifest.MakeNomadMigrateJob(st, comp)
+	njob.Canonicalize()
+
+	jobs := orch.client.Jobs()
+	_, _, err := jobs.Register(njob, &nomad.WriteOptions{})
+	if err != nil {
+		return err
+	}
+	defer jobs.Deregister(*njob.ID, true, &nomad.WriteOptions{})
+
+	q := &nomad.QueryOptions{}
+	for {
+		stubs, _, err := jobs.Allocations(*njob.ID, false, q)
+		if err != nil {
+			return err
+		}
+
+		for _, stub := range stubs {
+			switch stub.ClientStatus {
+			case "complete":
+				return nil
+			case "failed", "lost":
+				return fmt.Errorf("allocation %s %s: %s", stub.ID, stub.ClientStatus, stub.ClientDescription)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
 func (orch *nomadOrchestrator) Status(ctx context.Context, stack *thrapb.Stack) []*thrapb.CompStatus {
 	return nil
 }
 
+// Logs streams the task logs of the requested components from all running
+// allocations of the stack job.  Nomad does not timestamp log lines so the
+// since option is rejected
+func (orch *nomadOrchestrator) Logs(ctx context.Context, stack *thrapb.Stack, opts LogOptions, lines chan<- *LogLine) error {
+	if !opts.Since.IsZero() {
+		return errSinceNotSupported
+	}
+
+	comps, err := opts.SelectComponents(stack)
+	if err != nil {
+		return err
+	}
+
+	q := &nomad.QueryOptions{}
+	stubs, _, err := orch.client.Jobs().Allocations(stack.ID, false, q)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for _, stub := range stubs {
+		if stub.ClientStatus != "running" {
+			continue
+		}
+
+		alloc, _, err := orch.client.Allocations().Info(stub.ID, q)
+		if err != nil {
+			return err
+		}
+
+		for _, comp := range comps {
+			task := manifest.NomadTaskName(stack.ID, comp)
+			if _, ok := alloc.TaskStates[task]; !ok {
+				continue
+			}
+
+			for _, logType := range []string{"stdout", "stderr"} {
+				wg.Add(1)
+				go func(comp *thrapb.Component, task, logType string) {
+					defer wg.Done()
+
+					w := newLogLineWriter(comp.ID, logType, false, lines)
+					err := orch.streamTaskLogs(ctx, alloc, task, logType, opts.Follow, w)
+					w.Flush()
+
+					if err != nil && ctx.Err() == nil {
+						mu.Lock()
+						errs = append(errs, fmt.Errorf("%s: %v", comp.ID, err))
+						mu.Unlock()
+					}
+				}(comp, task, logType)
+			}
+		}
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+func (orch *nomadOrchestrator) streamTaskLogs(ctx context.Context, alloc *nomad.Allocation, task, logType string, follow bool, w *logLineWriter) error {
+	frames, errCh := orch.client.AllocFS().Logs(alloc, follow, task, logType, "start", 0, ctx.Done(), &nomad.QueryOptions{})
+	for {
+		select {
+		case frame, ok := <-frames:
+			if !ok {
+				return nil
+			}
+			w.Write(frame.Data)
+
+		case err := <-errCh:
+			return err
+
+		}
+	}
+}
+
+// PortAddr returns the address of the labeled port of the first running
+// allocation of the component task
+func (orch *nomadOrchestrator) PortAddr(ctx context.Context, stack *thrapb.Stack, compID, label string) (string, error) {
+	comp, _, err := componentPort(stack, compID, label)
+	if err != nil {
+		return "", err
+	}
+
+	task := manifest.NomadTaskName(stack.ID, comp)
+
+	var addr string
+	_, err = orch.runningAlloc(stack, func(alloc *nomad.Allocation) bool {
+		res, ok := alloc.TaskResources[task]
+		if !ok {
+			return false
+		}
+
+		for _, nw := range res.Networks {
+			for _, p := range append(nw.DynamicPorts, nw.ReservedPorts...) {
+				if p.Label == label {
+					addr = net.JoinHostPort(nw.IP, strconv.Itoa(p.Value))
+					return true
+				}
+			}
+		}
+		return false
+	})
+	if err != nil || addr != "" {
+		return addr, err
+	}
+
+	return "", fmt.Errorf("no running allocation with port: %s.%s", compID, label)
+}
+
 func (orch *nomadOrchestrator) Destroy(ctx context.Context, stack *thrapb.Stack) []*thrapb.ActionResult {
 	jobs := orch.client.Jobs()
 	q := &nomad.WriteOptions{}
@@ -0,0 +1,258 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package orchestrator
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/sniperkit/snk.fork.thrap/manifest"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"golang.org/x/net/websocket"
+)
+
+var errExecNoExit = errors.New("exec stream closed without an exit code")
+
+// Frames of the nomad alloc exec websocket protocol.  Data is base64 encoded
+// by encoding/json
+type nomadExecData struct {
+	Data  []byte `json:"data,omitempty"`
+	Close bool   `json:"close,omitempty"`
+}
+
+type nomadExecSize struct {
+	Height uint `json:"height"`
+	Width  uint `json:"width"`
+}
+
+type nomadExecInput struct {
+	Stdin   *nomadExecData `json:"stdin,omitempty"`
+	TTYSize *nomadExecSize `json:"tty_size,omitempty"`
+}
+
+type nomadExecOutput struct {
+	Stdout *nomadExecData `json:"stdout,omitempty"`
+	Stderr *nomadExecData `json:"stderr,omitempty"`
+	Exited bool           `json:"exited,omitempty"`
+	Result *struct {
+		ExitCode int `json:"exit_code"`
+	} `json:"result,omitempty"`
+}
+
+// Exec runs the command in the component task of the first running
+// allocation using the alloc exec websocket endpoint.  This is called
+// directly as the nomad api in use predates exec.  Nomad 0.9 or later is
+// required
+func (orch *nomadOrchestrator) Exec(ctx context.Context, stack *thrapb.Stack, compID string, req *ExecRequest) (int, error) {
+	comp, ok := stack.Components[compID]
+	if !ok {
+		return -1, fmt.Errorf("component not found: %s", compID)
+	}
+
+	task := manifest.NomadTaskName(stack.ID, comp)
+	alloc, err := orch.runningAlloc(stack, func(alloc *nomad.Allocation) bool {
+		_, ok := alloc.TaskStates[task]
+		return ok
+	})
+	if err != nil {
+		return -1, err
+	}
+	if alloc == nil {
+		return -1, fmt.Errorf("no running allocation: %s", compID)
+	}
+
+	wsconf, err := orch.execConfig(alloc.ID, task, req)
+	if err != nil {
+		return -1, err
+	}
+	conn, err := websocket.DialConfig(wsconf)
+	if err != nil {
+		return -1, err
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	code, err := nomadExec(conn, req)
+	if err != nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	return code, err
+}
+
+// execConfig returns the websocket config for the alloc exec request from
+// the client config
+func (orch *nomadOrchestrator) execConfig(allocID, task string, req *ExecRequest) (*websocket.Config, error) {
+	u, err := url.Parse(orch.config.Address)
+	if err != nil {
+		return nil, err
+	}
+	origin := u.String()
+
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	u.Path = "/v1/client/allocation/" + allocID + "/exec"
+
+	cmd, _ := json.Marshal(req.Cmd)
+	q := url.Values{}
+	q.Set("task", task)
+	q.Set("tty", strconv.FormatBool(req.TTY))
+	q.Set("command", string(cmd))
+	if orch.config.Region != "" {
+		q.Set("region", orch.config.Region)
+	}
+	u.RawQuery = q.Encode()
+
+	wsconf, err := websocket.NewConfig(u.String(), origin)
+	if err != nil {
+		return nil, err
+	}
+	if orch.config.SecretID != "" {
+		wsconf.Header.Set("X-Nomad-Token", orch.config.SecretID)
+	}
+	if u.Scheme == "wss" {
+		wsconf.TlsConfig, err = nomadTLSConfig(orch.config.TLSConfig)
+	}
+	return wsconf, err
+}
+
+// nomadExec streams stdin to the exec connection and its output to the
+// request writers until the command exits, returning its exit code
+func nomadExec(conn *websocket.Conn, req *ExecRequest) (int, error) {
+	if req.TTY && req.Height > 0 && req.Width > 0 {
+		size := &nomadExecSize{Height: req.Height, Width: req.Width}
+		if err := websocket.JSON.Send(conn, &nomadExecInput{TTYSize: size}); err != nil {
+			return -1, err
+		}
+	}
+
+	if req.Stdin != nil {
+		go func() {
+			buf := make([]byte, 4096)
+			for {
+				n, err := req.Stdin.Read(buf)
+				if n > 0 {
+					in := &nomadExecInput{Stdin: &nomadExecData{Data: buf[:n]}}
+					if websocket.JSON.Send(conn, in) != nil {
+						return
+					}
+				}
+				if err != nil {
+					websocket.JSON.Send(conn, &nomadExecInput{Stdin: &nomadExecData{Close: true}})
+					return
+				}
+			}
+		}()
+	}
+
+	stdout, stderr := req.Stdout, req.Stderr
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+	if stderr == nil {
+		stderr = ioutil.Discard
+	}
+
+	for {
+		var out nomadExecOutput
+		if err := websocket.JSON.Receive(conn, &out); err != nil {
+			if err == io.EOF {
+				err = errExecNoExit
+			}
+			return -1, err
+		}
+
+		if out.Stdout != nil && len(out.Stdout.Data) > 0 {
+			stdout.Write(out.Stdout.Data)
+		}
+		if out.Stderr != nil && len(out.Stderr.Data) > 0 {
+			stderr.Write(out.Stderr.Data)
+		}
+		if out.Exited && out.Result != nil {
+			return out.Result.ExitCode, nil
+		}
+	}
+}
+
+// nomadTLSConfig returns the tls config of the nomad client tls settings
+func nomadTLSConfig(conf *nomad.TLSConfig) (*tls.Config, error) {
+	tconf := &tls.Config{}
+	if conf == nil {
+		return tconf, nil
+	}
+
+	tconf.InsecureSkipVerify = conf.Insecure
+	tconf.ServerName = conf.TLSServerName
+
+	if conf.CACert != "" {
+		b, err := ioutil.ReadFile(conf.CACert)
+		if err != nil {
+			return nil, err
+		}
+		tconf.RootCAs = x509.NewCertPool()
+		if !tconf.RootCAs.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificates in %s", conf.CACert)
+		}
+	}
+
+	if conf.ClientCert != "" && conf.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(conf.ClientCert, conf.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		tconf.Certificates = []tls.Certificate{cert}
+	}
+
+	return tconf, nil
+}
+
+// runningAlloc returns the first running allocation of the stack job
+// matching the filter or nil if there is none
+func (orch *nomadOrchestrator) runningAlloc(stack *thrapb.Stack, match func(*nomad.Allocation) bool) (*nomad.Allocation, error) {
+	q := &nomad.QueryOptions{}
+	stubs, _, err := orch.client.Jobs().Allocations(stack.ID, false, q)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stub := range stubs {
+		if stub.ClientStatus != "running" {
+			continue
+		}
+
+		alloc, _, err := orch.client.Allocations().Info(stub.ID, q)
+		if err != nil {
+			return nil, err
+		}
+		if match(alloc) {
+			return alloc, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,91 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package orchestrator
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func Test_nomadExec(t *testing.T) {
+	var (
+		size  *nomadExecSize
+		stdin bytes.Buffer
+	)
+
+	srv := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		for {
+			var in nomadExecInput
+			if err := websocket.JSON.Receive(conn, &in); err != nil {
+				return
+			}
+			if in.TTYSize != nil {
+				size = in.TTYSize
+			}
+			if in.Stdin == nil {
+				continue
+			}
+			stdin.Write(in.Stdin.Data)
+			if !in.Stdin.Close {
+				continue
+			}
+
+			websocket.JSON.Send(conn, &nomadExecOutput{Stdout: &nomadExecData{Data: stdin.Bytes()}})
+			websocket.JSON.Send(conn, &nomadExecOutput{Stderr: &nomadExecData{Data: []byte("warn")}})
+			websocket.JSON.Send(conn, map[string]interface{}{
+				"exited": true,
+				"result": map[string]int{"exit_code": 3},
+			})
+			return
+		}
+	}))
+	defer srv.Close()
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), "", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var stdout, stderr bytes.Buffer
+	req := &ExecRequest{
+		TTY:    true,
+		Height: 24,
+		Width:  80,
+		Stdin:  strings.NewReader("echo hi"),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+
+	code, err := nomadExec(conn, req)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, "echo hi", stdout.String())
+	assert.Equal(t, "warn", stderr.String())
+	assert.Equal(t, &nomadExecSize{Height: 24, Width: 80}, size)
+}
+
+func Test_nomadExec_noExit(t *testing.T) {
+	srv := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		websocket.JSON.Send(conn, &nomadExecOutput{Stdout: &nomadExecData{Data: []byte("x")}})
+	}))
+	defer srv.Close()
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), "", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, err = nomadExec(conn, &ExecRequest{})
+	assert.Equal(t, errExecNoExit, err)
+}
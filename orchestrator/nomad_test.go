@@ -13,6 +13,7 @@ import (
 	"os"
 	"testing"
 
+	nomad "github.com/hashicorp/nomad/api"
 	"github.com/sniperkit/snk.fork.thrap/manifest"
 	"github.com/stretchr/testify/assert"
 )
@@ -34,7 +35,7 @@ func Test_nomad_dryrun(t *testing.T) {
 	orch, err := New(conf)
 	assert.Nil(t, err)
 
-	st, err := manifest.LoadManifest("../thrap.yml")
+	st, _, err := manifest.LoadManifest("../thrap.yml")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -50,3 +51,28 @@ func Test_nomad_dryrun(t *testing.T) {
 	b, _ := json.MarshalIndent(ijob, "", "  ")
 	fmt.Printf("%s\n", b)
 }
+
+func Test_nomadDatastoreStage(t *testing.T) {
+	njob := nomad.NewServiceJob("st", "st", "global", 50)
+	njob.AddTaskGroup(nomad.NewTaskGroup("st.db", 1))
+	njob.AddTaskGroup(nomad.NewTaskGroup("st.0", 1))
+
+	stage := nomadDatastoreStage(njob, nil, "st.db")
+	assert.Equal(t, 1, len(stage.TaskGroups))
+	assert.Equal(t, "st.db", *stage.TaskGroups[0].Name)
+	// The new job is left as is
+	assert.Equal(t, 2, len(njob.TaskGroups))
+
+	current := nomad.NewServiceJob("st", "st", "global", 50)
+	current.AddTaskGroup(nomad.NewTaskGroup("st.db", 1))
+	current.AddTaskGroup(nomad.NewTaskGroup("st.0", 2))
+
+	stage = nomadDatastoreStage(njob, current, "st.db")
+	assert.Equal(t, 2, len(stage.TaskGroups))
+	assert.Equal(t, njob.TaskGroups[0], stage.TaskGroups[0])
+	assert.Equal(t, current.TaskGroups[1], stage.TaskGroups[1])
+
+	njob = nomad.NewServiceJob("st", "st", "global", 50)
+	njob.AddTaskGroup(nomad.NewTaskGroup("st.0", 1))
+	assert.Nil(t, nomadDatastoreStage(njob, current, "st.db"))
+}
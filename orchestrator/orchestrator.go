@@ -21,6 +21,10 @@ type RequestOptions struct {
 	Dryrun bool
 	// Progress output
 	Output io.Writer
+	// If true host ports are allocated by the orchestrator rather than
+	// matching the component ports, allowing the same stack to be deployed
+	// more than once on a host
+	DynamicPorts bool
 }
 
 // Config holds the config used to init the orchestrator
@@ -45,6 +49,17 @@ type Orchestrator interface {
 	Destroy(ctx context.Context, stack *thrapb.Stack) []*thrapb.ActionResult
 	// Status of all comps
 	Status(ctx context.Context, stack *thrapb.Stack) []*thrapb.CompStatus
+
+	// Logs sends log lines of the requested components to the channel. If
+	// following it returns once the context is cancelled
+	Logs(ctx context.Context, stack *thrapb.Stack, opts LogOptions, lines chan<- *LogLine) error
+
+	// Exec runs a command inside a running component returning the exit code
+	Exec(ctx context.Context, stack *thrapb.Stack, compID string, req *ExecRequest) (int, error)
+
+	// PortAddr returns the network address a port of a running component,
+	// referenced by its label, is reachable at
+	PortAddr(ctx context.Context, stack *thrapb.Stack, compID, label string) (string, error)
 }
 
 // New returns a new orchestrator based on the given config
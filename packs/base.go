@@ -24,9 +24,11 @@ type BasePacks struct {
 	packs map[string]*BasePack
 }
 
-func NewBasePacks(dir string) *BasePacks {
+// NewBasePacks returns a new BasePacks with the directories containing the
+// pack data in priority order
+func NewBasePacks(dirs ...string) *BasePacks {
 	return &BasePacks{
-		basePackSet: &basePackSet{"web", dir},
+		basePackSet: &basePackSet{"web", dirs},
 		packs:       make(map[string]*BasePack),
 	}
 }
@@ -35,7 +37,7 @@ func (packs *BasePacks) Load(packID string) (*BasePack, error) {
 	if val, ok := packs.packs[packID]; ok {
 		return val, nil
 	}
-	pack, err := LoadBasePack(packID, packs.dir)
+	pack, err := LoadBasePack(packID, packs.dir(packID))
 	if err == nil {
 		packs.packs[packID] = pack
 	}
@@ -36,10 +36,11 @@ type DevPacks struct {
 	packs map[string]*DevPack
 }
 
-// NewDevPacks returns a new DevPack with the directory containing the pack data
-func NewDevPacks(dir string) *DevPacks {
+// NewDevPacks returns a new DevPack with the directories containing the pack
+// data in priority order
+func NewDevPacks(dirs ...string) *DevPacks {
 	return &DevPacks{
-		basePackSet: &basePackSet{"dev", dir},
+		basePackSet: &basePackSet{"dev", dirs},
 		packs:       make(map[string]*DevPack),
 	}
 }
@@ -54,7 +55,7 @@ func (packs *DevPacks) Load(packID string) (*DevPack, error) {
 		return val, nil
 	}
 
-	pack, err := LoadDevPack(packID, packs.dir)
+	pack, err := LoadDevPack(packID, packs.dir(packID))
 	if err == nil {
 		packs.packs[packID] = pack
 	}
@@ -154,6 +155,25 @@ func (lp *DevPack) Dockerfile(svars scope.Variables) (*dockerfile.Dockerfile, er
 
 }
 
+// SupportsVersion returns true if the language version satisfies any of the
+// version constraints of the pack or if it has none
+func (lp *DevPack) SupportsVersion(ver string) bool {
+	if len(lp.vc) == 0 {
+		return true
+	}
+
+	v, err := version.NewVersion(ver)
+	if err != nil {
+		return false
+	}
+	for _, c := range lp.vc {
+		if c.Check(v) {
+			return true
+		}
+	}
+	return false
+}
+
 // setVersionContraints sets the language version constraints from the provided
 // input versions at time of initialization
 func (lp *DevPack) setVersionContraints() error {
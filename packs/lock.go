@@ -0,0 +1,323 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package packs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	git "gopkg.in/src-d/go-git.v4"
+)
+
+var (
+	errLockMismatch      = errors.New("pack source changed since locked. Update the project lock")
+	errLockSourceMissing = errors.New("locked pack source not installed")
+)
+
+// Lock pins the revision and file digests of each pack source a project is
+// scaffolded from
+type Lock struct {
+	Sources map[string]*LockedSource `json:"sources"`
+}
+
+// LockedSource is the locked state of a single pack source
+type LockedSource struct {
+	Type SourceType `json:"type"`
+	URL  string     `json:"url,omitempty"`
+	// Git commit or archive checksum.  Empty for local directories
+	Revision string `json:"revision,omitempty"`
+	// Slash separated path relative to the source to the sha256 of the file
+	Files map[string]string `json:"files"`
+}
+
+// ReadLock reads the lock file
+func ReadLock(fpath string) (*Lock, error) {
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock Lock
+	if err = json.Unmarshal(b, &lock); err == nil && lock.Sources == nil {
+		lock.Sources = make(map[string]*LockedSource)
+	}
+	return &lock, err
+}
+
+// Marshal returns the json encoded lock
+func (lock *Lock) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(lock, "", "  ")
+	return append(b, '\n'), err
+}
+
+// all returns the layers followed by the default packs
+func (packs *Packs) all() []*layer {
+	def := &layer{name: DefaultSource, src: &Source{Type: SourceGit}, dir: packs.dir, version: packs.version}
+	if repo, err := git.PlainOpen(packs.dir); err == nil {
+		if remote, err := repo.Remote("origin"); err == nil && len(remote.Config().URLs) > 0 {
+			def.src.URL = remote.Config().URLs[0]
+		}
+	}
+	return append(append([]*layer{}, packs.layers...), def)
+}
+
+// Lock returns a lock of the current revision and files of every installed
+// pack source
+func (packs *Packs) Lock() (*Lock, error) {
+	lock := &Lock{Sources: make(map[string]*LockedSource)}
+
+	for _, l := range packs.all() {
+		if !utils.FileExists(l.dir) {
+			continue
+		}
+
+		ls := &LockedSource{Type: l.src.Type, URL: l.src.URL}
+		switch l.src.Type {
+		case SourceGit:
+			rev, err := packs.layerVersion(l)
+			if err != nil {
+				return nil, errors.Wrap(err, l.name)
+			}
+			ls.Revision = rev
+		case SourceArchive, SourceOCI:
+			ls.Revision = l.src.revision()
+		}
+
+		files, err := digestDir(l.dir)
+		if err != nil {
+			return nil, err
+		}
+		ls.Files = files
+		lock.Sources[l.name] = ls
+	}
+
+	return lock, nil
+}
+
+func (packs *Packs) layerVersion(l *layer) (string, error) {
+	if l.version != "" {
+		return l.version, nil
+	}
+	return repoVersion(l.dir)
+}
+
+// Locked returns the packs at the revisions of the lock.  Git sources at
+// another revision are written to dir.  Other sources must match the lock
+// as their previous contents are not available.  Installed sources not in
+// the lock are not used
+func (packs *Packs) Locked(lock *Lock, dir string) (*Packs, error) {
+	out := &Packs{dir: filepath.Join(dir, DefaultSource)}
+	found := make(map[string]bool, len(lock.Sources))
+
+	for _, l := range packs.all() {
+		ls, ok := lock.Sources[l.name]
+		if !ok || !utils.FileExists(l.dir) {
+			continue
+		}
+		found[l.name] = true
+
+		ldir, err := packs.lockedDir(l, ls, filepath.Join(dir, l.name))
+		if err != nil {
+			return nil, errors.Wrap(err, l.name)
+		}
+
+		ll := &layer{name: l.name, src: l.src, dir: ldir}
+		if l.src.Type == SourceGit {
+			ll.version = ls.Revision
+		}
+		if l.name == DefaultSource {
+			out.dir, out.version = ll.dir, ll.version
+		} else {
+			out.layers = append(out.layers, ll)
+		}
+	}
+
+	for name := range lock.Sources {
+		if !found[name] {
+			return nil, errors.Wrap(errLockSourceMissing, name)
+		}
+	}
+
+	return out, nil
+}
+
+// LockedDirs returns the directory with the locked contents of each source
+// that can be restored.  Git sources at another revision are written to dir.
+// Other sources are only included if they still match the lock
+func (packs *Packs) LockedDirs(lock *Lock, dir string) map[string]string {
+	out := make(map[string]string, len(lock.Sources))
+	for _, l := range packs.all() {
+		ls, ok := lock.Sources[l.name]
+		if !ok || !utils.FileExists(l.dir) {
+			continue
+		}
+		if ldir, err := packs.lockedDir(l, ls, filepath.Join(dir, l.name)); err == nil {
+			out[l.name] = ldir
+		}
+	}
+	return out
+}
+
+// lockedDir returns the directory with the contents of the layer at the
+// locked revision
+func (packs *Packs) lockedDir(l *layer, ls *LockedSource, dir string) (string, error) {
+	if l.src.Type == SourceGit {
+		current, err := packs.layerVersion(l)
+		if err != nil || current == ls.Revision {
+			return l.dir, err
+		}
+		return dir, snapshotRepo(l.dir, ls.Revision, dir)
+	}
+
+	files, err := digestDir(l.dir)
+	if err != nil {
+		return "", err
+	}
+	if !equalDigests(files, ls.Files) {
+		return "", errLockMismatch
+	}
+	return l.dir, nil
+}
+
+// LockChange is a pack file that differs between two locks
+type LockChange struct {
+	Source string
+	// Slash separated path relative to the source
+	Path string
+	// A, M or D
+	Status string
+}
+
+// DiffLocks returns the files added, modified and removed in each source
+// from one lock to another sorted by source and path
+func DiffLocks(from, to *Lock) []*LockChange {
+	var out []*LockChange
+
+	names := make(map[string]bool)
+	for name := range from.Sources {
+		names[name] = true
+	}
+	for name := range to.Sources {
+		names[name] = true
+	}
+
+	for name := range names {
+		var of, nf map[string]string
+		if ls, ok := from.Sources[name]; ok {
+			of = ls.Files
+		}
+		if ls, ok := to.Sources[name]; ok {
+			nf = ls.Files
+		}
+
+		for p, d := range nf {
+			od, ok := of[p]
+			switch {
+			case !ok:
+				out = append(out, &LockChange{Source: name, Path: p, Status: "A"})
+			case od != d:
+				out = append(out, &LockChange{Source: name, Path: p, Status: "M"})
+			}
+		}
+		for p := range of {
+			if _, ok := nf[p]; !ok {
+				out = append(out, &LockChange{Source: name, Path: p, Status: "D"})
+			}
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Source != out[j].Source {
+			return out[i].Source < out[j].Source
+		}
+		return out[i].Path < out[j].Path
+	})
+	return out
+}
+
+// SourceDir returns the directory of the named installed source
+func (packs *Packs) SourceDir(name string) (string, bool) {
+	for _, l := range packs.all() {
+		if l.name == name {
+			return l.dir, true
+		}
+	}
+	return "", false
+}
+
+// dirRevision returns the sha256 of the digests of the files under dir
+func dirRevision(dir string) (string, error) {
+	files, err := digestDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	for _, p := range paths {
+		io.WriteString(h, p+" "+files[p]+"\n")
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// digestDir returns the sha256 of each file under dir keyed by the slash
+// separated relative path.  Git metadata is skipped
+func digestDir(dir string) (map[string]string, error) {
+	out := make(map[string]string)
+	err := filepath.Walk(dir, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		b, err := ioutil.ReadFile(fpath)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, fpath)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(b)
+		out[filepath.ToSlash(rel)] = hex.EncodeToString(sum[:])
+		return nil
+	})
+	return out, err
+}
+
+func equalDigests(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package packs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func Test_Packs_Lock(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "packlock-")
+	defer os.RemoveAll(tmpdir)
+
+	packdir := filepath.Join(tmpdir, "packs")
+	repo, err := git.PlainInit(packdir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, _ := repo.Worktree()
+
+	commit := func(content string) {
+		fpath := filepath.Join(packdir, "dev", "go", "Dockerfile")
+		os.MkdirAll(filepath.Dir(fpath), 0755)
+		ioutil.WriteFile(fpath, []byte(content), 0644)
+		wt.Add("dev/go/Dockerfile")
+		_, err := wt.Commit(content, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	teamdir := filepath.Join(tmpdir, "team")
+	os.MkdirAll(filepath.Join(teamdir, "web", "nginx"), 0755)
+	ioutil.WriteFile(filepath.Join(teamdir, "web", "nginx", "manifest.hcl"), []byte("nginx"), 0644)
+
+	commit("FROM golang:1.10\n")
+
+	p, _ := New(packdir)
+	p.AddLayer("team", &Source{Type: SourceDir, URL: teamdir}, teamdir)
+
+	lock, err := p.Lock()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(lock.Sources))
+	first, _ := p.Version()
+	assert.Equal(t, first, lock.Sources[DefaultSource].Revision)
+	assert.Equal(t, []string{"dev/go/Dockerfile"}, keys(lock.Sources[DefaultSource].Files))
+	assert.Equal(t, []string{"web/nginx/manifest.hcl"}, keys(lock.Sources["team"].Files))
+
+	b, err := lock.Marshal()
+	assert.Nil(t, err)
+	lockfile := filepath.Join(tmpdir, "packs.lock")
+	ioutil.WriteFile(lockfile, b, 0644)
+	read, err := ReadLock(lockfile)
+	assert.Nil(t, err)
+	assert.Equal(t, lock, read)
+
+	commit("FROM golang:1.11\n")
+
+	// Locked to the first revision
+	locked, err := p.Locked(lock, filepath.Join(tmpdir, "locked"))
+	assert.Nil(t, err)
+	ver, _ := locked.Version()
+	assert.Equal(t, first, ver)
+	b, _ = ioutil.ReadFile(filepath.Join(locked.Dir(), "dev", "go", "Dockerfile"))
+	assert.Equal(t, "FROM golang:1.10\n", string(b))
+	assert.Equal(t, []string{teamdir}, locked.Layers())
+
+	updated, _ := p.Lock()
+	changes := DiffLocks(lock, updated)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, &LockChange{Source: DefaultSource, Path: "dev/go/Dockerfile", Status: "M"}, changes[0])
+
+	dirs := p.LockedDirs(lock, filepath.Join(tmpdir, "dirs"))
+	b, _ = ioutil.ReadFile(filepath.Join(dirs[DefaultSource], "dev", "go", "Dockerfile"))
+	assert.Equal(t, "FROM golang:1.10\n", string(b))
+
+	// Local sources can not be restored
+	os.Remove(filepath.Join(teamdir, "web", "nginx", "manifest.hcl"))
+	_, err = p.Locked(lock, filepath.Join(tmpdir, "locked2"))
+	assert.Equal(t, errLockMismatch, errors.Cause(err))
+	_, ok := p.LockedDirs(lock, filepath.Join(tmpdir, "dirs2"))["team"]
+	assert.False(t, ok)
+
+	updated, _ = p.Lock()
+	changes = DiffLocks(lock, updated)
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, &LockChange{Source: "team", Path: "web/nginx/manifest.hcl", Status: "D"}, changes[1])
+
+	// Locked sources must be installed
+	p2, _ := New(packdir)
+	_, err = p2.Locked(lock, filepath.Join(tmpdir, "locked3"))
+	assert.Equal(t, errLockSourceMissing, errors.Cause(err))
+}
+
+func keys(m map[string]string) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
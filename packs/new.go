@@ -0,0 +1,105 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package packs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+)
+
+var errPackExists = errors.New("pack exists")
+
+var devPackTemplate = map[string]string{
+	packManfiestFile: `# Language version constraints supported by the pack
+Versions = [">= 1.0"]
+
+# Version used when a component does not specify one
+DefaultVersion = "1.0"
+
+# Source file extensions.  The first is the primary one
+FileExts = [".{{id}}"]
+
+# Build outputs excluded from the image build context
+IgnoreFiles = []
+
+# Base images to develop and publish with
+DevImages = ["{{id}}"]
+PubImages = ["{{id}}"]
+
+# Files rendered into a project.  One of them must be the dockerfile
+ScaffoldFiles = ["Dockerfile"]
+`,
+	"Dockerfile": `# Rendered for each component using the pack.  Available variables are
+# lang.id, lang.version, stack.* and vcs.*
+FROM {{id}}:${lang.version}
+
+WORKDIR /app
+COPY . .
+
+CMD ["{{id}}", "--version"]
+`,
+}
+
+var basePackTemplate = map[string]string{
+	packManfiestFile: `# Image of the component
+Image = "{{id}}"
+
+# Image version constraints supported by the pack
+Versions = [">= 1.0"]
+
+# Version used when a component does not specify one
+DefaultVersion = "1.0"
+
+# Additional files part of the pack
+Files = []
+`,
+}
+
+// NewPack scaffolds a new pack of the type with the id in dir i.e. dir/dev/go.
+// It returns the paths of the files created
+func NewPack(dir, typ, id string) ([]string, error) {
+	if id == "" {
+		return nil, errPackIDRequired
+	}
+
+	var tmpl map[string]string
+	switch typ {
+	case devPackID:
+		tmpl = devPackTemplate
+	case webPackID, dsPackID:
+		tmpl = basePackTemplate
+	default:
+		return nil, errors.Wrap(errPackTypeUnknown, typ)
+	}
+
+	pdir := filepath.Join(dir, typ, id)
+	if utils.FileExists(pdir) {
+		return nil, errors.Wrap(errPackExists, pdir)
+	}
+	if err := os.MkdirAll(pdir, 0755); err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(tmpl))
+	for name, content := range tmpl {
+		fpath := filepath.Join(pdir, name)
+		content = strings.Replace(content, "{{id}}", id, -1)
+		if err := ioutil.WriteFile(fpath, []byte(content), 0644); err != nil {
+			return nil, err
+		}
+		out = append(out, fpath)
+	}
+	sort.Strings(out)
+
+	return out, nil
+}
@@ -0,0 +1,203 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package packs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const ociScheme = "oci://"
+
+// Accepted manifest media types.  Packs are pushed as a single layer artifact
+var ociManifestTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+type ociManifest struct {
+	Layers []struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+	} `json:"layers"`
+}
+
+// parseOCIReference parses oci://host/repo:tag or oci://host/repo@digest
+// into its host, repo and tag or digest.  The tag defaults to latest
+func parseOCIReference(ref string) (host, repo, tag string, err error) {
+	if !strings.HasPrefix(ref, ociScheme) {
+		err = errors.Wrap(errOCIReferenceInvalid, ref)
+		return
+	}
+
+	s := strings.TrimPrefix(ref, ociScheme)
+	i := strings.Index(s, "/")
+	if i <= 0 || i == len(s)-1 {
+		err = errors.Wrap(errOCIReferenceInvalid, ref)
+		return
+	}
+	host, repo = s[:i], s[i+1:]
+
+	if i = strings.Index(repo, "@"); i > 0 {
+		repo, tag = repo[:i], repo[i+1:]
+	} else if i = strings.LastIndex(repo, ":"); i > 0 {
+		repo, tag = repo[:i], repo[i+1:]
+	} else {
+		tag = "latest"
+	}
+
+	if repo == "" || tag == "" {
+		err = errors.Wrap(errOCIReferenceInvalid, ref)
+	}
+	return
+}
+
+// fetchOCI downloads the first layer of the artifact verifying it against
+// its digest and the source checksum if set
+func (src *Source) fetchOCI(ctx context.Context) ([]byte, error) {
+	host, repo, tag, err := parseOCIReference(src.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	reg := &ociRegistry{base: "https://" + host, repo: repo}
+
+	header := http.Header{"Accept": ociManifestTypes}
+	b, err := reg.get(ctx, "/manifests/"+tag, header)
+	if err != nil {
+		return nil, err
+	}
+	// References pinned by digest are verified against it
+	if strings.HasPrefix(tag, "sha256:") {
+		if err = verifyChecksum(b, tag); err != nil {
+			return nil, err
+		}
+	}
+
+	var manifest ociManifest
+	if err = json.Unmarshal(b, &manifest); err != nil {
+		return nil, err
+	}
+	if len(manifest.Layers) == 0 {
+		return nil, errOCIManifestNoLayers
+	}
+
+	digest := manifest.Layers[0].Digest
+	if src.Checksum != "" && src.Checksum != digest {
+		return nil, errors.Wrap(errChecksumMismatch, digest)
+	}
+
+	if b, err = reg.get(ctx, "/blobs/"+digest, nil); err != nil {
+		return nil, err
+	}
+	return b, verifyChecksum(b, digest)
+}
+
+// ociRegistry is a minimal registry v2 client supporting anonymous bearer
+// tokens
+type ociRegistry struct {
+	base  string
+	repo  string
+	token string
+}
+
+func (reg *ociRegistry) get(ctx context.Context, path string, header http.Header) ([]byte, error) {
+	u := reg.base + "/v2/" + reg.repo + path
+	if header == nil {
+		header = make(http.Header)
+	}
+	if reg.token != "" {
+		header.Set("Authorization", "Bearer "+reg.token)
+	}
+
+	b, resp, err := httpDo(ctx, u, header)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized || reg.token != "" {
+		return b, err
+	}
+
+	if reg.token, err = reg.login(ctx, resp.Header.Get("WWW-Authenticate")); err != nil {
+		return nil, err
+	}
+	header.Set("Authorization", "Bearer "+reg.token)
+	return httpGet(ctx, u, header)
+}
+
+// login requests an anonymous token from the realm of the bearer challenge
+func (reg *ociRegistry) login(ctx context.Context, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", errOCIAuthNotSupported
+	}
+
+	params := parseChallenge(strings.TrimPrefix(challenge, "Bearer "))
+	realm := params["realm"]
+	if realm == "" {
+		return "", errOCIAuthNotSupported
+	}
+
+	q := url.Values{}
+	if s := params["service"]; s != "" {
+		q.Set("service", s)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + reg.repo + ":pull"
+	}
+	q.Set("scope", scope)
+
+	b, err := httpGet(ctx, realm+"?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.Unmarshal(b, &resp); err != nil {
+		return "", err
+	}
+	if resp.Token != "" {
+		return resp.Token, nil
+	}
+	return resp.AccessToken, nil
+}
+
+// parseChallenge parses the comma separated key="value" pairs of an auth
+// challenge
+func parseChallenge(s string) map[string]string {
+	out := make(map[string]string)
+	for len(s) > 0 {
+		i := strings.Index(s, "=")
+		if i < 0 {
+			break
+		}
+		key := strings.TrimSpace(s[:i])
+		s = s[i+1:]
+
+		var val string
+		if strings.HasPrefix(s, `"`) {
+			j := strings.Index(s[1:], `"`)
+			if j < 0 {
+				val, s = s[1:], ""
+			} else {
+				val, s = s[1:j+1], s[j+2:]
+			}
+		} else if j := strings.Index(s, ","); j >= 0 {
+			val, s = s[:j], s[j:]
+		} else {
+			val, s = s, ""
+		}
+		out[key] = val
+		s = strings.TrimLeft(s, ", ")
+	}
+	return out
+}
@@ -11,11 +11,14 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
 	"github.com/sniperkit/snk.fork.thrap/utils"
 	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
 )
 
 const packManfiestFile = "manifest.hcl"
@@ -26,16 +29,34 @@ const (
 	devPackID = "dev"
 )
 
+// ErrSnapshotUnavailable is returned when a previous revision of a source can
+// not be restored.  Only git sources keep their history
+var ErrSnapshotUnavailable = errors.New("pack source revision not available")
+
 var (
 	errPackDirRequired        = errors.New("pack directory required")
 	errPackDirExists          = errors.New("pack directory exists")
 	errPackSourceNotSupported = errors.New("pack source not supported")
 )
 
-// Packs is the interface to access various packs.
+// Packs is the interface to access various packs.  Packs of additional
+// sources are layered over the default packs
 type Packs struct {
 	// local pack path.  /{path}/{to}/packs
 	dir string
+	// packs of additional sources, highest priority first
+	layers []*layer
+	// pinned version of the default packs when not read from the repo
+	version string
+}
+
+// layer is the installed packs of a source
+type layer struct {
+	name string
+	src  *Source
+	dir  string
+	// pinned revision when not read from the repo
+	version string
 }
 
 // New returns a new packs manager
@@ -74,6 +95,31 @@ func (packs *Packs) Dir() string {
 	return packs.dir
 }
 
+// AddLayer layers the packs of the named source installed in dir over the
+// existing ones.  Layers must be added from highest to lowest priority.
+// Packs in layers take precedence over the default packs
+func (packs *Packs) AddLayer(name string, src *Source, dir string) {
+	packs.layers = append(packs.layers, &layer{name: name, src: src, dir: dir})
+}
+
+// Layers returns the pack directories of additional sources in priority order
+func (packs *Packs) Layers() []string {
+	out := make([]string, len(packs.layers))
+	for i, l := range packs.layers {
+		out[i] = l.dir
+	}
+	return out
+}
+
+// dirs returns the directories of the kind of pack in priority order
+func (packs *Packs) dirs(kind string) []string {
+	out := make([]string, 0, len(packs.layers)+1)
+	for _, l := range packs.layers {
+		out = append(out, filepath.Join(l.dir, kind))
+	}
+	return append(out, filepath.Join(packs.dir, kind))
+}
+
 // Load downloads packs from the given git remoteURL
 func (packs *Packs) Load(remoteURL string) error {
 	if utils.FileExists(packs.dir) {
@@ -117,40 +163,192 @@ func (packs *Packs) Update() error {
 	return wt.Pull(opt)
 }
 
+// Version returns the commit of the default packs repo checked out.  Use
+// SourceVersion for packs of other sources
+func (packs *Packs) Version() (string, error) {
+	if packs.version != "" {
+		return packs.version, nil
+	}
+	return repoVersion(packs.dir)
+}
+
+func repoVersion(dir string) (string, error) {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return ref.Hash().String(), nil
+}
+
+// DevSource returns the name of the source the dev pack is loaded from
+func (packs *Packs) DevSource(packID string) string {
+	for _, l := range packs.layers {
+		if utils.FileExists(filepath.Join(l.dir, devPackID, packID)) {
+			return l.name
+		}
+	}
+	return DefaultSource
+}
+
+// SourceVersion returns the revision of the named source.  This is the commit
+// of git sources and the checksum of archives and oci artifacts.  Local
+// directories are identified by the digest of their files
+func (packs *Packs) SourceVersion(name string) (string, error) {
+	for _, l := range packs.all() {
+		if l.name == name {
+			return packs.revision(l)
+		}
+	}
+	return "", errors.Wrap(errLockSourceMissing, name)
+}
+
+func (packs *Packs) revision(l *layer) (string, error) {
+	switch l.src.Type {
+	case SourceGit:
+		return packs.layerVersion(l)
+	case SourceArchive, SourceOCI:
+		return l.src.revision(), nil
+	}
+	return dirRevision(l.dir)
+}
+
+// Snapshot returns the packs with the named source at the given revision.
+// Git sources at another revision are written to dir.  Other sources are only
+// available at their installed revision.  This is used to re-render files of
+// an older pack version
+func (packs *Packs) Snapshot(source, version, dir string) (*Packs, error) {
+	out := &Packs{}
+	found := false
+
+	for _, l := range packs.all() {
+		sl := *l
+		if l.name == source {
+			found = true
+			current, err := packs.revision(l)
+			if err != nil {
+				return nil, err
+			}
+			if current != version {
+				if l.src.Type != SourceGit {
+					return nil, errors.Wrap(ErrSnapshotUnavailable, source)
+				}
+				if err = snapshotRepo(l.dir, version, dir); err != nil {
+					return nil, err
+				}
+				sl.dir, sl.version = dir, version
+			}
+		}
+
+		if l.name == DefaultSource {
+			out.dir, out.version = sl.dir, sl.version
+		} else {
+			out.layers = append(out.layers, &sl)
+		}
+	}
+
+	if !found {
+		return nil, errors.Wrap(errLockSourceMissing, source)
+	}
+	return out, nil
+}
+
+// snapshotRepo writes the files of the repo in repoDir at the commit to dir
+func snapshotRepo(repoDir, version, dir string) error {
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		return err
+	}
+
+	commit, err := repo.CommitObject(plumbing.NewHash(version))
+	if err != nil {
+		return errors.Wrap(err, version)
+	}
+	files, err := commit.Files()
+	if err != nil {
+		return err
+	}
+
+	return files.ForEach(func(f *object.File) error {
+		contents, err := f.Contents()
+		if err != nil {
+			return err
+		}
+
+		fpath := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err = os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(fpath, []byte(contents), 0644)
+	})
+}
+
 // Web returns a web packs manager
 func (packs *Packs) Web() *BasePacks {
-	return NewBasePacks(filepath.Join(packs.dir, webPackID))
+	return NewBasePacks(packs.dirs(webPackID)...)
 }
 
 // Dev returns a dev packs manager
 func (packs *Packs) Dev() *DevPacks {
-	return NewDevPacks(filepath.Join(packs.dir, devPackID))
+	return NewDevPacks(packs.dirs(devPackID)...)
 }
 
 // Datastore returns a datastore packs manager
 func (packs *Packs) Datastore() *BasePacks {
-	return NewBasePacks(filepath.Join(packs.dir, dsPackID))
+	return NewBasePacks(packs.dirs(dsPackID)...)
 }
 
 // packs of a singular kind
 type basePackSet struct {
 	typ string
-	dir string
+	// directories in priority order
+	dirs []string
 }
 
 func (packs *basePackSet) Type() string {
 	return packs.typ
 }
 
+// dir returns the first directory containing the pack.  It defaults to the
+// lowest priority directory
+func (packs *basePackSet) dir(packID string) string {
+	for _, d := range packs.dirs {
+		if utils.FileExists(filepath.Join(d, packID)) {
+			return d
+		}
+	}
+	return packs.dirs[len(packs.dirs)-1]
+}
+
+// List returns the ids of the packs across all directories
 func (packs *basePackSet) List() ([]string, error) {
-	files, err := ioutil.ReadDir(packs.dir)
-	if err == nil {
-		packs := make([]string, 0, len(files))
+	var (
+		seen = make(map[string]bool)
+		out  = make([]string, 0)
+		err  error
+	)
+
+	for _, d := range packs.dirs {
+		var files []os.FileInfo
+		if files, err = ioutil.ReadDir(d); err != nil {
+			continue
+		}
 		for _, f := range files {
-			packs = append(packs, f.Name())
+			if !seen[f.Name()] {
+				seen[f.Name()] = true
+				out = append(out, f.Name())
+			}
 		}
-		return packs, nil
 	}
 
-	return nil, err
+	if len(seen) == 0 && err != nil {
+		return nil, err
+	}
+
+	sort.Strings(out)
+	return out, nil
 }
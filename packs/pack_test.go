@@ -13,9 +13,12 @@ import (
 	"testing"
 
 	homedir "github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
 	"github.com/sniperkit/snk.fork.thrap/utils"
 	"github.com/stretchr/testify/assert"
 	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
 )
 
 type testCasePacksNew struct {
@@ -84,3 +87,85 @@ func Test_Packs_Load(t *testing.T) {
 	err = p.Update()
 	assert.Equal(t, git.NoErrAlreadyUpToDate, err)
 }
+
+func Test_Packs_Snapshot(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "packsnap-")
+	defer os.RemoveAll(tmpdir)
+
+	packdir := filepath.Join(tmpdir, "packs")
+	repo, err := git.PlainInit(packdir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, _ := repo.Worktree()
+
+	commit := func(content string) string {
+		fpath := filepath.Join(packdir, "dev", "go", "Dockerfile")
+		os.MkdirAll(filepath.Dir(fpath), 0755)
+		ioutil.WriteFile(fpath, []byte(content), 0644)
+		wt.Add("dev/go/Dockerfile")
+		h, err := wt.Commit(content, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h.String()
+	}
+
+	first := commit("FROM golang:1.10\n")
+	second := commit("FROM golang:1.11\n")
+
+	p, _ := New(packdir)
+	ver, err := p.Version()
+	assert.Nil(t, err)
+	assert.Equal(t, second, ver)
+
+	snapdir := filepath.Join(tmpdir, "snap")
+	snap, err := p.Snapshot(DefaultSource, first, snapdir)
+	assert.Nil(t, err)
+	assert.Equal(t, snapdir, snap.Dir())
+	ver, _ = snap.Version()
+	assert.Equal(t, first, ver)
+
+	b, err := ioutil.ReadFile(filepath.Join(snapdir, "dev", "go", "Dockerfile"))
+	assert.Nil(t, err)
+	assert.Equal(t, "FROM golang:1.10\n", string(b))
+
+	_, err = p.Snapshot(DefaultSource, "0000000000000000000000000000000000000000", filepath.Join(tmpdir, "bad"))
+	assert.NotNil(t, err)
+
+	// Layered sources are versioned on their own
+	teamdir := filepath.Join(tmpdir, "team")
+	os.MkdirAll(filepath.Join(teamdir, "dev", "rust"), 0755)
+	ioutil.WriteFile(filepath.Join(teamdir, "dev", "rust", "Dockerfile"), []byte("FROM rust\n"), 0644)
+	p.AddLayer("team", &Source{Type: SourceDir, URL: teamdir}, teamdir)
+
+	assert.Equal(t, "team", p.DevSource("rust"))
+	assert.Equal(t, DefaultSource, p.DevSource("go"))
+
+	teamver, err := p.SourceVersion("team")
+	assert.Nil(t, err)
+	assert.NotEqual(t, second, teamver)
+	_, err = p.SourceVersion("missing")
+	assert.Equal(t, errLockSourceMissing, errors.Cause(err))
+
+	snap, err = p.Snapshot("team", teamver, filepath.Join(tmpdir, "teamsnap"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{teamdir}, snap.Layers())
+	assert.Equal(t, packdir, snap.Dir())
+
+	_, err = p.Snapshot("team", "sha256:00", filepath.Join(tmpdir, "teamsnap"))
+	assert.Equal(t, ErrSnapshotUnavailable, errors.Cause(err))
+}
+
+func Test_DevPack_SupportsVersion(t *testing.T) {
+	lp := &DevPack{Language: &thrapb.Language{Versions: []string{">= 1.10, < 2.0"}}}
+	assert.Nil(t, lp.setVersionContraints())
+
+	assert.True(t, lp.SupportsVersion("1.11"))
+	assert.False(t, lp.SupportsVersion("1.9"))
+	assert.False(t, lp.SupportsVersion("latest"))
+
+	assert.True(t, (&DevPack{Language: &thrapb.Language{}}).SupportsVersion("0.1"))
+}
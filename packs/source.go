@@ -0,0 +1,421 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package packs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/euforia/hclencoder"
+	"github.com/hashicorp/hcl"
+	"github.com/pkg/errors"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"github.com/sniperkit/snk.fork.thrap/vcs"
+)
+
+// DefaultSource is the name of the default packs repo.  It is always the
+// lowest priority source
+const DefaultSource = "default"
+
+// SourceType is the type of a pack source
+type SourceType string
+
+const (
+	// SourceGit is a git repo checked out at a pinned ref
+	SourceGit SourceType = "git"
+	// SourceDir is a local directory used in place
+	SourceDir SourceType = "dir"
+	// SourceArchive is a tar.gz file or url verified against a checksum
+	SourceArchive SourceType = "archive"
+	// SourceOCI is an artifact in an OCI registry i.e. oci://host/repo:tag
+	SourceOCI SourceType = "oci"
+)
+
+var (
+	errSourceNameReserved   = errors.New("source name reserved")
+	errSourceTypeUnknown    = errors.New("unknown source type")
+	errSourceURLRequired    = errors.New("source url required")
+	errChecksumRequired     = errors.New("checksum required")
+	errOCIChecksumRequired  = errors.New("checksum required unless the oci reference is pinned by @sha256 digest")
+	errChecksumMismatch     = errors.New("checksum mismatch")
+	errArchivePathInvalid   = errors.New("archive path outside of destination")
+	errOCIReferenceInvalid  = errors.New("invalid oci reference")
+	errOCIManifestNoLayers  = errors.New("oci manifest has no layers")
+	errOCIAuthNotSupported  = errors.New("oci registry auth not supported")
+	errSourceDirNotDir      = errors.New("source path not a directory")
+	errSourceStatusNotOK    = errors.New("unexpected response status")
+	errSourceChecksumFormat = errors.New("checksum must be sha256:<hex>")
+)
+
+// httpClient is used to download archives and oci artifacts
+var httpClient = http.DefaultClient
+
+// Source is a named location packs are installed from.  Sources with a higher
+// priority are layered over those with a lower one
+type Source struct {
+	Type SourceType `hcl:"type"`
+	// Git url, local path, archive path or url, or oci reference
+	URL string `hcl:"url"`
+	// Git ref to check out
+	Ref string `hcl:"ref" hcle:"omitempty"`
+	// sha256:<hex> checksum of an archive or oci layer
+	Checksum string `hcl:"checksum" hcle:"omitempty"`
+	Priority int    `hcl:"priority"`
+}
+
+// Validate checks the source has the required fields for its type
+func (src *Source) Validate() error {
+	if src.URL == "" {
+		return errSourceURLRequired
+	}
+
+	switch src.Type {
+	case SourceGit, SourceDir:
+	case SourceArchive:
+		if src.Checksum == "" {
+			return errChecksumRequired
+		}
+	case SourceOCI:
+		if _, _, _, err := parseOCIReference(src.URL); err != nil {
+			return err
+		}
+		if src.revision() == "" {
+			return errOCIChecksumRequired
+		}
+	default:
+		return errors.Wrap(errSourceTypeUnknown, string(src.Type))
+	}
+
+	if src.Checksum != "" {
+		if _, err := parseChecksum(src.Checksum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revision returns the checksum pinning the contents of an archive or oci
+// source.  oci references pinned by digest are identified by the digest
+func (src *Source) revision() string {
+	if src.Checksum != "" || src.Type != SourceOCI {
+		return src.Checksum
+	}
+	if _, _, tag, err := parseOCIReference(src.URL); err == nil && strings.HasPrefix(tag, "sha256:") {
+		return tag
+	}
+	return ""
+}
+
+// Path returns the directory packs of the named source are read from.  Local
+// directories are used in place, others are installed under root
+func (src *Source) Path(root, name string) string {
+	if src.Type == SourceDir {
+		return src.URL
+	}
+	return filepath.Join(root, name)
+}
+
+// Install installs or updates the packs of the source into dir
+func (src *Source) Install(ctx context.Context, dir string) error {
+	switch src.Type {
+	case SourceGit:
+		_, _, err := vcs.SyncSource(ctx, dir, src.URL, src.Ref, "")
+		return err
+
+	case SourceDir:
+		stat, err := os.Stat(dir)
+		if err == nil && !stat.IsDir() {
+			err = errSourceDirNotDir
+		}
+		return err
+
+	case SourceArchive:
+		b, err := src.fetchArchive(ctx)
+		if err != nil {
+			return err
+		}
+		return installArchive(b, dir)
+
+	case SourceOCI:
+		b, err := src.fetchOCI(ctx)
+		if err != nil {
+			return err
+		}
+		return installArchive(b, dir)
+
+	}
+
+	return errors.Wrap(errSourceTypeUnknown, string(src.Type))
+}
+
+// fetchArchive reads the archive from a url or local file and verifies its
+// checksum
+func (src *Source) fetchArchive(ctx context.Context) ([]byte, error) {
+	var (
+		b   []byte
+		err error
+	)
+
+	if strings.HasPrefix(src.URL, "http://") || strings.HasPrefix(src.URL, "https://") {
+		b, err = httpGet(ctx, src.URL, nil)
+	} else {
+		var fpath string
+		if fpath, err = utils.GetAbsPath(src.URL); err == nil {
+			b, err = ioutil.ReadFile(fpath)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return b, verifyChecksum(b, src.Checksum)
+}
+
+// Sources are the configured pack sources keyed by name
+type Sources struct {
+	Sources map[string]*Source `hcl:"source"`
+}
+
+// ReadSources reads the sources file.  No sources are returned if the file
+// does not exist
+func ReadSources(fpath string) (*Sources, error) {
+	srcs := &Sources{Sources: make(map[string]*Source)}
+
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return srcs, nil
+		}
+		return nil, err
+	}
+
+	if err = hcl.Unmarshal(b, srcs); err == nil && srcs.Sources == nil {
+		srcs.Sources = make(map[string]*Source)
+	}
+	return srcs, err
+}
+
+// Write writes the sources to the file
+func (srcs *Sources) Write(fpath string) error {
+	b, err := hclencoder.Encode(srcs)
+	if err == nil {
+		err = ioutil.WriteFile(fpath, b, 0644)
+	}
+	return err
+}
+
+// Add validates and adds the named source replacing an existing one
+func (srcs *Sources) Add(name string, src *Source) error {
+	if name == DefaultSource {
+		return errors.Wrap(errSourceNameReserved, name)
+	}
+	if err := src.Validate(); err != nil {
+		return err
+	}
+	srcs.Sources[name] = src
+	return nil
+}
+
+// Names returns the source names from highest to lowest priority.  Sources of
+// the same priority are ordered by name
+func (srcs *Sources) Names() []string {
+	names := make([]string, 0, len(srcs.Sources))
+	for name := range srcs.Sources {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		pi, pj := srcs.Sources[names[i]].Priority, srcs.Sources[names[j]].Priority
+		if pi != pj {
+			return pi > pj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// NewLayered returns the packs in dir with the installed sources configured
+// in sourcesFile layered over them.  Sources are installed under sourcesDir
+func NewLayered(dir, sourcesFile, sourcesDir string) (*Packs, *Sources, error) {
+	pks, err := New(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	srcs, err := ReadSources(sourcesFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, name := range srcs.Names() {
+		src := srcs.Sources[name]
+		if dir := src.Path(sourcesDir, name); utils.FileExists(dir) {
+			pks.AddLayer(name, src, dir)
+		}
+	}
+	return pks, srcs, nil
+}
+
+func parseChecksum(sum string) (string, error) {
+	if !strings.HasPrefix(sum, "sha256:") {
+		return "", errSourceChecksumFormat
+	}
+
+	h := strings.ToLower(strings.TrimPrefix(sum, "sha256:"))
+	if b, err := hex.DecodeString(h); err != nil || len(b) != sha256.Size {
+		return "", errSourceChecksumFormat
+	}
+	return h, nil
+}
+
+func verifyChecksum(b []byte, sum string) error {
+	want, err := parseChecksum(sum)
+	if err != nil {
+		return err
+	}
+
+	got := sha256.Sum256(b)
+	if hex.EncodeToString(got[:]) != want {
+		return errors.Wrap(errChecksumMismatch, "sha256:"+hex.EncodeToString(got[:]))
+	}
+	return nil
+}
+
+func httpGet(ctx context.Context, u string, header http.Header) ([]byte, error) {
+	b, _, err := httpDo(ctx, u, header)
+	return b, err
+}
+
+// httpDo performs a GET returning the body on a 200.  The response is
+// returned on other statuses for the caller to inspect
+func httpDo(ctx context.Context, u string, header http.Header) ([]byte, *http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp, fmt.Errorf("%s: %v %s", u, errSourceStatusNotOK, resp.Status)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	return b, resp, err
+}
+
+// installArchive extracts the gzipped or plain tar archive replacing the
+// contents of dir.  A single top level directory in the archive is stripped
+func installArchive(b []byte, dir string) error {
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+
+	tmpdir, err := ioutil.TempDir(filepath.Dir(dir), "."+filepath.Base(dir)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpdir)
+
+	if err = extractTar(b, tmpdir); err != nil {
+		return err
+	}
+
+	root := tmpdir
+	files, err := ioutil.ReadDir(tmpdir)
+	if err != nil {
+		return err
+	}
+	if len(files) == 1 && files[0].IsDir() && !isPackKind(files[0].Name()) {
+		root = filepath.Join(tmpdir, files[0].Name())
+	}
+
+	if err = os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.Rename(root, dir)
+}
+
+func isPackKind(name string) bool {
+	return name == webPackID || name == devPackID || name == dsPackID
+}
+
+func extractTar(b []byte, dir string) error {
+	var r io.Reader = bytes.NewReader(b)
+	if len(b) > 1 && b[0] == 0x1f && b[1] == 0x8b {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		fpath := filepath.Join(dir, hdr.Name)
+		if fpath != dir && !strings.HasPrefix(fpath, dir+string(filepath.Separator)) {
+			return errors.Wrap(errArchivePathInvalid, hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(fpath, 0755)
+
+		case tar.TypeReg, tar.TypeRegA:
+			err = writeTarFile(tr, fpath, os.FileMode(hdr.Mode).Perm())
+
+		}
+		// Links and special files are not needed by packs
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func writeTarFile(r io.Reader, fpath string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+		return err
+	}
+
+	fh, err := os.OpenFile(fpath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode|0600)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(fh, r); err != nil {
+		fh.Close()
+		return err
+	}
+	return fh.Close()
+}
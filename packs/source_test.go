@@ -0,0 +1,220 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package packs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sniperkit/snk.fork.thrap/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func testTarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func testChecksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func Test_Packs_layers(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("", "packlayers-")
+	defer os.RemoveAll(tmpdir)
+
+	def := filepath.Join(tmpdir, "default")
+	private := filepath.Join(tmpdir, "private")
+	os.MkdirAll(filepath.Join(def, "dev", "go"), 0755)
+	os.MkdirAll(filepath.Join(def, "dev", "python"), 0755)
+	os.MkdirAll(filepath.Join(private, "dev", "go"), 0755)
+	os.MkdirAll(filepath.Join(private, "dev", "rust"), 0755)
+
+	pks, _ := New(def)
+	pks.AddLayer("private", &Source{Type: SourceDir, URL: private}, private)
+
+	dev := pks.Dev()
+	list, err := dev.List()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"go", "python", "rust"}, list)
+
+	assert.Equal(t, filepath.Join(private, "dev"), dev.dir("go"))
+	assert.Equal(t, filepath.Join(def, "dev"), dev.dir("python"))
+	assert.Equal(t, filepath.Join(def, "dev"), dev.dir("missing"))
+
+	// Missing kinds are skipped
+	list, err = pks.Web().List()
+	assert.NotNil(t, err)
+	assert.Nil(t, list)
+}
+
+func Test_Sources_Names(t *testing.T) {
+	srcs := &Sources{Sources: map[string]*Source{
+		"public":  &Source{Type: SourceGit, URL: "https://example.com/packs.git"},
+		"team":    &Source{Type: SourceDir, URL: "/opt/packs", Priority: 10},
+		"company": &Source{Type: SourceDir, URL: "/srv/packs", Priority: 10},
+	}}
+	assert.Equal(t, []string{"company", "team", "public"}, srcs.Names())
+
+	err := srcs.Add(DefaultSource, &Source{Type: SourceDir, URL: "/tmp"})
+	assert.Equal(t, errSourceNameReserved, errors.Cause(err))
+
+	err = srcs.Add("bad", &Source{Type: SourceArchive, URL: "packs.tar.gz"})
+	assert.Equal(t, errChecksumRequired, err)
+
+	err = srcs.Add("bad", &Source{Type: SourceArchive, URL: "packs.tar.gz", Checksum: "md5:abc"})
+	assert.Equal(t, errSourceChecksumFormat, err)
+
+	err = srcs.Add("bad", &Source{Type: SourceOCI, URL: "oci://ghcr.io/org/packs:1.0"})
+	assert.Equal(t, errOCIChecksumRequired, err)
+
+	err = srcs.Add("pinned", &Source{Type: SourceOCI, URL: "oci://ghcr.io/org/packs@sha256:abc"})
+	assert.Nil(t, err)
+
+	err = srcs.Add("bad", &Source{Type: "svn", URL: "svn://example.com"})
+	assert.Equal(t, errSourceTypeUnknown, errors.Cause(err))
+}
+
+func Test_Source_Install_archive(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("", "packarchive-")
+	defer os.RemoveAll(tmpdir)
+
+	b := testTarGz(t, map[string]string{
+		"packs-1.0/dev/go/manifest.hcl":       "go",
+		"packs-1.0/datastore/redis/README.md": "redis",
+	})
+	archive := filepath.Join(tmpdir, "packs-1.0.tar.gz")
+	ioutil.WriteFile(archive, b, 0644)
+
+	dir := filepath.Join(tmpdir, "sources", "offline")
+	src := &Source{Type: SourceArchive, URL: archive, Checksum: testChecksum([]byte("other"))}
+	err := src.Install(context.Background(), dir)
+	assert.Equal(t, errChecksumMismatch, errors.Cause(err))
+	assert.False(t, utils.FileExists(dir))
+
+	src.Checksum = testChecksum(b)
+	assert.Nil(t, src.Install(context.Background(), dir))
+	got, err := ioutil.ReadFile(filepath.Join(dir, "dev", "go", "manifest.hcl"))
+	assert.Nil(t, err)
+	assert.Equal(t, "go", string(got))
+
+	// Reinstall replaces the contents
+	b = testTarGz(t, map[string]string{"dev/rust/manifest.hcl": "rust"})
+	ioutil.WriteFile(archive, b, 0644)
+	src.Checksum = testChecksum(b)
+	assert.Nil(t, src.Install(context.Background(), dir))
+	assert.True(t, utils.FileExists(filepath.Join(dir, "dev", "rust", "manifest.hcl")))
+	assert.False(t, utils.FileExists(filepath.Join(dir, "dev", "go")))
+
+	// Paths outside of the destination
+	b = testTarGz(t, map[string]string{"../evil": "x"})
+	ioutil.WriteFile(archive, b, 0644)
+	src.Checksum = testChecksum(b)
+	err = src.Install(context.Background(), dir)
+	assert.Equal(t, errArchivePathInvalid, errors.Cause(err))
+}
+
+func Test_Source_Install_oci(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("", "packoci-")
+	defer os.RemoveAll(tmpdir)
+
+	layer := testTarGz(t, map[string]string{"web/nginx/manifest.hcl": "nginx"})
+	digest := testChecksum(layer)
+	manifest := []byte(fmt.Sprintf(`{"layers":[{"mediaType":"application/vnd.oci.image.layer.v1.tar+gzip","digest":"%s"}]}`, digest))
+	mdigest := testChecksum(manifest)
+
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			assert.Equal(t, "repository:org/packs:pull", r.URL.Query().Get("scope"))
+			w.Write([]byte(`{"token":"abc"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.Header().Set("WWW-Authenticate",
+				fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="repository:org/packs:pull"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/org/packs/manifests/1.0", "/v2/org/packs/manifests/" + mdigest, "/v2/org/packs/manifests/" + testChecksum(nil):
+			w.Write(manifest)
+		case "/v2/org/packs/blobs/" + digest:
+			w.Write(layer)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	httpClient = srv.Client()
+	defer func() { httpClient = http.DefaultClient }()
+
+	host := strings.TrimPrefix(srv.URL, "https://")
+	dir := filepath.Join(tmpdir, "registry")
+
+	src := &Source{Type: SourceOCI, URL: "oci://" + host + "/org/packs:1.0", Checksum: testChecksum([]byte("other"))}
+	err := src.Install(context.Background(), dir)
+	assert.Equal(t, errChecksumMismatch, errors.Cause(err))
+
+	src.Checksum = digest
+	assert.Nil(t, src.Install(context.Background(), dir))
+	assert.True(t, utils.FileExists(filepath.Join(dir, "web", "nginx", "manifest.hcl")))
+
+	src.URL = "oci://" + host + "/org/packs:2.0"
+	assert.NotNil(t, src.Install(context.Background(), dir))
+
+	// Pinned references are verified against the digest
+	src = &Source{Type: SourceOCI, URL: "oci://" + host + "/org/packs@" + mdigest}
+	assert.Nil(t, src.Install(context.Background(), dir))
+	src.URL = "oci://" + host + "/org/packs@" + testChecksum(nil)
+	err = src.Install(context.Background(), dir)
+	assert.Equal(t, errChecksumMismatch, errors.Cause(err))
+}
+
+func Test_parseOCIReference(t *testing.T) {
+	host, repo, tag, err := parseOCIReference("oci://ghcr.io/org/packs:1.2")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ghcr.io", "org/packs", "1.2"}, []string{host, repo, tag})
+
+	host, repo, tag, err = parseOCIReference("oci://localhost:5000/packs")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"localhost:5000", "packs", "latest"}, []string{host, repo, tag})
+
+	_, repo, tag, err = parseOCIReference("oci://ghcr.io/packs@sha256:abc")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"packs", "sha256:abc"}, []string{repo, tag})
+
+	_, _, _, err = parseOCIReference("ghcr.io/packs")
+	assert.Equal(t, errOCIReferenceInvalid, errors.Cause(err))
+}
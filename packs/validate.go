@@ -0,0 +1,237 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package packs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl"
+	hclast "github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hil"
+	"github.com/hashicorp/hil/ast"
+	"github.com/pkg/errors"
+	"github.com/sniperkit/snk.fork.thrap/consts"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/vars"
+)
+
+var (
+	errPackTypeUnknown      = errors.New("unknown pack type")
+	errImageRequired        = errors.New("image required")
+	errDefaultVersionNeeded = errors.New("default version required")
+	errDefaultVersionRange  = errors.New("default version does not satisfy any version constraint")
+)
+
+// Variables available to dev pack templates.  Component and dependency
+// variables are matched by prefix
+var (
+	devPackVars = []string{
+		vars.LangID, vars.LangVersion,
+		vars.StackID, vars.StackName, vars.StackDescription, vars.StackVersion,
+	}
+	devPackVarPrefixes = []string{
+		"vcs.", consts.CompVarPrefixKey + ".", consts.DepVarPrefixKey + ".",
+	}
+)
+
+// Validate checks the pack of the type with the id in dir i.e. dir/dev/go.
+// It checks the manifest schema, version constraints and files.  Dev packs
+// must have a dockerfile and templates may only reference known variables.
+// It returns all problems found
+func Validate(dir, typ, id string) []error {
+	pdir := filepath.Join(dir, typ, id)
+	b, err := ioutil.ReadFile(filepath.Join(pdir, packManfiestFile))
+	if err != nil {
+		return []error{err}
+	}
+
+	switch typ {
+	case devPackID:
+		return validateDevPack(pdir, b)
+	case webPackID, dsPackID:
+		return validateBasePack(pdir, b)
+	}
+	return []error{errors.Wrap(errPackTypeUnknown, typ)}
+}
+
+func validateDevPack(pdir string, manifest []byte) []error {
+	var conf thrapb.Language
+	errs := decodeManifest(manifest, &conf)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	lp := &DevPack{Language: &conf, dir: pdir}
+	if err := lp.setVersionContraints(); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %v", packManfiestFile, err))
+	} else if err = checkDefaultVersion(conf.DefaultVersion, lp.vc); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %v", packManfiestFile, err))
+	}
+
+	if lp.getDockerfile() == "" {
+		errs = append(errs, fmt.Errorf("%s: %v", packManfiestFile, errDockerfileMissing))
+	}
+
+	for _, fpath := range conf.ScaffoldFiles {
+		b, err := ioutil.ReadFile(filepath.Join(pdir, fpath))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, err = range checkTemplateVars(b) {
+			errs = append(errs, fmt.Errorf("%s: %v", fpath, err))
+		}
+	}
+
+	return errs
+}
+
+func validateBasePack(pdir string, manifest []byte) []error {
+	var conf thrapb.PackManifest
+	errs := decodeManifest(manifest, &conf)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if conf.Image == "" {
+		errs = append(errs, fmt.Errorf("%s: %v", packManfiestFile, errImageRequired))
+	}
+
+	vc := make([]version.Constraints, 0, len(conf.Versions))
+	for _, ct := range conf.Versions {
+		c, err := version.NewConstraint(ct)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", packManfiestFile, err))
+			continue
+		}
+		vc = append(vc, c)
+	}
+	if len(vc) == len(conf.Versions) {
+		if err := checkDefaultVersion(conf.DefaultVersion, vc); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", packManfiestFile, err))
+		}
+	}
+
+	for _, fpath := range conf.Files {
+		if _, err := ioutil.ReadFile(filepath.Join(pdir, fpath)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// decodeManifest decodes the manifest into out reporting keys that do not
+// map to a field as hcl silently ignores them
+func decodeManifest(b []byte, out interface{}) []error {
+	root, err := hcl.ParseBytes(b)
+	if err != nil {
+		return []error{fmt.Errorf("%s: %v", packManfiestFile, err)}
+	}
+	if err = hcl.DecodeObject(out, root); err != nil {
+		return []error{fmt.Errorf("%s: %v", packManfiestFile, err)}
+	}
+
+	list, ok := root.Node.(*hclast.ObjectList)
+	if !ok {
+		return nil
+	}
+
+	fields := manifestFields(out)
+	var errs []error
+	for _, item := range list.Items {
+		if len(item.Keys) == 0 {
+			continue
+		}
+		key, _ := item.Keys[0].Token.Value().(string)
+		if !fields[strings.ToLower(key)] {
+			errs = append(errs, fmt.Errorf("%s:%d: unknown key %q", packManfiestFile, item.Pos().Line, key))
+		}
+	}
+	return errs
+}
+
+// manifestFields returns the lower cased field names hcl decodes into
+func manifestFields(v interface{}) map[string]bool {
+	typ := reflect.TypeOf(v).Elem()
+	out := make(map[string]bool, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" || strings.HasPrefix(f.Name, "XXX_") {
+			continue
+		}
+		out[strings.ToLower(f.Name)] = true
+	}
+	return out
+}
+
+func checkDefaultVersion(def string, vc []version.Constraints) error {
+	if def == "" {
+		return errDefaultVersionNeeded
+	}
+	if len(vc) == 0 {
+		return nil
+	}
+
+	v, err := version.NewVersion(def)
+	if err != nil {
+		return fmt.Errorf("default version %s: %v", def, err)
+	}
+	for _, c := range vc {
+		if c.Check(v) {
+			return nil
+		}
+	}
+	return errors.Wrap(errDefaultVersionRange, def)
+}
+
+// checkTemplateVars parses the template and returns an error for each
+// reference to an unknown variable
+func checkTemplateVars(b []byte) []error {
+	root, err := hil.Parse(string(b))
+	if err != nil {
+		return []error{err}
+	}
+
+	var (
+		errs []error
+		seen = make(map[string]bool)
+	)
+	root.Accept(func(n ast.Node) ast.Node {
+		va, ok := n.(*ast.VariableAccess)
+		if !ok || seen[va.Name] {
+			return n
+		}
+		seen[va.Name] = true
+
+		if !isDevPackVar(va.Name) {
+			errs = append(errs, fmt.Errorf("%s: unknown variable %q", va.Pos(), va.Name))
+		}
+		return n
+	})
+
+	return errs
+}
+
+func isDevPackVar(name string) bool {
+	for _, v := range devPackVars {
+		if name == v {
+			return true
+		}
+	}
+	for _, p := range devPackVarPrefixes {
+		if strings.HasPrefix(name, p) && len(name) > len(p) {
+			return true
+		}
+	}
+	return false
+}
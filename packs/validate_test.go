@@ -0,0 +1,105 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package packs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewPack_Validate(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "packnew-")
+	defer os.RemoveAll(tmpdir)
+
+	files, err := NewPack(tmpdir, "dev", "rust")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(tmpdir, "dev", "rust", "Dockerfile"),
+		filepath.Join(tmpdir, "dev", "rust", packManfiestFile),
+	}, files)
+	assert.Nil(t, Validate(tmpdir, "dev", "rust"))
+
+	_, err = NewPack(tmpdir, "dev", "rust")
+	assert.Equal(t, errPackExists, errors.Cause(err))
+	_, err = NewPack(tmpdir, "foo", "rust")
+	assert.Equal(t, errPackTypeUnknown, errors.Cause(err))
+
+	_, err = NewPack(tmpdir, "datastore", "redis")
+	assert.Nil(t, err)
+	assert.Nil(t, Validate(tmpdir, "datastore", "redis"))
+}
+
+func Test_Validate_dev(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "packvalidate-")
+	defer os.RemoveAll(tmpdir)
+
+	pdir := filepath.Join(tmpdir, "dev", "go")
+	os.MkdirAll(pdir, 0755)
+	ioutil.WriteFile(filepath.Join(pdir, packManfiestFile), []byte(`
+Versions = ["~> 1.10", "bad"]
+DefaultVersion = "1.10"
+ScaffoldFiles = ["main.go", "missing.txt"]
+Dockerimage = "golang"
+`), 0644)
+	ioutil.WriteFile(filepath.Join(pdir, "main.go"), []byte("// ${stack.name} ${comp.api.version} ${lang.verison}\n"), 0644)
+
+	errs := Validate(tmpdir, "dev", "go")
+	assert.Equal(t, 1, len(errs))
+	assert.Contains(t, errs[0].Error(), `unknown key "Dockerimage"`)
+
+	ioutil.WriteFile(filepath.Join(pdir, packManfiestFile), []byte(`
+Versions = ["~> 1.10", "bad"]
+DefaultVersion = "1.10"
+ScaffoldFiles = ["main.go", "missing.txt"]
+`), 0644)
+
+	var msgs []string
+	for _, err := range Validate(tmpdir, "dev", "go") {
+		msgs = append(msgs, err.Error())
+	}
+	out := strings.Join(msgs, "\n")
+	assert.Equal(t, 4, len(msgs), out)
+	assert.Contains(t, out, "Malformed constraint: bad")
+	assert.Contains(t, out, errDockerfileMissing.Error())
+	assert.Contains(t, out, "missing.txt")
+	assert.Contains(t, out, `main.go: 1:40: unknown variable "lang.verison"`)
+
+	ioutil.WriteFile(filepath.Join(pdir, packManfiestFile), []byte(`
+Versions = ["~> 1.10"]
+DefaultVersion = "1.9"
+ScaffoldFiles = ["Dockerfile"]
+`), 0644)
+	ioutil.WriteFile(filepath.Join(pdir, "Dockerfile"), []byte("FROM golang:${lang.version}\n"), 0644)
+	errs = Validate(tmpdir, "dev", "go")
+	assert.Equal(t, 1, len(errs))
+	assert.Contains(t, errs[0].Error(), errDefaultVersionRange.Error())
+}
+
+func Test_Validate_base(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "packvalidate-")
+	defer os.RemoveAll(tmpdir)
+
+	pdir := filepath.Join(tmpdir, "web", "nginx")
+	os.MkdirAll(pdir, 0755)
+	ioutil.WriteFile(filepath.Join(pdir, packManfiestFile), []byte(`
+DefaultVersion = "1.15"
+Files = ["nginx.conf"]
+`), 0644)
+
+	errs := Validate(tmpdir, "web", "nginx")
+	assert.Equal(t, 2, len(errs))
+
+	errs = Validate(tmpdir, "web", "apache")
+	assert.Equal(t, 1, len(errs))
+	assert.True(t, os.IsNotExist(errs[0]))
+}
@@ -0,0 +1,121 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package registry
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+)
+
+// ImageLock pins image references to the digests they resolved to
+type ImageLock struct {
+	// Image reference as written to its digest
+	Images map[string]string `json:"images"`
+}
+
+// NewImageLock returns an empty ImageLock
+func NewImageLock() *ImageLock {
+	return &ImageLock{Images: make(map[string]string)}
+}
+
+// ReadImageLock reads the lock file
+func ReadImageLock(fpath string) (*ImageLock, error) {
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock ImageLock
+	if err = json.Unmarshal(b, &lock); err == nil && lock.Images == nil {
+		lock.Images = make(map[string]string)
+	}
+	return &lock, err
+}
+
+// Marshal returns the json encoded lock
+func (lock *ImageLock) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(lock, "", "  ")
+	return append(b, '\n'), err
+}
+
+// Pin returns the image reference with its locked digest appended.  Images
+// not locked, already containing a digest or a nil lock return the image as
+// is
+func (lock *ImageLock) Pin(image string) string {
+	if lock == nil || strings.Contains(image, "@") {
+		return image
+	}
+	if dgst, ok := lock.Images[image]; ok {
+		return image + "@" + dgst
+	}
+	return image
+}
+
+// NewestTag returns the newest of the tags newer than tag that satisfies any
+// one of the version constraints.  Only tags with the same number of version
+// segments and suffix are considered i.e. 1.11-alpine only matches 1.12-alpine.
+// An empty string is returned if there is none or tag is not a version
+func NewestTag(tag string, tags []string, constraints []string) (string, error) {
+	vc := make([]version.Constraints, len(constraints))
+	for i, ct := range constraints {
+		c, err := version.NewConstraint(ct)
+		if err != nil {
+			return "", err
+		}
+		vc[i] = c
+	}
+
+	cur, suffix, segments, err := splitTagVersion(tag)
+	if err != nil {
+		return "", nil
+	}
+
+	var (
+		newest    *version.Version
+		newestTag string
+	)
+	for _, t := range tags {
+		v, s, n, err := splitTagVersion(t)
+		if err != nil || s != suffix || n != segments || !v.GreaterThan(cur) {
+			continue
+		}
+		if !satisfiesAny(v, vc) {
+			continue
+		}
+		if newest == nil || v.GreaterThan(newest) {
+			newest, newestTag = v, t
+		}
+	}
+
+	return newestTag, nil
+}
+
+// splitTagVersion returns the version of the tag, the suffix following it and
+// the number of version segments
+func splitTagVersion(tag string) (*version.Version, string, int, error) {
+	var suffix string
+	if i := strings.Index(tag, "-"); i >= 0 {
+		tag, suffix = tag[:i], tag[i:]
+	}
+	v, err := version.NewVersion(tag)
+	return v, suffix, strings.Count(tag, ".") + 1, err
+}
+
+func satisfiesAny(v *version.Version, vc []version.Constraints) bool {
+	if len(vc) == 0 {
+		return true
+	}
+	for _, c := range vc {
+		if c.Check(v) {
+			return true
+		}
+	}
+	return false
+}
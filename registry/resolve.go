@@ -0,0 +1,389 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+)
+
+const (
+	// DefaultImageHost is the registry host of images without one
+	DefaultImageHost = "registry-1.docker.io"
+	// Max number of tag list pages followed
+	maxTagPages = 50
+)
+
+// Manifest media types accepted when resolving digests.  Lists and indexes
+// are preferred so the digest is the same for all platforms
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+var (
+	errImageRefInvalid  = errors.New("image reference invalid")
+	errAuthUnsupported  = errors.New("registry authentication not supported")
+	errDigestMissing    = errors.New("registry returned no digest")
+	errTokenMissing     = errors.New("registry token missing")
+	linkNextRegex       = regexp.MustCompile(`<([^>]+)>;\s*rel="?next"?`)
+	challengeParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// ImageRef is a parsed image reference i.e. quay.io/coreos/etcd:v3.3
+type ImageRef struct {
+	// Name as written without the tag or digest
+	Name string
+	// Registry host
+	Host string
+	// Repository on the host
+	Repo string
+	// Tag defaulting to latest when neither a tag or digest is given
+	Tag    string
+	Digest string
+}
+
+// ParseImageRef parses an image reference of the form
+// [host/]repo[:tag][@digest]
+func ParseImageRef(s string) (*ImageRef, error) {
+	ref := &ImageRef{}
+	if i := strings.Index(s, "@"); i >= 0 {
+		s, ref.Digest = s[:i], s[i+1:]
+	}
+
+	if i := strings.LastIndex(s, ":"); i > strings.LastIndex(s, "/") {
+		s, ref.Tag = s[:i], s[i+1:]
+	}
+	if s == "" || strings.ToLower(s) != s || strings.ContainsAny(s, " \t") {
+		return nil, errImageRefInvalid
+	}
+	ref.Name = s
+
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.Host, ref.Repo = parts[0], parts[1]
+	} else {
+		ref.Repo = s
+	}
+
+	switch ref.Host {
+	case "", "docker.io", "index.docker.io":
+		ref.Host = DefaultImageHost
+		if !strings.Contains(ref.Repo, "/") {
+			ref.Repo = "library/" + ref.Repo
+		}
+	}
+
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref, nil
+}
+
+// Reference returns the tag or digest to request
+func (ref *ImageRef) Reference() string {
+	if ref.Digest != "" {
+		return ref.Digest
+	}
+	return ref.Tag
+}
+
+func (ref *ImageRef) String() string {
+	out := ref.Name
+	if ref.Tag != "" {
+		out += ":" + ref.Tag
+	}
+	if ref.Digest != "" {
+		out += "@" + ref.Digest
+	}
+	return out
+}
+
+// Resolver resolves image tags to digests using the registry v2 api.
+// Registries requiring authentication are authenticated with the credentials
+// set for their host, requesting anonymous bearer tokens for hosts without
+type Resolver struct {
+	client *http.Client
+	// Credentials by registry host
+	auths map[string]types.AuthConfig
+
+	mu sync.Mutex
+	// Authorization headers by host and repo
+	tokens map[string]string
+}
+
+// NewResolver returns a Resolver using the http client or the default client
+// if nil
+func NewResolver(client *http.Client) *Resolver {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Resolver{
+		client: client,
+		auths:  make(map[string]types.AuthConfig),
+		tokens: make(map[string]string),
+	}
+}
+
+// SetAuth sets the credentials used for the registry at addr.  addr may be a
+// host or url as configured for the registry
+func (res *Resolver) SetAuth(addr string, auth types.AuthConfig) {
+	res.auths[RegistryHost(addr)] = auth
+}
+
+// RegistryHost returns the host of a registry address as used in image
+// references.  Docker hub addresses return the default image host
+func RegistryHost(addr string) string {
+	host := addr
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+
+	switch host {
+	case "", "docker.io", "index.docker.io", "registry.hub.docker.com":
+		return DefaultImageHost
+	}
+	return host
+}
+
+// Digest returns the content digest of the image manifest.  For multi
+// platform images this is the digest of the manifest list
+func (res *Resolver) Digest(ref *ImageRef) (string, error) {
+	u := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.Host, ref.Repo, ref.Reference())
+
+	resp, err := res.do(ref, "HEAD", u)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if dgst := resp.Header.Get("Docker-Content-Digest"); dgst != "" {
+		return dgst, nil
+	}
+
+	// Not all registries return the digest on a HEAD
+	resp, err = res.do(ref, "GET", u)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if dgst := resp.Header.Get("Docker-Content-Digest"); dgst != "" {
+		return dgst, nil
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if len(b) == 0 {
+		return "", errDigestMissing
+	}
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// Tags returns all tags of the image repository
+func (res *Resolver) Tags(ref *ImageRef) ([]string, error) {
+	var (
+		out []string
+		u   = fmt.Sprintf("https://%s/v2/%s/tags/list", ref.Host, ref.Repo)
+	)
+
+	for i := 0; i < maxTagPages && u != ""; i++ {
+		resp, err := res.do(ref, "GET", u)
+		if err != nil {
+			return nil, err
+		}
+
+		var list struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, list.Tags...)
+
+		u, err = nextLink(resp.Request.URL, resp.Header.Get("Link"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// do performs the request authenticating as per the challenge if challenged.
+// Non 2xx responses are returned as errors
+func (res *Resolver) do(ref *ImageRef, method, u string) (*http.Response, error) {
+	key := ref.Host + "/" + ref.Repo
+
+	resp, err := res.request(method, u, res.token(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+
+		var authz string
+		authz, err = res.authorize(resp.Header.Get("WWW-Authenticate"), ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", ref.Host, err)
+		}
+		res.mu.Lock()
+		res.tokens[key] = authz
+		res.mu.Unlock()
+
+		if resp, err = res.request(method, u, authz); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s", method, u, resp.Status)
+	}
+	return resp, nil
+}
+
+func (res *Resolver) request(method, u, authz string) (*http.Response, error) {
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if authz != "" {
+		req.Header.Set("Authorization", authz)
+	}
+	return res.client.Do(req)
+}
+
+// authorize returns the authorization header answering the challenge of the
+// registry of the image.  Basic challenges require credentials for the host
+func (res *Resolver) authorize(challenge string, ref *ImageRef) (string, error) {
+	auth, ok := res.auths[ref.Host]
+	if strings.HasPrefix(strings.ToLower(challenge), "basic") {
+		if !ok {
+			return "", errAuthUnsupported
+		}
+		return "Basic " + basicAuth(auth), nil
+	}
+
+	token, err := res.fetchToken(challenge, ref.Repo, auth)
+	if err != nil {
+		return "", err
+	}
+	return "Bearer " + token, nil
+}
+
+// basicAuth returns the encoded user and password of the credentials.
+// Encoded credentials as returned by ecr are used as is
+func basicAuth(auth types.AuthConfig) string {
+	if auth.Username == "" && auth.Auth != "" {
+		return auth.Auth
+	}
+	return base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))
+}
+
+func (res *Resolver) token(key string) string {
+	res.mu.Lock()
+	defer res.mu.Unlock()
+	return res.tokens[key]
+}
+
+// fetchToken requests a pull token for the repo as per the bearer challenge.
+// The token is requested anonymously if the credentials are empty
+func (res *Resolver) fetchToken(challenge, repo string, auth types.AuthConfig) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", errAuthUnsupported
+	}
+
+	params := make(map[string]string)
+	for _, m := range challengeParamRegex.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+	if params["realm"] == "" {
+		return "", errAuthUnsupported
+	}
+
+	u, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	if params["service"] != "" {
+		q.Set("service", params["service"])
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + repo + ":pull"
+	}
+	q.Set("scope", scope)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if auth.Username != "" || auth.Auth != "" {
+		req.Header.Set("Authorization", "Basic "+basicAuth(auth))
+	}
+	resp, err := res.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request: %s", resp.Status)
+	}
+
+	var tok struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", err
+	}
+	if tok.Token != "" {
+		return tok.Token, nil
+	}
+	if tok.AccessToken != "" {
+		return tok.AccessToken, nil
+	}
+	return "", errTokenMissing
+}
+
+// nextLink returns the absolute url of the next page in the link header
+func nextLink(base *url.URL, link string) (string, error) {
+	m := linkNextRegex.FindStringSubmatch(link)
+	if m == nil {
+		return "", nil
+	}
+	u, err := base.Parse(m[1])
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
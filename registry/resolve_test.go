@@ -0,0 +1,181 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseImageRef(t *testing.T) {
+	for in, want := range map[string]ImageRef{
+		"consul:1.2.0": {Name: "consul", Host: DefaultImageHost, Repo: "library/consul", Tag: "1.2.0"},
+		"alpine":       {Name: "alpine", Host: DefaultImageHost, Repo: "library/alpine", Tag: "latest"},
+		"euforia/thrap@sha256:abc": {
+			Name: "euforia/thrap", Host: DefaultImageHost, Repo: "euforia/thrap", Digest: "sha256:abc",
+		},
+		"quay.io/coreos/etcd:v3.3": {Name: "quay.io/coreos/etcd", Host: "quay.io", Repo: "coreos/etcd", Tag: "v3.3"},
+		"localhost:5000/app":       {Name: "localhost:5000/app", Host: "localhost:5000", Repo: "app", Tag: "latest"},
+	} {
+		ref, err := ParseImageRef(in)
+		fatal(t, err)
+		assert.Equal(t, want, *ref, in)
+	}
+
+	_, err := ParseImageRef("Bad:1")
+	assert.Equal(t, errImageRefInvalid, err)
+}
+
+func testRegistry(t *testing.T) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			assert.Equal(t, "repository:library/consul:pull", r.URL.Query().Get("scope"))
+			json.NewEncoder(w).Encode(map[string]string{"token": "secret"})
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+srv.URL+`/token",service="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/library/consul/manifests/1.2.0":
+			assert.Contains(t, r.Header.Get("Accept"), "manifest.list.v2+json")
+			w.Header().Set("Docker-Content-Digest", "sha256:123")
+		case "/v2/library/consul/tags/list":
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/library/consul/tags/list?last=1.2.0>; rel="next"`)
+				json.NewEncoder(w).Encode(map[string][]string{"tags": {"1.1.0", "1.2.0"}})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string][]string{"tags": {"1.2.1", "latest"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return srv
+}
+
+func Test_Resolver(t *testing.T) {
+	srv := testRegistry(t)
+	defer srv.Close()
+
+	res := NewResolver(srv.Client())
+	ref, err := ParseImageRef(strings.TrimPrefix(srv.URL, "https://") + "/library/consul:1.2.0")
+	fatal(t, err)
+
+	dgst, err := res.Digest(ref)
+	fatal(t, err)
+	assert.Equal(t, "sha256:123", dgst)
+
+	tags, err := res.Tags(ref)
+	fatal(t, err)
+	assert.Equal(t, []string{"1.1.0", "1.2.0", "1.2.1", "latest"}, tags)
+
+	ref.Tag = "0.1"
+	_, err = res.Digest(ref)
+	assert.Contains(t, err.Error(), "404")
+}
+
+func Test_ImageLock(t *testing.T) {
+	var lock *ImageLock
+	assert.Equal(t, "consul:1.2.0", lock.Pin("consul:1.2.0"))
+
+	lock = NewImageLock()
+	lock.Images["consul:1.2.0"] = "sha256:123"
+	assert.Equal(t, "consul:1.2.0@sha256:123", lock.Pin("consul:1.2.0"))
+	assert.Equal(t, "consul:1.2.0@sha256:456", lock.Pin("consul:1.2.0@sha256:456"))
+	assert.Equal(t, "consul:1.3.0", lock.Pin("consul:1.3.0"))
+}
+
+func Test_NewestTag(t *testing.T) {
+	tags := []string{"1.10", "1.11", "1.11.2", "1.12", "1.12-alpine", "2.0", "latest"}
+
+	newest, err := NewestTag("1.10", tags, []string{">= 1.10, < 2.0"})
+	fatal(t, err)
+	assert.Equal(t, "1.12", newest)
+
+	newest, _ = NewestTag("1.11-alpine", tags, nil)
+	assert.Equal(t, "1.12-alpine", newest)
+
+	newest, _ = NewestTag("1.11.1", tags, nil)
+	assert.Equal(t, "1.11.2", newest)
+
+	newest, _ = NewestTag("latest", tags, nil)
+	assert.Equal(t, "", newest)
+
+	_, err = NewestTag("1.10", tags, []string{"bad"})
+	assert.NotNil(t, err)
+}
+
+func Test_Resolver_auth(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		switch {
+		case r.URL.Path == "/token":
+			if !ok || user != "me" || pass != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "private"})
+
+		case strings.HasPrefix(r.URL.Path, "/v2/basic/"):
+			if !ok || user != "me" || pass != "pass" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:456")
+
+		case r.Header.Get("Authorization") != "Bearer private":
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+srv.URL+`/token",service="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+
+		default:
+			w.Header().Set("Docker-Content-Digest", "sha256:123")
+		}
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "https://")
+	bearer, _ := ParseImageRef(host + "/myorg/api:1.0.0")
+	basic, _ := ParseImageRef(host + "/basic/api:1.0.0")
+
+	// Anonymous
+	res := NewResolver(srv.Client())
+	_, err := res.Digest(bearer)
+	assert.NotNil(t, err)
+	_, err = res.Digest(basic)
+	assert.NotNil(t, err)
+
+	res = NewResolver(srv.Client())
+	res.SetAuth(srv.URL, types.AuthConfig{Username: "me", Password: "pass"})
+	dgst, err := res.Digest(bearer)
+	fatal(t, err)
+	assert.Equal(t, "sha256:123", dgst)
+	dgst, err = res.Digest(basic)
+	fatal(t, err)
+	assert.Equal(t, "sha256:456", dgst)
+}
+
+func Test_RegistryHost(t *testing.T) {
+	assert.Equal(t, DefaultImageHost, RegistryHost(""))
+	assert.Equal(t, DefaultImageHost, RegistryHost("https://registry.hub.docker.com"))
+	assert.Equal(t, "quay.io", RegistryHost("quay.io"))
+	assert.Equal(t, "123.dkr.ecr.us-west-2.amazonaws.com",
+		RegistryHost("https://123.dkr.ecr.us-west-2.amazonaws.com/"))
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/sniperkit/snk.fork.thrap/core"
 	"github.com/sniperkit/snk.fork.thrap/thrapb"
+	"github.com/sniperkit/snk.fork.thrap/utils"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -56,6 +57,14 @@ func (s *GRPCService) GetIdentity(ctx context.Context, ident *thrapb.Identity) (
 	return idt.Get(ident.ID)
 }
 
+// VerifyPublishOverride implements the server-side grpc call
+func (s *GRPCService) VerifyPublishOverride(ctx context.Context, o *thrapb.PublishOverride) (*thrapb.Identity, error) {
+	s.handleIncomingContext(ctx, "identity."+o.Identity+".verify-override")
+
+	idt := s.core.Identity()
+	return idt.VerifyOverride(o)
+}
+
 // IterIdentities implements the server-side grpc call
 func (s *GRPCService) IterIdentities(opts *thrapb.IterOptions, stream thrapb.Thrap_IterIdentitiesServer) error {
 	s.handleIncomingContext(stream.Context(), "identity.list")
@@ -113,6 +122,43 @@ func (s *GRPCService) IterStacks(opts *thrapb.IterOptions, stream thrapb.Thrap_I
 	})
 }
 
+// CreatePreview implements the server-side grpc call.  It records a preview
+// deployed by the client so the agent destroys it once it expires
+func (s *GRPCService) CreatePreview(ctx context.Context, preview *thrapb.Preview) (*thrapb.Preview, error) {
+	s.handleIncomingContext(ctx, "preview."+preview.ID+".create")
+	return s.core.RecordPreview(preview)
+}
+
+// IterPreviews implements the server-side grpc call
+func (s *GRPCService) IterPreviews(opts *thrapb.IterOptions, stream thrapb.Thrap_IterPreviewsServer) error {
+	s.handleIncomingContext(stream.Context(), "preview.list")
+
+	return s.core.IterPreviews(opts.Prefix, func(preview *thrapb.Preview) error {
+		return stream.Send(preview)
+	})
+}
+
+// DestroyPreview implements the server-side grpc call
+func (s *GRPCService) DestroyPreview(ctx context.Context, preview *thrapb.Preview) (*thrapb.Preview, error) {
+	s.handleIncomingContext(ctx, "preview."+preview.ID+".destroy")
+
+	ar, err := s.core.DestroyPreview(ctx, preview.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make(map[string]error)
+	for _, r := range ar {
+		if r.Error != nil {
+			errs[r.Resource] = r.Error
+		}
+	}
+	if len(errs) > 0 {
+		return preview, utils.FlattenErrors(errs)
+	}
+	return preview, nil
+}
+
 func (s *GRPCService) handleIncomingContext(ctx context.Context, call string) {
 	// TODO: auth
 	md, ok := metadata.FromIncomingContext(ctx)
@@ -0,0 +1,118 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package store
+
+import (
+	"errors"
+
+	"github.com/dgraph-io/badger"
+	"github.com/gogo/protobuf/proto"
+	"github.com/sniperkit/snk.fork.thrap/thrapb"
+)
+
+const defaultPreviewPrefix = "/preview/"
+
+var (
+	// ErrPreviewNotFound is used when a preview is not found in the store
+	ErrPreviewNotFound = errors.New("preview not found")
+)
+
+// BadgerPreviewStorage implments a badger backed PreviewStorage interface
+type BadgerPreviewStorage struct {
+	db *badger.DB
+}
+
+// NewBadgerPreviewStorage returns a new badger backed preview storage
+func NewBadgerPreviewStorage(db *badger.DB) *BadgerPreviewStorage {
+	return &BadgerPreviewStorage{db: db}
+}
+
+func (store *BadgerPreviewStorage) getOpaqueKey(k string) []byte {
+	return []byte(defaultPreviewPrefix + k)
+}
+
+// Get returns a preview by its id
+func (store *BadgerPreviewStorage) Get(id string) (*thrapb.Preview, error) {
+	var (
+		key     = store.getOpaqueKey(id)
+		preview *thrapb.Preview
+	)
+
+	err := store.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return ErrPreviewNotFound
+			}
+			return err
+		}
+		preview, err = previewFromItem(item)
+		return err
+	})
+
+	return preview, err
+}
+
+// Set creates or replaces the preview.  A preview is redeployed on every
+// update of its branch so it is always overwritten
+func (store *BadgerPreviewStorage) Set(preview *thrapb.Preview) error {
+	key := store.getOpaqueKey(preview.ID)
+	val, err := proto.Marshal(preview)
+	if err != nil {
+		return err
+	}
+
+	return store.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key, val)
+	})
+}
+
+// Iter iterates over each preview from the starting point
+func (store *BadgerPreviewStorage) Iter(start string, callback func(*thrapb.Preview) error) error {
+	prefix := store.getOpaqueKey(start)
+
+	return store.db.View(func(txn *badger.Txn) error {
+		iter := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer iter.Close()
+
+		for iter.Seek(prefix); iter.ValidForPrefix(prefix); iter.Next() {
+			preview, err := previewFromItem(iter.Item())
+			if err != nil {
+				return err
+			}
+			if err = callback(preview); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Delete removes the preview given the id
+func (store *BadgerPreviewStorage) Delete(id string) error {
+	key := store.getOpaqueKey(id)
+
+	return store.db.Update(func(txn *badger.Txn) error {
+		if _, err := txn.Get(key); err != nil {
+			return ErrPreviewNotFound
+		}
+		return txn.Delete(key)
+	})
+}
+
+func previewFromItem(item *badger.Item) (*thrapb.Preview, error) {
+	val, err := item.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	var preview thrapb.Preview
+	err = proto.Unmarshal(val, &preview)
+
+	return &preview, err
+}
@@ -70,7 +70,11 @@ func NewHCLFileProfileStorage(fpath string) *HCLFileProfileStorage {
 
 // LoadHCLFileProfileStorage loads all profiles in a directory containing .thrap dir
 func LoadHCLFileProfileStorage(dir string) (*HCLFileProfileStorage, error) {
-	fpath := filepath.Join(dir, consts.WorkDir, consts.ProfilesFile)
+	return ReadHCLFileProfileStorage(filepath.Join(dir, consts.WorkDir, consts.ProfilesFile))
+}
+
+// ReadHCLFileProfileStorage loads the profiles from the given file
+func ReadHCLFileProfileStorage(fpath string) (*HCLFileProfileStorage, error) {
 	db, err := parseProfiles(fpath)
 	if err == nil {
 		return &HCLFileProfileStorage{profilesDB: db, file: fpath}, nil
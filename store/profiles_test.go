@@ -22,4 +22,12 @@ func Test_Profiles(t *testing.T) {
 	assert.Equal(t, "local", db.Default)
 	assert.Equal(t, "docker", db.Profiles["local"].Orchestrator)
 	assert.Equal(t, "docker", db.Profiles["local"].Registry)
+	assert.Nil(t, db.Profiles["local"].Publish)
+
+	pol := db.Profiles["live"].Publish
+	assert.NotNil(t, pol)
+	assert.Equal(t, []string{"master", "release/*"}, pol.Branches)
+	assert.Equal(t, []string{"v*"}, pol.Tags)
+	assert.True(t, pol.SignedCommits)
+	assert.True(t, pol.Pushed)
 }
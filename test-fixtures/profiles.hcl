@@ -20,6 +20,12 @@ profiles {
     live {
         orchestrator = "nomad"
         registry = "ecr"
+        publish {
+            branches = ["master", "release/*"]
+            tags = ["v*"]
+            signed_commits = true
+            pushed = true
+        }
     }
     // Example 
     custom {
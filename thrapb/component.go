@@ -11,6 +11,7 @@ import (
 	"errors"
 	"hash"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/docker/docker/api/types"
@@ -43,8 +44,39 @@ const (
 var (
 	errTypeNotSpecified = errors.New("component type not specified")
 	errDatastoreHead    = errors.New("datastore cannot be a head")
+	errSeedNotDatastore = errors.New("seed only supported by datastores")
+	errSeedCmdMissing   = errors.New("seed command not specified")
+	errMigrateDatastore = errors.New("datastore cannot have a migration")
 )
 
+const (
+	// DefaultSeedTarget is the container directory seed files are copied to
+	// when not specified
+	DefaultSeedTarget = "/thrap-seed"
+	// DefaultSeedTimeout is the default number of seconds to wait for a seed
+	// to succeed
+	DefaultSeedTimeout = 60
+	// DefaultMigrateTimeout is the default number of seconds to wait for a
+	// migration to complete
+	DefaultMigrateTimeout = 300
+)
+
+// PortLabels labels the ports the same way image ports are populated i.e.
+// default for a single port otherwise port<number>
+func PortLabels(ports []int32) map[string]int32 {
+	if len(ports) == 0 {
+		return nil
+	}
+	if len(ports) == 1 {
+		return map[string]int32{"default": ports[0]}
+	}
+	out := make(map[string]int32, len(ports))
+	for _, p := range ports {
+		out["port"+strconv.Itoa(int(p))] = p
+	}
+	return out
+}
+
 // NewComponent returns a new Compoenent of the given type, name and version
 func NewComponent(name, version string, typ CompType) *Component {
 	return &Component{
@@ -108,6 +140,16 @@ func (comp *Component) IsBuildable() bool {
 	return comp.Build != nil && comp.Build.Dockerfile != ""
 }
 
+// HasSeed returns true if the component has seed data defined
+func (comp *Component) HasSeed() bool {
+	return comp.Seed != nil
+}
+
+// HasMigration returns true if the component has a migration hook
+func (comp *Component) HasMigration() bool {
+	return comp.Migrate != nil
+}
+
 // HasSecrets returns true if this component has specified secrets mgmt
 func (comp *Component) HasSecrets() bool {
 	return comp.Secrets != nil && comp.Secrets.Destination != ""
@@ -131,6 +173,21 @@ func (comp *Component) Validate() error {
 
 	}
 
+	if comp.HasSeed() {
+		if err := comp.validateSeed(); err != nil {
+			return err
+		}
+	}
+
+	if comp.HasMigration() {
+		if comp.Type == CompTypeDatastore {
+			return errMigrateDatastore
+		}
+		if comp.Migrate.Timeout <= 0 {
+			comp.Migrate.Timeout = DefaultMigrateTimeout
+		}
+	}
+
 	return comp.validateCommon()
 }
 
@@ -146,6 +203,23 @@ func (comp *Component) HasLanguage() bool {
 	return comp.Language.Lang() != ""
 }
 
+func (comp *Component) validateSeed() error {
+	if comp.Type != CompTypeDatastore {
+		return errSeedNotDatastore
+	}
+	if comp.Seed.Cmd == "" {
+		return errSeedCmdMissing
+	}
+
+	if comp.Seed.Target == "" {
+		comp.Seed.Target = DefaultSeedTarget
+	}
+	if comp.Seed.Timeout <= 0 {
+		comp.Seed.Timeout = DefaultSeedTimeout
+	}
+	return nil
+}
+
 func (comp *Component) validateCommon() error {
 
 	var err error
@@ -237,6 +311,19 @@ func (comp *Component) Hash(h hash.Hash) {
 	h.Write([]byte(comp.Cmd))
 	h.Write([]byte(strings.Join(comp.Args, "")))
 
+	if comp.Seed != nil {
+		h.Write([]byte(strings.Join(comp.Seed.Files, "")))
+		h.Write([]byte(comp.Seed.Target))
+		h.Write([]byte(comp.Seed.Cmd))
+		binary.Write(h, binary.BigEndian, comp.Seed.Timeout)
+	}
+
+	if comp.Migrate != nil {
+		h.Write([]byte(comp.Migrate.Cmd))
+		h.Write([]byte(strings.Join(comp.Migrate.Args, "")))
+		binary.Write(h, binary.BigEndian, comp.Migrate.Timeout)
+	}
+
 }
 
 // CompStatus holds the overall component status
@@ -48,3 +48,42 @@ func Test_Component(t *testing.T) {
 	c.Secrets = &Secrets{Destination: "foo"}
 	assert.True(t, c.HasSecrets())
 }
+
+func Test_Component_Seed(t *testing.T) {
+	c := &Component{
+		Type:    CompTypeAPI,
+		Version: "1.0.0",
+		Seed:    &Seed{Cmd: "true"},
+	}
+	assert.Equal(t, errSeedNotDatastore, c.Validate())
+
+	c.Type = CompTypeDatastore
+	c.Seed.Cmd = ""
+	assert.Equal(t, errSeedCmdMissing, c.Validate())
+
+	c.Seed.Cmd = "psql -f init.sql"
+	assert.Nil(t, c.Validate())
+	assert.True(t, c.HasSeed())
+	assert.Equal(t, DefaultSeedTarget, c.Seed.Target)
+	assert.Equal(t, int64(DefaultSeedTimeout), c.Seed.Timeout)
+}
+
+func Test_Component_Migrate(t *testing.T) {
+	c := &Component{
+		Type:    CompTypeDatastore,
+		Version: "1.0.0",
+		Migrate: &Migrate{Cmd: "migrate"},
+	}
+	assert.Equal(t, errMigrateDatastore, c.Validate())
+
+	c.Type = CompTypeAPI
+	assert.Nil(t, c.Validate())
+	assert.True(t, c.HasMigration())
+	assert.Equal(t, int64(DefaultMigrateTimeout), c.Migrate.Timeout)
+}
+
+func Test_PortLabels(t *testing.T) {
+	assert.Nil(t, PortLabels(nil))
+	assert.Equal(t, map[string]int32{"default": 80}, PortLabels([]int32{80}))
+	assert.Equal(t, map[string]int32{"port80": 80, "port443": 443}, PortLabels([]int32{80, 443}))
+}
@@ -6,6 +6,11 @@ Sniperkit-Bot
 
 package thrapb
 
+import (
+	"encoding/binary"
+	"hash"
+)
+
 // DefaultProfile returns the default local profile
 func DefaultProfile() *Profile {
 	return &Profile{
@@ -14,3 +19,14 @@ func DefaultProfile() *Profile {
 		Registry:     "docker",
 	}
 }
+
+// SigHash returns the hash to be used to sign the override. This is
+// everything except the signature itself
+func (o *PublishOverride) SigHash(h hash.Hash) []byte {
+	for _, s := range []string{o.Identity, o.Stack, o.Profile, o.Commit, o.Reason} {
+		binary.Write(h, binary.BigEndian, uint32(len(s)))
+		h.Write([]byte(s))
+	}
+	binary.Write(h, binary.BigEndian, o.Timestamp)
+	return h.Sum(nil)
+}
@@ -19,6 +19,8 @@ Sniperkit-Bot
 		Volume
 		Envionment
 		HealthCheck
+		Seed
+		Migrate
 		Component
 		PackManifest
 		Language
@@ -26,6 +28,9 @@ Sniperkit-Bot
 		Identity
 		Artifact
 		Profile
+		PublishPolicy
+		PublishOverride
+		Preview
 		IterOptions
 */
 package thrapb
@@ -210,6 +215,86 @@ func (m *HealthCheck) GetPortLabel() string {
 	return ""
 }
 
+type Seed struct {
+	// Fixture files or scripts, relative to the project, copied into the
+	// container
+	Files []string `protobuf:"bytes,1,rep,name=Files" json:"Files,omitempty" hcl:"files" yaml:",omitempty"`
+	// Container directory the files are copied to
+	Target string `protobuf:"bytes,2,opt,name=Target,proto3" json:"Target,omitempty" hcl:"target" hcle:"omitempty" yaml:",omitempty"`
+	// Shell command run from the target directory to load the fixtures
+	Cmd string `protobuf:"bytes,3,opt,name=Cmd,proto3" json:"Cmd,omitempty" hcl:"cmd"`
+	// Seconds to wait for the datastore to become healthy before running the command
+	Timeout int64 `protobuf:"varint,4,opt,name=Timeout,proto3" json:"Timeout,omitempty" hcl:"timeout" hcle:"omitempty" yaml:",omitempty"`
+}
+
+func (m *Seed) Reset()                    { *m = Seed{} }
+func (m *Seed) String() string            { return proto.CompactTextString(m) }
+func (*Seed) ProtoMessage()               {}
+func (*Seed) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{5} }
+
+func (m *Seed) GetFiles() []string {
+	if m != nil {
+		return m.Files
+	}
+	return nil
+}
+
+func (m *Seed) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+func (m *Seed) GetCmd() string {
+	if m != nil {
+		return m.Cmd
+	}
+	return ""
+}
+
+func (m *Seed) GetTimeout() int64 {
+	if m != nil {
+		return m.Timeout
+	}
+	return 0
+}
+
+type Migrate struct {
+	// Command run in a one-shot container of the component image in place
+	// of the image default command
+	Cmd  string   `protobuf:"bytes,1,opt,name=Cmd,proto3" json:"Cmd,omitempty" hcl:"cmd" hcle:"omitempty" yaml:",omitempty"`
+	Args []string `protobuf:"bytes,2,rep,name=Args" json:"Args,omitempty" hcl:"args" hcle:"omitempty" yaml:",omitempty"`
+	// Seconds to wait for the migration to complete
+	Timeout int64 `protobuf:"varint,3,opt,name=Timeout,proto3" json:"Timeout,omitempty" hcl:"timeout" hcle:"omitempty" yaml:",omitempty"`
+}
+
+func (m *Migrate) Reset()                    { *m = Migrate{} }
+func (m *Migrate) String() string            { return proto.CompactTextString(m) }
+func (*Migrate) ProtoMessage()               {}
+func (*Migrate) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{6} }
+
+func (m *Migrate) GetCmd() string {
+	if m != nil {
+		return m.Cmd
+	}
+	return ""
+}
+
+func (m *Migrate) GetArgs() []string {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+func (m *Migrate) GetTimeout() int64 {
+	if m != nil {
+		return m.Timeout
+	}
+	return 0
+}
+
 type Component struct {
 	ID       string     `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty" hcle:"omit" yaml:"-"`
 	Name     string     `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty" hcl:"name"`
@@ -239,12 +324,16 @@ type Component struct {
 	Args []string `protobuf:"bytes,15,rep,name=Args" json:"Args,omitempty" hcl:"args" hcle:"omitempty" yaml:",omitempty"`
 	// All healthchecks
 	HealthChecks []*HealthCheck `protobuf:"bytes,16,rep,name=HealthChecks" json:"HealthChecks,omitempty"`
+	// Data loaded once into a datastore after it starts
+	Seed *Seed `protobuf:"bytes,17,opt,name=Seed" json:"Seed,omitempty" hcl:"seed" hcle:"omitempty" yaml:",omitempty"`
+	// Migration run to completion before the stack is deployed
+	Migrate *Migrate `protobuf:"bytes,18,opt,name=Migrate" json:"Migrate,omitempty" hcl:"migrate" hcle:"omitempty" yaml:",omitempty"`
 }
 
 func (m *Component) Reset()                    { *m = Component{} }
 func (m *Component) String() string            { return proto.CompactTextString(m) }
 func (*Component) ProtoMessage()               {}
-func (*Component) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{5} }
+func (*Component) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{7} }
 
 func (m *Component) GetID() string {
 	if m != nil {
@@ -358,6 +447,20 @@ func (m *Component) GetHealthChecks() []*HealthCheck {
 	return nil
 }
 
+func (m *Component) GetSeed() *Seed {
+	if m != nil {
+		return m.Seed
+	}
+	return nil
+}
+
+func (m *Component) GetMigrate() *Migrate {
+	if m != nil {
+		return m.Migrate
+	}
+	return nil
+}
+
 type PackManifest struct {
 	// Pack name
 	Name string `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
@@ -374,7 +477,7 @@ type PackManifest struct {
 func (m *PackManifest) Reset()                    { *m = PackManifest{} }
 func (m *PackManifest) String() string            { return proto.CompactTextString(m) }
 func (*PackManifest) ProtoMessage()               {}
-func (*PackManifest) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{6} }
+func (*PackManifest) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{8} }
 
 func (m *PackManifest) GetName() string {
 	if m != nil {
@@ -433,7 +536,7 @@ type Language struct {
 func (m *Language) Reset()                    { *m = Language{} }
 func (m *Language) String() string            { return proto.CompactTextString(m) }
 func (*Language) ProtoMessage()               {}
-func (*Language) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{7} }
+func (*Language) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{9} }
 
 func (m *Language) GetName() string {
 	if m != nil {
@@ -503,7 +606,7 @@ type Stack struct {
 func (m *Stack) Reset()                    { *m = Stack{} }
 func (m *Stack) String() string            { return proto.CompactTextString(m) }
 func (*Stack) ProtoMessage()               {}
-func (*Stack) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{8} }
+func (*Stack) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{10} }
 
 func (m *Stack) GetID() string {
 	if m != nil {
@@ -560,7 +663,7 @@ type Identity struct {
 func (m *Identity) Reset()                    { *m = Identity{} }
 func (m *Identity) String() string            { return proto.CompactTextString(m) }
 func (*Identity) ProtoMessage()               {}
-func (*Identity) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{9} }
+func (*Identity) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{11} }
 
 func (m *Identity) GetID() string {
 	if m != nil {
@@ -615,7 +718,7 @@ type Artifact struct {
 func (m *Artifact) Reset()                    { *m = Artifact{} }
 func (m *Artifact) String() string            { return proto.CompactTextString(m) }
 func (*Artifact) ProtoMessage()               {}
-func (*Artifact) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{10} }
+func (*Artifact) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{12} }
 
 func (m *Artifact) GetID() github_com_opencontainers_go_digest.Digest {
 	if m != nil {
@@ -657,12 +760,14 @@ type Profile struct {
 	Orchestrator string `protobuf:"bytes,2,opt,name=Orchestrator,proto3" json:"Orchestrator,omitempty" hcl:"orchestrator"`
 	Secrets      string `protobuf:"bytes,3,opt,name=Secrets,proto3" json:"Secrets,omitempty" hcl:"secrets"`
 	Registry     string `protobuf:"bytes,4,opt,name=Registry,proto3" json:"Registry,omitempty" hcl:"registry"`
+	// Conditions artifacts may be published under. Unrestricted if not set
+	Publish *PublishPolicy `protobuf:"bytes,5,opt,name=Publish" json:"Publish,omitempty" hcl:"publish" hcle:"omitempty"`
 }
 
 func (m *Profile) Reset()                    { *m = Profile{} }
 func (m *Profile) String() string            { return proto.CompactTextString(m) }
 func (*Profile) ProtoMessage()               {}
-func (*Profile) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{11} }
+func (*Profile) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{13} }
 
 func (m *Profile) GetID() string {
 	if m != nil {
@@ -692,6 +797,234 @@ func (m *Profile) GetRegistry() string {
 	return ""
 }
 
+func (m *Profile) GetPublish() *PublishPolicy {
+	if m != nil {
+		return m.Publish
+	}
+	return nil
+}
+
+type PublishPolicy struct {
+	// Branches artifacts may be published from. Glob patterns i.e. release/*
+	Branches []string `protobuf:"bytes,1,rep,name=Branches" json:"Branches,omitempty" hcl:"branches" hcle:"omitempty"`
+	// Tags artifacts may be published from. Glob patterns i.e. v*
+	Tags []string `protobuf:"bytes,2,rep,name=Tags" json:"Tags,omitempty" hcl:"tags" hcle:"omitempty"`
+	// Require a gpg or ssh signed HEAD commit
+	SignedCommits bool `protobuf:"varint,3,opt,name=SignedCommits,proto3" json:"SignedCommits,omitempty" hcl:"signed_commits" hcle:"omitempty"`
+	// Armored pgp public keyring file gpg signatures are verified against
+	GPGKeyring string `protobuf:"bytes,4,opt,name=GPGKeyring,proto3" json:"GPGKeyring,omitempty" hcl:"gpg_keyring" hcle:"omitempty"`
+	// authorized_keys formatted file ssh signatures are verified against
+	SSHAllowedKeys string `protobuf:"bytes,5,opt,name=SSHAllowedKeys,proto3" json:"SSHAllowedKeys,omitempty" hcl:"ssh_allowed_keys" hcle:"omitempty"`
+	// Require HEAD to be pushed to the remote
+	Pushed bool `protobuf:"varint,6,opt,name=Pushed,proto3" json:"Pushed,omitempty" hcl:"pushed" hcle:"omitempty"`
+	// Identities that may override a violated policy. Any registered
+	// identity if empty
+	Overriders []string `protobuf:"bytes,7,rep,name=Overriders" json:"Overriders,omitempty" hcl:"overriders" hcle:"omitempty"`
+}
+
+func (m *PublishPolicy) Reset()                    { *m = PublishPolicy{} }
+func (m *PublishPolicy) String() string            { return proto.CompactTextString(m) }
+func (*PublishPolicy) ProtoMessage()               {}
+func (*PublishPolicy) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{14} }
+
+func (m *PublishPolicy) GetBranches() []string {
+	if m != nil {
+		return m.Branches
+	}
+	return nil
+}
+
+func (m *PublishPolicy) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *PublishPolicy) GetSignedCommits() bool {
+	if m != nil {
+		return m.SignedCommits
+	}
+	return false
+}
+
+func (m *PublishPolicy) GetGPGKeyring() string {
+	if m != nil {
+		return m.GPGKeyring
+	}
+	return ""
+}
+
+func (m *PublishPolicy) GetSSHAllowedKeys() string {
+	if m != nil {
+		return m.SSHAllowedKeys
+	}
+	return ""
+}
+
+func (m *PublishPolicy) GetPushed() bool {
+	if m != nil {
+		return m.Pushed
+	}
+	return false
+}
+
+func (m *PublishPolicy) GetOverriders() []string {
+	if m != nil {
+		return m.Overriders
+	}
+	return nil
+}
+
+type PublishOverride struct {
+	// Registered identity signing the override
+	Identity string `protobuf:"bytes,1,opt,name=Identity,proto3" json:"identity"`
+	Stack    string `protobuf:"bytes,2,opt,name=Stack,proto3" json:"stack"`
+	Profile  string `protobuf:"bytes,3,opt,name=Profile,proto3" json:"profile"`
+	// Commit the override applies to
+	Commit string `protobuf:"bytes,4,opt,name=Commit,proto3" json:"commit"`
+	Reason string `protobuf:"bytes,5,opt,name=Reason,proto3" json:"reason"`
+	// Unix nanoseconds
+	Timestamp int64 `protobuf:"varint,6,opt,name=Timestamp,proto3" json:"timestamp"`
+	// Signature of the identity over the SigHash
+	Signature []byte `protobuf:"bytes,7,opt,name=Signature,proto3" json:"signature"`
+}
+
+func (m *PublishOverride) Reset()                    { *m = PublishOverride{} }
+func (m *PublishOverride) String() string            { return proto.CompactTextString(m) }
+func (*PublishOverride) ProtoMessage()               {}
+func (*PublishOverride) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{15} }
+
+func (m *PublishOverride) GetIdentity() string {
+	if m != nil {
+		return m.Identity
+	}
+	return ""
+}
+
+func (m *PublishOverride) GetStack() string {
+	if m != nil {
+		return m.Stack
+	}
+	return ""
+}
+
+func (m *PublishOverride) GetProfile() string {
+	if m != nil {
+		return m.Profile
+	}
+	return ""
+}
+
+func (m *PublishOverride) GetCommit() string {
+	if m != nil {
+		return m.Commit
+	}
+	return ""
+}
+
+func (m *PublishOverride) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *PublishOverride) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *PublishOverride) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+type Preview struct {
+	// Isolated stack id derived from the stack id and branch
+	ID string `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	// Branch or pull request the preview was created from
+	Name string `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	// ID of the stack the preview is of
+	StackID string `protobuf:"bytes,3,opt,name=StackID,proto3" json:"StackID,omitempty"`
+	// Profile the preview was deployed with
+	Profile *Profile `protobuf:"bytes,4,opt,name=Profile" json:"Profile,omitempty"`
+	// Deployed preview stack
+	Stack *Stack `protobuf:"bytes,5,opt,name=Stack" json:"Stack,omitempty"`
+	// Ingress hostnames of the preview
+	Hosts []string `protobuf:"bytes,6,rep,name=Hosts" json:"Hosts,omitempty"`
+	// Unix nanoseconds
+	Created int64 `protobuf:"varint,7,opt,name=Created,proto3" json:"Created,omitempty"`
+	// Unix nanoseconds after which the preview is destroyed. Zero never
+	// expires
+	Expires int64 `protobuf:"varint,8,opt,name=Expires,proto3" json:"Expires,omitempty"`
+}
+
+func (m *Preview) Reset()                    { *m = Preview{} }
+func (m *Preview) String() string            { return proto.CompactTextString(m) }
+func (*Preview) ProtoMessage()               {}
+func (*Preview) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{16} }
+
+func (m *Preview) GetID() string {
+	if m != nil {
+		return m.ID
+	}
+	return ""
+}
+
+func (m *Preview) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Preview) GetStackID() string {
+	if m != nil {
+		return m.StackID
+	}
+	return ""
+}
+
+func (m *Preview) GetProfile() *Profile {
+	if m != nil {
+		return m.Profile
+	}
+	return nil
+}
+
+func (m *Preview) GetStack() *Stack {
+	if m != nil {
+		return m.Stack
+	}
+	return nil
+}
+
+func (m *Preview) GetHosts() []string {
+	if m != nil {
+		return m.Hosts
+	}
+	return nil
+}
+
+func (m *Preview) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *Preview) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
 type IterOptions struct {
 	Prefix string `protobuf:"bytes,1,opt,name=Prefix,proto3" json:"Prefix,omitempty"`
 }
@@ -699,7 +1032,7 @@ type IterOptions struct {
 func (m *IterOptions) Reset()                    { *m = IterOptions{} }
 func (m *IterOptions) String() string            { return proto.CompactTextString(m) }
 func (*IterOptions) ProtoMessage()               {}
-func (*IterOptions) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{12} }
+func (*IterOptions) Descriptor() ([]byte, []int) { return fileDescriptorThrap, []int{17} }
 
 func (m *IterOptions) GetPrefix() string {
 	if m != nil {
@@ -714,6 +1047,8 @@ func init() {
 	proto.RegisterType((*Volume)(nil), "Volume")
 	proto.RegisterType((*Envionment)(nil), "Envionment")
 	proto.RegisterType((*HealthCheck)(nil), "HealthCheck")
+	proto.RegisterType((*Seed)(nil), "Seed")
+	proto.RegisterType((*Migrate)(nil), "Migrate")
 	proto.RegisterType((*Component)(nil), "Component")
 	proto.RegisterType((*PackManifest)(nil), "PackManifest")
 	proto.RegisterType((*Language)(nil), "Language")
@@ -721,6 +1056,9 @@ func init() {
 	proto.RegisterType((*Identity)(nil), "Identity")
 	proto.RegisterType((*Artifact)(nil), "Artifact")
 	proto.RegisterType((*Profile)(nil), "Profile")
+	proto.RegisterType((*PublishPolicy)(nil), "PublishPolicy")
+	proto.RegisterType((*PublishOverride)(nil), "PublishOverride")
+	proto.RegisterType((*Preview)(nil), "Preview")
 	proto.RegisterType((*IterOptions)(nil), "IterOptions")
 }
 
@@ -743,6 +1081,10 @@ type ThrapClient interface {
 	IterIdentities(ctx context.Context, in *IterOptions, opts ...grpc.CallOption) (Thrap_IterIdentitiesClient, error)
 	ConfirmIdentity(ctx context.Context, in *Identity, opts ...grpc.CallOption) (*Identity, error)
 	GetIdentity(ctx context.Context, in *Identity, opts ...grpc.CallOption) (*Identity, error)
+	VerifyPublishOverride(ctx context.Context, in *PublishOverride, opts ...grpc.CallOption) (*Identity, error)
+	CreatePreview(ctx context.Context, in *Preview, opts ...grpc.CallOption) (*Preview, error)
+	IterPreviews(ctx context.Context, in *IterOptions, opts ...grpc.CallOption) (Thrap_IterPreviewsClient, error)
+	DestroyPreview(ctx context.Context, in *Preview, opts ...grpc.CallOption) (*Preview, error)
 }
 
 type thrapClient struct {
@@ -871,6 +1213,65 @@ func (c *thrapClient) GetIdentity(ctx context.Context, in *Identity, opts ...grp
 	return out, nil
 }
 
+func (c *thrapClient) VerifyPublishOverride(ctx context.Context, in *PublishOverride, opts ...grpc.CallOption) (*Identity, error) {
+	out := new(Identity)
+	err := grpc.Invoke(ctx, "/Thrap/VerifyPublishOverride", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thrapClient) CreatePreview(ctx context.Context, in *Preview, opts ...grpc.CallOption) (*Preview, error) {
+	out := new(Preview)
+	err := grpc.Invoke(ctx, "/Thrap/CreatePreview", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thrapClient) IterPreviews(ctx context.Context, in *IterOptions, opts ...grpc.CallOption) (Thrap_IterPreviewsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Thrap_serviceDesc.Streams[2], c.cc, "/Thrap/IterPreviews", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &thrapIterPreviewsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Thrap_IterPreviewsClient interface {
+	Recv() (*Preview, error)
+	grpc.ClientStream
+}
+
+type thrapIterPreviewsClient struct {
+	grpc.ClientStream
+}
+
+func (x *thrapIterPreviewsClient) Recv() (*Preview, error) {
+	m := new(Preview)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *thrapClient) DestroyPreview(ctx context.Context, in *Preview, opts ...grpc.CallOption) (*Preview, error) {
+	out := new(Preview)
+	err := grpc.Invoke(ctx, "/Thrap/DestroyPreview", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Thrap service
 
 type ThrapServer interface {
@@ -882,6 +1283,10 @@ type ThrapServer interface {
 	IterIdentities(*IterOptions, Thrap_IterIdentitiesServer) error
 	ConfirmIdentity(context.Context, *Identity) (*Identity, error)
 	GetIdentity(context.Context, *Identity) (*Identity, error)
+	VerifyPublishOverride(context.Context, *PublishOverride) (*Identity, error)
+	CreatePreview(context.Context, *Preview) (*Preview, error)
+	IterPreviews(*IterOptions, Thrap_IterPreviewsServer) error
+	DestroyPreview(context.Context, *Preview) (*Preview, error)
 }
 
 func RegisterThrapServer(s *grpc.Server, srv ThrapServer) {
@@ -1038,38 +1443,125 @@ func _Thrap_GetIdentity_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
-var _Thrap_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "Thrap",
-	HandlerType: (*ThrapServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "RegisterStack",
-			Handler:    _Thrap_RegisterStack_Handler,
-		},
-		{
-			MethodName: "CommitStack",
-			Handler:    _Thrap_CommitStack_Handler,
-		},
-		{
-			MethodName: "GetStack",
-			Handler:    _Thrap_GetStack_Handler,
-		},
-		{
-			MethodName: "RegisterIdentity",
-			Handler:    _Thrap_RegisterIdentity_Handler,
-		},
-		{
-			MethodName: "ConfirmIdentity",
-			Handler:    _Thrap_ConfirmIdentity_Handler,
-		},
-		{
-			MethodName: "GetIdentity",
-			Handler:    _Thrap_GetIdentity_Handler,
-		},
-	},
-	Streams: []grpc.StreamDesc{
-		{
-			StreamName:    "IterStacks",
+func _Thrap_VerifyPublishOverride_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishOverride)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThrapServer).VerifyPublishOverride(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Thrap/VerifyPublishOverride",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThrapServer).VerifyPublishOverride(ctx, req.(*PublishOverride))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Thrap_CreatePreview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Preview)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThrapServer).CreatePreview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Thrap/CreatePreview",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThrapServer).CreatePreview(ctx, req.(*Preview))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Thrap_IterPreviews_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(IterOptions)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ThrapServer).IterPreviews(m, &thrapIterPreviewsServer{stream})
+}
+
+type Thrap_IterPreviewsServer interface {
+	Send(*Preview) error
+	grpc.ServerStream
+}
+
+type thrapIterPreviewsServer struct {
+	grpc.ServerStream
+}
+
+func (x *thrapIterPreviewsServer) Send(m *Preview) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Thrap_DestroyPreview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Preview)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThrapServer).DestroyPreview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Thrap/DestroyPreview",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThrapServer).DestroyPreview(ctx, req.(*Preview))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Thrap_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "Thrap",
+	HandlerType: (*ThrapServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterStack",
+			Handler:    _Thrap_RegisterStack_Handler,
+		},
+		{
+			MethodName: "CommitStack",
+			Handler:    _Thrap_CommitStack_Handler,
+		},
+		{
+			MethodName: "GetStack",
+			Handler:    _Thrap_GetStack_Handler,
+		},
+		{
+			MethodName: "RegisterIdentity",
+			Handler:    _Thrap_RegisterIdentity_Handler,
+		},
+		{
+			MethodName: "ConfirmIdentity",
+			Handler:    _Thrap_ConfirmIdentity_Handler,
+		},
+		{
+			MethodName: "GetIdentity",
+			Handler:    _Thrap_GetIdentity_Handler,
+		},
+		{
+			MethodName: "VerifyPublishOverride",
+			Handler:    _Thrap_VerifyPublishOverride_Handler,
+		},
+		{
+			MethodName: "CreatePreview",
+			Handler:    _Thrap_CreatePreview_Handler,
+		},
+		{
+			MethodName: "DestroyPreview",
+			Handler:    _Thrap_DestroyPreview_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "IterStacks",
 			Handler:       _Thrap_IterStacks_Handler,
 			ServerStreams: true,
 		},
@@ -1078,6 +1570,11 @@ var _Thrap_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Thrap_IterIdentities_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "IterPreviews",
+			Handler:       _Thrap_IterPreviews_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "thrap.proto",
 }
@@ -1265,6 +1762,100 @@ func (m *HealthCheck) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *Seed) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Seed) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Files) > 0 {
+		for _, s := range m.Files {
+			dAtA[i] = 0xa
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if len(m.Target) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.Target)))
+		i += copy(dAtA[i:], m.Target)
+	}
+	if len(m.Cmd) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.Cmd)))
+		i += copy(dAtA[i:], m.Cmd)
+	}
+	if m.Timeout != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(m.Timeout))
+	}
+	return i, nil
+}
+
+func (m *Migrate) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Migrate) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Cmd) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.Cmd)))
+		i += copy(dAtA[i:], m.Cmd)
+	}
+	if len(m.Args) > 0 {
+		for _, s := range m.Args {
+			dAtA[i] = 0x12
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if m.Timeout != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(m.Timeout))
+	}
+	return i, nil
+}
+
 func (m *Component) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1440,6 +2031,30 @@ func (m *Component) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	if m.Seed != nil {
+		dAtA[i] = 0x8a
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(m.Seed.Size()))
+		n4, err := m.Seed.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n4
+	}
+	if m.Migrate != nil {
+		dAtA[i] = 0x92
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(m.Migrate.Size()))
+		n5, err := m.Migrate.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n5
+	}
 	return i, nil
 }
 
@@ -1682,11 +2297,11 @@ func (m *Stack) MarshalTo(dAtA []byte) (int, error) {
 				dAtA[i] = 0x12
 				i++
 				i = encodeVarintThrap(dAtA, i, uint64(v.Size()))
-				n4, err := v.MarshalTo(dAtA[i:])
+				n6, err := v.MarshalTo(dAtA[i:])
 				if err != nil {
 					return 0, err
 				}
-				i += n4
+				i += n6
 			}
 		}
 	}
@@ -1710,11 +2325,11 @@ func (m *Stack) MarshalTo(dAtA []byte) (int, error) {
 				dAtA[i] = 0x12
 				i++
 				i = encodeVarintThrap(dAtA, i, uint64(v.Size()))
-				n5, err := v.MarshalTo(dAtA[i:])
+				n7, err := v.MarshalTo(dAtA[i:])
 				if err != nil {
 					return 0, err
 				}
-				i += n5
+				i += n7
 			}
 		}
 	}
@@ -1896,10 +2511,20 @@ func (m *Profile) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintThrap(dAtA, i, uint64(len(m.Registry)))
 		i += copy(dAtA[i:], m.Registry)
 	}
+	if m.Publish != nil {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(m.Publish.Size()))
+		n8, err := m.Publish.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n8
+	}
 	return i, nil
 }
 
-func (m *IterOptions) Marshal() (dAtA []byte, err error) {
+func (m *PublishPolicy) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -1909,31 +2534,266 @@ func (m *IterOptions) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *IterOptions) MarshalTo(dAtA []byte) (int, error) {
+func (m *PublishPolicy) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if len(m.Prefix) > 0 {
-		dAtA[i] = 0xa
+	if len(m.Branches) > 0 {
+		for _, s := range m.Branches {
+			dAtA[i] = 0xa
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if len(m.Tags) > 0 {
+		for _, s := range m.Tags {
+			dAtA[i] = 0x12
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if m.SignedCommits {
+		dAtA[i] = 0x18
+		i++
+		if m.SignedCommits {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
 		i++
-		i = encodeVarintThrap(dAtA, i, uint64(len(m.Prefix)))
-		i += copy(dAtA[i:], m.Prefix)
 	}
-	return i, nil
-}
-
-func encodeVarintThrap(dAtA []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+	if len(m.GPGKeyring) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.GPGKeyring)))
+		i += copy(dAtA[i:], m.GPGKeyring)
 	}
-	dAtA[offset] = uint8(v)
-	return offset + 1
-}
-func (m *Build) Size() (n int) {
-	var l int
+	if len(m.SSHAllowedKeys) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.SSHAllowedKeys)))
+		i += copy(dAtA[i:], m.SSHAllowedKeys)
+	}
+	if m.Pushed {
+		dAtA[i] = 0x30
+		i++
+		if m.Pushed {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Overriders) > 0 {
+		for _, s := range m.Overriders {
+			dAtA[i] = 0x3a
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	return i, nil
+}
+
+func (m *PublishOverride) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PublishOverride) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Identity) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.Identity)))
+		i += copy(dAtA[i:], m.Identity)
+	}
+	if len(m.Stack) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.Stack)))
+		i += copy(dAtA[i:], m.Stack)
+	}
+	if len(m.Profile) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.Profile)))
+		i += copy(dAtA[i:], m.Profile)
+	}
+	if len(m.Commit) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.Commit)))
+		i += copy(dAtA[i:], m.Commit)
+	}
+	if len(m.Reason) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.Reason)))
+		i += copy(dAtA[i:], m.Reason)
+	}
+	if m.Timestamp != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(m.Timestamp))
+	}
+	if len(m.Signature) > 0 {
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.Signature)))
+		i += copy(dAtA[i:], m.Signature)
+	}
+	return i, nil
+}
+
+func (m *Preview) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Preview) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ID) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.ID)))
+		i += copy(dAtA[i:], m.ID)
+	}
+	if len(m.Name) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
+	}
+	if len(m.StackID) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.StackID)))
+		i += copy(dAtA[i:], m.StackID)
+	}
+	if m.Profile != nil {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(m.Profile.Size()))
+		n9, err := m.Profile.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n9
+	}
+	if m.Stack != nil {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(m.Stack.Size()))
+		n10, err := m.Stack.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n10
+	}
+	if len(m.Hosts) > 0 {
+		for _, s := range m.Hosts {
+			dAtA[i] = 0x32
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if m.Created != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(m.Created))
+	}
+	if m.Expires != 0 {
+		dAtA[i] = 0x40
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(m.Expires))
+	}
+	return i, nil
+}
+
+func (m *IterOptions) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *IterOptions) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Prefix) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintThrap(dAtA, i, uint64(len(m.Prefix)))
+		i += copy(dAtA[i:], m.Prefix)
+	}
+	return i, nil
+}
+
+func encodeVarintThrap(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *Build) Size() (n int) {
+	var l int
 	_ = l
 	l = len(m.Dockerfile)
 	if l > 0 {
@@ -2020,6 +2880,48 @@ func (m *HealthCheck) Size() (n int) {
 	return n
 }
 
+func (m *Seed) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Files) > 0 {
+		for _, s := range m.Files {
+			l = len(s)
+			n += 1 + l + sovThrap(uint64(l))
+		}
+	}
+	l = len(m.Target)
+	if l > 0 {
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	l = len(m.Cmd)
+	if l > 0 {
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	if m.Timeout != 0 {
+		n += 1 + sovThrap(uint64(m.Timeout))
+	}
+	return n
+}
+
+func (m *Migrate) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Cmd)
+	if l > 0 {
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	if len(m.Args) > 0 {
+		for _, s := range m.Args {
+			l = len(s)
+			n += 1 + l + sovThrap(uint64(l))
+		}
+	}
+	if m.Timeout != 0 {
+		n += 1 + sovThrap(uint64(m.Timeout))
+	}
+	return n
+}
+
 func (m *Component) Size() (n int) {
 	var l int
 	_ = l
@@ -2099,6 +3001,14 @@ func (m *Component) Size() (n int) {
 			n += 2 + l + sovThrap(uint64(l))
 		}
 	}
+	if m.Seed != nil {
+		l = m.Seed.Size()
+		n += 2 + l + sovThrap(uint64(l))
+	}
+	if m.Migrate != nil {
+		l = m.Migrate.Size()
+		n += 2 + l + sovThrap(uint64(l))
+	}
 	return n
 }
 
@@ -2312,49 +3222,162 @@ func (m *Profile) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovThrap(uint64(l))
 	}
+	if m.Publish != nil {
+		l = m.Publish.Size()
+		n += 1 + l + sovThrap(uint64(l))
+	}
 	return n
 }
 
-func (m *IterOptions) Size() (n int) {
+func (m *PublishPolicy) Size() (n int) {
 	var l int
 	_ = l
-	l = len(m.Prefix)
+	if len(m.Branches) > 0 {
+		for _, s := range m.Branches {
+			l = len(s)
+			n += 1 + l + sovThrap(uint64(l))
+		}
+	}
+	if len(m.Tags) > 0 {
+		for _, s := range m.Tags {
+			l = len(s)
+			n += 1 + l + sovThrap(uint64(l))
+		}
+	}
+	if m.SignedCommits {
+		n += 2
+	}
+	l = len(m.GPGKeyring)
 	if l > 0 {
 		n += 1 + l + sovThrap(uint64(l))
 	}
-	return n
-}
-
-func sovThrap(x uint64) (n int) {
-	for {
-		n++
-		x >>= 7
-		if x == 0 {
-			break
+	l = len(m.SSHAllowedKeys)
+	if l > 0 {
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	if m.Pushed {
+		n += 2
+	}
+	if len(m.Overriders) > 0 {
+		for _, s := range m.Overriders {
+			l = len(s)
+			n += 1 + l + sovThrap(uint64(l))
 		}
 	}
 	return n
 }
-func sozThrap(x uint64) (n int) {
-	return sovThrap(uint64((x << 1) ^ uint64((int64(x) >> 63))))
-}
-func (m *Build) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowThrap
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
+
+func (m *PublishOverride) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Identity)
+	if l > 0 {
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	l = len(m.Stack)
+	if l > 0 {
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	l = len(m.Profile)
+	if l > 0 {
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	l = len(m.Commit)
+	if l > 0 {
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	l = len(m.Reason)
+	if l > 0 {
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	if m.Timestamp != 0 {
+		n += 1 + sovThrap(uint64(m.Timestamp))
+	}
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	return n
+}
+
+func (m *Preview) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.ID)
+	if l > 0 {
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	l = len(m.StackID)
+	if l > 0 {
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	if m.Profile != nil {
+		l = m.Profile.Size()
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	if m.Stack != nil {
+		l = m.Stack.Size()
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	if len(m.Hosts) > 0 {
+		for _, s := range m.Hosts {
+			l = len(s)
+			n += 1 + l + sovThrap(uint64(l))
+		}
+	}
+	if m.Created != 0 {
+		n += 1 + sovThrap(uint64(m.Created))
+	}
+	if m.Expires != 0 {
+		n += 1 + sovThrap(uint64(m.Expires))
+	}
+	return n
+}
+
+func (m *IterOptions) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Prefix)
+	if l > 0 {
+		n += 1 + l + sovThrap(uint64(l))
+	}
+	return n
+}
+
+func sovThrap(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozThrap(x uint64) (n int) {
+	return sovThrap(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *Build) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowThrap
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
 				break
 			}
 		}
@@ -3063,7 +4086,7 @@ func (m *HealthCheck) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Component) Unmarshal(dAtA []byte) error {
+func (m *Seed) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3086,15 +4109,15 @@ func (m *Component) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Component: wiretype end group for non-group")
+			return fmt.Errorf("proto: Seed: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Component: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Seed: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Files", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3119,11 +4142,11 @@ func (m *Component) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ID = string(dAtA[iNdEx:postIndex])
+			m.Files = append(m.Files, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Target", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3148,11 +4171,11 @@ func (m *Component) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Name = string(dAtA[iNdEx:postIndex])
+			m.Target = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Cmd", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3177,11 +4200,80 @@ func (m *Component) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Version = string(dAtA[iNdEx:postIndex])
+			m.Cmd = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timeout", wireType)
+			}
+			m.Timeout = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timeout |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipThrap(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthThrap
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Migrate) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowThrap
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Migrate: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Migrate: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Cmd", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3206,11 +4298,11 @@ func (m *Component) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Type = CompType(dAtA[iNdEx:postIndex])
+			m.Cmd = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 5:
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Language", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Args", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3235,13 +4327,13 @@ func (m *Component) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Language = LanguageID(dAtA[iNdEx:postIndex])
+			m.Args = append(m.Args, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
-		case 6:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Build", wireType)
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timeout", wireType)
 			}
-			var msglen int
+			m.Timeout = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowThrap
@@ -3251,30 +4343,66 @@ func (m *Component) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				m.Timeout |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipThrap(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
 				return ErrInvalidLengthThrap
 			}
-			postIndex := iNdEx + msglen
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Build == nil {
-				m.Build = &Build{}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Component) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowThrap
 			}
-			if err := m.Build.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
 			}
-			iNdEx = postIndex
-		case 7:
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Component: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Component: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Secrets", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowThrap
@@ -3284,12 +4412,190 @@ func (m *Component) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Version = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Type = CompType(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Language", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Language = LanguageID(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Build", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Build == nil {
+				m.Build = &Build{}
+			}
+			if err := m.Build.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Secrets", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
 				return ErrInvalidLengthThrap
 			}
 			postIndex := iNdEx + msglen
@@ -3721,6 +5027,72 @@ func (m *Component) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 17:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seed", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Seed == nil {
+				m.Seed = &Seed{}
+			}
+			if err := m.Seed.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 18:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Migrate", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Migrate == nil {
+				m.Migrate = &Migrate{}
+			}
+			if err := m.Migrate.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipThrap(dAtA[iNdEx:])
@@ -5347,6 +6719,789 @@ func (m *Profile) Unmarshal(dAtA []byte) error {
 			}
 			m.Registry = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Publish", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Publish == nil {
+				m.Publish = &PublishPolicy{}
+			}
+			if err := m.Publish.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipThrap(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthThrap
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PublishPolicy) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowThrap
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PublishPolicy: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PublishPolicy: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Branches", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Branches = append(m.Branches, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tags", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Tags = append(m.Tags, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SignedCommits", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.SignedCommits = bool(v != 0)
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GPGKeyring", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.GPGKeyring = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SSHAllowedKeys", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SSHAllowedKeys = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Pushed", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Pushed = bool(v != 0)
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Overriders", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Overriders = append(m.Overriders, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipThrap(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthThrap
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PublishOverride) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowThrap
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PublishOverride: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PublishOverride: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Identity", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Identity = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Stack", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Stack = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Profile", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Profile = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Commit", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Commit = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Reason = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			m.Timestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timestamp |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipThrap(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthThrap
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Preview) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowThrap
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Preview: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Preview: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StackID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StackID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Profile", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Profile == nil {
+				m.Profile = &Profile{}
+			}
+			if err := m.Profile.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Stack", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Stack == nil {
+				m.Stack = &Stack{}
+			}
+			if err := m.Stack.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hosts", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthThrap
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Hosts = append(m.Hosts, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Created", wireType)
+			}
+			m.Created = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Created |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Expires", wireType)
+			}
+			m.Expires = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowThrap
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Expires |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipThrap(dAtA[iNdEx:])
@@ -5555,109 +7710,146 @@ var (
 func init() { proto.RegisterFile("thrap.proto", fileDescriptorThrap) }
 
 var fileDescriptorThrap = []byte{
-	// 1660 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x58, 0x49, 0x6f, 0x23, 0xc7,
-	0x15, 0x0e, 0xf7, 0xe6, 0x23, 0x47, 0xd2, 0x94, 0xc7, 0x83, 0x06, 0x61, 0xab, 0x99, 0xf2, 0x38,
-	0x21, 0x62, 0xab, 0xb5, 0xd8, 0xc1, 0xd8, 0x82, 0x91, 0xc0, 0x14, 0x95, 0x31, 0x31, 0x9b, 0xd2,
-	0x52, 0x26, 0x40, 0x2e, 0x46, 0xa9, 0x59, 0x24, 0x1b, 0xea, 0x85, 0xa8, 0x2e, 0x0a, 0x62, 0x72,
-	0x48, 0x90, 0x5f, 0x10, 0xe4, 0x37, 0xe4, 0x92, 0x4b, 0x7e, 0x47, 0x10, 0xe4, 0x90, 0x6b, 0x2e,
-	0x8d, 0x60, 0xf2, 0x0f, 0xfa, 0x38, 0x87, 0x20, 0xa8, 0xa5, 0x17, 0xc9, 0xb2, 0x87, 0x0a, 0x90,
-	0x8b, 0xd4, 0x6f, 0xfb, 0xaa, 0xea, 0xd5, 0xab, 0xaf, 0x5e, 0x11, 0x3a, 0x7c, 0xce, 0xc8, 0xc2,
-	0x5e, 0xb0, 0x88, 0x47, 0xbd, 0x9d, 0x99, 0xc7, 0xe7, 0xcb, 0x73, 0xdb, 0x8d, 0x82, 0xdd, 0x59,
-	0x34, 0x8b, 0x76, 0xa5, 0xfa, 0x7c, 0x39, 0x95, 0x92, 0x14, 0xe4, 0x97, 0x72, 0xc7, 0xbf, 0x81,
-	0xc6, 0x70, 0xe9, 0xf9, 0x13, 0xf4, 0x29, 0xc0, 0x28, 0x72, 0x2f, 0x28, 0x9b, 0x7a, 0x3e, 0x35,
-	0x2b, 0xfd, 0xca, 0xa0, 0x3d, 0x7c, 0x90, 0x26, 0xd6, 0xd6, 0xdc, 0xf5, 0x0f, 0xf1, 0x24, 0x37,
-	0x61, 0xa7, 0xe4, 0x87, 0xbe, 0x80, 0xd6, 0x51, 0x14, 0x72, 0x7a, 0xc5, 0xcd, 0xaa, 0x0c, 0xc1,
-	0x69, 0x62, 0x6d, 0xcb, 0x10, 0x57, 0xe9, 0x71, 0x7f, 0xee, 0xfa, 0xf4, 0x10, 0x47, 0x81, 0xc7,
-	0x69, 0xb0, 0xe0, 0x2b, 0xec, 0x64, 0x21, 0x98, 0x41, 0xeb, 0x94, 0xba, 0x8c, 0xf2, 0x18, 0x3d,
-	0x86, 0xce, 0x88, 0xc6, 0xdc, 0x0b, 0x09, 0xf7, 0xa2, 0x50, 0x8f, 0xff, 0x6e, 0x9a, 0x58, 0xf7,
-	0xd5, 0xf8, 0x85, 0x0d, 0x3b, 0x65, 0x4f, 0x64, 0x83, 0x71, 0x46, 0x83, 0x85, 0x4f, 0x38, 0xd5,
-	0x53, 0x40, 0x69, 0x62, 0x6d, 0xc8, 0x28, 0xae, 0x0d, 0xd8, 0xc9, 0x7d, 0xf0, 0x6f, 0xa1, 0xf9,
-	0x2a, 0xf2, 0x97, 0x01, 0x45, 0x4f, 0xa1, 0x79, 0x1a, 0x2d, 0x99, 0x9b, 0xad, 0xf6, 0x93, 0x34,
-	0xb1, 0x76, 0x65, 0x5c, 0x2c, 0xd5, 0xdf, 0x9c, 0x79, 0x7f, 0x45, 0x02, 0xff, 0x10, 0x7f, 0x5c,
-	0x5a, 0x8b, 0x86, 0x40, 0x03, 0x68, 0x9e, 0x11, 0x36, 0xa3, 0x59, 0x1e, 0xb6, 0xd2, 0xc4, 0xea,
-	0xaa, 0x49, 0x48, 0x35, 0x76, 0xb4, 0x1d, 0xff, 0xa9, 0x02, 0x70, 0x1c, 0x5e, 0x7a, 0x51, 0x18,
-	0xd0, 0x90, 0x23, 0x0c, 0xf5, 0x9f, 0x15, 0x19, 0xdf, 0x48, 0x13, 0x0b, 0x64, 0x98, 0xca, 0xb5,
-	0xb4, 0xa1, 0xcf, 0xa1, 0xfe, 0x8a, 0xb0, 0xd8, 0xac, 0xf6, 0x6b, 0x83, 0xce, 0xc1, 0xbb, 0x76,
-	0x11, 0x6e, 0x0b, 0xfd, 0x71, 0xc8, 0xd9, 0xaa, 0x14, 0x7a, 0x49, 0x58, 0x8c, 0x1d, 0x19, 0xd2,
-	0x7b, 0x0c, 0xed, 0xdc, 0x05, 0x6d, 0x41, 0xed, 0x82, 0xae, 0xd4, 0x50, 0x8e, 0xf8, 0x44, 0x0f,
-	0xa0, 0x71, 0x49, 0xfc, 0xa5, 0x4e, 0x9d, 0xa3, 0x84, 0xc3, 0xea, 0x67, 0x15, 0xfc, 0xe7, 0x0a,
-	0x74, 0xbe, 0xa2, 0xc4, 0xe7, 0xf3, 0xa3, 0x39, 0x75, 0x2f, 0x50, 0x0f, 0x8c, 0x13, 0x51, 0x31,
-	0x6e, 0xe4, 0x6b, 0x80, 0x5c, 0x46, 0x08, 0xea, 0x27, 0x84, 0xcf, 0x35, 0x88, 0xfc, 0x46, 0x0f,
-	0xa1, 0xf9, 0x9c, 0xf2, 0x79, 0x34, 0x31, 0x6b, 0x52, 0xab, 0x25, 0x64, 0x42, 0xeb, 0xcc, 0x0b,
-	0x68, 0xb4, 0xe4, 0x66, 0xbd, 0x5f, 0x19, 0xd4, 0x9c, 0x4c, 0x14, 0x23, 0x8c, 0x43, 0x4e, 0xd9,
-	0x25, 0xf1, 0xcd, 0x86, 0x34, 0xe5, 0x32, 0x7a, 0x0f, 0xda, 0x27, 0x11, 0xe3, 0xcf, 0xc8, 0x39,
-	0xf5, 0xcd, 0xa6, 0x04, 0x2c, 0x14, 0xf8, 0x77, 0x00, 0xed, 0xa3, 0x28, 0x58, 0x44, 0xa1, 0xc8,
-	0xe8, 0x00, 0xaa, 0xe3, 0x91, 0xce, 0xa7, 0x99, 0x26, 0xd6, 0x83, 0x62, 0x1b, 0xb3, 0x1d, 0xdc,
-	0xc1, 0x4e, 0x75, 0x3c, 0x12, 0xb9, 0x7f, 0x41, 0x82, 0xac, 0x6e, 0x8a, 0x04, 0x86, 0x24, 0x10,
-	0xb9, 0x17, 0x36, 0xf4, 0x02, 0x5a, 0xaf, 0x28, 0x8b, 0x45, 0x51, 0xca, 0x85, 0x0c, 0x3f, 0x4d,
-	0x13, 0x6b, 0x4f, 0xe5, 0x59, 0xe9, 0x6f, 0x29, 0x8b, 0x5b, 0x6a, 0x5e, 0x83, 0x20, 0x1b, 0xea,
-	0x67, 0xab, 0x05, 0x95, 0x8b, 0x6f, 0x0f, 0x7b, 0xf9, 0x98, 0x7c, 0xb5, 0xa0, 0xf8, 0x4d, 0x62,
-	0x19, 0x62, 0x21, 0xc2, 0xc3, 0x91, 0x7e, 0xe8, 0x6b, 0x30, 0x9e, 0x91, 0x70, 0xb6, 0x24, 0x33,
-	0x2a, 0xb3, 0xd2, 0x1e, 0x1e, 0xa5, 0x89, 0xb5, 0x2f, 0x63, 0x7c, 0x6d, 0x58, 0xa7, 0x52, 0xdf,
-	0x24, 0x16, 0x64, 0x40, 0xe3, 0x91, 0x93, 0x83, 0xa2, 0x9f, 0x6a, 0x06, 0x90, 0x69, 0xed, 0x1c,
-	0x34, 0x6d, 0x29, 0x0d, 0xbf, 0x9f, 0x26, 0xd6, 0xfb, 0x72, 0x94, 0x73, 0x21, 0xdf, 0x56, 0xfb,
-	0x9a, 0x39, 0x9e, 0xe4, 0xa7, 0xd8, 0x6c, 0x49, 0x08, 0xc3, 0xd6, 0xf2, 0xf0, 0x83, 0x34, 0xb1,
-	0x2c, 0x75, 0xa4, 0x94, 0xe6, 0x36, 0x98, 0x9c, 0x03, 0xbe, 0x86, 0x86, 0xd8, 0xd3, 0xd8, 0x34,
-	0x74, 0x9d, 0xe7, 0x7b, 0x6a, 0x4b, 0xbd, 0xaa, 0xf3, 0x83, 0x34, 0xb1, 0x6c, 0x89, 0xb9, 0x10,
-	0xca, 0xb5, 0x4e, 0xa9, 0xc2, 0x45, 0x3f, 0x07, 0xe3, 0xf8, 0x8a, 0x53, 0x16, 0x12, 0xdf, 0x6c,
-	0xf7, 0x2b, 0x03, 0x63, 0xf8, 0xe3, 0x3c, 0x97, 0x54, 0x1b, 0xd6, 0xc2, 0xcb, 0x61, 0xd0, 0x31,
-	0xd4, 0xbf, 0xa2, 0x64, 0x62, 0x82, 0x84, 0xdb, 0x4f, 0x13, 0x6b, 0x47, 0xc2, 0xcd, 0x29, 0x99,
-	0xac, 0x05, 0x25, 0xc3, 0xd1, 0x4b, 0xa8, 0x1d, 0x87, 0x97, 0x66, 0x47, 0xe6, 0xaf, 0x53, 0x3a,
-	0xe0, 0xc3, 0xbd, 0x34, 0xb1, 0x3e, 0x56, 0x33, 0x0c, 0x2f, 0xd7, 0x42, 0x14, 0x48, 0xc8, 0x85,
-	0xe6, 0x51, 0x14, 0x4e, 0xbd, 0x99, 0xd9, 0x95, 0xc9, 0x7c, 0x58, 0x4a, 0xa6, 0x32, 0xa8, 0x6c,
-	0x16, 0xa4, 0xe7, 0x4a, 0xed, 0x7a, 0xa4, 0xa7, 0x10, 0xd0, 0x2f, 0xa1, 0xa5, 0xb8, 0x34, 0x36,
-	0xef, 0xc9, 0x51, 0x5a, 0xb6, 0x92, 0xcb, 0x87, 0x44, 0x39, 0xac, 0x85, 0x9b, 0xa1, 0xa1, 0x21,
-	0xd4, 0x8e, 0x82, 0x89, 0xb9, 0x21, 0xeb, 0xbd, 0xc8, 0x80, 0x1b, 0xac, 0x97, 0x53, 0x11, 0x2c,
-	0x76, 0xe6, 0x4b, 0x36, 0x8b, 0xcd, 0xcd, 0x7e, 0x6d, 0xd0, 0x2e, 0xed, 0x0c, 0x61, 0xb3, 0xf5,
-	0x66, 0x23, 0xc3, 0xd1, 0x1e, 0x74, 0x4b, 0x34, 0x18, 0x9b, 0x5b, 0x72, 0xa1, 0x5d, 0xbb, 0xa4,
-	0x74, 0xae, 0x79, 0xf4, 0x3e, 0x03, 0x28, 0xca, 0xf5, 0x6d, 0x9c, 0xdb, 0x28, 0x71, 0x6e, 0xef,
-	0x73, 0xe8, 0x94, 0xf6, 0xe6, 0x4e, 0x74, 0xfd, 0xc7, 0x0a, 0x74, 0x4f, 0x88, 0x7b, 0xf1, 0x9c,
-	0x84, 0xde, 0x94, 0xc6, 0x5c, 0x70, 0xb2, 0xe4, 0x36, 0x15, 0xad, 0xb8, 0xac, 0x07, 0x86, 0xa6,
-	0x21, 0x75, 0x97, 0xb4, 0x9d, 0x5c, 0x46, 0x3f, 0x80, 0x8d, 0x11, 0x9d, 0x92, 0xa5, 0xcf, 0xaf,
-	0xd1, 0x9d, 0x73, 0x43, 0x2b, 0xa6, 0x30, 0x0e, 0x04, 0x19, 0xd5, 0xd5, 0x14, 0xa4, 0x20, 0xb4,
-	0xe2, 0xa6, 0x8a, 0xcd, 0x86, 0x84, 0x55, 0x02, 0xfe, 0x7d, 0xb5, 0x20, 0xaf, 0xff, 0xdb, 0x84,
-	0x7a, 0x60, 0x88, 0xd1, 0x8e, 0xaf, 0x78, 0x6c, 0xd6, 0x15, 0x46, 0x26, 0xa3, 0x3e, 0x74, 0xc6,
-	0xb3, 0x30, 0x62, 0xb4, 0x3c, 0xb9, 0xb2, 0x4a, 0x5c, 0x2c, 0x23, 0x7a, 0x29, 0x17, 0x11, 0x9b,
-	0x4d, 0x69, 0x2f, 0x14, 0xf2, 0xda, 0x59, 0x9e, 0x6b, 0x6b, 0x4b, 0x59, 0x73, 0x05, 0x7a, 0x04,
-	0xf7, 0x4e, 0x5d, 0x32, 0x9d, 0x46, 0xfe, 0x44, 0xe1, 0x1b, 0xd2, 0xe3, 0xba, 0x12, 0xff, 0xad,
-	0x0e, 0x8d, 0x53, 0x4e, 0xdc, 0x0b, 0x7d, 0x31, 0x55, 0xef, 0x70, 0x31, 0xd5, 0xd6, 0xbb, 0x98,
-	0xea, 0xdf, 0x76, 0x31, 0xad, 0x75, 0xe6, 0x74, 0x1e, 0x9f, 0x01, 0xe4, 0x14, 0xa1, 0x52, 0x25,
-	0x58, 0x43, 0xce, 0xbc, 0xe0, 0x0e, 0xcd, 0xc1, 0x45, 0x63, 0xe8, 0xe6, 0x16, 0xec, 0x94, 0xe2,
-	0xd1, 0x14, 0xba, 0x23, 0xba, 0xa0, 0xe1, 0x84, 0x86, 0xae, 0xa7, 0x53, 0xdb, 0x39, 0x30, 0x35,
-	0x5e, 0xd9, 0xa4, 0x10, 0x07, 0x69, 0x62, 0x3d, 0xd2, 0xad, 0x5e, 0x61, 0xbb, 0x6d, 0xc2, 0xd7,
-	0x70, 0xd1, 0x2f, 0x64, 0xdf, 0xe8, 0x32, 0x6f, 0x21, 0xfb, 0xc6, 0xd6, 0x8d, 0x4e, 0x6e, 0x52,
-	0xd8, 0xbe, 0xfb, 0x9a, 0x56, 0x5d, 0x65, 0xe6, 0xdb, 0x1b, 0xc3, 0xe6, 0x8d, 0x35, 0xdf, 0x72,
-	0x1a, 0xfb, 0xe5, 0xd3, 0xd8, 0x39, 0x80, 0x22, 0x4d, 0xe5, 0x43, 0xfd, 0x14, 0xee, 0x7f, 0x63,
-	0xb9, 0xff, 0x2b, 0x18, 0xfe, 0x67, 0x15, 0x8c, 0xf1, 0x84, 0x86, 0xdc, 0xe3, 0x2b, 0xf4, 0x5e,
-	0xa9, 0xd1, 0xe9, 0xa6, 0x89, 0x65, 0xc8, 0x25, 0x7b, 0x13, 0x55, 0x43, 0x1f, 0x42, 0xe3, 0x38,
-	0x20, 0x9e, 0xaf, 0x0b, 0x6e, 0x33, 0x4d, 0xac, 0x8e, 0xba, 0x47, 0x84, 0x16, 0x3b, 0xca, 0x8a,
-	0xf6, 0x65, 0x89, 0xfb, 0x9e, 0xfb, 0x94, 0xae, 0x64, 0xbd, 0x75, 0x87, 0xef, 0xa4, 0x89, 0xb5,
-	0xa9, 0x6e, 0x58, 0x69, 0xb9, 0xa0, 0x2b, 0xec, 0x14, 0x5e, 0x02, 0xf9, 0x45, 0x14, 0xba, 0x8a,
-	0x02, 0xea, 0x25, 0xe4, 0x50, 0x68, 0xb1, 0xa3, 0xac, 0xe8, 0x0b, 0x68, 0x9f, 0x7a, 0xb3, 0x90,
-	0xf0, 0x25, 0x53, 0xad, 0x4b, 0x77, 0xb8, 0x9d, 0x26, 0x56, 0x4f, 0xf5, 0x03, 0x99, 0x05, 0x97,
-	0xf7, 0xa0, 0x08, 0x40, 0x8f, 0xa1, 0xfe, 0x9c, 0x72, 0xa2, 0x0b, 0xe7, 0x1d, 0x3b, 0x5b, 0xb5,
-	0x2d, 0xb4, 0x37, 0x3b, 0xde, 0x80, 0x72, 0x82, 0x1d, 0x19, 0x20, 0x3a, 0xde, 0xdc, 0xe5, 0x4e,
-	0x14, 0xfa, 0x9f, 0x0a, 0x18, 0x5f, 0x32, 0xee, 0x4d, 0x89, 0xcb, 0xd1, 0x4f, 0x4a, 0xb9, 0xb5,
-	0xdf, 0x24, 0xd6, 0x8f, 0x4a, 0xcf, 0xaa, 0x68, 0x41, 0x43, 0xf1, 0xba, 0x21, 0x5e, 0x48, 0x59,
-	0xbc, 0x3b, 0x8b, 0x76, 0x26, 0xde, 0x8c, 0xc6, 0xdc, 0x1e, 0xc9, 0x7f, 0x32, 0xfb, 0x08, 0xea,
-	0x67, 0x64, 0x96, 0xb1, 0x9a, 0xfc, 0x46, 0x3b, 0xd0, 0x94, 0xfd, 0x6a, 0x6c, 0xd6, 0x74, 0x83,
-	0x93, 0x0d, 0x67, 0x2b, 0xbd, 0x9c, 0xb3, 0xa3, 0x9d, 0x44, 0xa7, 0x7c, 0xc4, 0x28, 0xe1, 0x74,
-	0x92, 0x75, 0xca, 0x5a, 0x14, 0x94, 0x37, 0x22, 0x9c, 0x9c, 0x7a, 0xbf, 0xa6, 0x59, 0xa7, 0x9c,
-	0xc9, 0xe2, 0x0e, 0x29, 0x81, 0xdd, 0x29, 0x01, 0x7f, 0xaf, 0x40, 0xeb, 0x84, 0x45, 0xf2, 0x61,
-	0xb7, 0x7e, 0x13, 0x7d, 0x08, 0xdd, 0x97, 0xcc, 0x9d, 0xd3, 0x98, 0x33, 0xc2, 0x23, 0xa6, 0xcb,
-	0xed, 0x61, 0x9a, 0x58, 0x48, 0xee, 0x4d, 0x54, 0x32, 0x62, 0xe7, 0x9a, 0x2f, 0xfa, 0xa8, 0x68,
-	0x1d, 0x15, 0xd5, 0xdd, 0x4f, 0x13, 0xeb, 0xde, 0xb5, 0x86, 0xb1, 0x68, 0x0f, 0x6d, 0x30, 0x1c,
-	0x3a, 0xf3, 0x62, 0xce, 0x56, 0x9a, 0xf1, 0x8a, 0x97, 0x1e, 0xd3, 0x06, 0xec, 0xe4, 0x3e, 0xf8,
-	0x43, 0xe8, 0x8c, 0x39, 0x65, 0x2f, 0xe5, 0x89, 0x8e, 0xc5, 0x83, 0xe4, 0x84, 0xd1, 0xa9, 0x77,
-	0xa5, 0x93, 0xa1, 0xa5, 0x83, 0xbf, 0x54, 0xa1, 0x71, 0x26, 0x1e, 0xd0, 0xc8, 0x82, 0x7b, 0x2a,
-	0x98, 0x32, 0x45, 0xd8, 0x4d, 0x45, 0x57, 0x3d, 0xfd, 0x1f, 0xbd, 0x2f, 0xee, 0xe7, 0x20, 0xf0,
-	0xf8, 0xed, 0xe6, 0x1e, 0x18, 0x4f, 0xe8, 0xb7, 0xd8, 0x1e, 0x01, 0x8c, 0x33, 0xdc, 0x18, 0x75,
-	0xed, 0xd2, 0xcc, 0x32, 0x9f, 0xbd, 0x0a, 0x1a, 0xc0, 0x56, 0x36, 0x83, 0xfc, 0x94, 0xb7, 0xf3,
-	0xd2, 0xef, 0x15, 0x9f, 0xe8, 0x23, 0xd8, 0x18, 0x17, 0x5e, 0x82, 0x09, 0xaf, 0x63, 0x16, 0xae,
-	0x7b, 0x15, 0xf4, 0x43, 0xc1, 0x66, 0xe1, 0xd4, 0x63, 0xc1, 0x5b, 0x50, 0x3f, 0x80, 0xce, 0x13,
-	0xca, 0xbf, 0xdb, 0x69, 0xb8, 0xff, 0xd7, 0xd7, 0xdb, 0x95, 0x7f, 0xbc, 0xde, 0xae, 0xfc, 0xeb,
-	0xf5, 0x76, 0xe5, 0x0f, 0xff, 0xde, 0xfe, 0xde, 0xaf, 0xac, 0xd2, 0xe1, 0xa0, 0xcb, 0x69, 0xc4,
-	0x3c, 0xb2, 0x2b, 0x7f, 0x93, 0x50, 0x7f, 0xcf, 0xcf, 0x9b, 0xf2, 0xc7, 0x86, 0x4f, 0xfe, 0x1b,
-	0x00, 0x00, 0xff, 0xff, 0x84, 0xd4, 0x84, 0xa1, 0xaa, 0x10, 0x00, 0x00,
+	// 2246 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x59, 0xcd, 0x8f, 0x1b, 0x49,
+	0x15, 0xc7, 0xe3, 0xef, 0x67, 0xcf, 0x64, 0x52, 0x9b, 0x8d, 0x5a, 0x26, 0x3b, 0x35, 0xdb, 0x9b,
+	0xec, 0x1a, 0xb2, 0xe9, 0x4c, 0x26, 0x41, 0xd9, 0x44, 0x2b, 0x56, 0xf1, 0x78, 0x36, 0xb1, 0xf2,
+	0x65, 0xda, 0x43, 0x40, 0x5c, 0xa2, 0x9a, 0x76, 0xd9, 0x6e, 0x8d, 0xdd, 0x6d, 0x55, 0x97, 0xbd,
+	0x31, 0x1c, 0x90, 0xf8, 0x0b, 0x10, 0x47, 0xce, 0x20, 0xc1, 0x7f, 0x82, 0x38, 0x21, 0x71, 0xda,
+	0x4b, 0x0b, 0x05, 0x4e, 0x39, 0xb6, 0xc4, 0x25, 0x12, 0x08, 0xd5, 0x47, 0x7f, 0xd8, 0xeb, 0xdd,
+	0x38, 0xb0, 0x5c, 0x32, 0xfd, 0xbe, 0x7e, 0x55, 0xf5, 0xea, 0xbd, 0x57, 0xef, 0x39, 0x50, 0xe3,
+	0x23, 0x46, 0xa6, 0xd6, 0x94, 0xf9, 0xdc, 0x6f, 0x5c, 0x1b, 0xba, 0x7c, 0x34, 0x3b, 0xb5, 0x1c,
+	0x7f, 0x72, 0x7d, 0xe8, 0x0f, 0xfd, 0xeb, 0x92, 0x7d, 0x3a, 0x1b, 0x48, 0x4a, 0x12, 0xf2, 0x4b,
+	0xa9, 0x9b, 0xbf, 0x80, 0x62, 0x6b, 0xe6, 0x8e, 0xfb, 0xe8, 0x16, 0x40, 0xdb, 0x77, 0xce, 0x28,
+	0x1b, 0xb8, 0x63, 0x6a, 0xe4, 0xf6, 0x73, 0xcd, 0x6a, 0xeb, 0x42, 0x14, 0xe2, 0xdd, 0x91, 0x33,
+	0xbe, 0x6b, 0xf6, 0x13, 0x91, 0x69, 0x67, 0xf4, 0xd0, 0xa7, 0x50, 0x3e, 0xf2, 0x3d, 0x4e, 0x5f,
+	0x70, 0x63, 0x4b, 0x9a, 0x98, 0x51, 0x88, 0xf7, 0xa4, 0x89, 0xa3, 0xf8, 0xe6, 0xfe, 0xc8, 0x19,
+	0xd3, 0xbb, 0xa6, 0x3f, 0x71, 0x39, 0x9d, 0x4c, 0xf9, 0xc2, 0xb4, 0x63, 0x13, 0x93, 0x41, 0xb9,
+	0x47, 0x1d, 0x46, 0x79, 0x80, 0x6e, 0x43, 0xad, 0x4d, 0x03, 0xee, 0x7a, 0x84, 0xbb, 0xbe, 0xa7,
+	0xd7, 0x7f, 0x37, 0x0a, 0xf1, 0x79, 0xb5, 0x7e, 0x2a, 0x33, 0xed, 0xac, 0x26, 0xb2, 0xa0, 0x72,
+	0x42, 0x27, 0xd3, 0x31, 0xe1, 0x54, 0x6f, 0x01, 0x45, 0x21, 0xde, 0x91, 0x56, 0x5c, 0x0b, 0x4c,
+	0x3b, 0xd1, 0x31, 0x7f, 0x09, 0xa5, 0x67, 0xfe, 0x78, 0x36, 0xa1, 0xe8, 0x21, 0x94, 0x7a, 0xfe,
+	0x8c, 0x39, 0xf1, 0x69, 0x6f, 0x46, 0x21, 0xbe, 0x2e, 0xed, 0x02, 0xc9, 0xfe, 0xea, 0xce, 0xf7,
+	0x17, 0x64, 0x32, 0xbe, 0x6b, 0x7e, 0x9c, 0x39, 0x8b, 0x86, 0x40, 0x4d, 0x28, 0x9d, 0x10, 0x36,
+	0xa4, 0xb1, 0x1f, 0x76, 0xa3, 0x10, 0xd7, 0xd5, 0x26, 0x24, 0xdb, 0xb4, 0xb5, 0xdc, 0xfc, 0x5d,
+	0x0e, 0xe0, 0xd8, 0x9b, 0xbb, 0xbe, 0x37, 0xa1, 0x1e, 0x47, 0x26, 0x14, 0x3e, 0x4f, 0x3d, 0xbe,
+	0x13, 0x85, 0x18, 0xa4, 0x99, 0xf2, 0xb5, 0x94, 0xa1, 0x3b, 0x50, 0x78, 0x46, 0x58, 0x60, 0x6c,
+	0xed, 0xe7, 0x9b, 0xb5, 0xc3, 0x77, 0xad, 0xd4, 0xdc, 0x12, 0xfc, 0x63, 0x8f, 0xb3, 0x45, 0xc6,
+	0x74, 0x4e, 0x58, 0x60, 0xda, 0xd2, 0xa4, 0x71, 0x1b, 0xaa, 0x89, 0x0a, 0xda, 0x85, 0xfc, 0x19,
+	0x5d, 0xa8, 0xa5, 0x6c, 0xf1, 0x89, 0x2e, 0x40, 0x71, 0x4e, 0xc6, 0x33, 0xed, 0x3a, 0x5b, 0x11,
+	0x77, 0xb7, 0x3e, 0xc9, 0x99, 0x7f, 0xcc, 0x41, 0xed, 0x01, 0x25, 0x63, 0x3e, 0x3a, 0x1a, 0x51,
+	0xe7, 0x0c, 0x35, 0xa0, 0xd2, 0x15, 0x11, 0xe3, 0xf8, 0x63, 0x0d, 0x90, 0xd0, 0x08, 0x41, 0xa1,
+	0x4b, 0xf8, 0x48, 0x83, 0xc8, 0x6f, 0x74, 0x11, 0x4a, 0x8f, 0x29, 0x1f, 0xf9, 0x7d, 0x23, 0x2f,
+	0xb9, 0x9a, 0x42, 0x06, 0x94, 0x4f, 0xdc, 0x09, 0xf5, 0x67, 0xdc, 0x28, 0xec, 0xe7, 0x9a, 0x79,
+	0x3b, 0x26, 0xc5, 0x0a, 0x1d, 0x8f, 0x53, 0x36, 0x27, 0x63, 0xa3, 0x28, 0x45, 0x09, 0x8d, 0x2e,
+	0x41, 0xb5, 0xeb, 0x33, 0xfe, 0x88, 0x9c, 0xd2, 0xb1, 0x51, 0x92, 0x80, 0x29, 0xc3, 0xfc, 0x57,
+	0x0e, 0x0a, 0x3d, 0x4a, 0xfb, 0xe8, 0x36, 0x14, 0x85, 0xc3, 0x02, 0x23, 0xb7, 0x9f, 0x6f, 0x56,
+	0x5b, 0xef, 0x47, 0x21, 0x7e, 0x2f, 0xf1, 0x66, 0xb0, 0xee, 0xfe, 0x94, 0xbe, 0x88, 0x85, 0xa5,
+	0xeb, 0x4b, 0x63, 0x41, 0x5f, 0xdf, 0x46, 0xb1, 0xa0, 0x20, 0x10, 0x86, 0xfc, 0xd1, 0x44, 0x9f,
+	0xbb, 0xb5, 0x1d, 0x85, 0xb8, 0xaa, 0x12, 0x62, 0xd2, 0x37, 0x6d, 0x21, 0x41, 0x4f, 0x56, 0x7c,
+	0xd0, 0xba, 0x15, 0x85, 0xf8, 0x40, 0x2d, 0xa7, 0xf8, 0x1b, 0xad, 0x17, 0x83, 0x98, 0xff, 0xc8,
+	0x41, 0xf9, 0xb1, 0x3b, 0x64, 0x84, 0x53, 0xd4, 0x52, 0x8b, 0xab, 0x70, 0x3a, 0x88, 0x42, 0xfc,
+	0x71, 0xb2, 0xf8, 0x26, 0x98, 0x72, 0x7f, 0xc7, 0x50, 0xb8, 0xc7, 0x86, 0x2a, 0xde, 0xaa, 0xad,
+	0x1b, 0x51, 0x88, 0xaf, 0x49, 0x10, 0xc2, 0x86, 0xc1, 0x46, 0x28, 0xd2, 0x3c, 0x7b, 0xcc, 0xfc,
+	0xb7, 0x71, 0xcc, 0x3f, 0xd4, 0xa0, 0x7a, 0xe4, 0x4f, 0xa6, 0xbe, 0x27, 0x12, 0xa7, 0x09, 0x5b,
+	0x9d, 0xb6, 0x3e, 0xa7, 0x11, 0x85, 0xf8, 0x42, 0x0a, 0x15, 0xa3, 0x5c, 0x33, 0xed, 0xad, 0x4e,
+	0x5b, 0xa4, 0xd8, 0x13, 0x32, 0x89, 0xcb, 0x43, 0x9a, 0x27, 0x1e, 0x99, 0x88, 0x14, 0x13, 0x32,
+	0xb1, 0xd7, 0x67, 0x94, 0x05, 0xa2, 0xf6, 0xa8, 0x7b, 0x4b, 0xf7, 0x3a, 0x57, 0xfc, 0x35, 0x5b,
+	0x5b, 0x53, 0xda, 0x34, 0x08, 0xb2, 0xa0, 0x70, 0xb2, 0x98, 0x52, 0x79, 0xbf, 0xd5, 0x56, 0x23,
+	0x59, 0x93, 0x2f, 0xa6, 0xd4, 0x7c, 0x1d, 0xe2, 0x8a, 0x38, 0x88, 0xd0, 0xb0, 0xa5, 0x1e, 0x7a,
+	0x0e, 0x95, 0x47, 0xc4, 0x1b, 0xce, 0xc8, 0x90, 0xca, 0xe0, 0xaf, 0xb6, 0x8e, 0xa2, 0x10, 0xdf,
+	0x90, 0x36, 0x63, 0x2d, 0xd8, 0xc4, 0x5b, 0xaf, 0x43, 0x0c, 0x31, 0x50, 0xa7, 0x6d, 0x27, 0xa0,
+	0xe8, 0x33, 0x5d, 0xe8, 0x65, 0xf6, 0xd4, 0x0e, 0x4b, 0x96, 0xa4, 0x32, 0x29, 0x72, 0x2a, 0xe8,
+	0xb5, 0x29, 0x22, 0x35, 0xd1, 0xfd, 0xa4, 0x58, 0x1b, 0x65, 0x09, 0x51, 0xb1, 0x34, 0xdd, 0xfa,
+	0x20, 0x0a, 0x31, 0x56, 0x95, 0x53, 0x71, 0xd6, 0x5e, 0xa3, 0xd6, 0x46, 0xcf, 0xa1, 0x28, 0x52,
+	0x37, 0x30, 0x2a, 0xba, 0x9c, 0x25, 0x77, 0x6a, 0x49, 0xbe, 0x2a, 0x67, 0x87, 0x51, 0x88, 0x2d,
+	0x89, 0x39, 0x15, 0xcc, 0x8d, 0x22, 0x45, 0xe1, 0xa2, 0x1f, 0x41, 0xe5, 0xf8, 0x05, 0xa7, 0xcc,
+	0x23, 0x63, 0xa3, 0xba, 0x9f, 0x6b, 0x56, 0x5a, 0x3f, 0x48, 0x7c, 0x49, 0xb5, 0x60, 0x23, 0xbc,
+	0x04, 0x46, 0x64, 0xc4, 0x03, 0x4a, 0xfa, 0x06, 0x48, 0xb8, 0x34, 0x23, 0x46, 0x94, 0x6c, 0x96,
+	0x57, 0xd2, 0x1c, 0x3d, 0x85, 0xfc, 0xb1, 0x37, 0x37, 0x6a, 0xd2, 0x7f, 0xb5, 0x4c, 0x1d, 0xcf,
+	0x64, 0x2a, 0xf5, 0xe6, 0x9b, 0x65, 0xea, 0xb1, 0x37, 0x47, 0x0e, 0x94, 0x8e, 0x7c, 0x6f, 0xe0,
+	0x0e, 0x8d, 0xba, 0x74, 0xe6, 0xc5, 0x8c, 0x33, 0x95, 0x40, 0x79, 0x33, 0xad, 0x67, 0x8e, 0xe4,
+	0x6e, 0x56, 0xcf, 0x14, 0x02, 0xfa, 0x09, 0x94, 0xd5, 0x93, 0x19, 0x18, 0xdb, 0x72, 0x95, 0xb2,
+	0xa5, 0xe8, 0x6c, 0x92, 0x28, 0x85, 0xcd, 0x12, 0x5a, 0xa3, 0xc5, 0xb5, 0x6a, 0xe7, 0xdb, 0xa8,
+	0x55, 0xe7, 0xfe, 0xb7, 0x5a, 0x75, 0x00, 0xf5, 0xcc, 0x6b, 0x17, 0x18, 0xbb, 0xf2, 0xa0, 0x75,
+	0x2b, 0xc3, 0xb4, 0x97, 0x34, 0xd0, 0x23, 0xf5, 0xe6, 0x18, 0xe7, 0xe5, 0x65, 0x16, 0x2d, 0x41,
+	0x64, 0xd6, 0x0f, 0x28, 0xdd, 0x30, 0x32, 0x84, 0x21, 0xfa, 0x69, 0x52, 0xc1, 0x0d, 0xa4, 0xb3,
+	0x4b, 0xd3, 0x19, 0x27, 0x4f, 0x14, 0x67, 0x33, 0x27, 0x6b, 0xf3, 0xc6, 0x27, 0x00, 0x69, 0x5a,
+	0xbd, 0xa9, 0x05, 0x28, 0x66, 0x5a, 0x80, 0xc6, 0x1d, 0xa8, 0x65, 0x62, 0xe8, 0xad, 0xba, 0x87,
+	0xdf, 0xe4, 0xa0, 0xde, 0x25, 0xce, 0xd9, 0x63, 0xe2, 0xb9, 0x03, 0x1a, 0x70, 0xd1, 0x22, 0xc8,
+	0x1a, 0xac, 0xac, 0xe5, 0xb7, 0x78, 0xf0, 0x75, 0xb9, 0xd4, 0x4f, 0x8d, 0x9d, 0xd0, 0xe8, 0x43,
+	0xd8, 0x69, 0xd3, 0x01, 0x99, 0x8d, 0xf9, 0x52, 0x59, 0xb6, 0x57, 0xb8, 0x62, 0x0b, 0x9d, 0x09,
+	0x19, 0xea, 0x42, 0x6b, 0x2b, 0x42, 0x70, 0x55, 0x1f, 0x50, 0x94, 0xb0, 0x8a, 0x30, 0x7f, 0xb5,
+	0x95, 0x16, 0xd9, 0xff, 0xdb, 0x86, 0x1a, 0x50, 0x11, 0xab, 0x1d, 0xbf, 0xe0, 0x81, 0x51, 0x50,
+	0x18, 0x31, 0x8d, 0xf6, 0xa1, 0xd6, 0x19, 0x7a, 0x3e, 0xa3, 0xd9, 0xcd, 0x65, 0x59, 0xa2, 0xcf,
+	0x69, 0xd3, 0xb9, 0x3c, 0x44, 0x60, 0x94, 0xa4, 0x3c, 0x65, 0x08, 0x69, 0x77, 0x76, 0xaa, 0xa5,
+	0x65, 0x25, 0x4d, 0x18, 0xe8, 0x32, 0x6c, 0xf7, 0x1c, 0x32, 0x18, 0xf8, 0xe3, 0xbe, 0xc2, 0xaf,
+	0x48, 0x8d, 0x65, 0xa6, 0xf9, 0xe7, 0x02, 0x14, 0x7b, 0x9c, 0x38, 0x67, 0xfa, 0x01, 0xdd, 0x7a,
+	0x8b, 0x07, 0x34, 0xbf, 0xd9, 0x03, 0x5a, 0xf8, 0xba, 0x07, 0x74, 0xa3, 0xda, 0xa0, 0xfd, 0xf8,
+	0x08, 0x20, 0x29, 0x65, 0xca, 0x55, 0xa2, 0xba, 0xc9, 0x9d, 0xa7, 0x35, 0x4e, 0xbf, 0x15, 0xe9,
+	0x9c, 0xe2, 0x24, 0x12, 0xd3, 0xce, 0xd8, 0xa3, 0x01, 0xd4, 0xdb, 0x74, 0x4a, 0xbd, 0x3e, 0xf5,
+	0x1c, 0x57, 0xbb, 0xb6, 0x76, 0x68, 0x68, 0xbc, 0xac, 0x48, 0x21, 0x36, 0xa3, 0x10, 0x5f, 0xd6,
+	0x93, 0x47, 0x2a, 0x5b, 0xb7, 0xe1, 0x25, 0x5c, 0xf4, 0x63, 0x39, 0xc6, 0x38, 0xcc, 0x9d, 0xca,
+	0x31, 0xa6, 0xbc, 0xd2, 0x4c, 0xf6, 0x53, 0xd9, 0x37, 0xb7, 0x13, 0x6a, 0xc8, 0x89, 0x75, 0x1b,
+	0x1d, 0x38, 0xb7, 0x72, 0xe6, 0x35, 0xd9, 0xb8, 0x9f, 0xcd, 0xc6, 0xda, 0x21, 0xa4, 0x6e, 0xca,
+	0x26, 0xf5, 0x43, 0x38, 0xff, 0x95, 0xe3, 0xfe, 0xb7, 0x60, 0xe6, 0x97, 0x5b, 0x50, 0xe9, 0xf4,
+	0xa9, 0xc7, 0x5d, 0xbe, 0x40, 0x97, 0x32, 0x0d, 0x59, 0x3d, 0x0a, 0x71, 0x45, 0x1e, 0xd9, 0xed,
+	0xab, 0x18, 0xba, 0x02, 0xc5, 0xe3, 0x09, 0x71, 0xc7, 0x3a, 0xe0, 0xce, 0x45, 0x21, 0xae, 0x49,
+	0x05, 0x2a, 0xb8, 0xa6, 0xad, 0xa4, 0xe8, 0x86, 0x0c, 0xf1, 0xb1, 0xeb, 0x3c, 0xa4, 0x0b, 0x19,
+	0x6f, 0xf5, 0xd6, 0x3b, 0x51, 0x88, 0xcf, 0x49, 0xd5, 0xa9, 0x94, 0x9c, 0xd1, 0x85, 0x69, 0xa7,
+	0x5a, 0x02, 0xf9, 0x89, 0xef, 0x39, 0xaa, 0x04, 0x14, 0x32, 0xc8, 0x9e, 0xe0, 0x9a, 0xb6, 0x92,
+	0xa2, 0x4f, 0xa1, 0xda, 0x73, 0x87, 0x1e, 0xe1, 0x33, 0xa6, 0x5a, 0xac, 0x7a, 0x6b, 0x2f, 0x0a,
+	0x71, 0x43, 0xaa, 0x06, 0xb1, 0xc4, 0xcc, 0xde, 0x41, 0x6a, 0x80, 0x6e, 0x43, 0xe1, 0x31, 0xe5,
+	0x44, 0x07, 0xce, 0x3b, 0x56, 0x7c, 0x6a, 0x4b, 0x70, 0x57, 0x07, 0xb0, 0x09, 0xe5, 0xc4, 0xb4,
+	0xa5, 0x81, 0x18, 0xc0, 0x12, 0x95, 0xb7, 0x2a, 0xa1, 0xff, 0xce, 0x41, 0xe5, 0x1e, 0xe3, 0xee,
+	0x80, 0x38, 0x1c, 0xfd, 0x30, 0xe3, 0x5b, 0xeb, 0x75, 0x88, 0xbf, 0x9f, 0x99, 0xf2, 0xfd, 0x29,
+	0xf5, 0xc4, 0xb0, 0x4d, 0x5c, 0x8f, 0xb2, 0xe0, 0xfa, 0xd0, 0xbf, 0xd6, 0x77, 0x87, 0x34, 0xe0,
+	0x56, 0x5b, 0xfe, 0x91, 0xde, 0x47, 0x50, 0x38, 0x21, 0x71, 0x47, 0x6f, 0xcb, 0x6f, 0x74, 0x0d,
+	0x4a, 0x72, 0x7c, 0x0a, 0x8c, 0xbc, 0x6e, 0xc4, 0xe2, 0xe5, 0x2c, 0xc5, 0x97, 0x7b, 0xb6, 0xb5,
+	0x92, 0x18, 0xdc, 0x8e, 0x18, 0x25, 0x9c, 0xf6, 0xe3, 0xc1, 0x4d, 0x93, 0xa2, 0xe4, 0xb5, 0x09,
+	0x27, 0x3d, 0xf7, 0xe7, 0x34, 0x1e, 0xdc, 0x62, 0x5a, 0xbc, 0x21, 0x19, 0xb0, 0xb7, 0x72, 0xc0,
+	0x6f, 0xb7, 0xa0, 0xdc, 0x65, 0xbe, 0xfc, 0x9d, 0x61, 0xf3, 0x66, 0xff, 0x2e, 0xd4, 0x9f, 0x32,
+	0x67, 0x44, 0x03, 0xce, 0x08, 0xf7, 0x99, 0x0e, 0xb7, 0x8b, 0x51, 0x88, 0x91, 0xbc, 0x1b, 0x3f,
+	0x23, 0x34, 0xed, 0x25, 0x5d, 0x74, 0x35, 0x6d, 0x71, 0x55, 0xa9, 0x3b, 0x1f, 0x85, 0x78, 0x7b,
+	0xa9, 0xb1, 0x4d, 0xdb, 0x58, 0x0b, 0x2a, 0x36, 0x1d, 0xba, 0x01, 0x67, 0x0b, 0xa3, 0xb0, 0xf2,
+	0xc3, 0x03, 0xd3, 0x02, 0xd3, 0x4e, 0x74, 0xd0, 0x23, 0x28, 0xcb, 0x98, 0x0d, 0x46, 0xd2, 0x49,
+	0xb5, 0xc3, 0x1d, 0x4b, 0xd3, 0x5d, 0x7f, 0xec, 0x3a, 0x8b, 0xcc, 0x4f, 0x27, 0x53, 0xc5, 0x5f,
+	0x37, 0x5f, 0x68, 0x13, 0xf3, 0x9f, 0x79, 0xd8, 0x5e, 0x32, 0x47, 0x9f, 0x41, 0xa5, 0xc5, 0x88,
+	0xe7, 0x8c, 0x92, 0xf1, 0x37, 0x6d, 0xcb, 0x4f, 0xb5, 0x60, 0x0d, 0x62, 0x62, 0x84, 0x6e, 0x66,
+	0x63, 0xa4, 0x85, 0xa3, 0x10, 0x7f, 0x57, 0x4f, 0xc0, 0xeb, 0x3a, 0x29, 0x1d, 0x44, 0x4f, 0x61,
+	0x5b, 0x24, 0x09, 0xed, 0x1f, 0xf9, 0x93, 0x89, 0xab, 0x1d, 0x57, 0x69, 0x7d, 0x2f, 0x0a, 0xf1,
+	0x95, 0x24, 0xb3, 0x68, 0xff, 0xb9, 0xa3, 0xc4, 0x6b, 0x70, 0x96, 0xed, 0xd1, 0xe7, 0x00, 0xf7,
+	0xbb, 0xf7, 0x1f, 0xd2, 0x05, 0x73, 0xbd, 0xa1, 0x76, 0xec, 0x87, 0x51, 0x88, 0x4d, 0x89, 0x36,
+	0x9c, 0x0e, 0x9f, 0x9f, 0x29, 0xd9, 0x1a, 0xa8, 0x8c, 0x25, 0xea, 0xc1, 0x4e, 0xaf, 0xf7, 0xe0,
+	0xde, 0x78, 0xec, 0x7f, 0x41, 0xfb, 0x0f, 0xe9, 0x22, 0xd0, 0x63, 0xd5, 0xd5, 0x28, 0xc4, 0x1f,
+	0xa9, 0x9d, 0x05, 0xa3, 0xe7, 0x44, 0xc9, 0x05, 0xe6, 0xba, 0xbd, 0xad, 0x40, 0xa0, 0x3b, 0x50,
+	0xea, 0xce, 0x82, 0x11, 0x55, 0x53, 0x54, 0x25, 0x33, 0x3d, 0x4d, 0x25, 0x7b, 0x0d, 0x84, 0x36,
+	0x40, 0xc7, 0x00, 0x4f, 0xe7, 0x94, 0x31, 0xb7, 0x4f, 0x99, 0x7e, 0xbc, 0x5b, 0x57, 0xa2, 0x10,
+	0xbf, 0xaf, 0xa2, 0x32, 0x11, 0xad, 0x3b, 0x56, 0x6a, 0x68, 0xfe, 0x7e, 0x0b, 0xce, 0xe9, 0x7b,
+	0x8f, 0xb9, 0xa8, 0x99, 0x16, 0xe1, 0xb8, 0xfc, 0xbe, 0x0a, 0x71, 0xc5, 0xd5, 0x3c, 0x3b, 0x91,
+	0x22, 0xac, 0xdf, 0x7e, 0x9d, 0x15, 0xd5, 0x57, 0x21, 0x2e, 0x06, 0x82, 0x61, 0x2b, 0x3e, 0xba,
+	0x92, 0xa4, 0x9c, 0xce, 0x80, 0xda, 0xab, 0x10, 0x97, 0xa7, 0x8a, 0x65, 0xc7, 0x32, 0x64, 0x42,
+	0x49, 0xdd, 0x97, 0xbe, 0x20, 0x78, 0x15, 0xe2, 0x92, 0xba, 0x62, 0x5b, 0x4b, 0x84, 0x8e, 0x4d,
+	0x49, 0xe0, 0x7b, 0x46, 0x31, 0xd5, 0x61, 0x92, 0x63, 0x6b, 0x09, 0xba, 0x0a, 0x55, 0x31, 0xdc,
+	0x07, 0x9c, 0x4c, 0xa6, 0xd2, 0xa5, 0xf9, 0xd6, 0xf6, 0xab, 0x10, 0x57, 0x79, 0xcc, 0xb4, 0x53,
+	0xb9, 0x50, 0x4e, 0x0b, 0x78, 0x59, 0x16, 0x70, 0xa9, 0x9c, 0xd4, 0xee, 0x4c, 0xbd, 0x36, 0xff,
+	0x9a, 0x13, 0x27, 0xa1, 0x73, 0x97, 0x7e, 0x81, 0x76, 0xd2, 0xe2, 0x11, 0x17, 0xc3, 0xf4, 0xf7,
+	0x00, 0xdd, 0xbe, 0x18, 0x50, 0x96, 0x1e, 0xe8, 0xb4, 0x75, 0x5f, 0x17, 0x93, 0xc8, 0x4c, 0x5d,
+	0x52, 0xd0, 0x9d, 0x79, 0x77, 0xd5, 0x1f, 0x97, 0x62, 0xbf, 0x16, 0xf5, 0x70, 0xdd, 0xcb, 0x3a,
+	0xf5, 0x02, 0x14, 0x1f, 0xf8, 0x01, 0x8f, 0x1b, 0x3a, 0x45, 0x64, 0xeb, 0x69, 0x79, 0xb9, 0x9e,
+	0x1a, 0x50, 0x3e, 0x7e, 0x31, 0x75, 0x99, 0x6c, 0xe1, 0xa4, 0x44, 0x93, 0xe6, 0x15, 0xa8, 0x75,
+	0x38, 0x65, 0x4f, 0x65, 0x57, 0x10, 0x88, 0xdf, 0xd8, 0xba, 0x8c, 0x0e, 0xdc, 0x17, 0xfa, 0x70,
+	0x9a, 0x3a, 0xfc, 0x32, 0x0f, 0xc5, 0x13, 0xf1, 0x9b, 0x30, 0xc2, 0xb0, 0xad, 0x0a, 0x10, 0x65,
+	0x6a, 0x2f, 0x7a, 0x6b, 0x0d, 0xfd, 0x17, 0xbd, 0x07, 0x35, 0x75, 0x5f, 0xeb, 0xc5, 0x0d, 0xa8,
+	0xdc, 0xa7, 0x5f, 0x23, 0xbb, 0x0c, 0xd0, 0x89, 0x71, 0x03, 0x54, 0xb7, 0x32, 0x3b, 0x8b, 0x75,
+	0x0e, 0x72, 0xa8, 0x09, 0xbb, 0xf1, 0x0e, 0x92, 0x30, 0xac, 0x26, 0xcf, 0x67, 0x23, 0xfd, 0x44,
+	0x57, 0x61, 0xa7, 0x93, 0x6a, 0xb9, 0x74, 0x15, 0x33, 0x55, 0x3d, 0xc8, 0xa1, 0x8f, 0x44, 0x47,
+	0xe4, 0x0d, 0x5c, 0x36, 0x79, 0x03, 0xea, 0x07, 0x50, 0xbb, 0x4f, 0xf9, 0x1b, 0x94, 0x6e, 0xc1,
+	0xbb, 0xcf, 0x28, 0x73, 0x07, 0x8b, 0xd5, 0xd4, 0xda, 0xb5, 0x56, 0x38, 0xcb, 0xd0, 0xdb, 0xea,
+	0xca, 0xe2, 0x40, 0xab, 0x58, 0xfa, 0xab, 0x91, 0x7c, 0xa1, 0x26, 0xd4, 0xc5, 0x21, 0x34, 0xb9,
+	0x7a, 0xa6, 0x44, 0xef, 0x20, 0x87, 0x2e, 0x8b, 0x09, 0x23, 0xe0, 0xcc, 0x5f, 0x7c, 0x03, 0x5e,
+	0xeb, 0xc6, 0x9f, 0x5e, 0xee, 0xe5, 0xfe, 0xf2, 0x72, 0x2f, 0xf7, 0xb7, 0x97, 0x7b, 0xb9, 0x5f,
+	0xff, 0x7d, 0xef, 0x3b, 0x3f, 0xc3, 0x99, 0x5e, 0x80, 0xce, 0x06, 0x3e, 0x73, 0xc9, 0x75, 0xf9,
+	0x3f, 0x02, 0xea, 0xdf, 0xd3, 0xd3, 0x92, 0xfc, 0xa9, 0xff, 0xe6, 0x7f, 0x06, 0x00, 0xbd, 0x76,
+	0x0e, 0xe3, 0x28, 0x18, 0x00, 0x00,
 }
@@ -18,6 +18,22 @@ import (
 	homedir "github.com/mitchellh/go-homedir"
 )
 
+// Sign signs the data returning the fixed size r and s values of the
+// signature concatenated, as expected by VerifySignature
+func Sign(kp *ecdsa.PrivateKey, data []byte) ([]byte, error) {
+	r, s, err := ecdsa.Sign(rand.Reader, kp, data)
+	if err != nil {
+		return nil, err
+	}
+
+	size := (kp.Curve.Params().BitSize + 7) / 8
+	sig := make([]byte, 2*size)
+	rb, sb := r.Bytes(), s.Bytes()
+	copy(sig[size-len(rb):size], rb)
+	copy(sig[2*size-len(sb):], sb)
+	return sig, nil
+}
+
 func VerifySignature(pubkey, data, signature []byte) bool {
 	r := big.Int{}
 	s := big.Int{}
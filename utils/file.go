@@ -29,12 +29,14 @@ func GetLocalPath(in string) (dirpath string, err error) {
 		return os.Getwd()
 	}
 
+	if filepath.IsAbs(in) {
+		return in, nil
+	}
+
 	// Assume cwd + supplied path if not an absolute path
-	if !filepath.IsAbs(in) {
-		var wd string
-		if wd, err = os.Getwd(); err == nil {
-			dirpath = filepath.Join(wd, in)
-		}
+	var wd string
+	if wd, err = os.Getwd(); err == nil {
+		dirpath = filepath.Join(wd, in)
 	}
 
 	return
@@ -0,0 +1,49 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package utils
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CertAuthority(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "ca-")
+	defer os.RemoveAll(dir)
+
+	ca, err := LoadOrCreateCA(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, ca.Cert.IsCA)
+
+	// Should load the existing one
+	loaded, err := LoadOrCreateCA(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ca.Cert.Raw, loaded.Cert.Raw)
+
+	cert, err := loaded.Issue("api.stack.localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	_, err = leaf.Verify(x509.VerifyOptions{DNSName: "api.stack.localhost", Roots: pool})
+	assert.Nil(t, err)
+}
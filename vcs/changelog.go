@@ -0,0 +1,200 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package vcs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// Conventional commit subject i.e. feat(api)!: add endpoint
+var conventionalRe = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+
+// Change is a single non-merge commit parsed as a conventional commit
+type Change struct {
+	Hash string
+	// Conventional commit type i.e. feat, fix.  Commits not following the
+	// convention have an empty type
+	Type     string
+	Scope    string
+	Subject  string
+	Breaking bool
+	Author   string
+	When     time.Time
+	// Files changed relative to the repo root
+	Files []string
+}
+
+// ChangeSet is the set of changes between two revisions
+type ChangeSet struct {
+	// Revision the changes start after.  Empty if from the first commit
+	From string
+	// Revision the changes end at
+	To      string
+	Changes []*Change
+}
+
+// ParseCommitMessage parses a commit message as a conventional commit
+func ParseCommitMessage(msg string) *Change {
+	lines := strings.SplitN(strings.TrimSpace(msg), "\n", 2)
+	ch := &Change{Subject: strings.TrimSpace(lines[0])}
+
+	if m := conventionalRe.FindStringSubmatch(ch.Subject); m != nil {
+		ch.Type = strings.ToLower(m[1])
+		ch.Scope = m[2]
+		ch.Breaking = m[3] == "!"
+		ch.Subject = m[4]
+	}
+
+	if len(lines) > 1 && strings.Contains(lines[1], "BREAKING CHANGE") {
+		ch.Breaking = true
+	}
+
+	return ch
+}
+
+// ReadChanges returns the changes after the from revision up to and
+// including the to revision of the repo at path.  To defaults to HEAD.
+// From defaults to the greatest version tag prior to the to revision
+func ReadChanges(path, from, to string) (*ChangeSet, error) {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if to == "" {
+		to = "HEAD"
+	}
+	toHash, err := resolveCommit(repo, to)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := &ChangeSet{From: from, To: to}
+
+	var fromHash plumbing.Hash
+	if from != "" {
+		h, err := resolveCommit(repo, from)
+		if err != nil {
+			return nil, err
+		}
+		fromHash = *h
+	} else {
+		rv, err := versionAt(repo, *toHash, true)
+		if err != nil {
+			return nil, err
+		}
+		if !rv.TagHash.IsZero() {
+			cs.From, fromHash = rv.Tag, rv.TagHash
+		}
+	}
+
+	// Commits reachable from the start are excluded
+	seen := make(map[plumbing.Hash]struct{})
+	if !fromHash.IsZero() {
+		iter, err := repo.Log(&git.LogOptions{From: fromHash})
+		if err != nil {
+			return nil, err
+		}
+		iter.ForEach(func(c *object.Commit) error {
+			seen[c.Hash] = struct{}{}
+			return nil
+		})
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: *toHash})
+	if err != nil {
+		return nil, err
+	}
+
+	err = iter.ForEach(func(c *object.Commit) error {
+		if _, ok := seen[c.Hash]; ok {
+			return nil
+		}
+		seen[c.Hash] = struct{}{}
+
+		// Merges carry no changes of their own
+		if c.NumParents() > 1 {
+			return nil
+		}
+
+		ch := ParseCommitMessage(c.Message)
+		ch.Hash = c.Hash.String()
+		ch.Author = c.Author.Name
+		ch.When = c.Author.When
+
+		files, err := changedFiles(c)
+		if err != nil {
+			return err
+		}
+		ch.Files = files
+
+		cs.Changes = append(cs.Changes, ch)
+		return nil
+	})
+
+	return cs, err
+}
+
+// resolveCommit resolves the revision to a commit hash.  Annotated tags are
+// peeled to their commit as they are not resolved by go-git
+func resolveCommit(repo *git.Repository, rev string) (*plumbing.Hash, error) {
+	ref, err := repo.Reference(plumbing.ReferenceName("refs/tags/"+rev), true)
+	if err == nil {
+		hash := ref.Hash()
+		if to, err := repo.TagObject(hash); err == nil {
+			hash = to.Target
+		}
+		return &hash, nil
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", rev, err)
+	}
+	return hash, nil
+}
+
+// changedFiles returns the files changed by the commit against its parent
+func changedFiles(c *object.Commit) ([]string, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var ptree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if ptree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+
+	changes, err := object.DiffTree(ptree, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(changes))
+	for _, ch := range changes {
+		name := ch.To.Name
+		if name == "" {
+			name = ch.From.Name
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
@@ -0,0 +1,90 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package vcs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func Test_ParseCommitMessage(t *testing.T) {
+	ch := ParseCommitMessage("feat(api): add users endpoint\n\nbody")
+	assert.Equal(t, "feat", ch.Type)
+	assert.Equal(t, "api", ch.Scope)
+	assert.Equal(t, "add users endpoint", ch.Subject)
+	assert.False(t, ch.Breaking)
+
+	ch = ParseCommitMessage("Fix!: drop v1 routes")
+	assert.Equal(t, "fix", ch.Type)
+	assert.Equal(t, "", ch.Scope)
+	assert.True(t, ch.Breaking)
+
+	ch = ParseCommitMessage("refactor: rename\n\nBREAKING CHANGE: config keys renamed")
+	assert.True(t, ch.Breaking)
+
+	ch = ParseCommitMessage("Update readme")
+	assert.Equal(t, "", ch.Type)
+	assert.Equal(t, "Update readme", ch.Subject)
+}
+
+func Test_ReadChanges(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "chl-")
+	defer os.RemoveAll(tmpdir)
+
+	_, repo, _ := SetupLocalGitRepo("test", "me", tmpdir, "foo.com")
+	wt, _ := repo.Worktree()
+	tagger := &object.Signature{Name: "thrap", Email: "thrap", When: time.Now()}
+
+	commit := func(file, msg string) {
+		fpath := filepath.Join(tmpdir, file)
+		os.MkdirAll(filepath.Dir(fpath), 0755)
+		ioutil.WriteFile(fpath, []byte(msg), 0644)
+		wt.Add(file)
+		wt.Commit(msg, committer())
+	}
+
+	commit("README.md", "Initial commit")
+	CreateTag(tmpdir, "v1.0.0", "v1.0.0", tagger)
+	commit("api/main.go", "feat(api): add endpoint")
+	commit("web/index.html", "fix: broken link")
+
+	cs, err := ReadChanges(tmpdir, "", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.0.0", cs.From)
+	assert.Equal(t, 2, len(cs.Changes))
+	assert.Equal(t, "fix", cs.Changes[0].Type)
+	assert.Equal(t, []string{"web/index.html"}, cs.Changes[0].Files)
+	assert.Equal(t, []string{"api/main.go"}, cs.Changes[1].Files)
+
+	// Head tagged uses the prior version
+	CreateTag(tmpdir, "v1.1.0", "v1.1.0", tagger)
+	cs, err = ReadChanges(tmpdir, "", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.0.0", cs.From)
+	assert.Equal(t, 2, len(cs.Changes))
+
+	cs, err = ReadChanges(tmpdir, "v1.0.0", "HEAD~1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(cs.Changes))
+	assert.Equal(t, "api", cs.Changes[0].Scope)
+
+	// All commits
+	cs, err = ReadChanges(tmpdir, "", "v1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, "", cs.From)
+	assert.Equal(t, 1, len(cs.Changes))
+	assert.Equal(t, []string{"README.md"}, cs.Changes[0].Files)
+
+	_, err = ReadChanges(tmpdir, "v9.0.0", "")
+	assert.NotNil(t, err)
+}
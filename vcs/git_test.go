@@ -70,6 +70,77 @@ func Test_GetRepoVersion(t *testing.T) {
 
 }
 
+func Test_GetRepoVersion_semver(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "grv-")
+	defer os.RemoveAll(tmpdir)
+
+	_, repo, _ := SetupLocalGitRepo("test", "me", tmpdir, "foo.com")
+	wt, _ := repo.Worktree()
+
+	tagger := &object.Signature{Name: "thrap", Email: "thrap", When: time.Now()}
+	for i, tag := range []string{"v1.10.0", "v1.9.0", "v2.0.0-rc.1", "foo"} {
+		wt.Commit(fmt.Sprintf("commit %d", i), committer())
+		_, err := CreateTag(tmpdir, tag, "release "+tag, tagger)
+		assert.Nil(t, err)
+	}
+
+	// Greatest semver and not the latest tag
+	ver, err := ReadRepoVersion(tmpdir)
+	assert.Nil(t, err)
+	assert.Equal(t, "v2.0.0-rc.1", ver.Tag)
+	assert.Equal(t, 1, ver.Count)
+	assert.True(t, ver.IsPrerelease())
+
+	_, err = CreateTag(tmpdir, "foo", "", tagger)
+	assert.Contains(t, err.Error(), errTagExists.Error())
+
+	// Tags not reachable from head are ignored
+	wt.Checkout(&git.CheckoutOptions{Branch: "refs/heads/other", Create: true})
+	head, _ := repo.Head()
+	cmt, _ := repo.CommitObject(head.Hash())
+	cmt, _ = cmt.Parents().Next()
+	cmt, _ = cmt.Parents().Next()
+	wt.Reset(&git.ResetOptions{Commit: cmt.Hash, Mode: git.HardReset})
+
+	ver, _ = getRepoVersion(repo)
+	assert.Equal(t, "v1.10.0", ver.Tag)
+	assert.Equal(t, 1, ver.Count)
+}
+
+func Test_RepoVersion_Next(t *testing.T) {
+	for _, tc := range []struct {
+		tag, bump, pre, next string
+	}{
+		{"v0.0.0", "", "", "v0.0.1"},
+		{"v1.2.3", "patch", "", "v1.2.4"},
+		{"v1.2.3", "minor", "", "v1.3.0"},
+		{"v1.2.3", "major", "", "v2.0.0"},
+		{"1.2.3", "minor", "", "1.3.0"},
+		{"v1.2.3", "minor", "rc", "v1.3.0-rc.1"},
+		{"v1.3.0-rc.1", "minor", "rc", "v1.3.0-rc.2"},
+		{"v1.3.0-rc.2", "", "rc", "v1.3.0-rc.3"},
+		{"v1.3.0-rc.2", "minor", "", "v1.3.0"},
+		{"v1.3.0-rc.2", "", "", "v1.3.0"},
+		{"v1.3.0-rc.2", "major", "", "v2.0.0"},
+		{"v2.0.0-beta.2", "major", "rc", "v2.0.0-rc.1"},
+		{"v1.3.1-rc", "patch", "rc", "v1.3.1-rc.1"},
+	} {
+		rv := RepoVersion{Tag: tc.tag, Version: parseTagVersion(tc.tag)}
+		next, err := rv.Next(tc.bump, tc.pre)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.next, next, tc.tag)
+	}
+
+	rv := RepoVersion{Tag: "v1.0.0", Version: parseTagVersion("v1.0.0")}
+	_, err := rv.Next("build", "")
+	assert.Equal(t, errInvalidBump, err)
+	_, err = rv.Next("", "rc.1")
+	assert.Equal(t, errPreReleaseName, err)
+
+	_, err = RepoVersion{Tag: "tag3"}.Next("", "")
+	assert.Equal(t, errTagNotSemver, err)
+}
+
 func Test_VCS(t *testing.T) {
 	conf := &Config{Provider: "xxx"}
 	_, err := New(conf)
@@ -0,0 +1,238 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package vcs
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	git "gopkg.in/src-d/go-git.v4"
+)
+
+var errGiteaAddrRequired = errors.New("gitea 'addr' required")
+
+// giteaRepo is the subset of the gitea repository api object used
+type giteaRepo struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	FullName    string `json:"full_name"`
+	Description string `json:"description"`
+	Private     bool   `json:"private"`
+	CloneURL    string `json:"clone_url"`
+	SSHURL      string `json:"ssh_url"`
+	HTMLURL     string `json:"html_url"`
+}
+
+type giteaHook struct {
+	ID     int64             `json:"id,omitempty"`
+	Type   string            `json:"type"`
+	Config map[string]string `json:"config"`
+	Events []string          `json:"events"`
+	Active bool              `json:"active"`
+}
+
+// gitea repo extending git.  Gitea is always self-hosted so an address is
+// required
+type giteaVCS struct {
+	git      *GitVCS
+	username string
+	api      *restClient
+}
+
+// takes an optional underlying git vcs
+func newGiteaVCS(g *GitVCS) *giteaVCS {
+	gt := &giteaVCS{git: g}
+	if gt.git == nil {
+		gt.git = NewGitVCS()
+	}
+	return gt
+}
+
+func (gt *giteaVCS) ID() string {
+	return "gitea"
+}
+
+// Environment Variables:
+// GITEA_ACCESS_TOKEN
+func (gt *giteaVCS) Init(conf map[string]interface{}) error {
+	err := gt.git.Init(conf)
+	if err != nil {
+		return err
+	}
+
+	addr, err := confString(conf, "addr")
+	if err != nil {
+		return err
+	}
+	if addr == "" {
+		return errGiteaAddrRequired
+	}
+
+	token, err := confString(conf, "token")
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		token = os.Getenv("GITEA_ACCESS_TOKEN")
+	}
+
+	gt.username, err = confString(conf, "username")
+	if err != nil {
+		return err
+	}
+
+	gt.api = &restClient{
+		base:       baseURL(addr, "/api/v1"),
+		authHeader: "Authorization",
+	}
+	if token != "" {
+		gt.api.authValue = "token " + token
+	}
+	return nil
+}
+
+func (gt *giteaVCS) GlobalUser() string {
+	return gt.git.globalUser
+}
+
+func (gt *giteaVCS) GlobalEmail() string {
+	return gt.git.globalEmail
+}
+
+// repoPath returns the api path to the repo.  The owner defaults to the
+// configured user
+func (gt *giteaVCS) repoPath(repo *Repository) string {
+	owner := repo.Owner
+	if owner == "" {
+		owner = gt.username
+	}
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo.Name)
+}
+
+// Get returns info on a gitea repository
+func (gt *giteaVCS) Get(repo *Repository, opt Option) (interface{}, error) {
+	var r giteaRepo
+	err := gt.api.do("GET", gt.repoPath(repo), nil, &r)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Create creates a new repository under the user, or the organization if
+// the owner is not the configured user.  The repository is only created if
+// it does not exist
+func (gt *giteaVCS) Create(repo *Repository, opt Option) (interface{}, bool, error) {
+	r, err := gt.Get(repo, opt)
+	if err == nil {
+		return r, false, nil
+	}
+	if !IsNotFound(err) {
+		return nil, false, err
+	}
+
+	req := map[string]interface{}{
+		"name":        repo.Name,
+		"description": repo.Description,
+		"private":     repo.Private,
+	}
+
+	path := "/user/repos"
+	if repo.Owner != "" && repo.Owner != gt.username {
+		path = "/org/" + url.PathEscape(repo.Owner) + "/repos"
+	}
+
+	var created giteaRepo
+	err = gt.api.do("POST", path, req, &created)
+	if err != nil {
+		return nil, false, err
+	}
+	return &created, true, nil
+}
+
+// Delete deletes the repository from gitea
+func (gt *giteaVCS) Delete(repo *Repository, opt Option) error {
+	return gt.api.do("DELETE", gt.repoPath(repo), nil, nil)
+}
+
+// AddHook adds a push webhook, which includes tags, to the repository.
+// Deliveries are signed with the secret in the X-Gitea-Signature header.  An
+// existing hook with the same url is updated with the secret and events as
+// the secret may have been rotated
+func (gt *giteaVCS) AddHook(repo *Repository, hook *Hook) (interface{}, error) {
+	hooks, err := gt.listHooks(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		req = &giteaHook{
+			Type: "gitea",
+			Config: map[string]string{
+				"url":          hook.URL,
+				"content_type": "json",
+				"secret":       hook.Secret,
+			},
+			Events: []string{"push"},
+			Active: true,
+		}
+		method = "POST"
+		path   = gt.repoPath(repo) + "/hooks"
+	)
+	for _, h := range hooks {
+		if h.Config["url"] == hook.URL {
+			method = "PATCH"
+			path = fmt.Sprintf("%s/%d", path, h.ID)
+			break
+		}
+	}
+
+	var out giteaHook
+	if err = gt.api.do(method, path, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RemoveHook removes all repository webhooks with the hook url
+func (gt *giteaVCS) RemoveHook(repo *Repository, hook *Hook) error {
+	hooks, err := gt.listHooks(repo)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range hooks {
+		if h.Config["url"] != hook.URL {
+			continue
+		}
+		path := fmt.Sprintf("%s/hooks/%d", gt.repoPath(repo), h.ID)
+		if err = gt.api.do("DELETE", path, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (gt *giteaVCS) listHooks(repo *Repository) ([]*giteaHook, error) {
+	var hooks []*giteaHook
+	err := gt.api.do("GET", gt.repoPath(repo)+"/hooks", nil, &hooks)
+	return hooks, err
+}
+
+func (gt *giteaVCS) Open(repo *Repository, opt Option) (interface{}, error) {
+	return gt.git.Open(repo, opt)
+}
+
+func (gt *giteaVCS) Status(opt Option) (git.Status, error) {
+	return gt.git.Status(opt)
+}
+
+func (gt *giteaVCS) IgnoresFile() string {
+	return gt.git.IgnoresFile()
+}
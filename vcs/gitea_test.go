@@ -0,0 +1,169 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package vcs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGitea is a minimal in-memory gitea v1 api
+func fakeGitea(t *testing.T) *httptest.Server {
+	var (
+		repos  = map[string]*giteaRepo{}
+		hooks  = map[string][]*giteaHook{}
+		nextID int64
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token secret-token", r.Header.Get("Authorization"))
+
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1")
+		create := func(owner string) {
+			var req giteaRepo
+			json.NewDecoder(r.Body).Decode(&req)
+			nextID++
+			req.ID = nextID
+			req.FullName = owner + "/" + req.Name
+			repos[req.FullName] = &req
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(&req)
+		}
+
+		switch {
+		case r.Method == "POST" && path == "/user/repos":
+			create("me")
+		case r.Method == "POST" && path == "/org/myorg/repos":
+			create("myorg")
+
+		case strings.HasPrefix(path, "/repos/"):
+			parts := strings.SplitN(strings.TrimPrefix(path, "/repos/"), "/", 3)
+			name := parts[0] + "/" + parts[1]
+			repo, ok := repos[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			rest := ""
+			if len(parts) == 3 {
+				rest = parts[2]
+			}
+
+			switch {
+			case r.Method == "GET" && rest == "":
+				json.NewEncoder(w).Encode(repo)
+			case r.Method == "DELETE" && rest == "":
+				delete(repos, name)
+				w.WriteHeader(http.StatusNoContent)
+			case r.Method == "GET" && rest == "hooks":
+				json.NewEncoder(w).Encode(hooks[name])
+			case r.Method == "POST" && rest == "hooks":
+				var h giteaHook
+				json.NewDecoder(r.Body).Decode(&h)
+				nextID++
+				h.ID = nextID
+				hooks[name] = append(hooks[name], &h)
+				w.WriteHeader(http.StatusCreated)
+				json.NewEncoder(w).Encode(&h)
+			case r.Method == "PATCH" && strings.HasPrefix(rest, "hooks/"):
+				for _, h := range hooks[name] {
+					if fmt.Sprintf("hooks/%d", h.ID) == rest {
+						json.NewDecoder(r.Body).Decode(h)
+						json.NewEncoder(w).Encode(h)
+						return
+					}
+				}
+				w.WriteHeader(http.StatusNotFound)
+			case r.Method == "DELETE" && strings.HasPrefix(rest, "hooks/"):
+				out := hooks[name][:0]
+				for _, h := range hooks[name] {
+					if fmt.Sprintf("hooks/%d", h.ID) != rest {
+						out = append(out, h)
+					}
+				}
+				hooks[name] = out
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func Test_Gitea(t *testing.T) {
+	g := newGiteaVCS(nil)
+	err := g.Init(map[string]interface{}{})
+	assert.Equal(t, errGiteaAddrRequired, err)
+
+	srv := fakeGitea(t)
+	defer srv.Close()
+
+	err = g.Init(map[string]interface{}{
+		"addr":     srv.URL,
+		"token":    "secret-token",
+		"username": "me",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "gitea", g.ID())
+
+	var opt Option
+	repo := &Repository{Name: "api-test", Description: "test"}
+
+	_, err = g.Get(repo, opt)
+	assert.True(t, IsNotFound(err))
+
+	resp, created, err := g.Create(repo, opt)
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "me/api-test", resp.(*giteaRepo).FullName)
+
+	_, created, err = g.Create(repo, opt)
+	assert.Nil(t, err)
+	assert.False(t, created)
+
+	orepo := &Repository{Name: "api-test", Owner: "myorg", Private: true}
+	resp, created, err = g.Create(orepo, opt)
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.True(t, resp.(*giteaRepo).Private)
+
+	hook := &Hook{URL: "https://thrap.example.com/hook", Secret: "s3cr3t"}
+	h, err := g.AddHook(repo, hook)
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cr3t", h.(*giteaHook).Config["secret"])
+	assert.Equal(t, []string{"push"}, h.(*giteaHook).Events)
+
+	// Rotated secrets update the existing hook
+	hook.Secret = "r0tated"
+	h2, err := g.AddHook(repo, hook)
+	assert.Nil(t, err)
+	assert.Equal(t, h.(*giteaHook).ID, h2.(*giteaHook).ID)
+	hooks, _ := g.listHooks(repo)
+	assert.Equal(t, 1, len(hooks))
+	assert.Equal(t, "r0tated", hooks[0].Config["secret"])
+
+	assert.Nil(t, g.RemoveHook(repo, hook))
+	hooks, _ = g.listHooks(repo)
+	assert.Equal(t, 0, len(hooks))
+
+	assert.Nil(t, g.Delete(repo, opt))
+	_, err = g.Get(repo, opt)
+	assert.True(t, IsNotFound(err))
+}
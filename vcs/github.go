@@ -17,12 +17,14 @@ import (
 	git "gopkg.in/src-d/go-git.v4"
 )
 
-const defaultHookName = "thrap"
+// Github requires webhooks to be named web
+const defaultHookName = "web"
 
 // github repo extending git
 type githubVCS struct {
-	git    *GitVCS
-	client *github.Client
+	git      *GitVCS
+	username string
+	client   *github.Client
 }
 
 // takes an optional underlying git vcs
@@ -57,6 +59,11 @@ func (gh *githubVCS) Init(conf map[string]interface{}) error {
 			}
 		}
 
+		gh.username, err = confString(conf, "username")
+		if err != nil {
+			return err
+		}
+
 		httpClient := makeGithubHTTPClient(token)
 		gh.client = github.NewClient(httpClient)
 	}
@@ -79,22 +86,99 @@ func (gh *githubVCS) Get(repo *Repository, opt Option) (interface{}, error) {
 	return ghRepo, err
 }
 
-func (gh *githubVCS) AddHook(repo *Repository) (interface{}, error) {
+// AddHook adds a push webhook, which includes tags, to the repo.  Deliveries
+// are signed with the secret in the X-Hub-Signature header.  An existing hook
+// with the same url is updated with the secret and events as the secret may
+// have been rotated
+func (gh *githubVCS) AddHook(repo *Repository, hook *Hook) (interface{}, error) {
 	rs := gh.client.Repositories
-
 	ctx := context.Background()
+	owner := gh.owner(repo)
+
+	hooks, _, err := rs.ListHooks(ctx, owner, repo.Name, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	hookName := defaultHookName
-	hook := &github.Hook{
-		Name: &hookName,
+	active := true
+	ghook := &github.Hook{
+		Name:   &hookName,
+		Events: []string{"push"},
+		Active: &active,
+		Config: map[string]interface{}{
+			"url":          hook.URL,
+			"content_type": "json",
+			"secret":       hook.Secret,
+		},
+	}
+
+	for _, h := range hooks {
+		if h.Config["url"] == hook.URL {
+			rhook, _, err := rs.EditHook(ctx, owner, repo.Name, h.GetID(), ghook)
+			return rhook, err
+		}
 	}
 
-	rhook, _, err := rs.CreateHook(ctx, repo.Owner, repo.Name, hook)
+	rhook, _, err := rs.CreateHook(ctx, owner, repo.Name, ghook)
 	return rhook, err
 }
 
-func (gh *githubVCS) RemoveHook(repo *Repository) (interface{}, error) {
-	return nil, errors.New("to be implemented")
+// RemoveHook removes all repo webhooks with the hook url
+func (gh *githubVCS) RemoveHook(repo *Repository, hook *Hook) error {
+	rs := gh.client.Repositories
+	ctx := context.Background()
+	owner := gh.owner(repo)
+
+	hooks, _, err := rs.ListHooks(ctx, owner, repo.Name, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range hooks {
+		if h.Config["url"] != hook.URL {
+			continue
+		}
+		if _, err = rs.DeleteHook(ctx, owner, repo.Name, h.GetID()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishRelease creates the github release for the tag, updating the name
+// and notes if the release exists
+func (gh *githubVCS) PublishRelease(repo *Repository, rel *Release) (interface{}, error) {
+	rs := gh.client.Repositories
+	ctx := context.Background()
+	owner := gh.owner(repo)
+
+	ghrel := &github.RepositoryRelease{
+		TagName:    &rel.Tag,
+		Name:       &rel.Name,
+		Body:       &rel.Notes,
+		Prerelease: &rel.Prerelease,
+	}
+
+	existing, resp, err := rs.GetReleaseByTag(ctx, owner, repo.Name, rel.Tag)
+	if err == nil {
+		existing, _, err = rs.EditRelease(ctx, owner, repo.Name, existing.GetID(), ghrel)
+		return existing, err
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return nil, err
+	}
+
+	created, _, err := rs.CreateRelease(ctx, owner, repo.Name, ghrel)
+	return created, err
+}
+
+// owner returns the repo owner defaulting to the configured user
+func (gh *githubVCS) owner(repo *Repository) string {
+	if repo.Owner != "" {
+		return repo.Owner
+	}
+	return gh.username
 }
 
 // Create creates a new repo. Each call only fills in missing pieces so multiple
@@ -7,9 +7,16 @@ Sniperkit-Bot
 package vcs
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 
+	"github.com/google/go-github/github"
 	"github.com/sniperkit/snk.fork.thrap/config"
 	"github.com/stretchr/testify/assert"
 )
@@ -56,3 +63,75 @@ func Test_Github_calls(t *testing.T) {
 	err = g.Delete(repo, opt)
 	assert.Nil(t, err)
 }
+
+// fakeGithubHooks is a minimal in-memory github repo hooks api
+func fakeGithubHooks() *httptest.Server {
+	var (
+		hooks  []*github.Hook
+		nextID int64
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/me/api-test/hooks", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			json.NewEncoder(w).Encode(hooks)
+		case "POST":
+			var h github.Hook
+			json.NewDecoder(r.Body).Decode(&h)
+			nextID++
+			h.ID = &nextID
+			hooks = append(hooks, &h)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(&h)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/repos/me/api-test/hooks/", func(w http.ResponseWriter, r *http.Request) {
+		for _, h := range hooks {
+			if r.URL.Path != fmt.Sprintf("/repos/me/api-test/hooks/%d", h.GetID()) {
+				continue
+			}
+			if r.Method != "PATCH" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			id := h.GetID()
+			json.NewDecoder(r.Body).Decode(h)
+			h.ID = &id
+			json.NewEncoder(w).Encode(h)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func Test_Github_AddHook(t *testing.T) {
+	srv := fakeGithubHooks()
+	defer srv.Close()
+
+	g := newGithubVCS(nil)
+	g.username = "me"
+	g.client = github.NewClient(nil)
+	g.client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	repo := &Repository{Name: "api-test"}
+	hook := &Hook{URL: "https://thrap.example.com/hook", Secret: "s3cr3t"}
+	h, err := g.AddHook(repo, hook)
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cr3t", h.(*github.Hook).Config["secret"])
+	assert.Equal(t, []string{"push"}, h.(*github.Hook).Events)
+
+	// Rotated secrets update the existing hook
+	hook.Secret = "r0tated"
+	h2, err := g.AddHook(repo, hook)
+	assert.Nil(t, err)
+	assert.Equal(t, h.(*github.Hook).GetID(), h2.(*github.Hook).GetID())
+	assert.Equal(t, "r0tated", h2.(*github.Hook).Config["secret"])
+
+	hooks, _, _ := g.client.Repositories.ListHooks(context.Background(), "me", "api-test", nil)
+	assert.Equal(t, 1, len(hooks))
+}
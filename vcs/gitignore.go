@@ -6,9 +6,21 @@ Sniperkit-Bot
 
 package vcs
 
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
 var defaultGitIgnores = []string{
-	// Self
-	".thrap",
+	// Self.  The project locks, scaffold record and lint config are tracked
+	".thrap/*",
+	"!.thrap/packs.lock",
+	"!.thrap/images.lock",
+	"!.thrap/scaffold.json",
+	"!.thrap/lint.hcl",
 	"secrets.*",
 	// OS X
 	".Trash",
@@ -30,3 +42,59 @@ var defaultGitIgnores = []string{
 func DefaultGitIgnores() []string {
 	return defaultGitIgnores
 }
+
+// UntrackIgnores updates the .gitignore file in dir so the given project
+// relative files are no longer ignored.  An ignore of a whole parent
+// directory, as written by older versions, is narrowed to its contents as
+// git cannot re-include a file of an excluded directory.  It is a no-op if
+// there is no .gitignore file
+func UntrackIgnores(dir string, files ...string) error {
+	fpath := filepath.Join(dir, gitIgnoresFile)
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	updated := untrackIgnores(lines, files)
+	if strings.Join(updated, "\n") == strings.Join(lines, "\n") {
+		return nil
+	}
+
+	return ioutil.WriteFile(fpath, []byte(strings.Join(updated, "\n")+"\n"), 0644)
+}
+
+func untrackIgnores(lines, files []string) []string {
+	out := make([]string, len(lines))
+	copy(out, lines)
+
+	for _, file := range files {
+		var (
+			parent  = path.Dir(file)
+			negate  = "!" + file
+			ignored bool
+			present bool
+		)
+
+		for i, line := range out {
+			switch strings.TrimSpace(line) {
+			case parent, parent + "/", "/" + parent, "/" + parent + "/":
+				out[i] = parent + "/*"
+				ignored = true
+			case parent + "/*", "/" + parent + "/*":
+				ignored = true
+			case negate, "!/" + file:
+				present = true
+			}
+		}
+
+		if ignored && !present {
+			out = append(out, negate)
+		}
+	}
+
+	return out
+}
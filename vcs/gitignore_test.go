@@ -0,0 +1,28 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package vcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_untrackIgnores(t *testing.T) {
+	// Older projects ignore the whole work dir
+	got := untrackIgnores([]string{".thrap", "secrets.*"}, []string{".thrap/images.lock"})
+	assert.Equal(t, []string{".thrap/*", "secrets.*", "!.thrap/images.lock"}, got)
+
+	// Already tracked
+	lines := []string{".thrap/*", "!.thrap/packs.lock", "!.thrap/images.lock"}
+	got = untrackIgnores(lines, []string{".thrap/images.lock"})
+	assert.Equal(t, lines, got)
+
+	// Not ignored at all
+	got = untrackIgnores([]string{"*.log"}, []string{".thrap/images.lock"})
+	assert.Equal(t, []string{"*.log"}, got)
+}
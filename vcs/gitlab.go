@@ -0,0 +1,248 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package vcs
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	git "gopkg.in/src-d/go-git.v4"
+)
+
+const defaultGitlabAddr = "gitlab.com"
+
+// gitlabProject is the subset of the gitlab project api object used
+type gitlabProject struct {
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	Description       string `json:"description"`
+	Visibility        string `json:"visibility"`
+	SSHURLToRepo      string `json:"ssh_url_to_repo"`
+	HTTPURLToRepo     string `json:"http_url_to_repo"`
+	WebURL            string `json:"web_url"`
+}
+
+type gitlabHook struct {
+	ID                    int    `json:"id,omitempty"`
+	URL                   string `json:"url"`
+	Token                 string `json:"token,omitempty"`
+	PushEvents            bool   `json:"push_events"`
+	TagPushEvents         bool   `json:"tag_push_events"`
+	EnableSSLVerification bool   `json:"enable_ssl_verification"`
+}
+
+type gitlabNamespace struct {
+	ID   int    `json:"id"`
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+}
+
+// gitlab repo extending git.  Supports gitlab.com as well as self-hosted
+// instances
+type gitlabVCS struct {
+	git      *GitVCS
+	username string
+	api      *restClient
+}
+
+// takes an optional underlying git vcs
+func newGitlabVCS(g *GitVCS) *gitlabVCS {
+	gl := &gitlabVCS{git: g}
+	if gl.git == nil {
+		gl.git = NewGitVCS()
+	}
+	return gl
+}
+
+func (gl *gitlabVCS) ID() string {
+	return "gitlab"
+}
+
+// Environment Variables:
+// GITLAB_ACCESS_TOKEN
+func (gl *gitlabVCS) Init(conf map[string]interface{}) error {
+	err := gl.git.Init(conf)
+	if err != nil {
+		return err
+	}
+
+	addr, err := confString(conf, "addr")
+	if err != nil {
+		return err
+	}
+	if addr == "" {
+		addr = defaultGitlabAddr
+	}
+
+	token, err := confString(conf, "token")
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		token = os.Getenv("GITLAB_ACCESS_TOKEN")
+	}
+
+	gl.username, err = confString(conf, "username")
+	if err != nil {
+		return err
+	}
+
+	gl.api = &restClient{
+		base:       baseURL(addr, "/api/v4"),
+		authHeader: "PRIVATE-TOKEN",
+		authValue:  token,
+	}
+	return nil
+}
+
+func (gl *gitlabVCS) GlobalUser() string {
+	return gl.git.globalUser
+}
+
+func (gl *gitlabVCS) GlobalEmail() string {
+	return gl.git.globalEmail
+}
+
+// projectPath returns the url escaped project path used as the project id.
+// The owner defaults to the configured user
+func (gl *gitlabVCS) projectPath(repo *Repository) string {
+	owner := repo.Owner
+	if owner == "" {
+		owner = gl.username
+	}
+	return "/projects/" + url.PathEscape(owner+"/"+repo.Name)
+}
+
+// Get returns info on a gitlab project
+func (gl *gitlabVCS) Get(repo *Repository, opt Option) (interface{}, error) {
+	var proj gitlabProject
+	err := gl.api.do("GET", gl.projectPath(repo), nil, &proj)
+	if err != nil {
+		return nil, err
+	}
+	return &proj, nil
+}
+
+// Create creates a new project under the owner which may be a user or
+// group.  The project is only created if it does not exist
+func (gl *gitlabVCS) Create(repo *Repository, opt Option) (interface{}, bool, error) {
+	proj, err := gl.Get(repo, opt)
+	if err == nil {
+		return proj, false, nil
+	}
+	if !IsNotFound(err) {
+		return nil, false, err
+	}
+
+	req := map[string]interface{}{
+		"name":        repo.Name,
+		"path":        repo.Name,
+		"description": repo.Description,
+		"visibility":  "public",
+	}
+	if repo.Private {
+		req["visibility"] = "private"
+	}
+
+	// Projects are created under the token user unless a group or other
+	// namespace is requested
+	if repo.Owner != "" && repo.Owner != gl.username {
+		var ns gitlabNamespace
+		err = gl.api.do("GET", "/namespaces/"+url.PathEscape(repo.Owner), nil, &ns)
+		if err != nil {
+			return nil, false, fmt.Errorf("namespace %s: %v", repo.Owner, err)
+		}
+		req["namespace_id"] = ns.ID
+	}
+
+	var created gitlabProject
+	err = gl.api.do("POST", "/projects", req, &created)
+	if err != nil {
+		return nil, false, err
+	}
+	return &created, true, nil
+}
+
+// Delete deletes the project from gitlab
+func (gl *gitlabVCS) Delete(repo *Repository, opt Option) error {
+	return gl.api.do("DELETE", gl.projectPath(repo), nil, nil)
+}
+
+// AddHook adds a push and tag push webhook to the project.  The secret is
+// sent by gitlab as the X-Gitlab-Token header.  An existing hook with the
+// same url is updated with the secret and events as the secret may have been
+// rotated
+func (gl *gitlabVCS) AddHook(repo *Repository, hook *Hook) (interface{}, error) {
+	hooks, err := gl.listHooks(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		req = &gitlabHook{
+			URL:                   hook.URL,
+			Token:                 hook.Secret,
+			PushEvents:            true,
+			TagPushEvents:         true,
+			EnableSSLVerification: true,
+		}
+		method = "POST"
+		path   = gl.projectPath(repo) + "/hooks"
+	)
+	for _, h := range hooks {
+		if h.URL == hook.URL {
+			method = "PUT"
+			path = fmt.Sprintf("%s/%d", path, h.ID)
+			break
+		}
+	}
+
+	var out gitlabHook
+	if err = gl.api.do(method, path, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RemoveHook removes all project webhooks with the hook url
+func (gl *gitlabVCS) RemoveHook(repo *Repository, hook *Hook) error {
+	hooks, err := gl.listHooks(repo)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range hooks {
+		if h.URL != hook.URL {
+			continue
+		}
+		path := fmt.Sprintf("%s/hooks/%d", gl.projectPath(repo), h.ID)
+		if err = gl.api.do("DELETE", path, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (gl *gitlabVCS) listHooks(repo *Repository) ([]*gitlabHook, error) {
+	var hooks []*gitlabHook
+	err := gl.api.do("GET", gl.projectPath(repo)+"/hooks", nil, &hooks)
+	return hooks, err
+}
+
+func (gl *gitlabVCS) Open(repo *Repository, opt Option) (interface{}, error) {
+	return gl.git.Open(repo, opt)
+}
+
+func (gl *gitlabVCS) Status(opt Option) (git.Status, error) {
+	return gl.git.Status(opt)
+}
+
+func (gl *gitlabVCS) IgnoresFile() string {
+	return gl.git.IgnoresFile()
+}
@@ -0,0 +1,162 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package vcs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGitlab is a minimal in-memory gitlab v4 api
+func fakeGitlab(t *testing.T) *httptest.Server {
+	var (
+		projects = map[string]*gitlabProject{}
+		hooks    = map[string][]*gitlabHook{}
+		nextID   = 1
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret-token", r.Header.Get("PRIVATE-TOKEN"))
+
+		path := r.URL.EscapedPath()
+		switch {
+		case r.Method == "GET" && path == "/api/v4/namespaces/myorg":
+			json.NewEncoder(w).Encode(&gitlabNamespace{ID: 42, Path: "myorg", Kind: "group"})
+
+		case r.Method == "POST" && path == "/api/v4/projects":
+			var req map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&req)
+			owner := "me"
+			if req["namespace_id"] != nil {
+				owner = "myorg"
+			}
+			p := &gitlabProject{
+				ID:                nextID,
+				Name:              req["name"].(string),
+				PathWithNamespace: owner + "/" + req["name"].(string),
+				Visibility:        req["visibility"].(string),
+			}
+			nextID++
+			projects[owner+"%2F"+p.Name] = p
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(p)
+
+		default:
+			parts := strings.SplitN(strings.TrimPrefix(path, "/api/v4/projects/"), "/", 2)
+			id, rest := parts[0], ""
+			if len(parts) == 2 {
+				rest = parts[1]
+			}
+
+			p, ok := projects[id]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"message":"404 Project Not Found"}`))
+				return
+			}
+
+			switch {
+			case r.Method == "GET" && rest == "":
+				json.NewEncoder(w).Encode(p)
+			case r.Method == "DELETE" && rest == "":
+				delete(projects, id)
+				w.WriteHeader(http.StatusAccepted)
+			case r.Method == "GET" && rest == "hooks":
+				json.NewEncoder(w).Encode(hooks[id])
+			case r.Method == "POST" && rest == "hooks":
+				var h gitlabHook
+				json.NewDecoder(r.Body).Decode(&h)
+				h.ID = nextID
+				nextID++
+				hooks[id] = append(hooks[id], &h)
+				json.NewEncoder(w).Encode(&h)
+			case r.Method == "PUT" && strings.HasPrefix(rest, "hooks/"):
+				for _, h := range hooks[id] {
+					if fmt.Sprintf("hooks/%d", h.ID) == rest {
+						json.NewDecoder(r.Body).Decode(h)
+						json.NewEncoder(w).Encode(h)
+						return
+					}
+				}
+				w.WriteHeader(http.StatusNotFound)
+			case r.Method == "DELETE":
+				hooks[id] = hooks[id][:0]
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		}
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func Test_Gitlab(t *testing.T) {
+	srv := fakeGitlab(t)
+	defer srv.Close()
+
+	g := newGitlabVCS(nil)
+	err := g.Init(map[string]interface{}{
+		"addr":     srv.URL,
+		"token":    "secret-token",
+		"username": "me",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "gitlab", g.ID())
+
+	var opt Option
+	repo := &Repository{Name: "api-test", Private: true}
+
+	_, err = g.Get(repo, opt)
+	assert.True(t, IsNotFound(err))
+
+	resp, created, err := g.Create(repo, opt)
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "private", resp.(*gitlabProject).Visibility)
+
+	_, created, err = g.Create(repo, opt)
+	assert.Nil(t, err)
+	assert.False(t, created)
+
+	// Group owned
+	grepo := &Repository{Name: "api-test", Owner: "myorg"}
+	resp, created, err = g.Create(grepo, opt)
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "myorg/api-test", resp.(*gitlabProject).PathWithNamespace)
+
+	hook := &Hook{URL: "https://thrap.example.com/hook", Secret: "s3cr3t"}
+	h, err := g.AddHook(repo, hook)
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cr3t", h.(*gitlabHook).Token)
+	assert.True(t, h.(*gitlabHook).TagPushEvents)
+
+	// Rotated secrets update the existing hook
+	hook.Secret = "r0tated"
+	h2, err := g.AddHook(repo, hook)
+	assert.Nil(t, err)
+	assert.Equal(t, h.(*gitlabHook).ID, h2.(*gitlabHook).ID)
+	hooks, _ := g.listHooks(repo)
+	assert.Equal(t, 1, len(hooks))
+	assert.Equal(t, "r0tated", hooks[0].Token)
+
+	assert.Nil(t, g.RemoveHook(repo, hook))
+	hooks, _ = g.listHooks(repo)
+	assert.Equal(t, 0, len(hooks))
+
+	assert.Nil(t, g.Delete(repo, opt))
+	_, err = g.Get(repo, opt)
+	assert.True(t, IsNotFound(err))
+}
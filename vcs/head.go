@@ -0,0 +1,322 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package vcs
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/ssh"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+)
+
+const (
+	// SignaturePGP is a gpg signed commit
+	SignaturePGP = "pgp"
+	// SignatureSSH is a ssh signed commit
+	SignatureSSH = "ssh"
+
+	sshSigMagic     = "SSHSIG"
+	sshSigNamespace = "git"
+)
+
+var (
+	errCommitUnsigned      = errors.New("commit not signed")
+	errSignatureType       = errors.New("unsupported signature type")
+	errNoVerificationKeys  = errors.New("no keys to verify signature")
+	errSSHSignatureInvalid = errors.New("invalid ssh signature")
+	errSSHKeyNotAllowed    = errors.New("ssh signing key not allowed")
+)
+
+// Head describes the commit checked out in a repo
+type Head struct {
+	// Commit hash
+	Commit string
+	// Branch checked out. Empty if detached
+	Branch string
+	// Tags pointing to the commit
+	Tags []string
+	// Armored signature of the commit. Empty if unsigned
+	Signature string
+	// True if the commit is reachable from a remote tracking branch
+	Pushed bool
+
+	// commit object without the signature
+	payload []byte
+}
+
+// SignatureType returns the type of the commit signature, one of pgp or
+// ssh.  It returns an empty string if unsigned or unknown
+func (head *Head) SignatureType() string {
+	switch {
+	case strings.HasPrefix(head.Signature, "-----BEGIN PGP SIGNATURE-----"):
+		return SignaturePGP
+	case strings.HasPrefix(head.Signature, "-----BEGIN SSH SIGNATURE-----"):
+		return SignatureSSH
+	}
+	return ""
+}
+
+// VerifySignature verifies the commit signature.  PGP signatures are checked
+// against the armored keyring and SSH signatures against the keys in
+// authorized_keys format
+func (head *Head) VerifySignature(pgpKeyring, sshAllowedKeys []byte) error {
+	switch head.SignatureType() {
+	case SignaturePGP:
+		if len(pgpKeyring) == 0 {
+			return errNoVerificationKeys
+		}
+		keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(pgpKeyring))
+		if err != nil {
+			return err
+		}
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring,
+			bytes.NewReader(head.payload), strings.NewReader(head.Signature))
+		return err
+
+	case SignatureSSH:
+		if len(sshAllowedKeys) == 0 {
+			return errNoVerificationKeys
+		}
+		return verifySSHSignature(sshAllowedKeys, head.payload, head.Signature)
+
+	case "":
+		if head.Signature == "" {
+			return errCommitUnsigned
+		}
+	}
+
+	return errSignatureType
+}
+
+// ReadHead returns the head of the repo at path
+func ReadHead(path string) (*Head, error) {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	head := &Head{Commit: ref.Hash().String()}
+	if ref.Name().IsBranch() {
+		head.Branch = ref.Name().Short()
+	}
+
+	if tags, ok := tagsByCommit(repo)[ref.Hash()]; ok {
+		head.Tags = tags
+		sort.Strings(head.Tags)
+	}
+
+	obj, err := repo.Storer.EncodedObject(plumbing.CommitObject, ref.Hash())
+	if err != nil {
+		return nil, err
+	}
+	rd, err := obj.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+
+	head.payload, head.Signature, err = splitCommitSignature(rd)
+	if err != nil {
+		return nil, err
+	}
+
+	head.Pushed, err = isPushed(repo, ref.Hash())
+	return head, err
+}
+
+// splitCommitSignature returns the raw commit without the gpgsig header and
+// the signature in the header
+func splitCommitSignature(r io.Reader) ([]byte, string, error) {
+	var (
+		br      = bufio.NewReader(r)
+		payload bytes.Buffer
+		sig     bytes.Buffer
+		inSig   bool
+		headers = true
+	)
+
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, "", err
+		}
+
+		if headers {
+			switch {
+			case inSig && strings.HasPrefix(line, " "):
+				sig.WriteString(line[1:])
+				line = ""
+			case strings.HasPrefix(line, "gpgsig "):
+				inSig = true
+				sig.WriteString(strings.TrimPrefix(line, "gpgsig "))
+				line = ""
+			default:
+				inSig = false
+				if line == "\n" {
+					headers = false
+				}
+			}
+		}
+		payload.WriteString(line)
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return payload.Bytes(), sig.String(), nil
+}
+
+// isPushed returns true if the commit is reachable from any remote tracking
+// branch
+func isPushed(repo *git.Repository, hash plumbing.Hash) (bool, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return false, err
+	}
+
+	var remotes []plumbing.Hash
+	refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Name().IsRemote() && ref.Type() == plumbing.HashReference {
+			remotes = append(remotes, ref.Hash())
+		}
+		return nil
+	})
+
+	for _, rh := range remotes {
+		iter, err := repo.Log(&git.LogOptions{From: rh})
+		if err != nil {
+			return false, err
+		}
+
+		var found bool
+		err = iter.ForEach(func(c *object.Commit) error {
+			if c.Hash == hash {
+				found = true
+				return storer.ErrStop
+			}
+			return nil
+		})
+		if err != nil {
+			return false, err
+		}
+		if found {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// sshSig is the ssh signature blob following the magic preamble
+type sshSig struct {
+	Version   uint32
+	PublicKey []byte
+	Namespace string
+	Reserved  string
+	HashAlg   string
+	Signature []byte
+}
+
+// verifySSHSignature verifies the armored ssh signature of the payload was
+// made by one of the allowed keys
+func verifySSHSignature(allowedKeys, payload []byte, armored string) error {
+	armored = strings.TrimSpace(armored)
+	armored = strings.TrimPrefix(armored, "-----BEGIN SSH SIGNATURE-----")
+	armored = strings.TrimSuffix(armored, "-----END SSH SIGNATURE-----")
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(armored), ""))
+	if err != nil {
+		return err
+	}
+
+	if !bytes.HasPrefix(blob, []byte(sshSigMagic)) {
+		return errSSHSignatureInvalid
+	}
+
+	var sig sshSig
+	if err = ssh.Unmarshal(blob[len(sshSigMagic):], &sig); err != nil {
+		return err
+	}
+	if sig.Version != 1 || sig.Namespace != sshSigNamespace {
+		return errSSHSignatureInvalid
+	}
+
+	pub, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return err
+	}
+	if !sshKeyAllowed(allowedKeys, pub) {
+		return errSSHKeyNotAllowed
+	}
+
+	var h hash.Hash
+	switch sig.HashAlg {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return fmt.Errorf("unsupported ssh signature hash: %s", sig.HashAlg)
+	}
+	h.Write(payload)
+
+	var ssig ssh.Signature
+	if err = ssh.Unmarshal(sig.Signature, &ssig); err != nil {
+		return err
+	}
+
+	return pub.Verify(sshSignedData(sig.Namespace, sig.HashAlg, h.Sum(nil)), &ssig)
+}
+
+// sshSignedData returns the data an ssh signature is made over
+func sshSignedData(namespace, hashAlg string, digest []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(sshSigMagic)
+	for _, s := range [][]byte{[]byte(namespace), nil, []byte(hashAlg), digest} {
+		binary.Write(&buf, binary.BigEndian, uint32(len(s)))
+		buf.Write(s)
+	}
+	return buf.Bytes()
+}
+
+// sshKeyAllowed returns true if the key is in the authorized_keys formatted
+// list
+func sshKeyAllowed(allowedKeys []byte, key ssh.PublicKey) bool {
+	want := key.Marshal()
+	rest := allowedKeys
+	for len(rest) > 0 {
+		pub, _, _, r, err := ssh.ParseAuthorizedKey(rest)
+		if err != nil {
+			return false
+		}
+		if bytes.Equal(pub.Marshal(), want) {
+			return true
+		}
+		rest = r
+	}
+	return false
+}
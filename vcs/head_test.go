@@ -0,0 +1,156 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package vcs
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/ssh"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// signHead replaces the head commit of master with one signed by sign
+func signHead(t *testing.T, repo *git.Repository, sign func([]byte) string) {
+	ref, _ := repo.Head()
+	obj, _ := repo.Storer.EncodedObject(plumbing.CommitObject, ref.Hash())
+	rd, _ := obj.Reader()
+	raw, _ := ioutil.ReadAll(rd)
+	rd.Close()
+
+	i := bytes.Index(raw, []byte("\n\n"))
+	sig := strings.TrimSuffix(sign(raw), "\n")
+
+	var buf bytes.Buffer
+	buf.Write(raw[:i+1])
+	buf.WriteString("gpgsig " + strings.Replace(sig, "\n", "\n ", -1) + "\n")
+	buf.Write(raw[i+1:])
+
+	signed := repo.Storer.NewEncodedObject()
+	signed.SetType(plumbing.CommitObject)
+	w, _ := signed.Writer()
+	w.Write(buf.Bytes())
+	w.Close()
+
+	hash, err := repo.Storer.SetEncodedObject(signed)
+	assert.Nil(t, err)
+	repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/master", hash))
+}
+
+func Test_ReadHead(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "head-")
+	defer os.RemoveAll(tmpdir)
+
+	_, repo, _ := SetupLocalGitRepo("test", "me", tmpdir, "foo.com")
+	wt, _ := repo.Worktree()
+	ioutil.WriteFile(filepath.Join(tmpdir, "file"), []byte("one"), 0644)
+	wt.Add("file")
+	hash, _ := wt.Commit("one", committer())
+	tagger := &object.Signature{Name: "thrap", Email: "thrap", When: time.Now()}
+	CreateTag(tmpdir, "v1.0.0", "v1.0.0", tagger)
+
+	head, err := ReadHead(tmpdir)
+	assert.Nil(t, err)
+	assert.Equal(t, hash.String(), head.Commit)
+	assert.Equal(t, "master", head.Branch)
+	assert.Equal(t, []string{"v1.0.0"}, head.Tags)
+	assert.Equal(t, "", head.Signature)
+	assert.Equal(t, errCommitUnsigned, head.VerifySignature(nil, nil))
+	assert.False(t, head.Pushed)
+
+	repo.Storer.SetReference(plumbing.NewHashReference("refs/remotes/origin/master", hash))
+	head, _ = ReadHead(tmpdir)
+	assert.True(t, head.Pushed)
+}
+
+func Test_Head_VerifySignature_pgp(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "head-")
+	defer os.RemoveAll(tmpdir)
+
+	_, repo, _ := SetupLocalGitRepo("test", "me", tmpdir, "foo.com")
+	wt, _ := repo.Worktree()
+	ioutil.WriteFile(filepath.Join(tmpdir, "file"), []byte("one"), 0644)
+	wt.Add("file")
+	wt.Commit("one", committer())
+
+	entity, _ := openpgp.NewEntity("me", "", "me@foo.com", nil)
+	other, _ := openpgp.NewEntity("other", "", "other@foo.com", nil)
+	armoredKey := func(e *openpgp.Entity) []byte {
+		var buf bytes.Buffer
+		w, _ := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+		e.Serialize(w)
+		w.Close()
+		return buf.Bytes()
+	}
+
+	signHead(t, repo, func(payload []byte) string {
+		var buf bytes.Buffer
+		openpgp.ArmoredDetachSign(&buf, entity, bytes.NewReader(payload), nil)
+		return buf.String()
+	})
+
+	head, err := ReadHead(tmpdir)
+	assert.Nil(t, err)
+	assert.Equal(t, SignaturePGP, head.SignatureType())
+	assert.Nil(t, head.VerifySignature(armoredKey(entity), nil))
+	assert.NotNil(t, head.VerifySignature(armoredKey(other), nil))
+	assert.Equal(t, errNoVerificationKeys, head.VerifySignature(nil, []byte("ssh-ed25519 AAAA")))
+}
+
+func Test_Head_VerifySignature_ssh(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "head-")
+	defer os.RemoveAll(tmpdir)
+
+	_, repo, _ := SetupLocalGitRepo("test", "me", tmpdir, "foo.com")
+	wt, _ := repo.Worktree()
+	ioutil.WriteFile(filepath.Join(tmpdir, "file"), []byte("one"), 0644)
+	wt.Add("file")
+	wt.Commit("one", committer())
+
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	signer, _ := ssh.NewSignerFromKey(priv)
+	_, opriv, _ := ed25519.GenerateKey(rand.Reader)
+	osigner, _ := ssh.NewSignerFromKey(opriv)
+
+	signHead(t, repo, func(payload []byte) string {
+		digest := sha512.Sum512(payload)
+		sig, _ := signer.Sign(rand.Reader, sshSignedData(sshSigNamespace, "sha512", digest[:]))
+		blob := append([]byte(sshSigMagic), ssh.Marshal(&sshSig{
+			Version:   1,
+			PublicKey: signer.PublicKey().Marshal(),
+			Namespace: sshSigNamespace,
+			HashAlg:   "sha512",
+			Signature: ssh.Marshal(sig),
+		})...)
+		return "-----BEGIN SSH SIGNATURE-----\n" + base64.StdEncoding.EncodeToString(blob) +
+			"\n-----END SSH SIGNATURE-----\n"
+	})
+
+	head, err := ReadHead(tmpdir)
+	assert.Nil(t, err)
+	assert.Equal(t, SignatureSSH, head.SignatureType())
+
+	allowed := ssh.MarshalAuthorizedKey(signer.PublicKey())
+	assert.Nil(t, head.VerifySignature(nil, allowed))
+	assert.Equal(t, errSSHKeyNotAllowed, head.VerifySignature(nil, ssh.MarshalAuthorizedKey(osigner.PublicKey())))
+	both := append(ssh.MarshalAuthorizedKey(osigner.PublicKey()), allowed...)
+	assert.Nil(t, head.VerifySignature(nil, both))
+}
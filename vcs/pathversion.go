@@ -0,0 +1,199 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package vcs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+)
+
+// GetPathsVersion returns the version of the last commit that touched any of
+// the paths in the repo at path.  Paths are relative to the repo root where
+// '.' is the whole repo.  Uncommitted changes to the paths are added to the
+// version as a content hash i.e. v1.2.0-3-0a1b2c3d-dirty.4e5f6a7b, so the
+// version only changes when the paths do
+func GetPathsVersion(path string, paths []string) (string, error) {
+	vers, err := GetPathsVersions(path, map[string][]string{"": paths})
+	if err != nil {
+		return "", err
+	}
+	return vers[""], nil
+}
+
+// GetPathsVersions returns the version of each named set of paths as per
+// GetPathsVersion.  The history is walked and the worktree status read once
+// for all sets
+func GetPathsVersions(path string, sets map[string][]string) (map[string]string, error) {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cleaned := make(map[string][]string, len(sets))
+	for name, paths := range sets {
+		cleaned[name] = cleanPaths(paths)
+	}
+
+	lasts, err := lastCommitsTouching(repo, cleaned)
+	if err != nil {
+		return nil, err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		out = make(map[string]string, len(sets))
+		// Versions by commit as sets often share one
+		byHash = make(map[plumbing.Hash]string)
+	)
+	for name, paths := range cleaned {
+		last := lasts[name]
+		ver, ok := byHash[last]
+		if !ok {
+			rv, err := versionAt(repo, last, false)
+			if err != nil {
+				return nil, err
+			}
+			ver = rv.String()
+			byHash[last] = ver
+		}
+
+		var dirty []string
+		for file, fs := range status {
+			if fs.Worktree == git.Unmodified && fs.Staging == git.Unmodified {
+				continue
+			}
+			if matchesPaths(paths, file) {
+				dirty = append(dirty, file)
+			}
+		}
+
+		if len(dirty) > 0 {
+			sum, err := hashFiles(path, dirty)
+			if err != nil {
+				return nil, err
+			}
+			ver += "-dirty." + sum[:8]
+		}
+		out[name] = ver
+	}
+
+	return out, nil
+}
+
+// lastCommitsTouching returns the most recent commit from HEAD changing any
+// of the paths of each set.  HEAD is returned for sets none change.  The walk
+// stops once all sets are found
+func lastCommitsTouching(repo *git.Repository, sets map[string][]string) (map[string]plumbing.Hash, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]plumbing.Hash, len(sets))
+	err = iter.ForEach(func(c *object.Commit) error {
+		files, err := changedFiles(c)
+		if err != nil {
+			return err
+		}
+
+		for name, paths := range sets {
+			if _, ok := out[name]; ok {
+				continue
+			}
+			for _, f := range files {
+				if matchesPaths(paths, f) {
+					out[name] = c.Hash
+					break
+				}
+			}
+		}
+
+		if len(out) == len(sets) {
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range sets {
+		if _, ok := out[name]; !ok {
+			out[name] = head.Hash()
+		}
+	}
+	return out, nil
+}
+
+// cleanPaths returns the paths cleaned with forward slashes
+func cleanPaths(paths []string) []string {
+	out := make([]string, len(paths))
+	for i, p := range paths {
+		out[i] = filepath.ToSlash(filepath.Clean(p))
+	}
+	return out
+}
+
+// matchesPaths returns true if the file is one of the paths or within one
+func matchesPaths(paths []string, file string) bool {
+	for _, p := range paths {
+		if p == "." || file == p || strings.HasPrefix(file, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// hashFiles returns the hex sha256 of the names and contents of the files
+// relative to root.  Missing files are hashed by name only
+func hashFiles(root string, files []string) (string, error) {
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, file := range files {
+		h.Write([]byte(file))
+		h.Write([]byte{0})
+
+		fh, err := os.Open(filepath.Join(root, filepath.FromSlash(file)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", err
+		}
+		_, err = io.Copy(h, fh)
+		fh.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
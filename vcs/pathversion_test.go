@@ -0,0 +1,118 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package vcs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func Test_GetPathsVersion(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "pv-")
+	defer os.RemoveAll(tmpdir)
+
+	_, err := GetPathsVersion(tmpdir, []string{"."})
+	assert.NotNil(t, err)
+
+	_, repo, _ := SetupLocalGitRepo("test", "me", tmpdir, "foo.com")
+	wt, _ := repo.Worktree()
+	tagger := &object.Signature{Name: "thrap", Email: "thrap", When: time.Now()}
+
+	write := func(file, content string) {
+		fpath := filepath.Join(tmpdir, file)
+		os.MkdirAll(filepath.Dir(fpath), 0755)
+		ioutil.WriteFile(fpath, []byte(content), 0644)
+	}
+	commit := func(file, content string) {
+		write(file, content)
+		wt.Add(file)
+		wt.Commit("update "+file, committer())
+	}
+
+	commit("api/main.go", "package main")
+	commit("web/index.html", "<html>")
+	CreateTag(tmpdir, "v1.0.0", "v1.0.0", tagger)
+
+	api, err := GetPathsVersion(tmpdir, []string{"api", "api.dockerfile"})
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(api, "v0.0.0-"), api)
+
+	web, _ := GetPathsVersion(tmpdir, []string{"./web/"})
+	assert.Equal(t, "v1.0.0", web)
+
+	// Only changes to the paths change the version
+	commit("web/app.js", "app")
+	ver, _ := GetPathsVersion(tmpdir, []string{"api", "api.dockerfile"})
+	assert.Equal(t, api, ver)
+	ver, _ = GetPathsVersion(tmpdir, []string{"web"})
+	assert.NotEqual(t, web, ver)
+	assert.True(t, strings.HasPrefix(ver, "v1.0.0-1-"), ver)
+
+	commit("api.dockerfile", "FROM scratch")
+	ver, _ = GetPathsVersion(tmpdir, []string{"api", "api.dockerfile"})
+	assert.True(t, strings.HasPrefix(ver, "v1.0.0-2-"), ver)
+
+	// Uncommitted changes
+	write("api/main.go", "package main\n")
+	dirty, _ := GetPathsVersion(tmpdir, []string{"api"})
+	assert.Contains(t, dirty, "-dirty.")
+	again, _ := GetPathsVersion(tmpdir, []string{"api"})
+	assert.Equal(t, dirty, again)
+
+	write("api/main.go", "package main\n\n")
+	again, _ = GetPathsVersion(tmpdir, []string{"api"})
+	assert.NotEqual(t, dirty, again)
+
+	ver, _ = GetPathsVersion(tmpdir, []string{"web"})
+	assert.NotContains(t, ver, "-dirty.")
+}
+
+func Test_GetPathsVersions(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "pv-")
+	defer os.RemoveAll(tmpdir)
+
+	_, repo, _ := SetupLocalGitRepo("test", "me", tmpdir, "foo.com")
+	wt, _ := repo.Worktree()
+	tagger := &object.Signature{Name: "thrap", Email: "thrap", When: time.Now()}
+
+	commit := func(file, content string) {
+		fpath := filepath.Join(tmpdir, file)
+		os.MkdirAll(filepath.Dir(fpath), 0755)
+		ioutil.WriteFile(fpath, []byte(content), 0644)
+		wt.Add(file)
+		wt.Commit("update "+file, committer())
+	}
+
+	commit("api/main.go", "package main")
+	commit("web/index.html", "<html>")
+	CreateTag(tmpdir, "v1.0.0", "v1.0.0", tagger)
+	commit("web/app.js", "app")
+
+	sets := map[string][]string{
+		"api":  {"api"},
+		"web":  {"web"},
+		"none": {"docs"},
+	}
+	vers, err := GetPathsVersions(tmpdir, sets)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(vers))
+
+	for name, paths := range sets {
+		ver, _ := GetPathsVersion(tmpdir, paths)
+		assert.Equal(t, ver, vers[name], name)
+	}
+	assert.True(t, strings.HasPrefix(vers["web"], "v1.0.0-1-"), vers["web"])
+	// Untouched paths get the HEAD version
+	assert.Equal(t, vers["web"], vers["none"])
+}
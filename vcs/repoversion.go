@@ -7,23 +7,39 @@ Sniperkit-Bot
 package vcs
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
-	"io"
+	"strconv"
+	"strings"
 
+	version "github.com/hashicorp/go-version"
 	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
 )
 
 const (
 	defaultVersionTag = "v0.0.0"
 )
 
+var (
+	errTagNotSemver   = errors.New("tag is not a semantic version")
+	errInvalidBump    = errors.New("bump must be one of: major, minor, patch")
+	errTagExists      = errors.New("tag already exists")
+	errPreReleaseName = errors.New("pre-release must be alphanumeric")
+)
+
 // RepoVersion is the calculate repo / project / stack version
 type RepoVersion struct {
-	// Last known tag.  Defaults to v0.0.0
+	// Greatest semver tag reachable from HEAD.  If there are no semver tags
+	// the nearest tag is used.  Defaults to v0.0.0
 	Tag string
+	// Parsed tag.  This is nil if the tag is not a semantic version
+	Version *version.Version
+	// Commit the tag points to.  This is zero if no tag was found
+	TagHash plumbing.Hash
 	// Count from the tag
 	Count int
 	// Last commit hash
@@ -37,73 +53,277 @@ func (rver RepoVersion) String() string {
 	return fmt.Sprintf("%s-%d-%s", rver.Tag, rver.Count, rver.Hash.String()[:8])
 }
 
-// returns nil if tags cannot be found
-func getLatestTag(repo *git.Repository) (lastTag *plumbing.Reference) {
-	tags, _ := repo.Tags()
+// IsPrerelease returns true if the tag is a semver pre-release
+func (rver RepoVersion) IsPrerelease() bool {
+	return rver.Version != nil && rver.Version.Prerelease() != ""
+}
+
+// Next returns the next release tag after bumping the major, minor or patch
+// part of the version.  Bumping a pre-release to the part it precedes
+// releases it i.e. v1.1.0-rc.2 + minor = v1.1.0.  An empty bump releases a
+// pre-release or bumps the patch otherwise.  If pre is set a pre-release of
+// the bumped version is returned, incrementing the pre-release number if the
+// current version is already one i.e. v1.1.0-rc.1 + minor/rc = v1.1.0-rc.2
+func (rver RepoVersion) Next(bump, pre string) (string, error) {
+	if rver.Version == nil {
+		return "", errTagNotSemver
+	}
+	if pre != "" && !isAlphanumeric(pre) {
+		return "", errPreReleaseName
+	}
+
+	var (
+		segs    = rver.Version.Segments()
+		curPre  = rver.Version.Prerelease()
+		isPre   = curPre != ""
+		major   = segs[0]
+		minor   = segs[1]
+		patch   = segs[2]
+		nextPre = 1
+	)
+
+	switch bump {
+	case "major":
+		if !isPre || minor != 0 || patch != 0 {
+			major, minor, patch = major+1, 0, 0
+		}
+	case "minor":
+		if !isPre || patch != 0 {
+			minor, patch = minor+1, 0
+		}
+	case "patch", "":
+		if !isPre {
+			patch++
+		}
+	default:
+		return "", errInvalidBump
+	}
+
+	next := fmt.Sprintf("%d.%d.%d", major, minor, patch)
+	if pre != "" {
+		// Continue the numbering of the current pre-release of the same
+		// version
+		if isPre && next == fmt.Sprintf("%d.%d.%d", segs[0], segs[1], segs[2]) {
+			if id, n, ok := splitPrerelease(curPre); ok && id == pre {
+				nextPre = n + 1
+			}
+		}
+		next += fmt.Sprintf("-%s.%d", pre, nextPre)
+	}
+
+	if strings.HasPrefix(rver.Tag, "v") {
+		next = "v" + next
+	}
+	return next, nil
+}
+
+// splitPrerelease splits a pre-release of the form <id>.<n>
+func splitPrerelease(pre string) (string, int, bool) {
+	i := strings.LastIndex(pre, ".")
+	if i < 0 {
+		return pre, 0, true
+	}
+	n, err := strconv.Atoi(pre[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return pre[:i], n, true
+}
 
-	for {
-		t, err := tags.Next()
-		if err != nil {
-			break
+func isAlphanumeric(s string) bool {
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			return false
 		}
+	}
+	return true
+}
+
+// parseTagVersion returns the semantic version of the tag or nil if it is
+// not one
+func parseTagVersion(tag string) *version.Version {
+	if len(tag) == 0 || !(tag[0] == 'v' || (tag[0] >= '0' && tag[0] <= '9')) {
+		return nil
+	}
+	ver, err := version.NewVersion(tag)
+	if err != nil {
+		return nil
+	}
+	return ver
+}
+
+// tagsByCommit returns all tags keyed by the commit they point to.
+// Annotated tags are resolved to their commit
+func tagsByCommit(repo *git.Repository) map[plumbing.Hash][]string {
+	out := make(map[plumbing.Hash][]string)
 
-		lastTag = t
+	tags, err := repo.Tags()
+	if err != nil {
+		return out
 	}
 
-	return
+	tags.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		if to, err := repo.TagObject(hash); err == nil {
+			hash = to.Target
+		}
+		out[hash] = append(out[hash], ref.Name().Short())
+		return nil
+	})
+
+	return out
 }
 
-// GetRepoVersion returns the latest tag, the count from that tag and the hash
-// of the last commit
+// GetRepoVersion returns the greatest semver tag reachable from HEAD, the
+// count from that tag and the hash of the last commit
 func GetRepoVersion(path string) RepoVersion {
+	ver, _ := ReadRepoVersion(path)
+	return ver
+}
+
+// ReadRepoVersion is the same as GetRepoVersion but returns any error
+// encountered
+func ReadRepoVersion(path string) (RepoVersion, error) {
 	repo, err := git.PlainOpen(path)
 	if err != nil {
-		return RepoVersion{Tag: defaultVersionTag}
+		return RepoVersion{Tag: defaultVersionTag, Version: parseTagVersion(defaultVersionTag)}, err
 	}
-	ver, _ := getRepoVersion(repo)
-	return ver
+	return getRepoVersion(repo)
 }
 
 func getRepoVersion(repo *git.Repository) (RepoVersion, error) {
-	rv := RepoVersion{Tag: defaultVersionTag}
-
 	head, err := repo.Head()
 	if err != nil {
-		return rv, err
+		return RepoVersion{Tag: defaultVersionTag, Version: parseTagVersion(defaultVersionTag)}, err
+	}
+	return versionAt(repo, head.Hash(), false)
+}
+
+// versionAt returns the version of the commit.  If skipSelf is true tags on
+// the commit itself are ignored, returning the version prior to it
+func versionAt(repo *git.Repository, hash plumbing.Hash, skipSelf bool) (RepoVersion, error) {
+	rv := RepoVersion{
+		Tag:     defaultVersionTag,
+		Version: parseTagVersion(defaultVersionTag),
+		Hash:    hash,
 	}
-	rv.Hash = head.Hash()
 
-	iter, err := repo.Log(&git.LogOptions{From: rv.Hash})
+	iter, err := repo.Log(&git.LogOptions{From: hash})
 	if err != nil {
 		return rv, err
 	}
 
 	var (
-		cmtHash   plumbing.Hash
-		latestTag = getLatestTag(repo)
+		tags = tagsByCommit(repo)
+		// Number of commits walked
+		i int
+		// Nearest tag used when there are no semver tags
+		nearest      string
+		nearestCount int
+		nearestHash  plumbing.Hash
+		// Whether a semver tag was found
+		found bool
 	)
 
-	if latestTag != nil {
-		rv.Tag = latestTag.Name().Short()
-
-		to, _ := repo.TagObject(latestTag.Hash())
-		if to != nil {
-			// Annotated tag
-			cmtHash = to.Target
-		} else {
-			// Tag with no annotation
-			cmtHash = latestTag.Hash()
+	iter.ForEach(func(c *object.Commit) error {
+		if skipSelf && c.Hash == hash {
+			i++
+			return nil
 		}
 
-	}
+		for _, tag := range tags[c.Hash] {
+			ver := parseTagVersion(tag)
+			if ver == nil {
+				if nearest == "" {
+					nearest, nearestCount, nearestHash = tag, i, c.Hash
+				}
+				continue
+			}
 
-	iter.ForEach(func(c *object.Commit) error {
-		if bytes.Compare(cmtHash[:], c.Hash[:]) == 0 {
-			return io.EOF
+			if !found || ver.GreaterThan(rv.Version) {
+				rv.Tag, rv.Version, rv.Count = tag, ver, i
+				rv.TagHash = c.Hash
+				found = true
+			}
 		}
-		rv.Count++
+		i++
 		return nil
 	})
 
+	switch {
+	case found:
+	case nearest != "":
+		rv.Tag, rv.Version, rv.Count = nearest, nil, nearestCount
+		rv.TagHash = nearestHash
+	default:
+		rv.Count = i
+	}
+
 	return rv, nil
 }
+
+// CreateTag creates an annotated tag of the HEAD commit of the repo at path
+func CreateTag(path, name, message string, tagger *object.Signature) (*plumbing.Reference, error) {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	refName := plumbing.ReferenceName("refs/tags/" + name)
+	if _, err = repo.Reference(refName, false); err == nil {
+		return nil, fmt.Errorf("%s: %v", name, errTagExists)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	tag := &object.Tag{
+		Name:       name,
+		Tagger:     *tagger,
+		Message:    message,
+		TargetType: plumbing.CommitObject,
+		Target:     head.Hash(),
+	}
+
+	obj := repo.Storer.NewEncodedObject()
+	if err = tag.Encode(obj); err != nil {
+		return nil, err
+	}
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := plumbing.NewHashReference(refName, hash)
+	err = repo.Storer.SetReference(ref)
+	return ref, err
+}
+
+// PushTag pushes the tag to the remote.  The token is used to authenticate
+// against http remotes, otherwise the transport default is used
+func PushTag(path, remote, name, token string) error {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	rmt, err := repo.Remote(remote)
+	if err != nil {
+		return err
+	}
+
+	refName := "refs/tags/" + name
+	opt := &git.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{config.RefSpec(refName + ":" + refName)},
+	}
+
+	urls := rmt.Config().URLs
+	if token != "" && len(urls) > 0 && strings.HasPrefix(urls[0], "http") {
+		opt.Auth = &githttp.BasicAuth{Username: "thrap", Password: token}
+	}
+
+	return repo.Push(opt)
+}
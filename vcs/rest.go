@@ -0,0 +1,134 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package vcs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned when a vcs provider api responds with a non-2xx
+// status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("vcs api: %d %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound returns true if the error is a not found api error
+func IsNotFound(err error) bool {
+	if e, ok := err.(*APIError); ok {
+		return e.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// restClient is a minimal json api client shared by self-hostable providers
+type restClient struct {
+	// Base url including the api path prefix
+	base string
+	// Authorization header name and value set on each request
+	authHeader string
+	authValue  string
+
+	client *http.Client
+}
+
+// baseURL returns the url for the address.  https is assumed if a scheme is
+// not specified
+func baseURL(addr, apiPath string) string {
+	addr = strings.TrimSuffix(addr, "/")
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		addr = "https://" + addr
+	}
+	return addr + apiPath
+}
+
+// do performs the request json encoding in and decoding the response into
+// out if not nil
+func (rc *restClient) do(method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, rc.base+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if rc.authValue != "" {
+		req.Header.Set(rc.authHeader, rc.authValue)
+	}
+
+	client := rc.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := ioutil.ReadAll(resp.Body)
+		return &APIError{StatusCode: resp.StatusCode, Message: apiErrorMessage(b)}
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// apiErrorMessage extracts the message from a json error body falling back to
+// the raw body
+func apiErrorMessage(b []byte) string {
+	var m struct {
+		Message interface{} `json:"message"`
+		Error   string      `json:"error"`
+	}
+	if json.Unmarshal(b, &m) == nil {
+		if m.Message != nil {
+			return fmt.Sprint(m.Message)
+		}
+		if m.Error != "" {
+			return m.Error
+		}
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// confString returns the string value of key from the config
+func confString(conf map[string]interface{}, key string) (string, error) {
+	iface, ok := conf[key]
+	if !ok {
+		return "", nil
+	}
+	s, ok := iface.(string)
+	if !ok {
+		return "", fmt.Errorf("'%s' must be a string", key)
+	}
+	return s, nil
+}
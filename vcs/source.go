@@ -0,0 +1,217 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package vcs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+var (
+	errRefNotFound  = errors.New("ref not found")
+	errRefAmbiguous = errors.New("ambiguous short commit")
+
+	hexRe = regexp.MustCompile("^[0-9a-f]{4,40}$")
+)
+
+// SourceDir returns a stable directory under root to cache a clone of the
+// repo url in i.e. github.com-org-repo-0a1b2c3d
+func SourceDir(root, url string) string {
+	sum := sha256.Sum256([]byte(url))
+
+	name := url
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	}
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, ".git")
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == ':' || r == '\\' {
+			return '-'
+		}
+		return r
+	}, name)
+
+	return filepath.Join(root, name+"-"+hex.EncodeToString(sum[:4]))
+}
+
+// SyncSource clones the repo at url into dir or fetches into an existing
+// clone, then checks out the commit ref points to.  The ref may be a branch,
+// tag, full ref name or a full or short commit hash.  The token is used to
+// authenticate against http remotes, otherwise the transport default is used.
+// It returns the checked out commit and the branch if the ref names one
+func SyncSource(ctx context.Context, dir, url, ref, token string) (plumbing.Hash, string, error) {
+	var auth transport.AuthMethod
+	if token != "" && strings.HasPrefix(url, "http") {
+		auth = &githttp.BasicAuth{Username: "thrap", Password: token}
+	}
+
+	repo, err := git.PlainOpen(dir)
+	switch err {
+	case nil:
+		err = repo.FetchContext(ctx, &git.FetchOptions{Auth: auth, Tags: git.AllTags, Force: true})
+		if err == git.NoErrAlreadyUpToDate {
+			err = nil
+		}
+
+	case git.ErrRepositoryNotExists:
+		repo, err = git.PlainCloneContext(ctx, dir, false, &git.CloneOptions{
+			URL:        url,
+			Auth:       auth,
+			NoCheckout: true,
+		})
+	}
+	if err != nil {
+		return plumbing.ZeroHash, "", err
+	}
+
+	// The local HEAD of a reused clone is the commit previously checked out
+	// so the default branch is taken from the remote
+	name := ref
+	if name == "" || name == "HEAD" {
+		if name, err = remoteHeadRef(repo, auth); err != nil {
+			return plumbing.ZeroHash, "", fmt.Errorf("HEAD: %v", err)
+		}
+	}
+
+	hash, branch, err := resolveSourceRef(repo, name)
+	if err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("%s: %v", ref, err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, "", err
+	}
+
+	err = wt.Checkout(&git.CheckoutOptions{Hash: hash, Force: true})
+	return hash, branch, err
+}
+
+// remoteHeadRef returns the ref the default branch of the origin remote is
+// fetched to, or its commit if the remote does not advertise the branch
+func remoteHeadRef(repo *git.Repository, auth transport.AuthMethod) (string, error) {
+	remote, err := repo.Remote(defaultRemoteName)
+	if err != nil {
+		return "", err
+	}
+
+	refs, err := remote.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", err
+	}
+
+	for _, r := range refs {
+		if r.Name() != plumbing.HEAD {
+			continue
+		}
+		if r.Type() == plumbing.SymbolicReference {
+			return "refs/remotes/" + defaultRemoteName + "/" + r.Target().Short(), nil
+		}
+		return r.Hash().String(), nil
+	}
+	return "", errRefNotFound
+}
+
+// resolveSourceRef returns the commit of the ref in a clone and the branch
+// name if it is one.  Branches are resolved against the origin remote as
+// only the default branch is local
+func resolveSourceRef(repo *git.Repository, ref string) (plumbing.Hash, string, error) {
+	names := []string{ref}
+	if !strings.HasPrefix(ref, "refs/") && ref != "HEAD" {
+		names = []string{
+			"refs/remotes/" + defaultRemoteName + "/" + ref,
+			"refs/tags/" + ref,
+		}
+	}
+
+	for _, name := range names {
+		r, err := repo.Reference(plumbing.ReferenceName(name), true)
+		if err != nil {
+			continue
+		}
+
+		hash := r.Hash()
+		// Annotated tags point to a tag object
+		if tag, err := repo.TagObject(hash); err == nil {
+			hash = tag.Target
+		}
+		return hash, sourceBranch(r.Name()), nil
+	}
+
+	if !hexRe.MatchString(ref) {
+		return plumbing.ZeroHash, "", errRefNotFound
+	}
+
+	if len(ref) == 40 {
+		hash := plumbing.NewHash(ref)
+		if _, err := repo.CommitObject(hash); err != nil {
+			return plumbing.ZeroHash, "", errRefNotFound
+		}
+		return hash, "", nil
+	}
+
+	hash, err := resolveShortHash(repo, ref)
+	return hash, "", err
+}
+
+// sourceBranch returns the branch name of a local or origin remote branch
+// reference.  It returns an empty string for all other references
+func sourceBranch(name plumbing.ReferenceName) string {
+	s := name.String()
+	for _, prefix := range []string{"refs/heads/", "refs/remotes/" + defaultRemoteName + "/"} {
+		if strings.HasPrefix(s, prefix) {
+			return strings.TrimPrefix(s, prefix)
+		}
+	}
+	return ""
+}
+
+// resolveShortHash returns the only commit whose hash starts with prefix
+func resolveShortHash(repo *git.Repository, prefix string) (plumbing.Hash, error) {
+	iter, err := repo.CommitObjects()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	var found []plumbing.Hash
+	err = iter.ForEach(func(c *object.Commit) error {
+		if strings.HasPrefix(c.Hash.String(), prefix) {
+			found = append(found, c.Hash)
+			if len(found) > 1 {
+				return storer.ErrStop
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	switch len(found) {
+	case 0:
+		return plumbing.ZeroHash, errRefNotFound
+	case 1:
+		return found[0], nil
+	}
+	return plumbing.ZeroHash, errRefAmbiguous
+}
@@ -0,0 +1,101 @@
+/*
+Sniperkit-Bot
+- Date: 2018-08-11 22:25:29.898780201 +0200 CEST m=+0.118184110
+- Status: analyzed
+*/
+
+package vcs
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func Test_SourceDir(t *testing.T) {
+	dir := SourceDir("/cache", "https://github.com/org/repo.git")
+	assert.True(t, strings.HasPrefix(dir, "/cache/github.com-org-repo-"), dir)
+	assert.Equal(t, dir, SourceDir("/cache", "https://github.com/org/repo.git"))
+	assert.NotEqual(t, dir, SourceDir("/cache", "https://github.com/org/repo"))
+
+	dir = SourceDir("/cache", "git@github.com:org/repo.git")
+	assert.True(t, strings.HasPrefix(dir, "/cache/github.com-org-repo-"), dir)
+}
+
+func Test_SyncSource(t *testing.T) {
+	tmpdir, _ := ioutil.TempDir("/tmp", "src-")
+	defer os.RemoveAll(tmpdir)
+
+	origin := filepath.Join(tmpdir, "origin")
+	os.MkdirAll(origin, 0755)
+	_, repo, _ := SetupLocalGitRepo("test", "me", origin, "foo.com")
+	wt, _ := repo.Worktree()
+	tagger := &object.Signature{Name: "thrap", Email: "thrap", When: time.Now()}
+
+	commit := func(content string) plumbing.Hash {
+		ioutil.WriteFile(filepath.Join(origin, "file"), []byte(content), 0644)
+		wt.Add("file")
+		h, _ := wt.Commit(content, committer())
+		return h
+	}
+
+	first := commit("one")
+	CreateTag(origin, "v1.0.0", "v1.0.0", tagger)
+	second := commit("two")
+
+	dir := SourceDir(filepath.Join(tmpdir, "cache"), origin)
+	ctx := context.Background()
+
+	hash, branch, err := SyncSource(ctx, dir, origin, "master", "")
+	assert.Nil(t, err)
+	assert.Equal(t, second, hash)
+	assert.Equal(t, "master", branch)
+	b, _ := ioutil.ReadFile(filepath.Join(dir, "file"))
+	assert.Equal(t, "two", string(b))
+
+	hash, branch, err = SyncSource(ctx, dir, origin, "v1.0.0", "")
+	assert.Nil(t, err)
+	assert.Equal(t, first, hash)
+	assert.Equal(t, "", branch)
+	b, _ = ioutil.ReadFile(filepath.Join(dir, "file"))
+	assert.Equal(t, "one", string(b))
+
+	// Reuses the clone fetching new commits
+	third := commit("three")
+	hash, _, err = SyncSource(ctx, dir, origin, "master", "")
+	assert.Nil(t, err)
+	assert.Equal(t, third, hash)
+
+	hash, _, err = SyncSource(ctx, dir, origin, second.String()[:8], "")
+	assert.Nil(t, err)
+	assert.Equal(t, second, hash)
+
+	hash, _, err = SyncSource(ctx, dir, origin, first.String(), "")
+	assert.Nil(t, err)
+	assert.Equal(t, first, hash)
+
+	// Without a ref the remote default branch is built and not the commit
+	// checked out last
+	fourth := commit("four")
+	hash, branch, err = SyncSource(ctx, dir, origin, "", "")
+	assert.Nil(t, err)
+	assert.Equal(t, fourth, hash)
+	assert.Equal(t, "master", branch)
+	b, _ = ioutil.ReadFile(filepath.Join(dir, "file"))
+	assert.Equal(t, "four", string(b))
+
+	_, _, err = SyncSource(ctx, dir, origin, "nope", "")
+	assert.NotNil(t, err)
+
+	_, err = git.PlainOpen(dir)
+	assert.Nil(t, err)
+}
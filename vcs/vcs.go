@@ -25,6 +25,14 @@ type Repository struct {
 	Private     bool
 }
 
+// Hook is a repository webhook
+type Hook struct {
+	// Endpoint receiving the events
+	URL string
+	// Shared secret used to sign or authenticate deliveries
+	Secret string
+}
+
 // Config hold a VCS config
 type Config struct {
 	// Service providing the vcs functionality
@@ -62,6 +70,38 @@ type VCS interface {
 	ID() string
 }
 
+// IsSupported returns true if the provider id is a supported vcs
+func IsSupported(provider string) bool {
+	switch provider {
+	case "git", "github", "gitlab", "gitea":
+		return true
+	}
+	return false
+}
+
+// HookManager is implemented by remote providers that support repository
+// webhooks
+type HookManager interface {
+	// Add the hook if one with the same url does not exist
+	AddHook(*Repository, *Hook) (interface{}, error)
+	// Remove all hooks with the hook url
+	RemoveHook(*Repository, *Hook) error
+}
+
+// Release is a published release of a repository tag
+type Release struct {
+	Tag        string
+	Name       string
+	Notes      string
+	Prerelease bool
+}
+
+// ReleaseManager is implemented by remote providers that support releases
+type ReleaseManager interface {
+	// Create the release of the tag or update it if it exists
+	PublishRelease(*Repository, *Release) (interface{}, error)
+}
+
 // New returns a new VCS interface based on the given config
 func New(conf *Config) (VCS, error) {
 	var (
@@ -76,6 +116,12 @@ func New(conf *Config) (VCS, error) {
 	case "github":
 		v = newGithubVCS(nil)
 
+	case "gitlab":
+		v = newGitlabVCS(nil)
+
+	case "gitea":
+		v = newGiteaVCS(nil)
+
 	default:
 		err = fmt.Errorf("unsupported vcs: '%s'", conf.Provider)
 